@@ -0,0 +1,433 @@
+package mathx
+
+// This file is for Ball, midpoint-radius balls of real numbers, which
+// carry a rigorous error bound through arithmetic and elementary functions
+// at little more than the cost of the midpoint.
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// radPrec is the precision of the radius of a Ball.
+const radPrec = 30
+
+// Ball is an immutable ball [mid ± rad] of real numbers: a Float midpoint
+// and a 30-bit radius, which is finite or +Inf. Each operation rounds the
+// midpoint of its result to nearest, and rounds up a radius that covers
+// both that rounding and the radii of the operands, so that the result
+// contains the value of the operation at every point of the operands:
+//
+//	x := mathx.BallFromRat(mathx.NewRat(1, 3), 1000)
+//	y := x.Exp().Sin() // 1000 bits, with a radius around 2**-1000
+//
+// Unlike an Interval, only the midpoint is computed at full precision; the
+// radius is computed with a few bits. Arithmetic rounds to the larger
+// precision of the midpoints of the operands, and the functions of a Ball
+// to the precision of its midpoint (or to 64 bits if it has precision 0).
+// A ball whose radius is +Inf contains every real number. The zero value
+// is [0 ± 0].
+type Ball struct {
+	mid, rad *Float
+}
+
+// NewBall returns [mid ± rad], with rad rounded up to 30 bits. It panics
+// with ErrDomain if mid is infinite or rad is negative.
+func NewBall(mid, rad *Float) *Ball {
+	if mid.IsInf() || rad.Sign() < 0 {
+		panic(ErrDomain)
+	}
+	return &Ball{mid, upper(radUp().Set(rad))}
+}
+
+// BallFromFloat returns [x ± 0]. It panics with ErrDomain if x is
+// infinite.
+func BallFromFloat(x *Float) *Ball {
+	return NewBall(x, new(Float))
+}
+
+// BallFromRat returns x rounded to nearest with prec bits, with a radius
+// covering the rounding.
+func BallFromRat(x *Rat, prec uint) *Ball {
+	m := x.Float(prec, big.ToNearestEven)
+	return &Ball{m, roundingErr(m, prec)}
+}
+
+// BallFromInterval returns the least ball with the precision of x that
+// contains x, or one with an infinite radius if x is unbounded.
+func BallFromInterval(x *Interval) *Ball {
+	if !x.IsBounded() {
+		return wholeLine(x.precision())
+	}
+	m := x.Midpoint()
+	up := radUp()
+	return &Ball{m, maxFloat(upper(up.Sub(x.Hi(), m)), upper(up.Sub(m, x.Lo())))}
+}
+
+// PiBall returns π rounded to nearest with prec bits, or 64 bits if prec is
+// 0, with a radius covering the rounding.
+func PiBall(prec uint) *Ball {
+	p := Pi(prec)
+	return &Ball{p, (*Float)(pow2(expo((*big.Float)(p)) - int(p.Prec()) - 1))}
+}
+
+// radUp returns a context rounding radii up.
+func radUp() *FloatContext {
+	return &FloatContext{Prec: radPrec, Mode: big.ToPositiveInf}
+}
+
+// roundingErr returns a bound for the error of m, a value rounded to
+// nearest with prec bits: half an ulp, or 0 if m is exact. A zero that
+// underflowed is within the least positive Float of the value, and an
+// infinity that overflowed is anywhere from it.
+func roundingErr(m *Float, prec uint) *Float {
+	x := (*big.Float)(m)
+	switch {
+	case x.Acc() == big.Exact:
+		return new(Float)
+	case x.Sign() == 0:
+		return (*Float)(smallestFloat(radPrec))
+	case x.IsInf():
+		return (*Float)(newFloat(radPrec).SetInf(false))
+	}
+	return (*Float)(pow2(expo(x) - int(prec) - 1))
+}
+
+// wholeLine returns [0 ± Inf], with a midpoint of the precision.
+func wholeLine(prec uint) *Ball {
+	return &Ball{(*Float)(newFloat(prec)), (*Float)(newFloat(radPrec).SetInf(false))}
+}
+
+// radSum returns the sum of radii, rounded up.
+func radSum(xs ...*Float) *Float {
+	up := radUp()
+	s := new(Float)
+	for _, x := range xs {
+		s = upper(up.Add(s, x))
+	}
+	return s
+}
+
+// radMul returns the product of nonnegative x and y rounded up, with 0 if
+// either is 0.
+func radMul(x, y *Float) *Float {
+	if x.Sign() == 0 || y.Sign() == 0 {
+		return new(Float)
+	}
+	return upper(radUp().Mul(x, y))
+}
+
+// ball returns the ball with midpoint m, rounded to nearest with prec
+// bits, and radius r plus the rounding error of m, or the whole line if m
+// overflowed. A midpoint that underflowed to zero keeps a radius of the
+// least positive Float.
+func ball(m *Float, prec uint, r *Float) *Ball {
+	if m.IsInf() {
+		return wholeLine(prec)
+	}
+	return &Ball{m, radSum(r, roundingErr(m, prec))}
+}
+
+// Mid returns the midpoint of this.
+func (z *Ball) Mid() *Float {
+	if z.mid == nil {
+		return new(Float)
+	}
+	return z.mid
+}
+
+// Rad returns the radius of this.
+func (z *Ball) Rad() *Float {
+	if z.rad == nil {
+		return new(Float)
+	}
+	return z.rad
+}
+
+// Prec returns the precision of this, that of its midpoint.
+func (z *Ball) Prec() uint {
+	return z.Mid().Prec()
+}
+
+// precision returns the precision results are rounded to: that of z, or
+// 64 bits if z has precision 0.
+func (z *Ball) precision() uint {
+	if p := z.Prec(); p != 0 {
+		return p
+	}
+	return 64
+}
+
+// arithPrec returns the precision of the result of arithmetic on z and y.
+func (z *Ball) arithPrec(y *Ball) uint {
+	p := z.Prec()
+	if q := y.Prec(); q > p {
+		p = q
+	}
+	if p == 0 {
+		return 64
+	}
+	return p
+}
+
+// IsExact returns whether the radius of this is 0.
+func (z *Ball) IsExact() bool {
+	return z.Rad().Sign() == 0
+}
+
+// IsFinite returns whether the radius of this is finite.
+func (z *Ball) IsFinite() bool {
+	return !z.Rad().IsInf()
+}
+
+// Interval returns the least interval with the precision of this that
+// contains this.
+func (z *Ball) Interval() *Interval {
+	if !z.IsFinite() {
+		return entire(z.precision())
+	}
+	down, up := outward(z.precision())
+	return &Interval{lower(down.Sub(z.Mid(), z.Rad())), upper(up.Add(z.Mid(), z.Rad()))}
+}
+
+// lowInterval returns an interval of radPrec-bit Floats that contains this,
+// for bounding derivatives.
+func (z *Ball) lowInterval() *Interval {
+	if !z.IsFinite() {
+		return entire(radPrec)
+	}
+	down, up := outward(radPrec)
+	return &Interval{lower(down.Sub(z.Mid(), z.Rad())), upper(up.Add(z.Mid(), z.Rad()))}
+}
+
+// Contains returns whether x is in this.
+func (z *Ball) Contains(x *Float) bool {
+	switch {
+	case x.IsInf():
+		return false
+	case !z.IsFinite():
+		return true
+	}
+	m := (*big.Float)(z.Mid().Neg())
+	d := exactAdd((*big.Float)(x), m)
+	return d.Abs(d).Cmp((*big.Float)(z.Rad())) <= 0
+}
+
+// Overlaps returns whether this and y have a point in common.
+func (z *Ball) Overlaps(y *Ball) bool {
+	_, ok := z.Interval().Intersect(y.Interval())
+	return ok
+}
+
+// RelAccuracyBits returns roughly the number of correct bits of this,
+// log2(|mid|/rad), or the precision for an exact ball; it is negative if
+// the radius exceeds the midpoint.
+func (z *Ball) RelAccuracyBits() int {
+	switch m, r := (*big.Float)(z.Mid()), (*big.Float)(z.Rad()); {
+	case r.Sign() == 0:
+		return int(z.precision())
+	case r.IsInf():
+		return -big.MaxExp
+	case m.Sign() == 0:
+		return -expo(r)
+	default:
+		return expo(m) - expo(r)
+	}
+}
+
+// Add returns this + y.
+func (z *Ball) Add(y *Ball) *Ball {
+	prec := z.arithPrec(y)
+	m := (*Float)(newFloat(prec).Add((*big.Float)(z.Mid()), (*big.Float)(y.Mid())))
+	return ball(m, prec, radSum(z.Rad(), y.Rad()))
+}
+
+// Sub returns this - y.
+func (z *Ball) Sub(y *Ball) *Ball {
+	prec := z.arithPrec(y)
+	m := (*Float)(newFloat(prec).Sub((*big.Float)(z.Mid()), (*big.Float)(y.Mid())))
+	return ball(m, prec, radSum(z.Rad(), y.Rad()))
+}
+
+// Neg returns -this.
+func (z *Ball) Neg() *Ball {
+	return &Ball{z.Mid().Neg(), z.Rad()}
+}
+
+// Abs returns the absolute values of the points of this, in a ball with the
+// same radius.
+func (z *Ball) Abs() *Ball {
+	return &Ball{z.Mid().Abs(), z.Rad()}
+}
+
+// Mul returns this y. For x within r of a and y within s of b,
+// |x y - a b| <= |a| s + |b| r + r s.
+func (z *Ball) Mul(y *Ball) *Ball {
+	prec := z.arithPrec(y)
+	a, b, r, s := z.Mid(), y.Mid(), z.Rad(), y.Rad()
+	m := (*Float)(newFloat(prec).Mul((*big.Float)(a), (*big.Float)(b)))
+	return ball(m, prec, radSum(radMul(a.Abs(), s), radMul(b.Abs(), r), radMul(r, s)))
+}
+
+// Quo returns this / y. If y contains 0, the quotient has an infinite
+// radius. For x within r of a and y within s < |b| of b,
+// |x/y - a/b| <= (|a| s + |b| r) / (|b| (|b| - s)).
+func (z *Ball) Quo(y *Ball) *Ball {
+	prec := z.arithPrec(y)
+	a, b, r, s := z.Mid(), y.Mid(), z.Rad(), y.Rad()
+	if y.Contains(new(Float)) {
+		return wholeLine(prec)
+	}
+	m := (*Float)(newFloat(prec).Quo((*big.Float)(a), (*big.Float)(b)))
+	down := &FloatContext{Prec: radPrec, Mode: big.ToNegativeInf}
+	den := lower(down.Mul(b.Abs(), lower(down.Sub(b.Abs(), s))))
+	num := radSum(radMul(a.Abs(), s), radMul(b.Abs(), r))
+	e := new(Float)
+	if num.Sign() != 0 {
+		e = upper(radUp().Quo(num, den))
+	}
+	return ball(m, prec, e)
+}
+
+// apply returns f(mid) rounded to nearest, with the radius of this times a
+// bound for |f'| over this, where deriv returns an enclosure of f' over an
+// interval.
+func (z *Ball) apply(f func(c *FloatContext, x *Float) *Float, deriv func(x *Interval) *Interval) *Ball {
+	prec := z.precision()
+	m := f(&FloatContext{Prec: prec}, z.Mid())
+	r := new(Float)
+	if !z.IsExact() {
+		r = radMul(z.Rad(), deriv(z.lowInterval()).Abs().Hi())
+	}
+	return ball(m, prec, r)
+}
+
+// unit returns an enclosure of 1 for a derivative bounded by 1.
+func unit(*Interval) *Interval {
+	return IntervalFromFloat(NewFloat(1))
+}
+
+// Sqrt returns the square roots of the points of this that are at least 0.
+// It panics with ErrNaN if the midpoint of this is negative. If this
+// contains 0, the radius of the result is the square root of the largest
+// point.
+func (z *Ball) Sqrt() *Ball {
+	x := z.lowInterval()
+	if x.Lo().Sign() > 0 || z.IsExact() {
+		return z.apply((*FloatContext).Sqrt, func(x *Interval) *Interval {
+			return IntervalFromFloat(NewFloat(0.5)).Quo(x.Sqrt())
+		})
+	}
+	prec := z.precision()
+	m := (&FloatContext{Prec: prec}).Sqrt(z.Mid())
+	// both the midpoint and the roots are in [0, sqrt(hi)]
+	return ball(m, prec, x.Sqrt().Hi())
+}
+
+// Cbrt returns the cube roots of the points of this. If this contains 0,
+// the radius of the result is twice the cube root of the largest absolute
+// value of its points.
+func (z *Ball) Cbrt() *Ball {
+	x := z.lowInterval()
+	if !x.Contains(new(Float)) || z.IsExact() {
+		return z.apply((*FloatContext).Cbrt, func(x *Interval) *Interval {
+			c := x.Abs()
+			c = increasing(c.Lo(), c.Hi(), radPrec, (*FloatContext).Cbrt)
+			return IntervalFromFloat(NewFloat(1)).Quo(c.Sqr().Mul(IntervalFromFloat(NewFloat(3))))
+		})
+	}
+	prec := z.precision()
+	m := (&FloatContext{Prec: prec}).Cbrt(z.Mid())
+	a := x.Abs()
+	c := increasing(a.Lo(), a.Hi(), radPrec, (*FloatContext).Cbrt).Hi()
+	return ball(m, prec, radSum(c, c))
+}
+
+// Exp returns e**x for the points x of this.
+func (z *Ball) Exp() *Ball {
+	return z.apply((*FloatContext).Exp, (*Interval).Exp)
+}
+
+// Log returns the natural logarithms of the points of this. It panics with
+// ErrNaN if the midpoint of this is at most 0, and the result has an
+// infinite radius if this contains 0.
+func (z *Ball) Log() *Ball {
+	if z.Mid().Sign() <= 0 {
+		panic(ErrNaN)
+	}
+	return z.apply((*FloatContext).Log, func(x *Interval) *Interval {
+		return IntervalFromFloat(NewFloat(1)).Quo(x)
+	})
+}
+
+// Sin returns the sines of the points of this.
+func (z *Ball) Sin() *Ball {
+	return z.apply((*FloatContext).Sin, unit)
+}
+
+// Cos returns the cosines of the points of this.
+func (z *Ball) Cos() *Ball {
+	return z.apply((*FloatContext).Cos, unit)
+}
+
+// Tan returns the tangents of the points of this. The result has an
+// infinite radius if this contains a pole.
+func (z *Ball) Tan() *Ball {
+	return z.apply((*FloatContext).Tan, func(x *Interval) *Interval {
+		return x.Tan().Sqr().Add(IntervalFromFloat(NewFloat(1)))
+	})
+}
+
+// Atan returns the arctangents of the points of this.
+func (z *Ball) Atan() *Ball {
+	return z.apply((*FloatContext).Atan, unit)
+}
+
+// Sinh returns the hyperbolic sines of the points of this.
+func (z *Ball) Sinh() *Ball {
+	return z.apply((*FloatContext).Sinh, (*Interval).Cosh)
+}
+
+// Cosh returns the hyperbolic cosines of the points of this.
+func (z *Ball) Cosh() *Ball {
+	return z.apply((*FloatContext).Cosh, (*Interval).Sinh)
+}
+
+// Tanh returns the hyperbolic tangents of the points of this.
+func (z *Ball) Tanh() *Ball {
+	return z.apply((*FloatContext).Tanh, unit)
+}
+
+// Refine evaluates f with increasing precision, from 64 bits and growing
+// by half each time, until the radius of the result is at most tol, and
+// returns that ball and true. f must compute its result from its inputs
+// at the given precision, so that the radius shrinks as the precision
+// grows:
+//
+//	tol := mathx.NewFloat(1).SetExp(-3000)
+//	b, ok := mathx.Refine(func(prec uint) *mathx.Ball {
+//		return mathx.PiBall(prec).Exp().Sub(mathx.BallFromFloat(mathx.NewFloat(20)))
+//	}, tol, 1<<20)
+//
+// It gives up and returns the ball at maxPrec bits and false if that is
+// not within tol.
+func Refine(f func(prec uint) *Ball, tol *Float, maxPrec uint) (*Ball, bool) {
+	for prec := uint(64); ; prec += prec / 2 {
+		if prec > maxPrec {
+			prec = maxPrec
+		}
+		b := f(prec)
+		if b.Rad().Cmp(tol) <= 0 {
+			return b, true
+		}
+		if prec == maxPrec {
+			return b, false
+		}
+	}
+}
+
+// String returns this as "[mid +/- rad]", with the midpoint formatted as
+// by Float.String and the radius with 3 digits.
+func (z *Ball) String() string {
+	return fmt.Sprintf("[%v +/- %.3g]", z.Mid(), z.Rad())
+}
@@ -0,0 +1,214 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// randBall returns a ball with a midpoint from randFloat64 and a radius
+// that is 0 or a small fraction of it.
+func randBall(rnd *rand.Rand) *Ball {
+	m := randFloat64(rnd)
+	r := 0.0
+	if rnd.Intn(3) != 0 {
+		r = math.Ldexp(math.Abs(m), -rnd.Intn(40))
+	}
+	return NewBall(NewFloat(m), NewFloat(r))
+}
+
+// points returns the midpoint of z and its ends, mid ± rad exactly.
+func (z *Ball) points() []*Float {
+	m, r := (*big.Float)(z.Mid()), (*big.Float)(z.Rad())
+	return []*Float{(*Float)(exactAdd(m, new(big.Float).Neg(r))), z.Mid(), (*Float)(exactAdd(m, r))}
+}
+
+func TestBallArithmetic(t *testing.T) {
+	ops := map[string]struct {
+		f     func(x, y *Ball) *Ball
+		exact func(x, y *big.Rat) *big.Rat
+	}{
+		"Add": {(*Ball).Add, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Add(x, y) }},
+		"Sub": {(*Ball).Sub, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Sub(x, y) }},
+		"Mul": {(*Ball).Mul, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) }},
+		"Quo": {(*Ball).Quo, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Quo(x, y) }},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for name, op := range ops {
+		for i := 0; i < 200; i++ {
+			x, y := randBall(rnd), randBall(rnd)
+			got := op.f(x, y)
+			if name == "Quo" && y.Contains(new(Float)) {
+				if got.IsFinite() {
+					t.Errorf("%v / %v = %v", x, y, got)
+				}
+				continue
+			}
+			for _, a := range x.points() {
+				for _, b := range y.points() {
+					v := (*Rat)(op.exact((*big.Rat)(RatFromFloat(a)), (*big.Rat)(RatFromFloat(b))))
+					// the value is between Floats with many more bits
+					lo, hi := v.Float(400, big.ToNegativeInf), v.Float(400, big.ToPositiveInf)
+					if !got.Contains(lo) && !got.Contains(hi) {
+						t.Errorf("%s(%v, %v) = %v does not contain %s(%v, %v)", name, x, y, got, name, a, b)
+					}
+				}
+			}
+			// for exact operands, the radius is at most half an ulp
+			if x.IsExact() && y.IsExact() && got.RelAccuracyBits() < 52 {
+				t.Errorf("%s(%v, %v) = %v", name, x, y, got)
+			}
+		}
+	}
+
+	third := BallFromRat(NewRat(1, 3), 200)
+	if got := third.Mul(BallFromFloat(NewFloat(3))); !got.Contains(NewFloat(1)) || got.RelAccuracyBits() < 198 {
+		t.Errorf("3 (1/3) = %v", got)
+	}
+	if got := third.Add(third).Sub(third); !got.Overlaps(third) {
+		t.Errorf("1/3 + 1/3 - 1/3 = %v", got)
+	}
+}
+
+func TestBallFunctions(t *testing.T) {
+	funcs := map[string]struct {
+		f func(z *Ball) *Ball
+		g func(c *FloatContext, x *Float) *Float
+	}{
+		"Exp":  {(*Ball).Exp, (*FloatContext).Exp},
+		"Sin":  {(*Ball).Sin, (*FloatContext).Sin},
+		"Cos":  {(*Ball).Cos, (*FloatContext).Cos},
+		"Tan":  {(*Ball).Tan, (*FloatContext).Tan},
+		"Atan": {(*Ball).Atan, (*FloatContext).Atan},
+		"Sinh": {(*Ball).Sinh, (*FloatContext).Sinh},
+		"Cosh": {(*Ball).Cosh, (*FloatContext).Cosh},
+		"Tanh": {(*Ball).Tanh, (*FloatContext).Tanh},
+		"Sqrt": {(*Ball).Sqrt, (*FloatContext).Sqrt},
+		"Cbrt": {(*Ball).Cbrt, (*FloatContext).Cbrt},
+		"Log":  {(*Ball).Log, (*FloatContext).Log},
+	}
+	rnd := rand.New(rand.NewSource(2))
+	down, up := outward(400)
+	for name, fn := range funcs {
+		for i := 0; i < 40; i++ {
+			z := randBall(rnd)
+			switch name {
+			case "Exp", "Sinh", "Cosh":
+				z = z.Quo(BallFromFloat(NewFloat(64)))
+			case "Sqrt", "Log":
+				z = z.Abs()
+			}
+			if name == "Log" && z.Mid().Sign() == 0 {
+				continue
+			}
+			got := fn.f(z)
+			in := got.Interval()
+			for _, x := range z.points() {
+				if name == "Log" && x.Sign() <= 0 || name == "Sqrt" && x.Sign() < 0 {
+					continue
+				}
+				if in.Lo().Cmp(fn.g(up, x)) > 0 || in.Hi().Cmp(fn.g(down, x)) < 0 {
+					t.Errorf("%s(%v) = %v does not contain %s(%v) = %v", name, z, got, name, x, fn.g(up, x))
+				}
+			}
+		}
+
+		// at high precision, exact inputs keep nearly every bit
+		x := NewFloat(0.75).SetPrec(1000)
+		got := fn.f(BallFromFloat(x))
+		if got.Prec() != 1000 || got.RelAccuracyBits() < 998 {
+			t.Errorf("%s(%v) = %v (%d bits, %d correct)", name, x, got, got.Prec(), got.RelAccuracyBits())
+		}
+		if want := fn.g(&FloatContext{Prec: 1000}, x); got.Mid().Cmp(want) != 0 {
+			t.Errorf("%s(%v) has midpoint %v, expected %v", name, x, got.Mid(), want)
+		}
+	}
+}
+
+func TestBallSpecial(t *testing.T) {
+	b := func(m, r float64) *Ball { return NewBall(NewFloat(m), NewFloat(r)) }
+	cases := []struct {
+		name   string
+		got    *Ball
+		finite bool
+		in     []float64
+	}{
+		{"1 / [0 +/- 1]", b(1, 0).Quo(b(0, 1)), false, nil},
+		{"Log([1 +/- 2])", b(1, 2).Log(), false, nil},
+		{"Tan([1.5 +/- 0.1])", b(1.5, 0.1).Tan(), false, nil},
+		{"Sqrt([1 +/- 2])", b(1, 2).Sqrt(), true, []float64{0, 1, math.Sqrt(3)}},
+		{"Cbrt([0 +/- 8])", b(0, 8).Cbrt(), true, []float64{-2, 0, 2}},
+		{"Sin([0 +/- 10])", b(0, 10).Sin(), true, []float64{-1, 0, 1}},
+		{"Exp([0 +/- 0])", new(Ball).Exp(), true, []float64{1}},
+		{"Exp(1e10)", b(1e10, 0).Exp(), false, nil},
+	}
+	// e**-1e10 underflows, but the ball must still contain it
+	if got := b(-1e10, 0).Exp(); got.IsExact() || !got.Contains((*Float)(smallestFloat(64))) || got.Contains(NewFloat(-1)) {
+		t.Errorf("Exp(-1e10) = %v", got)
+	}
+	for _, c := range cases {
+		if c.got.IsFinite() != c.finite {
+			t.Errorf("%s = %v", c.name, c.got)
+		}
+		for _, x := range c.in {
+			if !c.got.Contains(NewFloat(x)) {
+				t.Errorf("%s = %v does not contain %v", c.name, c.got, x)
+			}
+		}
+	}
+
+	pi := PiBall(100)
+	if p := Pi(300); !pi.Contains(p) && !pi.Contains(p.NextUp()) {
+		t.Errorf("PiBall(100) = %v", pi)
+	}
+	if got := pi.Sin(); !got.Contains(new(Float)) || got.RelAccuracyBits() > 0 {
+		t.Errorf("Sin(%v) = %v", pi, got)
+	}
+	if got := pi.Interval(); got.Width().Cmp(NewFloat(1).SetExp(-96)) > 0 || BallFromInterval(got).Rad().Cmp(pi.Rad()) < 0 {
+		t.Errorf("PiBall(100) is in %v", got)
+	}
+	if s := b(1.5, 0.25).String(); s != "[1.5 +/- 0.25]" {
+		t.Errorf("String() = %q", s)
+	}
+
+	for name, f := range map[string]func(){
+		"NewBall(1, -1)":   func() { b(1, -1) },
+		"Log([-1 +/- 2])":  func() { b(-1, 2).Log() },
+		"Sqrt([-1 +/- 0])": func() { b(-1, 0).Sqrt() },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrDomain && r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+			t.Errorf("%s did not panic", name)
+		}()
+	}
+}
+
+func TestBallRefine(t *testing.T) {
+	// e**Pi - 20 loses about 4 bits to cancellation
+	tol := NewFloat(1).SetExp(-3000)
+	got, ok := Refine(func(prec uint) *Ball {
+		return PiBall(prec).Exp().Sub(BallFromFloat(NewFloat(20)))
+	}, tol, 1<<20)
+	if !ok || got.Rad().Cmp(tol) > 0 {
+		t.Fatalf("e**Pi - 20 = %v", got)
+	}
+	ctx := &FloatContext{Prec: 3100}
+	want := ctx.Sub(ctx.Exp(Pi(3200)), NewFloat(20))
+	if d := ctx.Sub(got.Mid(), want).Abs(); d.Cmp(ctx.Add(got.Rad(), NewFloat(1).SetExp(-3050))) > 0 {
+		t.Errorf("e**Pi - 20 = %v, expected %v", got, want)
+	}
+
+	// a ball that never narrows is returned at the largest precision
+	got, ok = Refine(func(prec uint) *Ball {
+		return NewBall(NewFloat(1).SetPrec(prec), NewFloat(1))
+	}, tol, 1000)
+	if ok || got.Prec() != 1000 {
+		t.Errorf("Refine gave %v (%d bits), %v", got, got.Prec(), ok)
+	}
+}
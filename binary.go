@@ -0,0 +1,333 @@
+package mathx
+
+// This file is for the binary serialization format shared by all of the
+// numeric types in this repository.
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+)
+
+/*
+Binary format
+
+Every value produced by a MarshalBinary method in this repository is a
+self-describing record:
+
+	version  1 byte   BinaryVersion (currently 1)
+	tag      1 byte   the type of the value (the Tag constants below)
+	length   uvarint  the number of bytes in the payload
+	payload  length bytes
+
+Integers inside payloads are unsigned or zig-zag signed varints as in
+encoding/binary, and magnitudes are big-endian bytes without leading
+zeros. The payloads are:
+
+	TagInt:        sign byte (1 if negative, else 0), magnitude
+	TagFloat:      rounding mode byte, uvarint precision, form byte (0 for
+	               zero, 1 for finite, 2 for infinite), sign byte, and for
+	               finite values a varint exponent e and magnitude m, with
+	               value m * 2**e (m is odd when written, but decoders
+	               accept any m of at most precision bits)
+	TagPolynomial: uvarint count, then each coefficient as a TagInt record,
+	               constant term first
+	TagDecimal:    sign byte, uvarint whole digit count, uvarint fraction
+	               digit count, magnitude of the digits as one integer
+	TagExpFloat:   sign byte (1 if negative), uvarint precision, varint
+	               exponent e, magnitude m, with value m * 2**e
+
+New fields will only be added by a new version, and decoders reject
+versions they do not know. Records can be concatenated, which is what
+Encoder and Decoder do.
+*/
+
+// BinaryVersion is the version of the binary format written by this package.
+const BinaryVersion = 1
+
+// Type tags in the binary format.
+const (
+	TagInt        byte = 'I' // mathx.Int
+	TagFloat      byte = 'F' // mathx.Float
+	TagPolynomial byte = 'P' // poly.IntPolynomial
+	TagDecimal    byte = 'D' // decimal.Decimal
+	TagExpFloat   byte = 'X' // experimental float.Float
+)
+
+// ErrBinaryFormat is returned when decoding malformed binary data.
+var ErrBinaryFormat = errors.New("mathx: malformed binary data")
+
+// AppendBinaryRecord appends a record with the given tag and payload to
+// buf, in the binary format above. It is for implementing MarshalBinary.
+func AppendBinaryRecord(buf []byte, tag byte, payload []byte) []byte {
+	buf = append(buf, BinaryVersion, tag)
+	buf = binary.AppendUvarint(buf, uint64(len(payload)))
+	return append(buf, payload...)
+}
+
+// ReadBinaryRecord splits the first record off of data, checking its
+// version and tag, and returns its payload and the data after it. It is
+// for implementing UnmarshalBinary.
+func ReadBinaryRecord(data []byte, tag byte) (payload, rest []byte, err error) {
+	if len(data) < 3 {
+		return nil, nil, ErrBinaryFormat
+	}
+	if data[0] != BinaryVersion {
+		return nil, nil, errors.New("mathx: unknown binary format version")
+	}
+	if data[1] != tag {
+		return nil, nil, errors.New("mathx: binary data is of the wrong type")
+	}
+	n, k := binary.Uvarint(data[2:])
+	if k <= 0 || n > uint64(len(data)-2-k) {
+		return nil, nil, ErrBinaryFormat
+	}
+	data = data[2+k:]
+	return data[:n], data[n:], nil
+}
+
+// AppendBinaryInt appends the sign and magnitude of x to buf, as in a
+// TagInt payload.
+func AppendBinaryInt(buf []byte, x *big.Int) []byte {
+	if x.Sign() < 0 {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	return append(buf, x.Bytes()...)
+}
+
+// ReadBinaryInt decodes a TagInt payload.
+func ReadBinaryInt(payload []byte) (*big.Int, error) {
+	if len(payload) == 0 || payload[0] > 1 {
+		return nil, ErrBinaryFormat
+	}
+	x := new(big.Int).SetBytes(payload[1:])
+	if payload[0] == 1 {
+		x.Neg(x)
+	}
+	return x, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the format
+// described in this package.
+func (z *Int) MarshalBinary() ([]byte, error) {
+	return AppendBinaryRecord(nil, TagInt, AppendBinaryInt(nil, (*big.Int)(z))), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see
+// DecodeIntBinary for an alternative.
+func (z *Int) UnmarshalBinary(data []byte) error {
+	x, err := DecodeIntBinary(data)
+	if err != nil {
+		return err
+	}
+	(*big.Int)(z).Set((*big.Int)(x))
+	return nil
+}
+
+// DecodeIntBinary returns a new Int decoded from data, as produced by
+// MarshalBinary.
+func DecodeIntBinary(data []byte) (*Int, error) {
+	payload, rest, err := ReadBinaryRecord(data, TagInt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, ErrBinaryFormat
+	}
+	x, err := ReadBinaryInt(payload)
+	return (*Int)(x), err
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the format
+// described in this package. The precision and rounding mode are kept.
+func (z *Float) MarshalBinary() ([]byte, error) {
+	f := (*big.Float)(z)
+	buf := []byte{byte(f.Mode())}
+	buf = binary.AppendUvarint(buf, uint64(f.Prec()))
+	sign := byte(0)
+	if f.Signbit() {
+		sign = 1
+	}
+	switch {
+	case f.Sign() == 0:
+		buf = append(buf, 0, sign)
+	case f.IsInf():
+		buf = append(buf, 2, sign)
+	default:
+		buf = append(buf, 1, sign)
+		// f = mant * 2^exp with 0.5 <= |mant| < 1 and MinPrec bits, so
+		// mant * 2^MinPrec is an odd integer; writing that, rather than
+		// mant * 2^prec, keeps 1.0 short at any precision
+		mant := new(big.Float)
+		exp := f.MantExp(mant)
+		bits := f.MinPrec()
+		mant.SetMantExp(mant, int(bits))
+		m, _ := mant.Int(nil)
+		buf = binary.AppendVarint(buf, int64(exp)-int64(bits))
+		buf = append(buf, m.Bytes()...)
+	}
+	return AppendBinaryRecord(nil, TagFloat, buf), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see
+// DecodeFloatBinary for an alternative.
+func (z *Float) UnmarshalBinary(data []byte) error {
+	x, err := DecodeFloatBinary(data)
+	if err != nil {
+		return err
+	}
+	(*big.Float)(z).SetPrec(x.Prec()).SetMode(x.Mode()).Set((*big.Float)(x))
+	return nil
+}
+
+// DecodeFloatBinary returns a new Float decoded from data, as produced by
+// MarshalBinary, with the original precision and rounding mode.
+func DecodeFloatBinary(data []byte) (*Float, error) {
+	payload, rest, err := ReadBinaryRecord(data, TagFloat)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 || len(payload) < 1 {
+		return nil, ErrBinaryFormat
+	}
+	mode := big.RoundingMode(payload[0])
+	prec, k := binary.Uvarint(payload[1:])
+	if k <= 0 || mode > big.ToPositiveInf || prec > big.MaxPrec {
+		return nil, ErrBinaryFormat
+	}
+	payload = payload[1+k:]
+	if len(payload) < 2 || payload[1] > 1 {
+		return nil, ErrBinaryFormat
+	}
+	form, neg := payload[0], payload[1] == 1
+	f := new(big.Float).SetPrec(uint(prec)).SetMode(mode)
+	switch form {
+	case 0:
+		if neg {
+			f.Neg(f)
+		}
+	case 2:
+		f.SetInf(neg)
+	case 1:
+		exp, k := binary.Varint(payload[2:])
+		if k <= 0 || prec == 0 {
+			return nil, ErrBinaryFormat
+		}
+		m := new(big.Int).SetBytes(payload[2+k:])
+		if m.Sign() == 0 || m.BitLen() > int(prec) || exp < big.MinExp-int64(prec) || exp > big.MaxExp {
+			return nil, ErrBinaryFormat
+		}
+		f.SetInt(m)
+		f.SetMantExp(f, int(exp))
+		if neg {
+			f.Neg(f)
+		}
+	default:
+		return nil, ErrBinaryFormat
+	}
+	return (*Float)(f), nil
+}
+
+// Encoder writes a stream of values in the binary format to a writer.
+type Encoder struct {
+	w *bufio.Writer
+}
+
+// NewEncoder returns an Encoder writing to w. Call Flush when done.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{bufio.NewWriter(w)}
+}
+
+// Encode writes the binary form of v.
+func (e *Encoder) Encode(v encoding.BinaryMarshaler) error {
+	data, err := v.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+// Decoder reads a stream of values in the binary format from a reader.
+type Decoder struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// PeekTag returns the type tag of the next value without consuming it,
+// or io.EOF if there are no more values.
+func (d *Decoder) PeekTag() (byte, error) {
+	b, err := d.r.Peek(2)
+	if err != nil {
+		if err == io.EOF && len(b) > 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	return b[1], nil
+}
+
+// Next returns the next whole record, which is only valid until the next
+// call, or io.EOF if there are no more values.
+func (d *Decoder) Next() ([]byte, error) {
+	head, err := d.r.Peek(2)
+	if err != nil {
+		if err == io.EOF && len(head) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	d.buf = append(d.buf[:0], head...)
+	d.r.Discard(2)
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	d.buf = binary.AppendUvarint(d.buf, n)
+	start := len(d.buf)
+	if n > uint64(1<<62) {
+		return nil, ErrBinaryFormat
+	}
+	for uint64(len(d.buf)-start) < n {
+		// grow gradually, so that a corrupt length cannot allocate much
+		chunk := n - uint64(len(d.buf)-start)
+		if chunk > 1<<20 {
+			chunk = 1 << 20
+		}
+		old := len(d.buf)
+		d.buf = append(d.buf, make([]byte, chunk)...)
+		if _, err := io.ReadFull(d.r, d.buf[old:]); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+	}
+	return d.buf, nil
+}
+
+// Decode reads the next value into v, or returns io.EOF if there are no
+// more values.
+func (d *Decoder) Decode(v encoding.BinaryUnmarshaler) error {
+	data, err := d.Next()
+	if err != nil {
+		return err
+	}
+	return v.UnmarshalBinary(data)
+}
@@ -0,0 +1,157 @@
+package mathx
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestIntBinary(t *testing.T) {
+	huge, _ := NewIntFromString("-93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	for _, x := range []*Int{NewInt(0), NewInt(1), NewInt(-1), NewInt(255), NewInt(-256), huge} {
+		data, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := DecodeIntBinary(data)
+		if err != nil || y.Cmp(x) != 0 {
+			t.Errorf("%v decoded as %v, %v", x, y, err)
+		}
+		z := NewInt(7)
+		if err := z.UnmarshalBinary(data); err != nil || z.Cmp(x) != 0 {
+			t.Errorf("%v unmarshaled as %v, %v", x, z, err)
+		}
+	}
+	// the format is stable
+	data, _ := NewInt(-300).MarshalBinary()
+	if want := []byte{1, 'I', 3, 1, 1, 44}; !bytes.Equal(data, want) {
+		t.Errorf("-300 encoded as %v, expected %v", data, want)
+	}
+}
+
+func TestFloatBinary(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var values []*big.Float
+	for mode := big.ToNearestEven; mode <= big.ToPositiveInf; mode++ {
+		for _, prec := range []uint{0, 1, 24, 53, 64, 200, 1000} {
+			values = append(values,
+				new(big.Float).SetPrec(prec).SetMode(mode),
+				new(big.Float).SetPrec(prec).SetMode(mode).Neg(new(big.Float)),
+				new(big.Float).SetPrec(prec).SetMode(mode).SetInf(true),
+				new(big.Float).SetPrec(prec).SetMode(mode).SetInf(false))
+			if prec == 0 {
+				continue
+			}
+			for i := 0; i < 10; i++ {
+				f := new(big.Float).SetPrec(prec).SetMode(mode).SetInt64(rnd.Int63() - rnd.Int63())
+				f.Quo(f, new(big.Float).SetInt64(rnd.Int63n(1000000)+1))
+				f.SetMantExp(f, rnd.Intn(20000)-10000)
+				values = append(values, f)
+			}
+		}
+	}
+	for _, f := range values {
+		x := (*Float)(f)
+		data, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := DecodeFloatBinary(data)
+		if err != nil {
+			t.Fatalf("%v: %v", f, err)
+		}
+		g := (*big.Float)(y)
+		if g.Cmp(f) != 0 || g.Signbit() != f.Signbit() || g.Prec() != f.Prec() || g.Mode() != f.Mode() {
+			t.Errorf("%v (prec %d, %v) decoded as %v (prec %d, %v)", f, f.Prec(), f.Mode(), g, g.Prec(), g.Mode())
+		}
+		z := NewFloat(7)
+		if err := z.UnmarshalBinary(data); err != nil || (*big.Float)(z).Cmp(f) != 0 || z.Prec() != f.Prec() || z.Mode() != f.Mode() {
+			t.Errorf("%v unmarshaled as %v, %v", f, z, err)
+		}
+	}
+
+	// the size depends on the bits used, not the precision
+	one := NewFloat(1).SetPrec(1000000)
+	if data, _ := one.MarshalBinary(); len(data) > 16 {
+		t.Errorf("1.0 at %d bits encodes as %d bytes", one.Prec(), len(data))
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	good, _ := NewInt(300).MarshalBinary()
+	bad := [][]byte{
+		nil,
+		{1},
+		{2, 'I', 1, 0},                        // unknown version
+		{1, 'F', 1, 0},                        // wrong tag
+		{1, 'I', 5, 0},                        // truncated
+		{1, 'I', 0},                           // empty payload
+		{1, 'I', 1, 2},                        // bad sign
+		append(good[:len(good):len(good)], 0), // trailing data
+	}
+	for _, data := range bad {
+		if _, err := DecodeIntBinary(data); err == nil {
+			t.Errorf("decoding %v should fail", data)
+		}
+	}
+	badFloat := [][]byte{
+		{1, 'F', 3, 9, 53, 0},         // bad mode
+		{1, 'F', 4, 0, 53, 3, 0},      // bad form
+		{1, 'F', 6, 0, 2, 1, 0, 0, 7}, // mantissa longer than precision
+		{1, 'F', 5, 0, 0, 1, 0, 0},    // finite with zero precision
+	}
+	for _, data := range badFloat {
+		if _, err := DecodeFloatBinary(data); err == nil {
+			t.Errorf("decoding %v should fail", data)
+		}
+	}
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	ints := []*Int{NewInt(0), NewInt(-5), NewInt(1).Lsh(10000)}
+	pi, _, _ := ParseFloat("3.14159265358979323846264338327950288", 10, 120, big.ToZero)
+	for _, x := range ints {
+		if err := enc.Encode(x); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Encode(pi); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := NewDecoder(&buf)
+	for _, x := range ints {
+		if tag, err := dec.PeekTag(); err != nil || tag != TagInt {
+			t.Fatalf("PeekTag gave %q, %v", tag, err)
+		}
+		y := new(Int)
+		if err := dec.Decode(y); err != nil || y.Cmp(x) != 0 {
+			t.Errorf("decoded %v, %v, expected %v", y, err, x)
+		}
+	}
+	if tag, err := dec.PeekTag(); err != nil || tag != TagFloat {
+		t.Fatalf("PeekTag gave %q, %v", tag, err)
+	}
+	f := new(Float)
+	if err := dec.Decode(f); err != nil || f.Cmp(pi) != 0 || f.Prec() != 120 || f.Mode() != big.ToZero {
+		t.Errorf("decoded %v, %v, expected %v", f, err, pi)
+	}
+	if _, err := dec.PeekTag(); err != io.EOF {
+		t.Errorf("PeekTag at end gave %v", err)
+	}
+	if err := dec.Decode(new(Int)); err != io.EOF {
+		t.Errorf("Decode at end gave %v", err)
+	}
+
+	dec = NewDecoder(bytes.NewReader([]byte{1, 'I', 5, 0, 1}))
+	if err := dec.Decode(new(Int)); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode of truncated stream gave %v", err)
+	}
+}
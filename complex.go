@@ -0,0 +1,425 @@
+package mathx
+
+// This file is for Complex, the immutable complex numbers with Float
+// parts, and their arithmetic, conversions and formatting.
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Complex is an immutable arbitrary-precision complex number, with a Float
+// real part and a Float imaginary part. It has the same two-argument API
+// as Float:
+//
+//	z := mathx.NewComplex(mathx.NewFloat(1), mathx.NewFloat(2))
+//	w := z.Mul(z.Conj()) // (5+0i)
+//
+// The precision of a Complex is the larger of the precisions of its parts,
+// and its rounding mode is that of its real part. Functions of a Complex
+// round both parts of their result to its precision (or to 64 bits if it
+// has precision 0) and mode, as the functions of a Float do; arithmetic
+// rounds to the larger precision of the operands, in the mode of the
+// receiver. Each part of each result is correctly rounded. The zero value
+// is 0.
+type Complex struct {
+	re, im *Float
+}
+
+// NewComplex returns re + im i.
+func NewComplex(re, im *Float) *Complex {
+	return &Complex{re, im}
+}
+
+// ComplexFromComplex128 returns c as a Complex with 53-bit parts. It
+// panics with ErrNaN if either part of c is NaN.
+func ComplexFromComplex128(c complex128) *Complex {
+	return &Complex{(*Float)(bigFloat64(real(c))), (*Float)(bigFloat64(imag(c)))}
+}
+
+// Complex128 returns the complex128 whose parts are nearest to those of
+// this, with ties to even.
+func (z *Complex) Complex128() complex128 {
+	re, _ := z.Real().Float64()
+	im, _ := z.Imag().Float64()
+	return complex(re, im)
+}
+
+// Real returns the real part of this.
+func (z *Complex) Real() *Float {
+	if z.re == nil {
+		return new(Float)
+	}
+	return z.re
+}
+
+// Imag returns the imaginary part of this.
+func (z *Complex) Imag() *Float {
+	if z.im == nil {
+		return new(Float)
+	}
+	return z.im
+}
+
+// parts returns the parts of this as big.Floats.
+func (z *Complex) parts() (*big.Float, *big.Float) {
+	return (*big.Float)(z.Real()), (*big.Float)(z.Imag())
+}
+
+// Prec returns the precision of this, the larger of the precisions of its
+// parts.
+func (z *Complex) Prec() uint {
+	p, q := z.Real().Prec(), z.Imag().Prec()
+	if q > p {
+		return q
+	}
+	return p
+}
+
+// Mode returns the rounding mode of this, that of its real part.
+func (z *Complex) Mode() big.RoundingMode {
+	return z.Real().Mode()
+}
+
+// precision returns the precision results are rounded to: that of z, or
+// 64 bits if z has precision 0.
+func (z *Complex) precision() uint {
+	if p := z.Prec(); p != 0 {
+		return p
+	}
+	return 64
+}
+
+// SetPrec returns this with both parts rounded to prec bits, in the mode
+// of this.
+func (z *Complex) SetPrec(prec uint) *Complex {
+	re, im := z.parts()
+	return &Complex{rounded(re, prec, z.Mode()), rounded(im, prec, z.Mode())}
+}
+
+// SetMode returns this with the rounding mode of both parts set to mode.
+func (z *Complex) SetMode(mode big.RoundingMode) *Complex {
+	return &Complex{z.Real().SetMode(mode), z.Imag().SetMode(mode)}
+}
+
+// IsInf returns whether either part of this is infinite.
+func (z *Complex) IsInf() bool {
+	return z.Real().IsInf() || z.Imag().IsInf()
+}
+
+// IsZero returns whether both parts of this are zero.
+func (z *Complex) IsZero() bool {
+	return z.Real().Sign() == 0 && z.Imag().Sign() == 0
+}
+
+// Equal returns whether this and y have equal parts; as for Float.Cmp, -0
+// equals +0.
+func (z *Complex) Equal(y *Complex) bool {
+	return z.Real().Cmp(y.Real()) == 0 && z.Imag().Cmp(y.Imag()) == 0
+}
+
+// complexResult returns a Complex with parts rounded to prec with the mode.
+func complexResult(re, im *big.Float, prec uint, mode big.RoundingMode) *Complex {
+	return &Complex{rounded(re, prec, mode), rounded(im, prec, mode)}
+}
+
+// arithPrec returns the precision of the result of arithmetic on z and y.
+func (z *Complex) arithPrec(y *Complex) uint {
+	p := z.Prec()
+	if q := y.Prec(); q > p {
+		p = q
+	}
+	if p == 0 {
+		return 64
+	}
+	return p
+}
+
+// Add returns this + y.
+func (z *Complex) Add(y *Complex) *Complex {
+	a, b := z.parts()
+	c, d := y.parts()
+	prec, mode := z.arithPrec(y), z.Mode()
+	re := newFloat(prec).SetMode(mode).Add(a, c)
+	im := newFloat(prec).SetMode(mode).Add(b, d)
+	return &Complex{(*Float)(re), (*Float)(im)}
+}
+
+// Sub returns this - y.
+func (z *Complex) Sub(y *Complex) *Complex {
+	a, b := z.parts()
+	c, d := y.parts()
+	prec, mode := z.arithPrec(y), z.Mode()
+	re := newFloat(prec).SetMode(mode).Sub(a, c)
+	im := newFloat(prec).SetMode(mode).Sub(b, d)
+	return &Complex{(*Float)(re), (*Float)(im)}
+}
+
+// exactMul returns x y without rounding.
+func exactMul(x, y *big.Float) *big.Float {
+	return newFloat(x.MinPrec()+y.MinPrec()).Mul(x, y)
+}
+
+// exactSum returns x + y without rounding, for finite x and y, with +0
+// for zeros of opposite signs.
+func exactSum(x, y *big.Float) *big.Float {
+	if x.Sign() == 0 && y.Sign() == 0 && x.Signbit() != y.Signbit() {
+		return new(big.Float)
+	}
+	return exactAdd(x, y)
+}
+
+// stickySum returns x + y for finite x and y. When the smaller term lies
+// wholly below the last of w+1 bits of the larger, and below its lowest
+// set bit, it is replaced by a power of 2 of the same sign that is lower
+// still, as in nearly. That keeps the rounding to w bits or fewer, in
+// every mode, without the cost of an exact sum across a wide gap in
+// exponents. The larger term is returned too in that case, and the exact
+// sum lies strictly between it and the result; otherwise, it is nil and
+// the result is exact.
+func stickySum(x, y *big.Float, w uint) (sum, bound *big.Float) {
+	if x.Sign() == 0 || y.Sign() == 0 {
+		return exactSum(x, y), nil
+	}
+	if expo(x) < expo(y) {
+		x, y = y, x
+	}
+	q := int(x.MinPrec())
+	if int(w)+1 > q {
+		q = int(w) + 1
+	}
+	off := expo(x) - q - 4
+	if expo(y) > off {
+		return exactSum(x, y), nil
+	}
+	d := pow2(off)
+	if y.Sign() < 0 {
+		d.Neg(d)
+	}
+	return newFloat(uint(q+8)).Add(x, d), x
+}
+
+// quoPart returns num / den rounded correctly to prec with the mode, where
+// num and den and their bounds are from stickySum with w bits, or false if
+// w is too small to decide the rounding.
+func quoPart(num, numBound, den, denBound *big.Float, w, prec uint, mode big.RoundingMode) (*Float, bool) {
+	if numBound == nil && (denBound == nil || num.Sign() == 0) {
+		return (*Float)(newFloat(prec).SetMode(mode).Quo(num, den)), true
+	}
+	// the quotient lies strictly between the least and greatest quotients
+	// of the bounds, as the numerator and denominator keep their signs
+	nums, dens := []*big.Float{num}, []*big.Float{den}
+	if numBound != nil {
+		nums = append(nums, numBound)
+	}
+	if denBound != nil {
+		dens = append(dens, denBound)
+	}
+	var lo, hi *big.Float
+	for _, n := range nums {
+		for _, d := range dens {
+			l := newFloat(w).SetMode(big.ToNegativeInf).Quo(n, d)
+			h := newFloat(w).SetMode(big.ToPositiveInf).Quo(n, d)
+			if lo == nil || l.Cmp(lo) < 0 {
+				lo = l
+			}
+			if hi == nil || h.Cmp(hi) > 0 {
+				hi = h
+			}
+		}
+	}
+	if lo.IsInf() || hi.IsInf() || lo.Sign() == 0 || hi.Sign() == 0 {
+		// beyond the exponent range
+		return (*Float)(newFloat(prec).SetMode(mode).Quo(num, den)), true
+	}
+	// round the values just inside the bounds, which may be boundaries; the
+	// accuracies differ if the result is between them
+	rlo, _ := nearly(lo, 1, math.MinInt32, prec, mode)
+	rhi, _ := nearly(hi, -1, math.MinInt32, prec, mode)
+	if rlo.Cmp(rhi) != 0 || rlo.Acc() != rhi.Acc() {
+		return nil, false
+	}
+	return rlo, true
+}
+
+// nanPanic converts a big.ErrNaN panic into ErrNaN.
+func nanPanic() {
+	if r := recover(); r != nil {
+		if _, ok := r.(big.ErrNaN); ok {
+			panic(ErrNaN)
+		}
+		panic(r)
+	}
+}
+
+// Mul returns this y. Each part is rounded once, from its exact value. If
+// a part of either operand is infinite, the parts are found as for
+// complex128, and Mul panics with ErrNaN if one is NaN.
+func (z *Complex) Mul(y *Complex) *Complex {
+	a, b := z.parts()
+	c, d := y.parts()
+	prec, mode := z.arithPrec(y), z.Mode()
+	if z.IsInf() || y.IsInf() {
+		defer nanPanic()
+		re := newFloat(prec).SetMode(mode).Sub(newFloat(prec).Mul(a, c), newFloat(prec).Mul(b, d))
+		im := newFloat(prec).SetMode(mode).Add(newFloat(prec).Mul(a, d), newFloat(prec).Mul(b, c))
+		return &Complex{(*Float)(re), (*Float)(im)}
+	}
+	// the sums are exact except across a wide gap, where the smaller
+	// product only decides the rounding
+	bd := exactMul(b, d)
+	re, _ := stickySum(exactMul(a, c), bd.Neg(bd), prec)
+	im, _ := stickySum(exactMul(a, d), exactMul(b, c), prec)
+	return complexResult(re, im, prec, mode)
+}
+
+// Quo returns this / y. Each part is rounded once, from its exact value.
+// If y is zero or a part of either operand is infinite, the parts are
+// those of complex128 division, and Quo panics with ErrNaN if one is NaN.
+func (z *Complex) Quo(y *Complex) *Complex {
+	a, b := z.parts()
+	c, d := y.parts()
+	prec, mode := z.arithPrec(y), z.Mode()
+	if y.IsZero() || z.IsInf() || y.IsInf() {
+		defer nanPanic()
+		re, im := quoSpecial(a, b, c, d)
+		return complexResult(re, im, prec, mode)
+	}
+	// (a + b i) / (c + d i) = ((a c + b d) + (b c - a d) i) / (c**2 + d**2),
+	// with the products exact, and the sums exact unless their terms are
+	// far apart, when the smaller terms usually only decide the rounding
+	cc, dd := exactMul(c, c), exactMul(d, d)
+	ac, bd := exactMul(a, c), exactMul(b, d)
+	bc, ad := exactMul(b, c), exactMul(a, d)
+	ad.Neg(ad)
+	var re, im *Float
+	for w := prec + 32; w <= 4*(prec+32); w *= 2 {
+		n, nb := stickySum(cc, dd, w)
+		if re == nil {
+			x, xb := stickySum(ac, bd, w)
+			re, _ = quoPart(x, xb, n, nb, w, prec, mode)
+		}
+		if im == nil {
+			y, yb := stickySum(bc, ad, w)
+			im, _ = quoPart(y, yb, n, nb, w, prec, mode)
+		}
+		if re != nil && im != nil {
+			return &Complex{re, im}
+		}
+	}
+	// a part is still undecided, as when it is exactly representable, so
+	// divide the exact sums
+	n := exactSum(cc, dd)
+	if re == nil {
+		re = (*Float)(newFloat(prec).SetMode(mode).Quo(exactSum(ac, bd), n))
+	}
+	if im == nil {
+		im = (*Float)(newFloat(prec).SetMode(mode).Quo(exactSum(bc, ad), n))
+	}
+	return &Complex{re, im}
+}
+
+// quoSpecial returns the parts of (a + b i) / (c + d i) for a zero divisor
+// or an infinite part, as complex128 division finds them (C99 Annex G). It
+// panics with big.ErrNaN for a NaN part.
+func quoSpecial(a, b, c, d *big.Float) (*big.Float, *big.Float) {
+	// infOne returns ±1 for ±Inf, and ±0 otherwise
+	infOne := func(x *big.Float) *big.Float {
+		r := new(big.Float)
+		if x.IsInf() {
+			r.SetInt64(1)
+		}
+		if x.Signbit() {
+			r.Neg(r)
+		}
+		return r
+	}
+	mul := func(x, y *big.Float) *big.Float { return new(big.Float).Mul(x, y) }
+	var s *big.Float
+	switch {
+	case c.Sign() == 0 && d.Sign() == 0:
+		s = new(big.Float).SetInf(c.Signbit())
+		return mul(s, a), mul(s, b)
+	case (c.IsInf() || d.IsInf()) && !a.IsInf() && !b.IsInf():
+		s = new(big.Float)
+		c, d = infOne(c), infOne(d)
+	case !c.IsInf() && !d.IsInf():
+		s = new(big.Float).SetInf(false)
+		a, b = infOne(a), infOne(b)
+	default:
+		panic(big.ErrNaN{})
+	}
+	re := new(big.Float).Add(mul(a, c), mul(b, d))
+	im := new(big.Float).Sub(mul(b, c), mul(a, d))
+	return mul(s, re), mul(s, im)
+}
+
+// Neg returns -this.
+func (z *Complex) Neg() *Complex {
+	return &Complex{z.Real().Neg(), z.Imag().Neg()}
+}
+
+// Conj returns the complex conjugate of this.
+func (z *Complex) Conj() *Complex {
+	return &Complex{z.Real(), z.Imag().Neg()}
+}
+
+// Abs returns the absolute value (modulus) of this, rounded to its
+// precision and mode, as for Float.Hypot.
+func (z *Complex) Abs() *Float {
+	return z.context().Hypot(z.Real(), z.Imag())
+}
+
+// Arg returns the argument (phase) of this, in [-Pi, Pi], rounded to its
+// precision and mode. As for math.Atan2, the signs of zero parts choose
+// between -Pi and Pi, so Arg is continuous from above on the negative
+// real axis, and from below for a negative zero imaginary part.
+func (z *Complex) Arg() *Float {
+	return z.context().Atan2(z.Imag(), z.Real())
+}
+
+// context returns a FloatContext for the precision and mode of z, for
+// the real functions of its parts.
+func (z *Complex) context() *FloatContext {
+	return &FloatContext{Prec: z.precision(), Mode: z.Mode()}
+}
+
+// String returns this formatted as by the %v verb, like a complex128:
+// "(1+2i)".
+func (z *Complex) String() string {
+	return fmt.Sprintf("%v", z)
+}
+
+// Text returns this formatted like a complex128, with each part formatted
+// as by Float.Text with the format and prec.
+func (z *Complex) Text(format byte, prec int) string {
+	im := z.Imag().Text(format, prec)
+	if !strings.HasPrefix(im, "-") && !strings.HasPrefix(im, "+") {
+		im = "+" + im
+	}
+	return "(" + z.Real().Text(format, prec) + im + "i)"
+}
+
+// Format implements fmt.Formatter, formatting this like a complex128: each
+// part is formatted as by Float.Format with the flags, width and precision,
+// and the imaginary part always has a sign.
+func (z *Complex) Format(s fmt.State, verb rune) {
+	var spec strings.Builder
+	spec.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			spec.WriteRune(flag)
+		}
+	}
+	if w, ok := s.Width(); ok {
+		fmt.Fprint(&spec, w)
+	}
+	if p, ok := s.Precision(); ok {
+		fmt.Fprintf(&spec, ".%d", p)
+	}
+	spec.WriteRune(verb)
+	f := spec.String()
+	fmt.Fprintf(s, "("+f+"%+"+f[1:]+"i)", z.Real(), z.Imag())
+}
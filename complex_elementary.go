@@ -0,0 +1,762 @@
+package mathx
+
+// This file is for the elementary functions on Complex. Each part of each
+// result is correctly rounded to the precision and rounding mode of the
+// receiver (or to 64 bits if the receiver has precision 0), and the branch
+// cuts and signed zeros follow the cmplx package. Where the cmplx package
+// gives a NaN part, these panic with ErrNaN.
+
+import (
+	"math/big"
+)
+
+// zeroPart returns a zero with the precision, mode and sign.
+func zeroPart(prec uint, mode big.RoundingMode, signbit bool) *Float {
+	f := newFloat(prec).SetMode(mode)
+	if signbit {
+		f.Neg(f)
+	}
+	return (*Float)(f)
+}
+
+// infPart returns an infinity with the precision, mode and sign.
+func infPart(prec uint, mode big.RoundingMode, signbit bool) *Float {
+	return (*Float)(newFloat(prec).SetMode(mode).SetInf(signbit))
+}
+
+// sinCosSignbits returns the sign bits of sin(x) and cos(x), for finite
+// x.
+func sinCosSignbits(x *big.Float) (bool, bool) {
+	if x.Sign() == 0 {
+		return x.Signbit(), false
+	}
+	c := &FloatContext{Prec: 2}
+	return c.Sin((*Float)(x)).Signbit(), c.Cos((*Float)(x)).Signbit()
+}
+
+// sinKernel returns sin(x), for finite nonzero x.
+func sinKernel(x *big.Float, w uint) *big.Float {
+	return sinCosKernel(x, false, false, w)
+}
+
+// cosKernel returns cos(x), for finite x.
+func cosKernel(x *big.Float, w uint) *big.Float {
+	if x.Sign() == 0 {
+		return newFloat(w).SetInt64(1)
+	}
+	return sinCosKernel(x, true, false, w)
+}
+
+// product returns the product of xs rounded to w bits, negated if neg is
+// set. A zero or infinite factor is taken to be the underflow or overflow
+// of a kernel, and the product is then an inexact zero or infinity, as ziv
+// expects.
+func product(w uint, neg bool, xs ...*big.Float) *big.Float {
+	signbit := neg
+	for _, x := range xs {
+		signbit = signbit != x.Signbit()
+	}
+	for _, x := range xs {
+		if x.IsInf() {
+			return (*big.Float)(overflow(w, big.ToNearestEven, signbit))
+		}
+	}
+	y := newFloat(w).SetInt64(1)
+	for _, x := range xs {
+		if x.Sign() == 0 {
+			return (*big.Float)(underflow(w, big.ToNearestEven, signbit))
+		}
+		y.Mul(y, x)
+	}
+	if neg {
+		y.Neg(y)
+	}
+	return y
+}
+
+// factor is a kernel and its argument, for productPart.
+type factor struct {
+	kernel func(x *big.Float, w uint) *big.Float
+	x      *big.Float
+}
+
+// productPart returns the product of the factors, negated if neg is set,
+// correctly rounded to prec with the mode. The product must be
+// transcendental.
+func productPart(prec uint, mode big.RoundingMode, neg bool, fs ...factor) *Float {
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		wp := w + 8
+		xs := make([]*big.Float, len(fs))
+		for i, f := range fs {
+			xs[i] = f.kernel(f.x, wp)
+		}
+		y := product(wp, neg, xs...)
+		if y.IsInf() || y.Sign() == 0 {
+			return y
+		}
+		return newFloat(w).Set(y)
+	})
+}
+
+// Exp returns e**z. Exp(x ± 0i) = e**x ± 0i, and for an infinite real
+// part, Exp(±Inf + y i) is an infinity or a zero with the signs of cos(y)
+// and sin(y), as for cmplx.Exp. Exp panics with ErrNaN if the imaginary
+// part of z is infinite and the real part is not -Inf.
+func (z *Complex) Exp() *Complex {
+	a, b := z.parts()
+	prec, mode := z.precision(), z.Mode()
+	switch {
+	case b.IsInf() && (!a.IsInf() || a.Sign() > 0):
+		panic(ErrNaN)
+	case b.Sign() == 0:
+		return &Complex{z.context().Exp(z.Real()), rounded(b, prec, mode)}
+	case a.IsInf() && b.IsInf():
+		return &Complex{zeroPart(prec, mode, false), zeroPart(prec, mode, b.Signbit())}
+	case a.IsInf() && a.Sign() > 0:
+		s, c := sinCosSignbits(b)
+		return &Complex{infPart(prec, mode, c), infPart(prec, mode, s)}
+	case a.IsInf():
+		s, c := sinCosSignbits(b)
+		return &Complex{zeroPart(prec, mode, c), zeroPart(prec, mode, s)}
+	case a.Sign() == 0:
+		ctx := z.context()
+		return &Complex{ctx.Cos(z.Imag()), ctx.Sin(z.Imag())}
+	}
+	if expo(a) > 32 {
+		// e**a is beyond the exponent range of big.Float
+		s, c := sinCosSignbits(b)
+		if a.Sign() > 0 {
+			return &Complex{overflow(prec, mode, c), overflow(prec, mode, s)}
+		}
+		return &Complex{underflow(prec, mode, c), underflow(prec, mode, s)}
+	}
+	return &Complex{
+		productPart(prec, mode, false, factor{expKernel, a}, factor{cosKernel, b}),
+		productPart(prec, mode, false, factor{expKernel, a}, factor{sinKernel, b}),
+	}
+}
+
+// normSquared returns a**2 + b**2 scaled by 4**-k, exactly, and k, which
+// is 0 unless the squares would be beyond the exponent range of big.Float.
+func normSquared(a, b *big.Float) (*big.Float, int) {
+	e := 0
+	for _, x := range []*big.Float{a, b} {
+		if x.Sign() != 0 && absInt(expo(x)) > absInt(e) {
+			e = expo(x)
+		}
+	}
+	k := 0
+	if absInt(e) > 1<<29 {
+		k = e
+		a = new(big.Float).SetMantExp(a, -k)
+		b = new(big.Float).SetMantExp(b, -k)
+	}
+	return exactSum(exactMul(a, a), exactMul(b, b)), k
+}
+
+// logAbsKernel returns log|a + b i| = log(s)/2 + k log(2), for s and k
+// from normSquared, with s 4**k != 1.
+func logAbsKernel(s *big.Float, k int, w uint) *big.Float {
+	if k == 0 {
+		y := logKernel(s, w)
+		return y.SetMantExp(y, -1)
+	}
+	// |k log(2)| is much larger than log(s)/2, so this does not cancel
+	wp := w + 8
+	y := logKernel(s, wp)
+	y.SetMantExp(y, -1)
+	l2 := ln2Constant.at(wp + 32)
+	l2.Mul(l2, new(big.Float).SetInt64(int64(k)))
+	return newFloat(w).Add(y, l2)
+}
+
+// logAbs returns log|a + b i| correctly rounded, for finite a and b, not
+// both zero.
+func logAbs(a, b *big.Float, prec uint, mode big.RoundingMode) *Float {
+	s, k := normSquared(a, b)
+	if k == 0 && s.Cmp(floatOne) == 0 {
+		return zeroPart(prec, mode, false)
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return logAbsKernel(s, k, w)
+	})
+}
+
+// Log returns the natural logarithm of z, log|z| + i Arg(z), with the
+// imaginary part in [-Pi, Pi]. The branch cut is the negative real axis,
+// where the sign of a zero imaginary part chooses between Pi and -Pi, as
+// for Arg. Log(0) = -Inf + i Arg(z), and the real part is +Inf if either
+// part of z is infinite.
+func (z *Complex) Log() *Complex {
+	a, b := z.parts()
+	prec, mode := z.precision(), z.Mode()
+	var re *Float
+	switch {
+	case z.IsInf():
+		re = infPart(prec, mode, false)
+	case z.IsZero():
+		re = infPart(prec, mode, true)
+	default:
+		re = logAbs(a, b, prec, mode)
+	}
+	return &Complex{re, z.Arg()}
+}
+
+// exactSqrt returns the square root of x > 0, and whether it is exact.
+func exactSqrt(x *big.Float) (*big.Float, bool) {
+	l := lsb(x)
+	m := intPart(x, l)
+	if l&1 != 0 {
+		m.Lsh(m, 1)
+		l--
+	}
+	r := new(big.Int).Sqrt(m)
+	if new(big.Int).Mul(r, r).Cmp(m) != 0 {
+		return nil, false
+	}
+	f := new(big.Float).SetInt(r)
+	return f.SetMantExp(f, l/2), true
+}
+
+// Sqrt returns the principal square root of z, which has a nonnegative
+// real part. The branch cut is the negative real axis, where the sign of
+// a zero imaginary part gives the sign of the result's imaginary part:
+// Sqrt(-4 + 0i) = 0 + 2i and Sqrt(-4 - 0i) = 0 - 2i. Sqrt(x ± Inf i) =
+// +Inf ± Inf i, Sqrt(+Inf ± y i) = +Inf ± 0i and Sqrt(-Inf ± y i) = +0 ±
+// Inf i.
+func (z *Complex) Sqrt() *Complex {
+	a, b := z.parts()
+	prec, mode := z.precision(), z.Mode()
+	switch {
+	case b.IsInf():
+		return &Complex{infPart(prec, mode, false), rounded(b, prec, mode)}
+	case a.IsInf() && a.Sign() > 0:
+		return &Complex{rounded(a, prec, mode), zeroPart(prec, mode, b.Signbit())}
+	case a.IsInf():
+		return &Complex{zeroPart(prec, mode, false), infPart(prec, mode, b.Signbit())}
+	case b.Sign() == 0 && a.Sign() == 0:
+		return &Complex{zeroPart(prec, mode, false), rounded(b, prec, mode)}
+	case b.Sign() == 0 && a.Sign() > 0:
+		return &Complex{root(a, 2, 0, prec, mode), rounded(b, prec, mode)}
+	case b.Sign() == 0:
+		// root gives the root of |x| with the sign of x
+		x := new(big.Float).Neg(a)
+		if b.Signbit() {
+			x = a
+		}
+		return &Complex{zeroPart(prec, mode, false), root(x, 2, 0, prec, mode)}
+	}
+
+	// with z scaled by 4**-k, so that the squares are in range, the root
+	// is t + o i for a >= 0 and o + t i for a < 0, times 2**k, where t =
+	// sqrt((|z| + |a|)/2) and o = b/(2t), with the sign of b moved to t
+	// for a < 0
+	e := expo(b)
+	if a.Sign() != 0 {
+		e = maxExp(expo(a), e)
+	}
+	k := e >> 1
+	as := new(big.Float).SetMantExp(a, -2*k)
+	bs := new(big.Float).SetMantExp(b, -2*k)
+	s := exactSum(exactMul(as, as), exactMul(bs, bs))
+	tNeg := a.Sign() < 0 && b.Signbit()
+	// o = num/t, unscaled
+	num := new(big.Float).SetMantExp(bs, k-1)
+	if a.Sign() < 0 {
+		num.Abs(num)
+	}
+	var t, o *Float
+	if r, ok := exactSqrt(s); ok {
+		u := exactAdd(r, new(big.Float).Abs(as))
+		if tt, ok := exactSqrt(u.SetMantExp(u, -1)); ok {
+			// t is exact, so o is rational and one division rounds it
+			o = (*Float)(newFloat(prec).SetMode(mode).Quo(num, tt))
+			if tNeg {
+				tt.Neg(tt)
+			}
+			t = rounded(tt.SetMantExp(tt, k), prec, mode)
+		}
+	}
+	if t == nil {
+		// t and o are irrational
+		kernel := func(w uint) *big.Float {
+			wp := w + 8
+			u := newFloat(wp).Sqrt(s)
+			u.Add(u, new(big.Float).Abs(as))
+			return newFloat(wp).Sqrt(u.SetMantExp(u, -1))
+		}
+		t = zivRel(prec, mode, func(w uint) *big.Float {
+			tt := kernel(w)
+			if tNeg {
+				tt.Neg(tt)
+			}
+			return newFloat(w).SetMantExp(tt, k)
+		})
+		o = zivRel(prec, mode, func(w uint) *big.Float {
+			return newFloat(w).Quo(num, kernel(w))
+		})
+	}
+	if a.Sign() < 0 {
+		return &Complex{o, t}
+	}
+	return &Complex{t, o}
+}
+
+// exactComplexSqrt returns the principal square root of a + b i, for
+// finite a and b that are not both zero, if its parts are exact.
+func exactComplexSqrt(a, b *big.Float) (*big.Float, *big.Float, bool) {
+	if b.Sign() == 0 {
+		r, ok := exactSqrt(new(big.Float).Abs(a))
+		switch {
+		case !ok:
+			return nil, nil, false
+		case a.Sign() > 0:
+			return r, new(big.Float).Set(b), true
+		case b.Signbit():
+			r.Neg(r)
+		}
+		return new(big.Float), r, true
+	}
+	r, ok := exactSqrt(exactSum(exactMul(a, a), exactMul(b, b)))
+	if !ok {
+		return nil, nil, false
+	}
+	u := exactAdd(r, new(big.Float).Abs(a))
+	t, ok := exactSqrt(u.SetMantExp(u, -1))
+	if !ok {
+		return nil, nil, false
+	}
+	// o = |b|/(2t) is exact if it is a Float at all, and then it has no
+	// more bits than b
+	o := newFloat(b.MinPrec()).Quo(new(big.Float).Abs(b), t)
+	if o.Acc() != big.Exact {
+		return nil, nil, false
+	}
+	o.SetMantExp(o, -1)
+	if a.Sign() < 0 {
+		t, o = o, t
+	}
+	if b.Signbit() {
+		o.Neg(o)
+	}
+	return t, o, true
+}
+
+// exactComplexPow returns (a + b i)**n exactly, for n > 0, unless a part
+// of a power along the way has more than limit bits.
+func exactComplexPow(a, b *big.Float, n *big.Int, limit uint) (*big.Float, *big.Float, bool) {
+	mul := func(x, y, c, d *big.Float) (*big.Float, *big.Float, bool) {
+		yd := exactMul(y, d)
+		r := exactSum(exactMul(x, c), yd.Neg(yd))
+		i := exactSum(exactMul(x, d), exactMul(y, c))
+		ok := !r.IsInf() && !i.IsInf() && r.MinPrec() <= limit && i.MinPrec() <= limit
+		return r, i, ok
+	}
+	re, im := big.NewFloat(1), new(big.Float)
+	ok := true
+	for i := 0; i < n.BitLen(); i++ {
+		if i > 0 {
+			if a, b, ok = mul(a, b, a, b); !ok {
+				return nil, nil, false
+			}
+		}
+		if n.Bit(i) == 1 {
+			if re, im, ok = mul(re, im, a, b); !ok {
+				return nil, nil, false
+			}
+		}
+	}
+	return re, im, true
+}
+
+// argKernel returns the argument of a + b i, for finite a and b that are
+// not both zero.
+func argKernel(a, b *big.Float, w uint) *big.Float {
+	wp := w + 8
+	var r *big.Float
+	switch {
+	case b.Sign() == 0 && a.Sign() > 0:
+		return newFloat(w)
+	case b.Sign() == 0:
+		r = piConstant.at(wp)
+	case a.Sign() == 0:
+		r = piConstant.at(wp)
+		r.SetMantExp(r, -1)
+	default:
+		r = atanKernel(newFloat(wp).Quo(b, a), wp)
+		if a.Sign() < 0 {
+			p := piConstant.at(wp)
+			if b.Signbit() {
+				p.Neg(p)
+			}
+			r.Add(r, p)
+		}
+		return newFloat(w).Set(r)
+	}
+	if b.Signbit() {
+		r.Neg(r)
+	}
+	return newFloat(w).Set(r)
+}
+
+// Pow returns z**y, the principal value exp(y Log(z)), so the branch cut
+// is that of Log. Pow(z, 0) = 1 for any z, and Pow(0, y) is 0 if the real
+// part of y is positive, +Inf + 0i if y is negative and real, and +Inf +
+// Inf i if the real part of y is negative; otherwise, Pow(0, y) panics
+// with ErrNaN. Pow also panics with ErrNaN if z or y has an infinite part.
+// Parts that are exact are found exactly, such as those of integer powers
+// and of square roots.
+func (z *Complex) Pow(y *Complex) *Complex {
+	a, b := z.parts()
+	c, d := y.parts()
+	prec, mode := z.precision(), z.Mode()
+	switch {
+	case y.IsZero() || a.Cmp(floatOne) == 0 && b.Sign() == 0:
+		return &Complex{rounded(floatOne, prec, mode), zeroPart(prec, mode, false)}
+	case z.IsInf() || y.IsInf():
+		panic(ErrNaN)
+	case z.IsZero():
+		switch {
+		case c.Sign() > 0:
+			return &Complex{zeroPart(prec, mode, false), zeroPart(prec, mode, false)}
+		case c.Sign() < 0 && d.Sign() == 0:
+			return &Complex{infPart(prec, mode, false), zeroPart(prec, mode, false)}
+		case c.Sign() < 0:
+			return &Complex{infPart(prec, mode, false), infPart(prec, mode, false)}
+		}
+		panic(ErrNaN)
+	}
+
+	// Arg(z) = j Pi/4 for these j, and then when y is real or |z| = 1,
+	// the phase of the result is q Pi for q = c j/4; a zero imaginary part
+	// of a real result has the signs of b and c, as for cmplx.Pow
+	j := -1
+	switch {
+	case b.Sign() == 0 && a.Sign() > 0:
+		j = 0
+	case b.Sign() == 0:
+		j = 4
+	case a.Sign() == 0:
+		j = 2
+	case cmpAbs(a, b) == 0 && a.Sign() > 0:
+		j = 1
+	case cmpAbs(a, b) == 0:
+		j = 3
+	}
+	imSignbit := j == 0 && b.Signbit() != c.Signbit()
+	if d.Sign() == 0 {
+		if r, ok := powReal(a, b, c, prec, mode); ok {
+			if r.im.Sign() == 0 {
+				r.im = zeroPart(prec, mode, imSignbit)
+			}
+			return r
+		}
+	}
+
+	// otherwise, the parts are transcendental or zero
+	unit := b.Sign() == 0 && cmpAbs(a, floatOne) == 0 || a.Sign() == 0 && cmpAbs(b, floatOne) == 0
+	reZero, imZero := false, false
+	if j >= 0 && (d.Sign() == 0 || unit) {
+		q := exactMul(c, big.NewFloat(float64(j)))
+		q.SetMantExp(q, -2)
+		imZero = q.IsInt()
+		reZero = !imZero && new(big.Float).SetMantExp(q, 1).IsInt()
+		if unit && d.Sign() == 0 && (imZero || reZero) {
+			// the result is ±1 or ±i, (-1)**m or (-1)**m i for m =
+			// floor(q), and m is even if q is a multiple of 2
+			one := big.NewFloat(1)
+			if lsb(q) < 1 {
+				m, _ := new(big.Float).SetMantExp(q, 1).Int(nil)
+				if m.Rsh(m, 1).Bit(0) == 1 {
+					one.Neg(one)
+				}
+			}
+			if imZero {
+				return &Complex{rounded(one, prec, mode), zeroPart(prec, mode, imSignbit)}
+			}
+			return &Complex{zeroPart(prec, mode, false), rounded(one, prec, mode)}
+		}
+	}
+
+	s, k := normSquared(a, b)
+	logOne := k == 0 && s.Cmp(floatOne) == 0
+	approx := func(w uint, real bool) (*big.Float, int) {
+		wp := w + 16
+		lr := new(big.Float)
+		if !logOne {
+			lr = logAbsKernel(s, k, wp)
+		}
+		li := argKernel(a, b, wp)
+		tr := newFloat(wp).Sub(newFloat(wp).Mul(c, lr), newFloat(wp).Mul(d, li))
+		ti := newFloat(wp).Add(newFloat(wp).Mul(c, li), newFloat(wp).Mul(d, lr))
+		// tr and ti are within 2**(m-wp+3) of their values, for |c| + |d|
+		// < 2**mc, |lr| + |li| < 2**ml and m = mc + ml
+		up := func(x, y *big.Float) int {
+			t := newFloat(8).SetMode(big.ToPositiveInf)
+			t.Add(t.Abs(x), new(big.Float).Abs(y))
+			if t.Sign() == 0 {
+				return 0
+			}
+			return expo(t)
+		}
+		m := maxExp(up(c, d)+up(lr, li), 0)
+		var f *big.Float
+		switch {
+		case real:
+			f = cosKernel(ti, wp)
+		case ti.Sign() != 0:
+			f = sinKernel(ti, wp)
+		default:
+			f = new(big.Float)
+		}
+		if tr.Sign() != 0 && expo(tr) > 32 {
+			if tr.Sign() > 0 {
+				return (*big.Float)(overflow(w, big.ToNearestEven, f.Signbit())), 0
+			}
+			return (*big.Float)(underflow(w, big.ToNearestEven, f.Signbit())), 0
+		}
+		ex := expKernel(tr, wp)
+		if ex.IsInf() || ex.Sign() == 0 {
+			return product(w, false, ex, f), 0
+		}
+		e := expo(ex) + m - int(wp) + 8
+		r := newFloat(w).Mul(ex, f)
+		if r.Sign() == 0 {
+			// the value is within 2**e of 0, so within 2**(e+1) of 2**e
+			return pow2(e), e + 1
+		}
+		return r, e
+	}
+	re := zeroPart(prec, mode, false)
+	if !reZero {
+		re = ziv(prec, mode, func(w uint) (*big.Float, int) { return approx(w, true) })
+	}
+	im := zeroPart(prec, mode, imSignbit)
+	if !imZero {
+		im = ziv(prec, mode, func(w uint) (*big.Float, int) { return approx(w, false) })
+	}
+	return &Complex{re, im}
+}
+
+// powReal returns (a + b i)**c for finite a and b that are not both zero,
+// and real c = n/2**k, if the 2**k-th root is exact and the power of it is
+// not too large to find exactly.
+func powReal(a, b, c *big.Float, prec uint, mode big.RoundingMode) (*Complex, bool) {
+	k := 0
+	if l := lsb(c); l < 0 {
+		k = -l
+	}
+	if k > 64 || expo(c) > 64 {
+		return nil, false
+	}
+	for i := 0; i < k; i++ {
+		var ok bool
+		if a, b, ok = exactComplexSqrt(a, b); !ok {
+			return nil, false
+		}
+	}
+	n, _ := new(big.Float).SetMantExp(c, k).Int(nil)
+	re, im, ok := exactComplexPow(a, b, new(big.Int).Abs(n), 2*prec+64)
+	if !ok {
+		return nil, false
+	}
+	if n.Sign() > 0 {
+		return complexResult(re, im, prec, mode), true
+	}
+	// 1/(re + im i) = (re - im i) / (re**2 + im**2)
+	m := exactSum(exactMul(re, re), exactMul(im, im))
+	im.Neg(im)
+	return &Complex{
+		(*Float)(newFloat(prec).SetMode(mode).Quo(re, m)),
+		(*Float)(newFloat(prec).SetMode(mode).Quo(im, m)),
+	}, true
+}
+
+// trigPart returns f(x) g(y), negated if neg is set, for f = sin (if sin
+// is set) or cos, and g = sinh (if sinh is set) or cosh, correctly rounded
+// to prec with the mode, for finite x. For infinite y, it is a signed zero
+// if f(x) is, and otherwise a signed infinity.
+func trigPart(x, y *big.Float, sin, sinh, neg bool, prec uint, mode big.RoundingMode) *Float {
+	sinNeg, cosNeg := sinCosSignbits(x)
+	signbit := neg != cosNeg != (sinh && y.Signbit())
+	if sin {
+		signbit = neg != sinNeg != (sinh && y.Signbit())
+	}
+	switch {
+	case sin && x.Sign() == 0 || sinh && y.Sign() == 0:
+		return zeroPart(prec, mode, signbit)
+	case y.IsInf():
+		return infPart(prec, mode, signbit)
+	case x.Sign() == 0 && y.Sign() == 0:
+		// cos(0) cosh(0)
+		one := big.NewFloat(1)
+		if neg {
+			one.Neg(one)
+		}
+		return rounded(one, prec, mode)
+	case y.Sign() != 0 && expo(y) > 31:
+		return overflow(prec, mode, signbit)
+	}
+	f := factor{cosKernel, x}
+	if sin {
+		f.kernel = sinKernel
+	}
+	g := factor{coshKernel, y}
+	if sinh {
+		g.kernel = sinhKernel
+	}
+	return productPart(prec, mode, neg, f, g)
+}
+
+// Sin returns the sine of z, sin(a) cosh(b) + cos(a) sinh(b) i for z = a +
+// b i. It panics with ErrNaN if a is infinite.
+func (z *Complex) Sin() *Complex {
+	a, b := z.parts()
+	if a.IsInf() {
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	return &Complex{
+		trigPart(a, b, true, false, false, prec, mode),
+		trigPart(a, b, false, true, false, prec, mode),
+	}
+}
+
+// Cos returns the cosine of z, cos(a) cosh(b) - sin(a) sinh(b) i for z = a
+// + b i. It panics with ErrNaN if a is infinite.
+func (z *Complex) Cos() *Complex {
+	a, b := z.parts()
+	if a.IsInf() {
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	return &Complex{
+		trigPart(a, b, false, false, false, prec, mode),
+		trigPart(a, b, true, true, true, prec, mode),
+	}
+}
+
+// Sinh returns the hyperbolic sine of z, cos(b) sinh(a) + sin(b) cosh(a) i
+// for z = a + b i. It panics with ErrNaN if b is infinite.
+func (z *Complex) Sinh() *Complex {
+	a, b := z.parts()
+	if b.IsInf() {
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	return &Complex{
+		trigPart(b, a, false, true, false, prec, mode),
+		trigPart(b, a, true, false, false, prec, mode),
+	}
+}
+
+// Cosh returns the hyperbolic cosine of z, cos(b) cosh(a) + sin(b) sinh(a)
+// i for z = a + b i. It panics with ErrNaN if b is infinite.
+func (z *Complex) Cosh() *Complex {
+	a, b := z.parts()
+	if b.IsInf() {
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	return &Complex{
+		trigPart(b, a, false, false, false, prec, mode),
+		trigPart(b, a, true, true, false, prec, mode),
+	}
+}
+
+// tanParts returns the parts of tan(x + y i), the real part from x and the
+// imaginary part from y, correctly rounded to prec with the mode; tanh(y +
+// x i) has the same parts swapped. x must be finite unless y is infinite.
+func tanParts(x, y *big.Float, prec uint, mode big.RoundingMode) (*Float, *Float) {
+	switch {
+	case y.IsInf():
+		// the signs are those of cmplx.Tan
+		signbit := x.Signbit()
+		if !x.IsInf() {
+			signbit, _ = sinCosSignbits(new(big.Float).SetMantExp(x, 1))
+		}
+		return zeroPart(prec, mode, signbit), rounded(big.NewFloat(float64(y.Sign())), prec, mode)
+	case x.IsInf():
+		panic(ErrNaN)
+	case x.Sign() == 0:
+		return zeroPart(prec, mode, x.Signbit()), (&FloatContext{Prec: prec, Mode: mode}).Tanh((*Float)(y))
+	case y.Sign() == 0:
+		return (&FloatContext{Prec: prec, Mode: mode}).Tan((*Float)(x)), zeroPart(prec, mode, y.Signbit())
+	}
+
+	// with s = sin(x), c = cos(x) and t = e**(-2|y|), the real part is 4 s
+	// c t / D and the imaginary part is ±(1 - t**2) / D, for D = 4 c**2 t +
+	// (1 - t)**2, which does not cancel
+	sinNeg, cosNeg := sinCosSignbits(x)
+	ay := new(big.Float).Abs(y)
+	var re, im *Float
+	if expo(y) > 30 {
+		re = underflow(prec, mode, sinNeg != cosNeg)
+	}
+	if expo(y) > 0 {
+		// the imaginary part is ±(1 - d) for d = 2t (t + cos(2x)) / D, and
+		// |d| < 2**(3 - 2 floor(|y|)); the sign of d is that of cos(2x)
+		// when t is smaller
+		f, _ := ay.Float64()
+		dExp := 3 - 2*int(f)
+		if f > 1<<30 {
+			dExp = -1 << 31
+		}
+		c2 := (&FloatContext{Prec: 2}).Cos((*Float)(new(big.Float).SetMantExp(x, 1)))
+		c2b := (*big.Float)(c2)
+		if c2b.Sign() != 0 && (dExp == -1<<31 || expo(c2b)-2 >= dExp-3) {
+			dir := -c2b.Sign() * y.Sign()
+			im, _ = nearly(big.NewFloat(float64(y.Sign())), dir, dExp, prec, mode)
+		}
+	}
+	kernel := func(w uint, real bool) *big.Float {
+		wp := w + 16
+		t2 := new(big.Float).SetMantExp(ay, 1)
+		t := expKernel(t2.Neg(t2), wp)
+		m1 := expm1Kernel(t2, wp)
+		m1.Neg(m1)
+		c := cosKernel(x, wp)
+		dd := newFloat(wp).Mul(c, c)
+		dd.Mul(dd, t)
+		dd.SetMantExp(dd, 2)
+		dd.Add(dd, newFloat(wp).Mul(m1, m1))
+		if real {
+			n := newFloat(wp).Mul(sinKernel(x, wp), c)
+			n.Mul(n, t)
+			n.SetMantExp(n, 2)
+			return newFloat(w).Quo(n, dd)
+		}
+		t4 := new(big.Float).SetMantExp(ay, 2)
+		m2 := expm1Kernel(t4.Neg(t4), wp)
+		m2.Neg(m2)
+		if y.Sign() < 0 {
+			m2.Neg(m2)
+		}
+		return newFloat(w).Quo(m2, dd)
+	}
+	if re == nil {
+		re = zivRel(prec, mode, func(w uint) *big.Float { return kernel(w, true) })
+	}
+	if im == nil {
+		im = zivRel(prec, mode, func(w uint) *big.Float { return kernel(w, false) })
+	}
+	return re, im
+}
+
+// Tan returns the tangent of z. Tan(a ± Inf i) = ±0 ± 1i, with the sign of
+// sin(2a) for the real part, and Tan panics with ErrNaN if the real part
+// of z is infinite and the imaginary part is not.
+func (z *Complex) Tan() *Complex {
+	a, b := z.parts()
+	re, im := tanParts(a, b, z.precision(), z.Mode())
+	return &Complex{re, im}
+}
+
+// Tanh returns the hyperbolic tangent of z. Tanh(±Inf + b i) = ±1 ± 0i,
+// with the sign of sin(2b) for the imaginary part, and Tanh panics with
+// ErrNaN if the imaginary part of z is infinite and the real part is not.
+func (z *Complex) Tanh() *Complex {
+	a, b := z.parts()
+	im, re := tanParts(b, a, z.precision(), z.Mode())
+	return &Complex{re, im}
+}
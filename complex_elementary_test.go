@@ -0,0 +1,218 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+var complexFuncs = map[string]func(*Complex) *Complex{
+	"Exp": (*Complex).Exp, "Log": (*Complex).Log, "Sqrt": (*Complex).Sqrt,
+	"Sin": (*Complex).Sin, "Cos": (*Complex).Cos, "Tan": (*Complex).Tan,
+	"Sinh": (*Complex).Sinh, "Cosh": (*Complex).Cosh, "Tanh": (*Complex).Tanh,
+	"Pow": func(z *Complex) *Complex { return z.Pow(ComplexFromComplex128(0.75 - 1.5i)) },
+}
+
+var cmplxFuncs = map[string]func(complex128) complex128{
+	"Exp": cmplx.Exp, "Log": cmplx.Log, "Sqrt": cmplx.Sqrt,
+	"Sin": cmplx.Sin, "Cos": cmplx.Cos, "Tan": cmplx.Tan,
+	"Sinh": cmplx.Sinh, "Cosh": cmplx.Cosh, "Tanh": cmplx.Tanh,
+	"Pow": func(z complex128) complex128 { return cmplx.Pow(z, 0.75-1.5i) },
+}
+
+func TestComplexElementaryComplex128(t *testing.T) {
+	// cmplx is accurate to within a few ulps of the larger part
+	near := func(got, want float64, scale float64) bool {
+		return math.Abs(got-want) <= 1e-14*scale
+	}
+	for name, f := range complexFuncs {
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			x := complex(math.Ldexp(2*rnd.Float64()-1, rnd.Intn(6)-2), math.Ldexp(2*rnd.Float64()-1, rnd.Intn(6)-2))
+			want := cmplxFuncs[name](x)
+			got := f(ComplexFromComplex128(x)).Complex128()
+			scale := cmplx.Abs(want)
+			if !near(real(got), real(want), scale) || !near(imag(got), imag(want), scale) {
+				t.Errorf("%s(%v) = %v, expected %v", name, x, got, want)
+			}
+		}
+	}
+}
+
+func TestComplexElementaryDirected(t *testing.T) {
+	parts := map[string]func(*Complex) *Float{"Re ": (*Complex).Real, "Im ": (*Complex).Imag}
+	for name, f := range complexFuncs {
+		rnd := rand.New(rand.NewSource(2))
+		for i := 0; i < 6; i++ {
+			x := complex(math.Ldexp(rnd.Float64(), rnd.Intn(6)-3), math.Ldexp(rnd.Float64(), rnd.Intn(6)-3))
+			if rnd.Intn(2) == 0 {
+				x = -x
+			}
+			for part, p := range parts {
+				g := func(x []*Float) *Float { return p(f(NewComplex(x[0], x[1]))) }
+				checkDirected(t, part+name, g, []float64{real(x), imag(x)}, []uint{2, 24, 100})
+			}
+		}
+	}
+}
+
+func TestComplexElementaryExact(t *testing.T) {
+	c := ComplexFromComplex128
+	i := c(1i)
+	cases := []struct {
+		name string
+		got  *Complex
+		want complex128
+	}{
+		{"Sqrt(3+4i)", c(3 + 4i).Sqrt(), 2 + 1i},
+		{"Sqrt(-3-4i)", c(-3 - 4i).Sqrt(), 1 - 2i},
+		{"Sqrt(-4)", c(-4).Sqrt(), 2i},
+		{"Sqrt(-4-0i)", c(complex(-4, math.Copysign(0, -1))).Sqrt(), -2i},
+		{"Sqrt(2i)", c(2i).Sqrt(), 1 + 1i},
+		{"Exp(0)", c(0).Exp(), 1},
+		{"Log(1)", c(1).Log(), 0},
+		{"Sin(0)", c(0).Sin(), 0},
+		{"Cosh(0)", c(0).Cosh(), 1},
+		{"Pow(i, 2)", i.Pow(c(2)), -1},
+		{"Pow(1+i, 2)", c(1 + 1i).Pow(c(2)), 2i},
+		{"Pow(1+i, -2)", c(1 + 1i).Pow(c(-2)), -0.5i},
+		{"Pow(-4, 0.5)", c(-4).Pow(c(0.5)), 2i},
+		{"Pow(-7+24i, 0.25)", c(-7 + 24i).Pow(c(0.25)), 2 + 1i},
+		{"Pow(-7+24i, 0.75)", c(-7 + 24i).Pow(c(0.75)), 2 + 11i},
+		{"Pow(i, 1e300)", i.Pow(c(1e300)), 1},
+		{"Pow(-1, 0x1p70 + 0x1p69)", c(-1).Pow(c(0x1p70 + 0x1p69)), 1},
+		{"Pow(i, 0x1p70 + 0x1p69 + 1)", i.Pow(NewComplex(NewFloat(0x1p70+0x1p69).SetPrec(80).Add(NewFloat(1)), nil)), 1i},
+		{"Pow(0, 2+i)", c(0).Pow(c(2 + 1i)), 0},
+		{"Pow(5, 0)", c(5).Pow(c(0)), 1},
+	}
+	for _, k := range cases {
+		if !sameComplex128(k.got, k.want) || k.got.Real().Acc() != big.Exact || k.got.Imag().Acc() != big.Exact {
+			t.Errorf("%s = %v, expected exactly %v", k.name, k.got, k.want)
+		}
+	}
+
+	// a part that is zero is exactly zero, and the other part is correctly
+	// rounded
+	zeros := []struct {
+		name   string
+		got    *Complex
+		want   float64
+		reZero bool
+	}{
+		{"Pow(-2, 1.5)", c(-2).Pow(c(1.5)), -2 * math.Sqrt2, true},
+		{"Pow(-2, 2.5)", c(-2).Pow(c(2.5)), 4 * math.Sqrt2, true},
+		{"Pow(2i, 3)", c(2i).Pow(c(3)), -8, true},
+		{"Pow(i, 0.5i)", i.Pow(c(0.5i)), math.Exp(-math.Pi / 4), false},
+		{"Pow(i, 1+0.5i)", i.Pow(c(1 + 0.5i)), math.Exp(-math.Pi / 4), true},
+		{"Pow(-1, 1+i)", c(-1).Pow(c(1 + 1i)), -math.Exp(-math.Pi), false},
+	}
+	for _, z := range zeros {
+		zero, other := z.got.Imag(), z.got.Real()
+		if z.reZero {
+			zero, other = other, zero
+		}
+		if zero.Sign() != 0 || math.Abs(float64Of((*big.Float)(other))-z.want) > 1e-15*math.Abs(z.want) {
+			t.Errorf("%s = %v", z.name, z.got)
+		}
+	}
+}
+
+func TestComplexElementarySpecial(t *testing.T) {
+	inf, nz := math.Inf(1), math.Copysign(0, -1)
+	parts := []float64{0, nz, 1.5, -2, inf, -inf}
+	for name, f := range complexFuncs {
+		for _, a := range parts {
+			for _, b := range parts {
+				x := complex(a, b)
+				want := cmplxFuncs[name](x)
+				if math.IsInf(a, 0) && math.IsInf(b, 0) && name == "Pow" {
+					continue
+				}
+				var got *Complex
+				func() {
+					defer func() {
+						if r := recover(); r != nil && r != ErrNaN {
+							t.Errorf("%s(%v) panicked with %v", name, x, r)
+						}
+					}()
+					got = f(ComplexFromComplex128(x))
+				}()
+				nan := math.IsNaN(real(want)) || math.IsNaN(imag(want))
+				switch {
+				case nan && got != nil:
+					t.Errorf("%s(%v) = %v, expected a panic", name, x, got)
+				case nan:
+				case got == nil:
+					t.Errorf("%s(%v) panicked, expected %v", name, x, want)
+				case !sameSpecial(got.Real(), real(want)) || !sameSpecial(got.Imag(), imag(want)):
+					t.Errorf("%s(%v) = %v, expected %v", name, x, got, want)
+				}
+			}
+		}
+	}
+}
+
+// sameSpecial returns whether f is want, for a zero or infinite want, or
+// is within a few ulps of it, for a finite want.
+func sameSpecial(f *Float, want float64) bool {
+	if want == 0 || math.IsInf(want, 0) {
+		return sameFloat64(f, want)
+	}
+	return math.Abs(float64Of((*big.Float)(f))-want) <= 1e-14*math.Abs(want)
+}
+
+func TestComplexElementaryLarge(t *testing.T) {
+	c := ComplexFromComplex128
+	cases := []struct {
+		name string
+		got  *Complex
+		want complex128
+	}{
+		{"Exp(1e10+i)", c(1e10 + 1i).Exp(), complex(math.Inf(1), math.Inf(1))},
+		{"Exp(-1e10+2i)", c(-1e10 + 2i).Exp(), complex(math.Copysign(0, -1), 0)},
+		{"Sin(1+1e10i)", c(1 + 1e10i).Sin(), complex(math.Inf(1), math.Inf(1))},
+		{"Cosh(-1e10+3i)", c(-1e10 + 3i).Cosh(), complex(math.Inf(-1), math.Inf(-1))},
+		{"Tan(1+1e10i)", c(1 + 1e10i).Tan(), complex(0, 1)},
+		{"Tanh(-1e10+2i)", c(-1e10 + 2i).Tanh(), complex(-1, math.Copysign(0, -1))},
+		{"Pow(2, 1e10)", c(2).Pow(c(1e10 + 1)), complex(math.Inf(1), 0)},
+	}
+	for _, k := range cases {
+		if !sameComplex128(k.got, k.want) {
+			t.Errorf("%s = %v, expected %v", k.name, k.got, k.want)
+		}
+	}
+	// the real part of tanh(40 + i) is 1 + d, for d > 0 with the sign of
+	// -cos(2), so small that only rounding up sees it
+	z := c(40 + 1i).SetPrec(100)
+	if got := z.SetMode(big.ToNegativeInf).Tanh().Real(); got.CmpInt64(1) != 0 {
+		t.Errorf("Tanh(40+i) rounded down = %v", got)
+	}
+	if got := z.SetMode(big.ToPositiveInf).Tanh().Real(); got.CmpInt64(1) <= 0 {
+		t.Errorf("Tanh(40+i) rounded up = %v", got)
+	}
+	// the norm of these is beyond the exponent range of big.Float
+	huge := NewComplex(NewFloat(3).SetExp(1<<30), NewFloat(4).SetExp(1<<30))
+	if got, want := huge.Log().Real(), NewFloat(5).SetExp(1<<30).Log(); got.Cmp(want) != 0 {
+		t.Errorf("Log(huge) = %v, expected %v", got, want)
+	}
+	if got := huge.Sqrt(); !sameComplex128(got.Quo(NewComplex(NewFloat(1).SetExp(1<<29), nil)), 2+1i) {
+		t.Errorf("Sqrt(huge) = %v", got)
+	}
+}
+
+func BenchmarkComplexExp256(b *testing.B) {
+	z := ComplexFromComplex128(1.25 - 0.5i).SetPrec(256)
+	for i := 0; i < b.N; i++ {
+		z.Exp()
+	}
+}
+
+func BenchmarkComplexPow256(b *testing.B) {
+	z := ComplexFromComplex128(1.25 - 0.5i).SetPrec(256)
+	y := ComplexFromComplex128(0.5 + 2i)
+	for i := 0; i < b.N; i++ {
+		z.Pow(y)
+	}
+}
@@ -0,0 +1,238 @@
+package mathx
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// sameComplex128 returns whether z is c, with the signs of zero parts.
+func sameComplex128(z *Complex, c complex128) bool {
+	return sameFloat64(z.Real(), real(c)) && sameFloat64(z.Imag(), imag(c))
+}
+
+func TestComplexArithmetic(t *testing.T) {
+	x := ComplexFromComplex128(1 + 2i)
+	y := ComplexFromComplex128(3 - 4i)
+	cases := []struct {
+		name string
+		got  *Complex
+		want complex128
+	}{
+		{"Add", x.Add(y), 4 - 2i},
+		{"Sub", x.Sub(y), -2 + 6i},
+		{"Mul", x.Mul(y), 11 + 2i},
+		{"Quo", x.Quo(y), -0.2 + 0.4i},
+		{"Neg", x.Neg(), -1 - 2i},
+		{"Conj", x.Conj(), 1 - 2i},
+		{"Mul Conj", x.Mul(x.Conj()), 5},
+		{"zero value", new(Complex).Add(x), 1 + 2i},
+	}
+	for _, c := range cases {
+		if !sameComplex128(c.got, c.want) {
+			t.Errorf("%s = %v, expected %v", c.name, c.got, c.want)
+		}
+	}
+	if got := y.Abs(); !sameFloat64(got, 5) || got.Acc() != big.Exact {
+		t.Errorf("|3-4i| = %v (%v)", got, got.Acc())
+	}
+	if got := ComplexFromComplex128(complex(-1, 0)).Arg(); !sameFloat64(got, math.Pi) {
+		t.Errorf("Arg(-1+0i) = %v", got)
+	}
+	if got := ComplexFromComplex128(complex(-1, math.Copysign(0, -1))).Arg(); !sameFloat64(got, -math.Pi) {
+		t.Errorf("Arg(-1-0i) = %v", got)
+	}
+
+	// the parts are rounded once, so Mul and Quo match complex128 when its
+	// fused operations are exact, and are correctly rounded otherwise
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := complex(randFloat64(rnd), randFloat64(rnd))
+		b := complex(randFloat64(rnd), randFloat64(rnd))
+		if b == 0 {
+			continue
+		}
+		za, zb := ComplexFromComplex128(a), ComplexFromComplex128(b)
+		ra, ia := new(big.Rat).SetFloat64(real(a)), new(big.Rat).SetFloat64(imag(a))
+		rb, ib := new(big.Rat).SetFloat64(real(b)), new(big.Rat).SetFloat64(imag(b))
+		mul := func(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) }
+		n := new(big.Rat).Add(mul(rb, rb), mul(ib, ib))
+		wants := []struct {
+			name string
+			got  *Float
+			want *big.Rat
+		}{
+			{"Re Mul", za.Mul(zb).Real(), new(big.Rat).Sub(mul(ra, rb), mul(ia, ib))},
+			{"Im Mul", za.Mul(zb).Imag(), new(big.Rat).Add(mul(ra, ib), mul(ia, rb))},
+			{"Re Quo", za.Quo(zb).Real(), new(big.Rat).Quo(new(big.Rat).Add(mul(ra, rb), mul(ia, ib)), n)},
+			{"Im Quo", za.Quo(zb).Imag(), new(big.Rat).Quo(new(big.Rat).Sub(mul(ia, rb), mul(ra, ib)), n)},
+		}
+		for _, w := range wants {
+			if !checkRounded(w.got, big.ToNearestEven, func(y *big.Rat) int { return y.Cmp(w.want) }) {
+				t.Errorf("%s(%v, %v) = %v, expected %v", w.name, a, b, w.got, w.want.FloatString(20))
+			}
+		}
+	}
+}
+
+func TestComplexWideExponents(t *testing.T) {
+	// parts far apart in exponent only decide the rounding, and must not
+	// cost an exact sum across the gap
+	const e = 100000000
+	p2 := func(k int) *Float { return (*Float)(pow2(k)) }
+	sum := func(x, y *Float) *Float { return x.SetPrec(200).Add(y) }
+	one := ComplexFromComplex128(1 + 1i)
+	cases := []struct {
+		mode         big.RoundingMode
+		mulRe, mulIm *Float
+		quoRe, quoIm *Float
+	}{
+		{big.ToNearestEven, p2(e), p2(e), p2(e - 1), p2(e - 1).Neg()},
+		{big.ToZero, sum(p2(e), p2(e-64).Neg()), p2(e), p2(e - 1), sum(p2(e-1).Neg(), p2(e-65))},
+		{big.AwayFromZero, p2(e), sum(p2(e), p2(e-63)), sum(p2(e-1), p2(e-64)), p2(e - 1).Neg()},
+	}
+	for _, c := range cases {
+		start := time.Now()
+		x := NewComplex(p2(e).SetPrec(64).SetMode(c.mode), p2(-e))
+		m, q := x.Mul(one), x.Quo(one)
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("Mul and Quo in %v took %v", c.mode, d)
+		}
+		if m.Real().Cmp(c.mulRe) != 0 || m.Imag().Cmp(c.mulIm) != 0 {
+			t.Errorf("Mul in %v = %v, expected %v + %vi", c.mode, m, c.mulRe, c.mulIm)
+		}
+		if q.Real().Cmp(c.quoRe) != 0 || q.Imag().Cmp(c.quoIm) != 0 {
+			t.Errorf("Quo in %v = %v, expected %v + %vi", c.mode, q, c.quoRe, c.quoIm)
+		}
+	}
+
+	// and are correctly rounded in every mode
+	rnd := rand.New(rand.NewSource(2))
+	modes := []big.RoundingMode{big.ToNearestEven, big.ToNearestAway, big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf}
+	for i := 0; i < 100; i++ {
+		part := func() *Float {
+			return (*Float)(new(big.Float).SetMantExp(big.NewFloat(randFloat64(rnd)), rnd.Intn(4000)-2000))
+		}
+		mode := modes[i%len(modes)]
+		za := NewComplex(part().SetMode(mode), part())
+		zb := NewComplex(part(), part())
+		if zb.IsZero() {
+			continue
+		}
+		rat := func(f *Float) *big.Rat { r, _ := f.Rat(nil); return r }
+		ra, ia, rb, ib := rat(za.Real()), rat(za.Imag()), rat(zb.Real()), rat(zb.Imag())
+		mul := func(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) }
+		n := new(big.Rat).Add(mul(rb, rb), mul(ib, ib))
+		wants := []struct {
+			name string
+			got  *Float
+			want *big.Rat
+		}{
+			{"Re Mul", za.Mul(zb).Real(), new(big.Rat).Sub(mul(ra, rb), mul(ia, ib))},
+			{"Im Mul", za.Mul(zb).Imag(), new(big.Rat).Add(mul(ra, ib), mul(ia, rb))},
+			{"Re Quo", za.Quo(zb).Real(), new(big.Rat).Quo(new(big.Rat).Add(mul(ra, rb), mul(ia, ib)), n)},
+			{"Im Quo", za.Quo(zb).Imag(), new(big.Rat).Quo(new(big.Rat).Sub(mul(ia, rb), mul(ra, ib)), n)},
+		}
+		for _, w := range wants {
+			if !checkRounded(w.got, mode, func(y *big.Rat) int { return y.Cmp(w.want) }) {
+				t.Errorf("%s(%v, %v) in %v = %v", w.name, za, zb, mode, w.got)
+			}
+		}
+	}
+}
+
+func TestComplexInf(t *testing.T) {
+	// the parts are those of complex128, and a NaN part is a panic
+	inf := math.Inf(1)
+	cases := []struct{ a, b complex128 }{
+		{complex(inf, 0), 2}, {complex(inf, 1), complex(0, 1)}, {complex(1, inf), complex(2, 3)},
+		{complex(inf, 0), complex(1, 1)}, {complex(inf, inf), 0}, {complex(2, 3), complex(inf, 0)},
+		{1, 0}, {complex(inf, 0), complex(inf, 0)}, {complex(-1, 2), complex(0, -inf)},
+		{complex(inf, 1), complex(1, 1)}, {complex(inf, inf), complex(1, -1)}, {complex(1, 1), 0},
+	}
+	for _, c := range cases {
+		za, zb := ComplexFromComplex128(c.a), ComplexFromComplex128(c.b)
+		ops := []struct {
+			name string
+			f    func() *Complex
+			want complex128
+		}{
+			{"Mul", func() *Complex { return za.Mul(zb) }, c.a * c.b},
+			{"Quo", func() *Complex { return za.Quo(zb) }, c.a / c.b},
+		}
+		for _, op := range ops {
+			var got *Complex
+			func() {
+				defer func() {
+					if r := recover(); r != nil && r != ErrNaN {
+						t.Errorf("%s(%v, %v) panicked with %v", op.name, c.a, c.b, r)
+					}
+				}()
+				got = op.f()
+			}()
+			nan := math.IsNaN(real(op.want)) || math.IsNaN(imag(op.want))
+			if nan && got != nil || !nan && (got == nil || !sameComplex128(got, op.want)) {
+				t.Errorf("%s(%v, %v) = %v, expected %v", op.name, c.a, c.b, got, op.want)
+			}
+		}
+	}
+}
+
+func TestComplexPrecision(t *testing.T) {
+	x := NewComplex(NewFloat(1).SetPrec(100).SetMode(big.ToZero), NewFloat(1).SetPrec(30))
+	if x.Prec() != 100 || x.Mode() != big.ToZero {
+		t.Errorf("x has %d bits and mode %v", x.Prec(), x.Mode())
+	}
+	third := x.Quo(ComplexFromComplex128(3))
+	if re := third.Real(); re.Prec() != 100 || re.Mode() != big.ToZero || re.Acc() != big.Below {
+		t.Errorf("1/3 = %v (%d bits, %v, %v)", re, re.Prec(), re.Mode(), re.Acc())
+	}
+	if got := x.SetPrec(10); got.Real().Prec() != 10 || got.Imag().Prec() != 10 {
+		t.Errorf("SetPrec(10) has parts of %d and %d bits", got.Real().Prec(), got.Imag().Prec())
+	}
+	if got := x.SetMode(big.AwayFromZero); got.Mode() != big.AwayFromZero || got.Imag().Mode() != big.AwayFromZero {
+		t.Errorf("SetMode gave modes %v and %v", got.Mode(), got.Imag().Mode())
+	}
+	if x.Real().Prec() != 100 || x.Mode() != big.ToZero {
+		t.Errorf("x was changed")
+	}
+	var zero Complex
+	if !zero.IsZero() || zero.IsInf() || zero.Prec() != 0 || zero.Exp().Real().Prec() != 64 {
+		t.Errorf("zero value is %v", &zero)
+	}
+	if !ComplexFromComplex128(1).Equal(ComplexFromComplex128(complex(1, math.Copysign(0, -1)))) {
+		t.Errorf("1+0i != 1-0i")
+	}
+	if c := ComplexFromComplex128(0.1 - 2i).Complex128(); c != 0.1-2i {
+		t.Errorf("Complex128 = %v", c)
+	}
+}
+
+func TestComplexFormat(t *testing.T) {
+	x := ComplexFromComplex128(1.5 - 2i)
+	cases := []struct {
+		got, want string
+	}{
+		{x.String(), "(1.5-2i)"},
+		{ComplexFromComplex128(complex(0, math.Inf(1))).String(), "(0+Infi)"},
+		{x.Text('g', 10), "(1.5-2i)"},
+		{ComplexFromComplex128(1+0.25i).Text('f', 2), "(1.00+0.25i)"},
+		{fmt.Sprintf("%.3f", x), "(1.500-2.000i)"},
+		{fmt.Sprintf("%+v", x.Neg()), "(-1.5+2i)"},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("got %q, expected %q", c.got, c.want)
+		}
+	}
+	// the same as complex128
+	for _, f := range []string{"%v", "%.3f", "%10.2e", "%+g", "%-8.1f"} {
+		c := complex(1.25, -3.5)
+		if got, want := fmt.Sprintf(f, ComplexFromComplex128(c)), fmt.Sprintf(f, c); got != want {
+			t.Errorf("%s gave %q, expected %q", f, got, want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package mathx
+
+// This file is for decoding Ints, Floats, and Rats without mutating a
+// value that may be shared, which the UnmarshalJSON, UnmarshalText,
+// GobDecode, and Scan methods cannot avoid.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ParseIntJSON returns a new Int decoded from the JSON number (or null,
+// which is decoded as nil) in data.
+func ParseIntJSON(data []byte) (*Int, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	x := new(big.Int)
+	if err := x.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return (*Int)(x), nil
+}
+
+// ParseIntText returns a new Int decoded from the text, as produced by
+// MarshalText.
+func ParseIntText(text []byte) (*Int, error) {
+	x := new(big.Int)
+	if err := x.UnmarshalText(text); err != nil {
+		return nil, err
+	}
+	return (*Int)(x), nil
+}
+
+// DecodeIntGob returns a new Int decoded from the gob bytes, as produced
+// by GobEncode.
+func DecodeIntGob(buf []byte) (*Int, error) {
+	x := new(big.Int)
+	if err := x.GobDecode(buf); err != nil {
+		return nil, err
+	}
+	return (*Int)(x), nil
+}
+
+// ReadInt reads a single integer from r, in the same way as fmt.Fscan.
+func ReadInt(r io.Reader) (*Int, error) {
+	x := new(big.Int)
+	if _, err := fmt.Fscan(r, x); err != nil {
+		return nil, err
+	}
+	return (*Int)(x), nil
+}
+
+// ScanInt scans a new Int from the state, for use in implementing
+// fmt.Scanner on types that contain an Int.
+func ScanInt(s fmt.ScanState, ch rune) (*Int, error) {
+	x := new(big.Int)
+	if err := x.Scan(s, ch); err != nil {
+		return nil, err
+	}
+	return (*Int)(x), nil
+}
+
+// textPrec returns a precision that holds every digit of a number written
+// with n characters: at least 64 bits, and 4 bits for each character, as a
+// decimal digit needs log2(10) < 4 bits and a hexadecimal digit 4.
+func textPrec(n int) uint {
+	if prec := uint(4 * n); prec > 64 {
+		return prec
+	}
+	return 64
+}
+
+// ParseFloatText returns a new Float decoded from the text, as produced
+// by MarshalText. Its precision is enough for every digit of the text, so
+// rounding it to the precision of the Float that was encoded gives that
+// Float back.
+func ParseFloatText(text []byte) (*Float, error) {
+	x := new(big.Float).SetPrec(textPrec(len(text)))
+	if err := x.UnmarshalText(text); err != nil {
+		return nil, err
+	}
+	return (*Float)(x), nil
+}
+
+// ParseFloatJSON returns a new Float decoded from data, which can be a
+// JSON number, a JSON string as produced by MarshalText, or null (which
+// is decoded as nil).
+func ParseFloatJSON(data []byte) (*Float, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	return ParseFloatText(data)
+}
+
+// DecodeFloatGob returns a new Float decoded from the gob bytes, as
+// produced by big.Float's GobEncode, with the original precision and
+// rounding mode.
+func DecodeFloatGob(buf []byte) (*Float, error) {
+	x := new(big.Float)
+	if err := x.GobDecode(buf); err != nil {
+		return nil, err
+	}
+	return (*Float)(x), nil
+}
+
+// ReadFloat reads a single floating-point number from r, in the same way
+// as fmt.Fscan.
+func ReadFloat(r io.Reader) (*Float, error) {
+	x := new(big.Float)
+	if _, err := fmt.Fscan(r, x); err != nil {
+		return nil, err
+	}
+	return (*Float)(x), nil
+}
+
+// ScanFloat scans a new Float from the state, for use in implementing
+// fmt.Scanner on types that contain a Float.
+func ScanFloat(s fmt.ScanState, ch rune) (*Float, error) {
+	x := new(big.Float)
+	if err := x.Scan(s, ch); err != nil {
+		return nil, err
+	}
+	return (*Float)(x), nil
+}
+
+// ParseRatText returns a new Rat decoded from the text, as produced by
+// MarshalText or in any form accepted by NewRatFromString.
+func ParseRatText(text []byte) (*Rat, error) {
+	x := new(big.Rat)
+	if err := x.UnmarshalText(text); err != nil {
+		return nil, err
+	}
+	return (*Rat)(x), nil
+}
+
+// ParseRatJSON returns a new Rat decoded from data, which can be a JSON
+// string as produced by MarshalJSON, a JSON number, or null (which is
+// decoded as nil).
+func ParseRatJSON(data []byte) (*Rat, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+	return ParseRatText(data)
+}
+
+// DecodeRatGob returns a new Rat decoded from the gob bytes, as produced
+// by GobEncode.
+func DecodeRatGob(buf []byte) (*Rat, error) {
+	x := new(big.Rat)
+	if err := x.GobDecode(buf); err != nil {
+		return nil, err
+	}
+	return (*Rat)(x), nil
+}
+
+// IntValue holds an Int for use as a struct field with encoding/json,
+// encoding/gob, and similar packages. Decoding into an IntValue replaces
+// the Int it points to with a new one, instead of mutating the old one,
+// which other code may still hold. It has all of the methods of Int.
+type IntValue struct {
+	*Int
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v IntValue) MarshalJSON() ([]byte, error) {
+	if v.Int == nil {
+		return []byte("null"), nil
+	}
+	return v.Int.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the held Int.
+func (v *IntValue) UnmarshalJSON(data []byte) error {
+	x, err := ParseIntJSON(data)
+	if err != nil {
+		return err
+	}
+	v.Int = x
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v IntValue) MarshalText() ([]byte, error) {
+	if v.Int == nil {
+		return []byte("<nil>"), nil
+	}
+	return v.Int.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, replacing the held
+// Int.
+func (v *IntValue) UnmarshalText(text []byte) error {
+	x, err := ParseIntText(text)
+	if err != nil {
+		return err
+	}
+	v.Int = x
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (v IntValue) GobEncode() ([]byte, error) {
+	if v.Int == nil {
+		return nil, nil
+	}
+	return v.Int.GobEncode()
+}
+
+// GobDecode implements gob.GobDecoder, replacing the held Int.
+func (v *IntValue) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		v.Int = nil
+		return nil
+	}
+	x, err := DecodeIntGob(buf)
+	if err != nil {
+		return err
+	}
+	v.Int = x
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the format of
+// Int.MarshalBinary, or as no bytes for a nil Int.
+func (v IntValue) MarshalBinary() ([]byte, error) {
+	if v.Int == nil {
+		return nil, nil
+	}
+	return v.Int.MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the held
+// Int.
+func (v *IntValue) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		v.Int = nil
+		return nil
+	}
+	x, err := DecodeIntBinary(data)
+	if err != nil {
+		return err
+	}
+	v.Int = x
+	return nil
+}
+
+// Scan implements fmt.Scanner, replacing the held Int.
+func (v *IntValue) Scan(s fmt.ScanState, ch rune) error {
+	x, err := ScanInt(s, ch)
+	if err != nil {
+		return err
+	}
+	v.Int = x
+	return nil
+}
+
+// FloatValue holds a Float for use as a struct field with encoding/json,
+// encoding/gob, and similar packages. Decoding into a FloatValue replaces the Float it
+// points to with a new one, instead of mutating the old one. It has all of
+// the methods of Float.
+type FloatValue struct {
+	*Float
+}
+
+// MarshalJSON implements json.Marshaler, encoding the Float as a JSON
+// string so that no precision is lost.
+func (v FloatValue) MarshalJSON() ([]byte, error) {
+	if v.Float == nil {
+		return []byte("null"), nil
+	}
+	text, err := v.Float.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	buf.Write(text)
+	buf.WriteByte('"')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a JSON number or
+// string, and replacing the held Float.
+func (v *FloatValue) UnmarshalJSON(data []byte) error {
+	x, err := ParseFloatJSON(data)
+	if err != nil {
+		return err
+	}
+	v.Float = x
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v FloatValue) MarshalText() ([]byte, error) {
+	if v.Float == nil {
+		return []byte("<nil>"), nil
+	}
+	return v.Float.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, replacing the held
+// Float.
+func (v *FloatValue) UnmarshalText(text []byte) error {
+	x, err := ParseFloatText(text)
+	if err != nil {
+		return err
+	}
+	v.Float = x
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, in the format of
+// Float.MarshalBinary, or as no bytes for a nil Float.
+func (v FloatValue) MarshalBinary() ([]byte, error) {
+	if v.Float == nil {
+		return nil, nil
+	}
+	return v.Float.MarshalBinary()
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the held
+// Float.
+func (v *FloatValue) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		v.Float = nil
+		return nil
+	}
+	x, err := DecodeFloatBinary(data)
+	if err != nil {
+		return err
+	}
+	v.Float = x
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+func (v FloatValue) GobEncode() ([]byte, error) {
+	if v.Float == nil {
+		return nil, nil
+	}
+	return (*big.Float)(v.Float).GobEncode()
+}
+
+// GobDecode implements gob.GobDecoder, replacing the held Float.
+func (v *FloatValue) GobDecode(buf []byte) error {
+	if len(buf) == 0 {
+		v.Float = nil
+		return nil
+	}
+	x, err := DecodeFloatGob(buf)
+	if err != nil {
+		return err
+	}
+	v.Float = x
+	return nil
+}
+
+// Scan implements fmt.Scanner, replacing the held Float.
+func (v *FloatValue) Scan(s fmt.ScanState, ch rune) error {
+	x, err := ScanFloat(s, ch)
+	if err != nil {
+		return err
+	}
+	v.Float = x
+	return nil
+}
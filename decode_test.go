@@ -0,0 +1,179 @@
+package mathx
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseIntConstructors(t *testing.T) {
+	s := "-93845895110924997939619620205961794350920309182366517272043413179685324014761"
+	expected, _ := NewIntFromString(s, 10)
+
+	if x, err := ParseIntJSON([]byte(s)); err != nil || x.Cmp(expected) != 0 {
+		t.Errorf("ParseIntJSON gave %v, %v", x, err)
+	}
+	if x, err := ParseIntJSON([]byte("null")); err != nil || x != nil {
+		t.Errorf("ParseIntJSON(null) gave %v, %v", x, err)
+	}
+	if _, err := ParseIntJSON([]byte("1.5")); err == nil {
+		t.Errorf("ParseIntJSON(1.5) should fail")
+	}
+	if x, err := ParseIntText([]byte(s)); err != nil || x.Cmp(expected) != 0 {
+		t.Errorf("ParseIntText gave %v, %v", x, err)
+	}
+	buf, _ := expected.GobEncode()
+	if x, err := DecodeIntGob(buf); err != nil || x.Cmp(expected) != 0 {
+		t.Errorf("DecodeIntGob gave %v, %v", x, err)
+	}
+	r := strings.NewReader("  " + s + " 12")
+	if x, err := ReadInt(r); err != nil || x.Cmp(expected) != 0 {
+		t.Errorf("ReadInt gave %v, %v", x, err)
+	}
+	if x, err := ReadInt(r); err != nil || x.Int64() != 12 {
+		t.Errorf("second ReadInt gave %v, %v", x, err)
+	}
+	if _, err := ReadInt(r); err == nil {
+		t.Errorf("ReadInt at EOF should fail")
+	}
+}
+
+func TestParseFloatConstructors(t *testing.T) {
+	expected := NewFloat(1.25)
+	for _, s := range []string{"1.25", `"1.25"`, "125e-2"} {
+		if x, err := ParseFloatJSON([]byte(s)); err != nil || x.Cmp(expected) != 0 {
+			t.Errorf("ParseFloatJSON(%s) gave %v, %v", s, x, err)
+		}
+	}
+	if x, err := ParseFloatText([]byte("-Inf")); err != nil || !x.IsInf() || x.Sign() > 0 {
+		t.Errorf("ParseFloatText(-Inf) gave %v, %v", x, err)
+	}
+	if x, err := ReadFloat(strings.NewReader(" 1.25 ")); err != nil || x.Cmp(expected) != 0 {
+		t.Errorf("ReadFloat gave %v, %v", x, err)
+	}
+
+	// more than 64 bits survive text and JSON
+	third := NewFloat(1).SetPrec(200).Quo(NewFloat(3))
+	text, _ := third.MarshalText()
+	if x, err := ParseFloatText(text); err != nil || x.Prec() < 200 || x.SetPrec(200).Cmp(third) != 0 {
+		t.Errorf("ParseFloatText(%s) gave %v, %v", text, x, err)
+	}
+	data, _ := json.Marshal(FloatValue{third})
+	var v FloatValue
+	if err := json.Unmarshal(data, &v); err != nil || v.SetPrec(200).Cmp(third) != 0 {
+		t.Errorf("JSON round trip of %v gave %v, %v", third, v.Float, err)
+	}
+}
+
+type scannedPair struct {
+	a *Int
+	b *Float
+}
+
+func (p *scannedPair) Scan(s fmt.ScanState, ch rune) error {
+	var err error
+	if p.a, err = ScanInt(s, 'd'); err != nil {
+		return err
+	}
+	p.b, err = ScanFloat(s, 'g')
+	return err
+}
+
+func TestScanConstructors(t *testing.T) {
+	var p scannedPair
+	if _, err := fmt.Sscan("42 2.5", &p); err != nil || p.a.Int64() != 42 || p.b.Cmp(NewFloat(2.5)) != 0 {
+		t.Errorf("Sscan gave %v, %v, %v", p.a, p.b, err)
+	}
+}
+
+type valueRecord struct {
+	N IntValue
+	F FloatValue
+}
+
+func TestIntValueDoesNotMutate(t *testing.T) {
+	shared := NewInt(7)
+	sharedF := NewFloat(0.5)
+	rec := valueRecord{IntValue{shared}, FloatValue{sharedF}}
+	if err := json.Unmarshal([]byte(`{"N": 123456789012345678901234567890, "F": "3.75"}`), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if shared.Int64() != 7 || sharedF.Cmp(NewFloat(0.5)) != 0 {
+		t.Errorf("decoding mutated the shared values: %v, %v", shared, sharedF)
+	}
+	if rec.N.String() != "123456789012345678901234567890" || rec.F.Cmp(NewFloat(3.75)) != 0 {
+		t.Errorf("decoded %v, %v", rec.N, rec.F)
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil || string(data) != `{"N":123456789012345678901234567890,"F":"3.75"}` {
+		t.Errorf("Marshal gave %s, %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`{"N": 1, "F": 2.5}`), &rec); err != nil || rec.N.Int64() != 1 || rec.F.Cmp(NewFloat(2.5)) != 0 {
+		t.Errorf("decoding a JSON number gave %v, %v, %v", rec.N, rec.F, err)
+	}
+	if err := json.Unmarshal([]byte(`{"N": null, "F": null}`), &rec); err != nil || rec.N.Int != nil || rec.F.Float != nil {
+		t.Errorf("decoding null gave %v, %v, %v", rec.N.Int, rec.F.Float, err)
+	}
+
+	var buf bytes.Buffer
+	shared = NewInt(-99)
+	if err := gob.NewEncoder(&buf).Encode(struct{ N IntValue }{IntValue{NewInt(12345)}}); err != nil {
+		t.Fatal(err)
+	}
+	out := struct{ N IntValue }{IntValue{shared}}
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if shared.Int64() != -99 || out.N.Int64() != 12345 {
+		t.Errorf("gob decoding gave %v and mutated %v", out.N, shared)
+	}
+
+	// gob of a FloatValue field, including into a zero FloatValue
+	buf.Reset()
+	third := NewFloat(1).SetPrec(200).Quo(NewFloat(3))
+	if err := gob.NewEncoder(&buf).Encode(valueRecord{IntValue{NewInt(1)}, FloatValue{third}}); err != nil {
+		t.Fatal(err)
+	}
+	var zero valueRecord
+	if err := gob.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&zero); err != nil || zero.F.Cmp(third) != 0 || zero.F.Prec() != 200 {
+		t.Errorf("gob decoding gave %v, %v", zero.F.Float, err)
+	}
+	rec = valueRecord{IntValue{shared}, FloatValue{sharedF}}
+	if err := gob.NewDecoder(&buf).Decode(&rec); err != nil || sharedF.Cmp(NewFloat(0.5)) != 0 || rec.F.Cmp(third) != 0 {
+		t.Errorf("gob decoding gave %v, %v and mutated %v", rec.F.Float, err, sharedF)
+	}
+}
+
+func TestValueBinaryDoesNotMutate(t *testing.T) {
+	shared, sharedF := NewInt(7), NewFloat(0.5)
+	n, f := IntValue{shared}, FloatValue{sharedF}
+	data, _ := NewInt(-12345).MarshalBinary()
+	if err := n.UnmarshalBinary(data); err != nil || n.Int64() != -12345 || shared.Int64() != 7 {
+		t.Errorf("UnmarshalBinary gave %v, %v and mutated %v", n.Int, err, shared)
+	}
+	data, _ = NewFloat(3.75).MarshalBinary()
+	if err := f.UnmarshalBinary(data); err != nil || f.Cmp(NewFloat(3.75)) != 0 || sharedF.Cmp(NewFloat(0.5)) != 0 {
+		t.Errorf("UnmarshalBinary gave %v, %v and mutated %v", f.Float, err, sharedF)
+	}
+
+	// through an Encoder and Decoder, and for nil values
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.Encode(IntValue{NewInt(99)})
+	e.Encode(FloatValue{NewFloat(-2)})
+	e.Flush()
+	d := NewDecoder(&buf)
+	if err := d.Decode(&n); err != nil || n.Int64() != 99 || shared.Int64() != 7 {
+		t.Errorf("Decode gave %v, %v and mutated %v", n.Int, err, shared)
+	}
+	if err := d.Decode(&f); err != nil || f.Cmp(NewFloat(-2)) != 0 || sharedF.Cmp(NewFloat(0.5)) != 0 {
+		t.Errorf("Decode gave %v, %v and mutated %v", f.Float, err, sharedF)
+	}
+	if data, err := (FloatValue{}).MarshalBinary(); err != nil || len(data) != 0 || f.UnmarshalBinary(data) != nil || f.Float != nil {
+		t.Errorf("nil FloatValue round trip gave %v, %v", f.Float, err)
+	}
+}
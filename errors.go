@@ -0,0 +1,229 @@
+package mathx
+
+// This file is for the error values returned by the Checked variants of
+// operations, which never panic on bad input.
+
+import (
+	"errors"
+	"math/big"
+)
+
+var (
+	// ErrDivisionByZero is returned when dividing by zero.
+	ErrDivisionByZero = errors.New("mathx: division by zero")
+	// ErrNoInverse is returned when a modular inverse does not exist.
+	ErrNoInverse = errors.New("mathx: no modular inverse")
+	// ErrDomain is returned when an argument is outside of the domain of a
+	// function, such as the square root of a negative number.
+	ErrDomain = errors.New("mathx: argument out of domain")
+	// ErrNaN is returned when a floating-point operation would produce a
+	// NaN, such as Inf - Inf. (math/big panics with big.ErrNaN instead.)
+	ErrNaN = errors.New("mathx: result is not a number")
+	// ErrOverflow is returned when a result does not fit in the requested
+	// type.
+	ErrOverflow = errors.New("mathx: overflow")
+)
+
+// DivChecked is like Div, but returns ErrDivisionByZero instead of
+// panicking.
+func (z *Int) DivChecked(y *Int) (*Int, error) {
+	if y.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return z.Div(y), nil
+}
+
+// QuoChecked is like Quo, but returns ErrDivisionByZero instead of
+// panicking.
+func (z *Int) QuoChecked(y *Int) (*Int, error) {
+	if y.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return z.Quo(y), nil
+}
+
+// QuoRemChecked is like QuoRem, but returns ErrDivisionByZero instead of
+// panicking.
+func (z *Int) QuoRemChecked(y *Int) (*Int, *Int, error) {
+	if y.Sign() == 0 {
+		return nil, nil, ErrDivisionByZero
+	}
+	q, r := z.QuoRem(y)
+	return q, r, nil
+}
+
+// ModChecked is like Mod, but returns ErrDivisionByZero instead of
+// panicking.
+func (z *Int) ModChecked(y *Int) (*Int, error) {
+	if y.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return z.Mod(y), nil
+}
+
+// RemChecked is like Rem, but returns ErrDivisionByZero instead of
+// panicking.
+func (z *Int) RemChecked(y *Int) (*Int, error) {
+	if y.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return z.Rem(y), nil
+}
+
+// ModInverseChecked is like ModInverse, but returns ErrNoInverse if this
+// is not invertible modulo n, and ErrDivisionByZero if n is 0.
+func (z *Int) ModInverseChecked(n *Int) (*Int, error) {
+	if n.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	if z.GCD(n).Abs().Cmp(NewInt(1)) != 0 {
+		return nil, ErrNoInverse
+	}
+	return z.ModInverse(n), nil
+}
+
+// ExpChecked is like Exp, but returns ErrNoInverse if y is negative and
+// this is not invertible modulo m, and ErrDomain if y is negative and
+// there is no modulus.
+func (z *Int) ExpChecked(y, m *Int) (*Int, error) {
+	if y.Sign() < 0 && (m == nil || m.Sign() == 0) {
+		// big.Int.Exp returns 1 here, which is not this**y
+		return nil, ErrDomain
+	}
+	r := new(big.Int).Exp((*big.Int)(z), (*big.Int)(y), (*big.Int)(m))
+	if r == nil {
+		return nil, ErrNoInverse
+	}
+	return (*Int)(r), nil
+}
+
+// SqrtChecked is like Sqrt, but returns ErrDomain if this is negative.
+func (z *Int) SqrtChecked() (*Int, error) {
+	if z.Sign() < 0 {
+		return nil, ErrDomain
+	}
+	return z.Sqrt(), nil
+}
+
+// NthRootChecked is like NthRoot, but returns ErrDomain if k < 1, or if
+// k is even and this is negative.
+func (z *Int) NthRootChecked(k int) (*Int, error) {
+	if k < 1 || (k&1 == 0 && z.Sign() < 0) {
+		return nil, ErrDomain
+	}
+	return z.NthRoot(k), nil
+}
+
+// Int64Checked is like Int64, but returns ErrOverflow if this does not
+// fit in an int64.
+func (z *Int) Int64Checked() (int64, error) {
+	if !(*big.Int)(z).IsInt64() {
+		return 0, ErrOverflow
+	}
+	return z.Int64(), nil
+}
+
+// Uint64Checked is like Uint64, but returns ErrOverflow if this does not
+// fit in a uint64.
+func (z *Int) Uint64Checked() (uint64, error) {
+	if !(*big.Int)(z).IsUint64() {
+		return 0, ErrOverflow
+	}
+	return z.Uint64(), nil
+}
+
+// checkedFloat runs a big.Float operation, converting a big.ErrNaN panic
+// into ErrNaN.
+func checkedFloat(op func() *big.Float) (f *Float, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(big.ErrNaN); !ok {
+				panic(r)
+			}
+			f, err = nil, ErrNaN
+		}
+	}()
+	return (*Float)(op()), nil
+}
+
+// AddChecked is like Add, but returns ErrNaN for Inf + -Inf instead of
+// panicking.
+func (z *Float) AddChecked(y *Float) (*Float, error) {
+	return checkedFloat(func() *big.Float {
+		return new(big.Float).Add((*big.Float)(z), (*big.Float)(y))
+	})
+}
+
+// SubChecked is like Sub, but returns ErrNaN for Inf - Inf instead of
+// panicking.
+func (z *Float) SubChecked(y *Float) (*Float, error) {
+	return checkedFloat(func() *big.Float {
+		return new(big.Float).Sub((*big.Float)(z), (*big.Float)(y))
+	})
+}
+
+// MulChecked is like Mul, but returns ErrNaN for 0 * Inf instead of
+// panicking.
+func (z *Float) MulChecked(y *Float) (*Float, error) {
+	return checkedFloat(func() *big.Float {
+		return new(big.Float).Mul((*big.Float)(z), (*big.Float)(y))
+	})
+}
+
+// QuoChecked is like Quo, but returns ErrDivisionByZero if y is zero
+// (rather than an infinity, or panicking for 0 / 0), and ErrNaN for
+// Inf / Inf.
+func (z *Float) QuoChecked(y *Float) (*Float, error) {
+	if y.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return checkedFloat(func() *big.Float {
+		return new(big.Float).Quo((*big.Float)(z), (*big.Float)(y))
+	})
+}
+
+// SqrtChecked is like Sqrt, but returns ErrDomain if this is negative
+// instead of panicking.
+func (z *Float) SqrtChecked() (*Float, error) {
+	if z.Sign() < 0 {
+		return nil, ErrDomain
+	}
+	return z.Sqrt(), nil
+}
+
+// IntChecked is like Int, but returns ErrOverflow if this is infinite.
+func (z *Float) IntChecked() (*Int, big.Accuracy, error) {
+	if z.IsInf() {
+		return nil, big.Exact, ErrOverflow
+	}
+	i, acc := z.Int()
+	return i, acc, nil
+}
+
+// Int64Checked is like Int64, but returns ErrOverflow if the integer part
+// of this does not fit in an int64.
+func (z *Float) Int64Checked() (int64, error) {
+	i, _, err := z.IntChecked()
+	if err != nil {
+		return 0, err
+	}
+	return i.Int64Checked()
+}
+
+// QuoChecked is like Quo, but returns ErrDivisionByZero if y is zero
+// instead of panicking.
+func (z *Rat) QuoChecked(y *Rat) (*Rat, error) {
+	if y.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return z.Quo(y), nil
+}
+
+// InvChecked is like Inv, but returns ErrDivisionByZero if this is zero
+// instead of panicking.
+func (z *Rat) InvChecked() (*Rat, error) {
+	if z.Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return z.Inv(), nil
+}
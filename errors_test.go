@@ -0,0 +1,127 @@
+package mathx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIntChecked(t *testing.T) {
+	zero := NewInt(0)
+	x := NewInt(17)
+	if _, err := x.DivChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("DivChecked by zero gave %v", err)
+	}
+	if _, err := x.QuoChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("QuoChecked by zero gave %v", err)
+	}
+	if _, _, err := x.QuoRemChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("QuoRemChecked by zero gave %v", err)
+	}
+	if _, err := x.ModChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("ModChecked by zero gave %v", err)
+	}
+	if _, err := x.RemChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("RemChecked by zero gave %v", err)
+	}
+	if q, err := x.DivChecked(NewInt(5)); err != nil || q.Int64() != 3 {
+		t.Errorf("DivChecked(17, 5) gave %v, %v", q, err)
+	}
+
+	if _, err := NewInt(6).ModInverseChecked(NewInt(9)); err != ErrNoInverse {
+		t.Errorf("ModInverseChecked(6, 9) gave %v", err)
+	}
+	if _, err := NewInt(6).ModInverseChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("ModInverseChecked(6, 0) gave %v", err)
+	}
+	if inv, err := NewInt(7).ModInverseChecked(NewInt(9)); err != nil || inv.Int64() != 4 {
+		t.Errorf("ModInverseChecked(7, 9) gave %v, %v", inv, err)
+	}
+
+	if _, err := NewInt(6).ExpChecked(NewInt(-1), NewInt(9)); err != ErrNoInverse {
+		t.Errorf("ExpChecked(6, -1, 9) gave %v", err)
+	}
+	if _, err := NewInt(6).ExpChecked(NewInt(-1), nil); err != ErrDomain {
+		t.Errorf("ExpChecked(6, -1) gave %v", err)
+	}
+	if r, err := NewInt(7).ExpChecked(NewInt(-2), NewInt(9)); err != nil || r.Int64() != 7 {
+		t.Errorf("ExpChecked(7, -2, 9) gave %v, %v", r, err)
+	}
+
+	if _, err := NewInt(-4).SqrtChecked(); err != ErrDomain {
+		t.Errorf("SqrtChecked(-4) gave %v", err)
+	}
+	if _, err := NewInt(-4).NthRootChecked(4); err != ErrDomain {
+		t.Errorf("NthRootChecked(-4, 4) gave %v", err)
+	}
+	if _, err := NewInt(4).NthRootChecked(0); err != ErrDomain {
+		t.Errorf("NthRootChecked(4, 0) gave %v", err)
+	}
+	if r, err := NewInt(-27).NthRootChecked(3); err != nil || r.Int64() != -3 {
+		t.Errorf("NthRootChecked(-27, 3) gave %v, %v", r, err)
+	}
+
+	big := NewInt(1).Lsh(64)
+	if _, err := big.Int64Checked(); err != ErrOverflow {
+		t.Errorf("Int64Checked(2^64) gave %v", err)
+	}
+	if _, err := big.Uint64Checked(); err != ErrOverflow {
+		t.Errorf("Uint64Checked(2^64) gave %v", err)
+	}
+	if _, err := NewInt(-1).Uint64Checked(); err != ErrOverflow {
+		t.Errorf("Uint64Checked(-1) gave %v", err)
+	}
+	if v, err := big.Sub64(1).Uint64Checked(); err != nil || v != math.MaxUint64 {
+		t.Errorf("Uint64Checked(2^64 - 1) gave %v, %v", v, err)
+	}
+}
+
+func TestFloatChecked(t *testing.T) {
+	inf := NewFloat(math.Inf(1))
+	ninf := NewFloat(math.Inf(-1))
+	zero := NewFloat(0)
+	one := NewFloat(1)
+
+	if _, err := inf.AddChecked(ninf); err != ErrNaN {
+		t.Errorf("Inf + -Inf gave %v", err)
+	}
+	if _, err := inf.SubChecked(inf); err != ErrNaN {
+		t.Errorf("Inf - Inf gave %v", err)
+	}
+	if _, err := zero.MulChecked(inf); err != ErrNaN {
+		t.Errorf("0 * Inf gave %v", err)
+	}
+	if _, err := inf.QuoChecked(inf); err != ErrNaN {
+		t.Errorf("Inf / Inf gave %v", err)
+	}
+	if _, err := one.QuoChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("1 / 0 gave %v", err)
+	}
+	if _, err := NewFloat(-2).SqrtChecked(); err != ErrDomain {
+		t.Errorf("Sqrt(-2) gave %v", err)
+	}
+	if _, _, err := inf.IntChecked(); err != ErrOverflow {
+		t.Errorf("Int(Inf) gave %v", err)
+	}
+	if _, err := NewFloat(1e19).Int64Checked(); err != ErrOverflow {
+		t.Errorf("Int64(1e19) gave %v", err)
+	}
+	if v, err := NewFloat(-2.5).Int64Checked(); err != nil || v != -2 {
+		t.Errorf("Int64(-2.5) gave %v, %v", v, err)
+	}
+	if s, err := one.AddChecked(one); err != nil || s.Cmp(NewFloat(2)) != 0 {
+		t.Errorf("1 + 1 gave %v, %v", s, err)
+	}
+}
+
+func TestRatChecked(t *testing.T) {
+	zero := NewRat(0, 1)
+	if _, err := NewRat(1, 2).QuoChecked(zero); err != ErrDivisionByZero {
+		t.Errorf("1/2 / 0 gave %v", err)
+	}
+	if _, err := zero.InvChecked(); err != ErrDivisionByZero {
+		t.Errorf("1/0 gave %v", err)
+	}
+	if q, err := NewRat(1, 2).QuoChecked(NewRat(3, 4)); err != nil || q.Cmp(NewRat(2, 3)) != 0 {
+		t.Errorf("1/2 / 3/4 gave %v, %v", q, err)
+	}
+}
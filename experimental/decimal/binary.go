@@ -0,0 +1,92 @@
+package decimal
+
+// This file is for the binary format; see the mathx package for its
+// description.
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/swenson/mathx"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The number of whole
+// and fraction digits is kept, so that "1.50" reads back as "1.50".
+func (d *Decimal) MarshalBinary() ([]byte, error) {
+	buf := []byte{0}
+	if d.neg {
+		buf[0] = 1
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(d.whole)))
+	buf = binary.AppendUvarint(buf, uint64(len(d.fraction)))
+	digits := new(big.Int)
+	if s := digitsToString(d.whole) + digitsToString(d.fraction); s != "" {
+		digits.SetString(s, 10)
+	}
+	buf = append(buf, digits.Bytes()...)
+	return mathx.AppendBinaryRecord(nil, mathx.TagDecimal, buf), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see
+// DecodeBinary for an alternative.
+func (d *Decimal) UnmarshalBinary(data []byte) error {
+	e, err := DecodeBinary(data)
+	if err != nil {
+		return err
+	}
+	*d = *e
+	return nil
+}
+
+// zeroAllowance is how many more digits than its magnitude holds a
+// decoded Decimal may have, for the leading and trailing zeros that
+// MarshalBinary counts but does not write, as in "0.001" or "1.500".
+const zeroAllowance = 1024
+
+// DecodeBinary returns a new Decimal decoded from data, as produced by
+// MarshalBinary. A record counting more zero digits than zeroAllowance is
+// rejected, so that a short record cannot allocate a great deal.
+func DecodeBinary(data []byte) (*Decimal, error) {
+	payload, rest, err := mathx.ReadBinaryRecord(data, mathx.TagDecimal)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 || len(payload) < 1 || payload[0] > 1 {
+		return nil, mathx.ErrBinaryFormat
+	}
+	neg := payload[0] == 1
+	payload = payload[1:]
+	nWhole, k := binary.Uvarint(payload)
+	if k <= 0 {
+		return nil, mathx.ErrBinaryFormat
+	}
+	payload = payload[k:]
+	nFraction, k := binary.Uvarint(payload)
+	if k <= 0 {
+		return nil, mathx.ErrBinaryFormat
+	}
+	payload = payload[k:]
+	// each byte of the magnitude holds log10(256) < 2.41 digits
+	limit := uint64(len(payload))*241/100 + 1 + zeroAllowance
+	if nWhole > limit || nFraction > limit || nWhole+nFraction > limit {
+		return nil, mathx.ErrBinaryFormat
+	}
+	s := ""
+	if len(payload) > 0 {
+		s = new(big.Int).SetBytes(payload).String()
+	}
+	n := int(nWhole + nFraction)
+	if n < len(s) {
+		return nil, mathx.ErrBinaryFormat
+	}
+	// the magnitude is the last len(s) of the n digits
+	digits := make([]int8, n)
+	copy(digits[n-len(s):], parseDigits(s))
+	return &Decimal{
+		neg:      neg,
+		whole:    digits[:nWhole:nWhole],
+		fraction: digits[nWhole:],
+	}, nil
+}
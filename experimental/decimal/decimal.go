@@ -28,10 +28,18 @@ TODO: subtraction is really, really slow. Should use faster algorithm.
 package decimal
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 )
 
+// ErrSyntax is returned by New when the string is not a decimal number.
+var ErrSyntax = errors.New("decimal: unknown format for decimal number")
+
+// ErrInvalid is returned by the Checked operations for a nil Decimal or one
+// with digits outside 0 to 9.
+var ErrInvalid = errors.New("decimal: nil or malformed decimal number")
+
 // RoundingMode represents the rounding requested.
 type RoundingMode int
 
@@ -62,7 +70,7 @@ var one = &Decimal{
 	fraction: []int8{},
 }
 
-var decimalRe = regexp.MustCompile(`-?([0-9]*)(\.[0-9]*)?`)
+var decimalRe = regexp.MustCompile(`^-?([0-9]*)(\.[0-9]*)?$`)
 
 func parseDigits(s string) []int8 {
 	if s == "" {
@@ -78,13 +86,14 @@ func parseDigits(s string) []int8 {
 	return arr
 }
 
-// New constructs a new Decimal from a string.
+// New constructs a new Decimal from a string, such as "-123.456".
+// It returns ErrSyntax if the string is not of that form.
 func New(s string) (*Decimal, error) {
 	d := new(Decimal)
-	if !decimalRe.MatchString(s) {
-		return nil, fmt.Errorf("Unknown format for decimal number")
-	}
 	match := decimalRe.FindStringSubmatch(s)
+	if match == nil || len(match[1])+len(match[2]) == 0 || match[2] == "." && match[1] == "" {
+		return nil, ErrSyntax
+	}
 	d.neg = false
 	if s[0] == '-' {
 		d.neg = true
@@ -334,6 +343,40 @@ func (d *Decimal) Add(e *Decimal) *Decimal {
 	return s
 }
 
+// valid returns whether d is a Decimal that the operations accept: not nil,
+// and with every digit from 0 to 9.
+func (d *Decimal) valid() bool {
+	if d == nil {
+		return false
+	}
+	for _, digits := range [][]int8{d.whole, d.fraction} {
+		for _, x := range digits {
+			if x < 0 || x > 9 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AddChecked is like Add, but returns ErrInvalid instead of panicking or
+// returning garbage if either operand is nil or malformed.
+func (d *Decimal) AddChecked(e *Decimal) (*Decimal, error) {
+	if !d.valid() || !e.valid() {
+		return nil, ErrInvalid
+	}
+	return d.Add(e), nil
+}
+
+// SubChecked is like Sub, but returns ErrInvalid instead of panicking or
+// returning garbage if either operand is nil or malformed.
+func (d *Decimal) SubChecked(e *Decimal) (*Decimal, error) {
+	if !d.valid() || !e.valid() {
+		return nil, ErrInvalid
+	}
+	return d.Sub(e), nil
+}
+
 // Mul10exp multiplies this number by 10 raised to the given power,
 // effectively shifting the number left by the given number of digits.
 func (d *Decimal) Mul10exp(n uint) *Decimal {
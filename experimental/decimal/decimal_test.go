@@ -1,6 +1,9 @@
 package decimal
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestInputOutput(t *testing.T) {
 	cases := []string{"0.0", "-1", "-1.0", "-1.01", "-123.456", "123123123123444444.412341923480192384901"}
@@ -403,3 +406,112 @@ func TestFloor(t *testing.T) {
 		}
 	}
 }
+
+func TestNewSyntax(t *testing.T) {
+	cases := []string{"", "-", ".", "-.", "abc", "1e5", "+1", "1.2.3", "12a", " 1"}
+	for _, c := range cases {
+		if d, err := New(c); err != ErrSyntax {
+			t.Errorf("New(%q) = %v, %v; expected ErrSyntax", c, d, err)
+		}
+	}
+	for _, c := range []string{"1.", ".5", "-0"} {
+		if _, err := New(c); err != nil {
+			t.Errorf("New(%q) failed: %v", c, err)
+		}
+	}
+}
+
+func TestAddSubChecked(t *testing.T) {
+	a, _ := New("953.067")
+	b, _ := New("90.57")
+	sum, err := a.AddChecked(b)
+	if err != nil || sum.String() != "1043.637" {
+		t.Errorf("AddChecked gave %v, %v", sum, err)
+	}
+	diff, err := b.SubChecked(a)
+	if err != nil || diff.String() != "-862.497" {
+		t.Errorf("SubChecked gave %v, %v", diff, err)
+	}
+
+	bad := &Decimal{whole: []int8{1, 12}, fraction: []int8{}}
+	for _, c := range []struct{ x, y *Decimal }{{a, nil}, {nil, b}, {a, bad}, {bad, b}} {
+		if got, err := c.x.AddChecked(c.y); err != ErrInvalid {
+			t.Errorf("AddChecked(%#v, %#v) = %v, %v; expected ErrInvalid", c.x, c.y, got, err)
+		}
+		if got, err := c.x.SubChecked(c.y); err != ErrInvalid {
+			t.Errorf("SubChecked(%#v, %#v) = %v, %v; expected ErrInvalid", c.x, c.y, got, err)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	d, _ := New("-123123123123444444.412341923480192384901")
+	v, err := d.Value()
+	if err != nil || v != "-123123123123444444.412341923480192384901" {
+		t.Errorf("Value gave %v, %v", v, err)
+	}
+	cases := []struct {
+		src      interface{}
+		expected string
+	}{
+		{"-123123123123444444.412341923480192384901", "-123123123123444444.412341923480192384901"},
+		{[]byte("0.000000000000000000000000000001"), "0.000000000000000000000000000001"},
+		{int64(-42), "-42"},
+		{0.1, "0.1"},
+	}
+	for _, c := range cases {
+		var n NullDecimal
+		if err := n.Scan(c.src); err != nil || !n.Valid || n.Decimal.String() != c.expected {
+			t.Errorf("Scan(%v) gave %v, %v, %v", c.src, n.Decimal, n.Valid, err)
+		}
+		if v, err := n.Value(); err != nil || v != c.expected {
+			t.Errorf("Value() after Scan(%v) gave %v, %v", c.src, v, err)
+		}
+	}
+	var n NullDecimal
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("Scan(nil) gave %v, %v", n.Valid, err)
+	}
+	if v, err := n.Value(); err != nil || v != nil {
+		t.Errorf("Value() of NULL gave %v, %v", v, err)
+	}
+	if err := n.Scan("1e5"); err != ErrSyntax {
+		t.Errorf("Scan(1e5) gave %v", err)
+	}
+	if err := n.Scan(true); err == nil {
+		t.Errorf("Scan(true) should fail")
+	}
+}
+
+func TestBinary(t *testing.T) {
+	cases := []string{"0", "0.0", "0.00", ".5", "-.05", "1.50", "-1", "007.0010", "123123123123444444.412341923480192384901",
+		"0." + strings.Repeat("0", 1000) + "1", strings.Repeat("9", 300)}
+	for _, c := range cases {
+		d, _ := New(c)
+		data, err := d.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := DecodeBinary(data)
+		if err != nil || e.String() != c {
+			t.Errorf("%s decoded as %v, %v", c, e, err)
+		}
+		var f Decimal
+		if err := f.UnmarshalBinary(data); err != nil || f.String() != c {
+			t.Errorf("%s unmarshaled as %v, %v", c, &f, err)
+		}
+	}
+	bad := [][]byte{
+		{1, 'D', 3, 2, 0, 0},     // bad sign
+		{1, 'D', 4, 0, 1, 0, 99}, // more digits than counted
+		{1, 'I', 3, 0, 0, 0},     // wrong type
+		// 2**27 whole and fraction digits, from one byte of magnitude
+		{1, 'D', 10, 0, 0x80, 0x80, 0x80, 0x40, 0x80, 0x80, 0x80, 0x40, 1},
+		{1, 'D', 5, 0, 0x81, 0x08, 0x81, 0x08}, // 1025 + 1025 zeros
+	}
+	for _, data := range bad {
+		if _, err := DecodeBinary(data); err == nil {
+			t.Errorf("decoding %v should fail", data)
+		}
+	}
+}
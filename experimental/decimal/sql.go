@@ -0,0 +1,64 @@
+package decimal
+
+// This file is for database/sql support. Decimal implements driver.Valuer
+// directly; NullDecimal is the scan destination, since scanning into a
+// Decimal would mutate a value that may be shared.
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// Value implements driver.Valuer, storing the Decimal as its exact
+// decimal string.
+func (d *Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// ParseSQL returns a new Decimal from a value read from a database/sql
+// driver: an int64, a float64 (using its shortest decimal form), or a
+// string or []byte, which is read exactly.
+func ParseSQL(src interface{}) (*Decimal, error) {
+	switch v := src.(type) {
+	case int64:
+		return New(strconv.FormatInt(v, 10))
+	case float64:
+		return New(strconv.FormatFloat(v, 'f', -1, 64))
+	case []byte:
+		return New(string(v))
+	case string:
+		return New(v)
+	}
+	return nil, fmt.Errorf("decimal: cannot scan %T into Decimal", src)
+}
+
+// NullDecimal is a Decimal that may be NULL, for use with database/sql.
+// It implements sql.Scanner, replacing (never mutating) the held Decimal,
+// and driver.Valuer.
+type NullDecimal struct {
+	Decimal *Decimal
+	Valid   bool // Valid is true if Decimal is not NULL
+}
+
+// Scan implements sql.Scanner.
+func (n *NullDecimal) Scan(src interface{}) error {
+	if src == nil {
+		n.Decimal, n.Valid = nil, false
+		return nil
+	}
+	d, err := ParseSQL(src)
+	if err != nil {
+		return err
+	}
+	n.Decimal, n.Valid = d, true
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n NullDecimal) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Decimal.Value()
+}
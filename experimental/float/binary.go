@@ -0,0 +1,64 @@
+package float
+
+// This file is for the binary format; see the mathx package for its
+// description.
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/swenson/mathx"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The precision is
+// kept.
+func (f *Float) MarshalBinary() ([]byte, error) {
+	buf := []byte{1}
+	if f.sign {
+		buf[0] = 0
+	}
+	buf = binary.AppendUvarint(buf, f.precision)
+	buf = binary.AppendVarint(buf, f.exp)
+	buf = append(buf, f.mantissa.Bytes()...)
+	return mathx.AppendBinaryRecord(nil, mathx.TagExpFloat, buf), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see
+// DecodeBinary for an alternative.
+func (f *Float) UnmarshalBinary(data []byte) error {
+	g, err := DecodeBinary(data)
+	if err != nil {
+		return err
+	}
+	*f = *g
+	return nil
+}
+
+// DecodeBinary returns a new Float decoded from data, as produced by
+// MarshalBinary.
+func DecodeBinary(data []byte) (*Float, error) {
+	payload, rest, err := mathx.ReadBinaryRecord(data, mathx.TagExpFloat)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 || len(payload) < 1 || payload[0] > 1 {
+		return nil, mathx.ErrBinaryFormat
+	}
+	f := new(Float)
+	f.sign = payload[0] == 0
+	payload = payload[1:]
+	var k int
+	f.precision, k = binary.Uvarint(payload)
+	if k <= 0 {
+		return nil, mathx.ErrBinaryFormat
+	}
+	payload = payload[k:]
+	f.exp, k = binary.Varint(payload)
+	if k <= 0 {
+		return nil, mathx.ErrBinaryFormat
+	}
+	f.mantissa = (*mathx.Int)(new(big.Int).SetBytes(payload[k:]))
+	return f, nil
+}
@@ -22,6 +22,9 @@ Currently supported:
 * Addition, subtraction, multiplication, division
 * Square root
 
+Div and Sqrt panic on bad input; DivChecked and SqrtChecked return the
+errors from the mathx package instead.
+
 TODO: rounding, logarithms, exponentiation, and everything else.
 */
 package float
@@ -224,7 +227,26 @@ func (f *Float) Div(_y *Float) *Float {
 	return z.normalize()
 }
 
+// DivChecked is like Div, but returns mathx.ErrDivisionByZero instead of
+// panicking.
+func (f *Float) DivChecked(y *Float) (*Float, error) {
+	if y.mantissa.Sign() == 0 {
+		return nil, mathx.ErrDivisionByZero
+	}
+	return f.Div(y), nil
+}
+
+// SqrtChecked is like Sqrt, but returns mathx.ErrDomain if this is
+// negative instead of panicking.
+func (f *Float) SqrtChecked() (*Float, error) {
+	if f.mantissa.Sign() != 0 && !f.sign {
+		return nil, mathx.ErrDomain
+	}
+	return f.Sqrt(), nil
+}
+
 // Sqrt returns the square root of this number.
+// If this is negative, this function will panic.
 func (f *Float) Sqrt() *Float {
 	//Sqrt uses Newton's Method
 	if f.mantissa.Sign() == 0 {
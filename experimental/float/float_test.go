@@ -247,3 +247,39 @@ func TestFloatSqrtNeg(t *testing.T) {
 	}()
 	x.Sqrt()
 }
+
+func TestFloatChecked(t *testing.T) {
+	if _, err := NewFloat(10.0).DivChecked(NewFloat(0.0)); err != mathx.ErrDivisionByZero {
+		t.Errorf("DivChecked by zero gave %v", err)
+	}
+	if _, err := NewFloat(-10.0).SqrtChecked(); err != mathx.ErrDomain {
+		t.Errorf("SqrtChecked of negative gave %v", err)
+	}
+	if z, err := NewFloat(4.0).SqrtChecked(); err != nil || z.Sub(NewFloat(2.0)).Abs().Cmp(NewFloat(1e-15)) > 0 {
+		t.Errorf("SqrtChecked(4) gave %v, %v", z, err)
+	}
+	if z, err := NewFloat(1.0).DivChecked(NewFloat(4.0)); err != nil || z.Sub(NewFloat(0.25)).Abs().Cmp(NewFloat(1e-15)) > 0 {
+		t.Errorf("DivChecked(1, 4) gave %v, %v", z, err)
+	}
+}
+
+func TestFloatBinary(t *testing.T) {
+	for _, x := range []float64{0, 1, -1, 0.1, -12345.5, 3.5e-20} {
+		f := NewFloat(x).WithPrecision(100)
+		data, err := f.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := DecodeBinary(data)
+		if err != nil || g.Cmp(f) != 0 || g.precision != 100 || g.String() != f.String() {
+			t.Errorf("%v decoded as %v, %v", f, g, err)
+		}
+		var h Float
+		if err := h.UnmarshalBinary(data); err != nil || h.String() != f.String() {
+			t.Errorf("%v unmarshaled as %v, %v", f, h, err)
+		}
+	}
+	if _, err := DecodeBinary([]byte{1, 'X', 3, 2, 0, 0}); err == nil {
+		t.Errorf("decoding a bad sign should fail")
+	}
+}
@@ -92,6 +92,115 @@ func TestClassNumber1(t *testing.T) {
 	}
 }
 
+// imagQuadTestCases returns the fundamental discriminants and class numbers
+// of the imaginary quadratic class number test cases.
+func imagQuadTestCases() (ds []int64, hs []int) {
+	for _, testCase := range classNumberTestCases {
+		p := poly.ParseIntPoly(testCase.polyString)
+		if p.Degree() != 2 || Discriminant(p).Sign() > 0 {
+			continue
+		}
+		ds = append(ds, makeFundamentalDiscriminant(Discriminant(p).Int64()))
+		hs = append(hs, testCase.classNumber)
+	}
+	return ds, hs
+}
+
+// classNumberInt and classNumberFastInt are classNumberImagQuadSlow on a
+// fundamental discriminant, with every value in the loops an Int or a
+// FastInt, to compare the two types on the class number test cases.
+func classNumberInt(D *mathx.Int) int {
+	one, four := mathx.NewInt(1), mathx.NewInt(4)
+	aD := D.Abs()
+	h := 1
+	// b <= floor(sqrt(|D|/3)) exactly when 3 b**2 <= |D|
+	for b := D.Mod(mathx.NewInt(2)); b.Mul(b).Mul64(3).Cmp(aD) <= 0; b = b.Add64(2) {
+		q := b.Mul(b).Sub(D).Quo(four)
+		a := b
+		if a.Cmp(one) <= 0 {
+			a = one
+		}
+		for a.Cmp(one) == 0 || a.Mul(a).Cmp(q) <= 0 {
+			if a.Cmp(one) != 0 && q.Rem(a).Sign() == 0 {
+				if a.Cmp(b) == 0 || a.Mul(a).Cmp(q) == 0 || b.Sign() == 0 {
+					h++
+				} else {
+					h += 2
+				}
+			}
+			a = a.Add64(1)
+		}
+	}
+	return h
+}
+
+func classNumberFastInt(D mathx.FastInt) int {
+	one, four := mathx.NewFastInt(1), mathx.NewFastInt(4)
+	aD := D.Abs()
+	h := 1
+	for b := D.Mod(mathx.NewFastInt(2)); b.Mul(b).Mul64(3).Cmp(aD) <= 0; b = b.Add64(2) {
+		q := b.Mul(b).Sub(D).Quo(four)
+		a := b
+		if a.Cmp(one) <= 0 {
+			a = one
+		}
+		for a.Cmp(one) == 0 || a.Mul(a).Cmp(q) <= 0 {
+			if a.Cmp(one) != 0 && q.Rem(a).Sign() == 0 {
+				if a.Cmp(b) == 0 || a.Mul(a).Cmp(q) == 0 || b.Sign() == 0 {
+					h++
+				} else {
+					h += 2
+				}
+			}
+			a = a.Add64(1)
+		}
+	}
+	return h
+}
+
+func TestClassNumberIntTypes(t *testing.T) {
+	ds, hs := imagQuadTestCases()
+	for i, D := range ds {
+		if h := classNumberInt(mathx.NewInt(D)); h != hs[i] {
+			t.Errorf("classNumberInt(%d) = %d, expected %d", D, h, hs[i])
+		}
+		if h := classNumberFastInt(mathx.NewFastInt(D)); h != hs[i] {
+			t.Errorf("classNumberFastInt(%d) = %d, expected %d", D, h, hs[i])
+		}
+	}
+}
+
+// BenchmarkClassNumberInt and BenchmarkClassNumberFastInt compare the
+// big.Int-backed Int with the small-value FastInt on the class number test
+// cases, with the integer type in the inner loop.
+func BenchmarkClassNumberInt(b *testing.B) {
+	ds, _ := imagQuadTestCases()
+	Ds := make([]*mathx.Int, len(ds))
+	for i, D := range ds {
+		Ds[i] = mathx.NewInt(D)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, D := range Ds {
+			classNumberInt(D)
+		}
+	}
+}
+
+func BenchmarkClassNumberFastInt(b *testing.B) {
+	ds, _ := imagQuadTestCases()
+	Ds := make([]mathx.FastInt, len(ds))
+	for i, D := range ds {
+		Ds[i] = mathx.NewFastInt(D)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, D := range Ds {
+			classNumberFastInt(D)
+		}
+	}
+}
+
 // from sage
 var regulatorTestCases = []struct {
 	polyString string
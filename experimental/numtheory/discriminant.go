@@ -35,8 +35,8 @@ func (k *NumberField) Discriminant() *mathx.Int {
 func Discriminant(p *poly.IntPolynomial) *mathx.Int {
 	if p.Degree() == 2 {
 		c, b, a := p.Coeff(0), p.Coeff(1), p.Coeff(2)
-		ac4 := mathx.NewInt(4).Mul(a).Mul(c)
-		return b.Mul(b).Sub(ac4)
+		var d mathx.IntBuilder
+		return d.AddMul(a, c).Mul64(-4).AddMul(b, b).Freeze()
 	}
 	return nil
 }
@@ -18,9 +18,24 @@ package numtheory
 import (
 	"math"
 
+	"github.com/swenson/mathx"
 	"github.com/swenson/mathx/poly"
 )
 
+// logUnit returns log((|u| + |v| sqrt(d)) / 2), computed in extended
+// precision and rounded once at the end.
+func logUnit(u, v, d int64) float64 {
+	if u < 0 {
+		u = -u
+	}
+	if v < 0 {
+		v = -v
+	}
+	s := mathx.FloatFromInt64(d).SetPrec(128).Sqrt()
+	l, _ := s.MulInt64(v).AddInt64(u).QuoInt64(2).Log().Float64()
+	return l
+}
+
 // cohen, 5.7.2,  p. 270
 func regulatorRealQuad(poly *poly.IntPolynomial) float64 {
 	D := Discriminant(poly).Int64()
@@ -33,7 +48,7 @@ func regulatorRealQuad(poly *poly.IntPolynomial) float64 {
 			if z == 4 || z == -4 {
 				u := x
 				v := y
-				return math.Log((float64(u) + float64(v)*math.Sqrt(float64(c))) / 2.0)
+				return logUnit(u, v, c)
 			}
 		}
 	}
@@ -71,5 +86,5 @@ func regulatorRealQuad(poly *poly.IntPolynomial) float64 {
 			break
 		}
 	}
-	return math.Log((math.Abs(float64(u)) + math.Abs(float64(v))*f) / 2.0)
+	return logUnit(u, v, D)
 }
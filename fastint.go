@@ -0,0 +1,539 @@
+package mathx
+
+// This file is for FastInt, the small-value-optimized companion to Int.
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// FastInt is an immutable arbitrary-precision integer that stores values
+// fitting in an int64 inline, and only allocates a big.Int when a result
+// overflows 64 bits. Since most integers in practice are small, arithmetic
+// on FastInt values usually does not allocate at all.
+//
+// FastInt has the methods of Int, taking and returning FastInt values.
+// Where Int returns nil (ModInverse, ModSqrt), FastInt also returns
+// whether there is a result, and Exp panics with ErrNoInverse instead; a
+// value cannot be used as an output argument, so DivMod and AndNot take
+// only the other operand. Results that fit in an int64 take a fast path,
+// and the rest delegate to big.Int.
+//
+// FastInt is a separate type rather than a new representation of Int:
+// Int is defined as a big.Int, and code (including this package) converts
+// freely between *Int and *big.Int, which would break if Int stopped
+// being one. Use Int and FastIntFromInt to convert between the two.
+//
+// FastInt is a value type; the zero value is 0.
+type FastInt struct {
+	small int64
+	big   *big.Int // non-nil only if the value does not fit in an int64
+}
+
+// NewFastInt returns x as a FastInt.
+func NewFastInt(x int64) FastInt {
+	return FastInt{small: x}
+}
+
+// FastIntFromInt returns x as a FastInt.
+func FastIntFromInt(x *Int) FastInt {
+	return fastIntFromBig(new(big.Int).Set((*big.Int)(x)))
+}
+
+// fastIntFromBig takes ownership of x, demoting it to an inline value if
+// it fits.
+func fastIntFromBig(x *big.Int) FastInt {
+	if x.IsInt64() {
+		return FastInt{small: x.Int64()}
+	}
+	return FastInt{big: x}
+}
+
+// toBig returns the value as a big.Int, which must not be modified.
+func (x FastInt) toBig() *big.Int {
+	if x.big != nil {
+		return x.big
+	}
+	return big.NewInt(x.small)
+}
+
+// Int returns this as an Int.
+func (x FastInt) Int() *Int {
+	if x.big != nil {
+		return (*Int)(new(big.Int).Set(x.big))
+	}
+	return NewInt(x.small)
+}
+
+// IsInt64 returns true if this fits in an int64.
+func (x FastInt) IsInt64() bool {
+	return x.big == nil
+}
+
+// Int64 returns this as an int64. If it does not fit, the result is
+// undefined.
+func (x FastInt) Int64() int64 {
+	if x.big != nil {
+		return x.big.Int64()
+	}
+	return x.small
+}
+
+// String returns this in base 10.
+func (x FastInt) String() string {
+	if x.big != nil {
+		return x.big.String()
+	}
+	return fmt.Sprint(x.small)
+}
+
+// Format implements fmt.Formatter, like Int.Format.
+func (x FastInt) Format(s fmt.State, ch rune) {
+	x.toBig().Format(s, ch)
+}
+
+// Sign returns the sign of this (-1, 0, or 1).
+func (x FastInt) Sign() int {
+	if x.big != nil {
+		return x.big.Sign()
+	}
+	switch {
+	case x.small < 0:
+		return -1
+	case x.small > 0:
+		return 1
+	}
+	return 0
+}
+
+// BitLen returns the size of the absolute value of this in bits.
+func (x FastInt) BitLen() int {
+	if x.big != nil {
+		return x.big.BitLen()
+	}
+	return bits.Len64(absUint64(x.small))
+}
+
+// absUint64 returns |x|, which is correct even for math.MinInt64.
+func absUint64(x int64) uint64 {
+	if x < 0 {
+		return -uint64(x)
+	}
+	return uint64(x)
+}
+
+// Cmp compares this to y, returning something < 0 if this < y, 0 if
+// this == y, and > 0 if this > y.
+func (x FastInt) Cmp(y FastInt) int {
+	if x.big == nil && y.big == nil {
+		switch {
+		case x.small < y.small:
+			return -1
+		case x.small > y.small:
+			return 1
+		}
+		return 0
+	}
+	return x.toBig().Cmp(y.toBig())
+}
+
+// Add returns this plus y.
+func (x FastInt) Add(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		s := x.small + y.small
+		// overflow iff both operands have the opposite sign of the sum
+		if (x.small^s)&(y.small^s) >= 0 {
+			return FastInt{small: s}
+		}
+	}
+	return fastIntFromBig(new(big.Int).Add(x.toBig(), y.toBig()))
+}
+
+// Add64 returns this plus y.
+func (x FastInt) Add64(y int64) FastInt {
+	return x.Add(FastInt{small: y})
+}
+
+// Sub returns this minus y.
+func (x FastInt) Sub(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		d := x.small - y.small
+		// overflow iff the operands differ in sign and the difference
+		// has the sign of y
+		if (x.small^y.small)&(x.small^d) >= 0 {
+			return FastInt{small: d}
+		}
+	}
+	return fastIntFromBig(new(big.Int).Sub(x.toBig(), y.toBig()))
+}
+
+// Sub64 returns this minus y.
+func (x FastInt) Sub64(y int64) FastInt {
+	return x.Sub(FastInt{small: y})
+}
+
+// Mul returns this times y.
+func (x FastInt) Mul(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		hi, lo := bits.Mul64(absUint64(x.small), absUint64(y.small))
+		if hi == 0 {
+			neg := (x.small < 0) != (y.small < 0)
+			if lo <= math.MaxInt64 {
+				if neg {
+					return FastInt{small: -int64(lo)}
+				}
+				return FastInt{small: int64(lo)}
+			}
+			if neg && lo == 1<<63 {
+				return FastInt{small: math.MinInt64}
+			}
+		}
+	}
+	return fastIntFromBig(new(big.Int).Mul(x.toBig(), y.toBig()))
+}
+
+// Mul64 returns this times y.
+func (x FastInt) Mul64(y int64) FastInt {
+	return x.Mul(FastInt{small: y})
+}
+
+// Neg returns the negation of this.
+func (x FastInt) Neg() FastInt {
+	if x.big == nil && x.small != math.MinInt64 {
+		return FastInt{small: -x.small}
+	}
+	return fastIntFromBig(new(big.Int).Neg(x.toBig()))
+}
+
+// Abs returns the absolute value of this.
+func (x FastInt) Abs() FastInt {
+	if x.Sign() < 0 {
+		return x.Neg()
+	}
+	return x
+}
+
+// Quo returns this divided by y, truncated toward zero (Go division).
+// It panics if y is zero.
+func (x FastInt) Quo(y FastInt) FastInt {
+	if x.big == nil && y.big == nil && !(x.small == math.MinInt64 && y.small == -1) {
+		return FastInt{small: x.small / y.small}
+	}
+	return fastIntFromBig(new(big.Int).Quo(x.toBig(), y.toBig()))
+}
+
+// Rem returns the remainder of this divided by y, with the sign of this
+// (Go division). It panics if y is zero.
+func (x FastInt) Rem(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		if y.small == -1 {
+			return FastInt{}
+		}
+		return FastInt{small: x.small % y.small}
+	}
+	return fastIntFromBig(new(big.Int).Rem(x.toBig(), y.toBig()))
+}
+
+// Div returns this divided by y with Euclidean division, like Int.Div.
+// It panics if y is zero.
+func (x FastInt) Div(y FastInt) FastInt {
+	q, r := x.Quo(y), x.Rem(y)
+	if r.Sign() < 0 {
+		if y.Sign() > 0 {
+			return q.Sub64(1)
+		}
+		return q.Add64(1)
+	}
+	return q
+}
+
+// Mod returns this modulo y with Euclidean division, so that the result
+// is in [0, |y|). It panics if y is zero.
+func (x FastInt) Mod(y FastInt) FastInt {
+	r := x.Rem(y)
+	if r.Sign() < 0 {
+		return r.Add(y.Abs())
+	}
+	return r
+}
+
+// GCD returns the (non-negative) greatest common divisor of this and y.
+func (x FastInt) GCD(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		g := gcdUint64(absUint64(x.small), absUint64(y.small))
+		if g <= math.MaxInt64 {
+			return FastInt{small: int64(g)}
+		}
+		return fastIntFromBig(new(big.Int).SetUint64(g))
+	}
+	a := new(big.Int).Abs(x.toBig())
+	return fastIntFromBig(a.GCD(nil, nil, a, new(big.Int).Abs(y.toBig())))
+}
+
+// gcdUint64 is the binary GCD algorithm.
+func gcdUint64(a, b uint64) uint64 {
+	if a == 0 {
+		return b
+	}
+	if b == 0 {
+		return a
+	}
+	shift := bits.TrailingZeros64(a | b)
+	a >>= uint(bits.TrailingZeros64(a))
+	for b != 0 {
+		b >>= uint(bits.TrailingZeros64(b))
+		if a > b {
+			a, b = b, a
+		}
+		b -= a
+	}
+	return a << uint(shift)
+}
+
+// Lsh returns this shifted left by n (that is, multiplied by 2^n).
+func (x FastInt) Lsh(n uint) FastInt {
+	if x.big == nil && n < 63 && x.BitLen()+int(n) < 63 {
+		return FastInt{small: x.small << n}
+	}
+	return fastIntFromBig(new(big.Int).Lsh(x.toBig(), n))
+}
+
+// Rsh returns this shifted right by n (that is, divided by 2^n and
+// rounded toward negative infinity).
+func (x FastInt) Rsh(n uint) FastInt {
+	if x.big == nil {
+		if n > 63 {
+			n = 63
+		}
+		return FastInt{small: x.small >> n}
+	}
+	return fastIntFromBig(new(big.Int).Rsh(x.big, n))
+}
+
+// Uint64 returns this as a uint64. If it does not fit, the result is
+// undefined.
+func (x FastInt) Uint64() uint64 {
+	if x.big == nil && x.small >= 0 {
+		return uint64(x.small)
+	}
+	return x.toBig().Uint64()
+}
+
+// Bit returns bit i of this in two's complement, like Int.Bit.
+func (x FastInt) Bit(i int) uint {
+	if x.big == nil {
+		if i > 63 {
+			i = 63
+		}
+		return uint(x.small>>uint(i)) & 1
+	}
+	return x.big.Bit(i)
+}
+
+// SetBit returns this with bit i set to b, in two's complement.
+func (x FastInt) SetBit(i int, b uint) FastInt {
+	if x.big == nil && i < 63 {
+		if b == 0 {
+			return FastInt{small: x.small &^ (1 << uint(i))}
+		}
+		return FastInt{small: x.small | 1<<uint(i)}
+	}
+	return fastIntFromBig(new(big.Int).SetBit(x.toBig(), i, b))
+}
+
+// And returns this bitwise-ANDed with y.
+func (x FastInt) And(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		return FastInt{small: x.small & y.small}
+	}
+	return fastIntFromBig(new(big.Int).And(x.toBig(), y.toBig()))
+}
+
+// AndNot returns this bitwise-ANDed with the complement of y.
+func (x FastInt) AndNot(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		return FastInt{small: x.small &^ y.small}
+	}
+	return fastIntFromBig(new(big.Int).AndNot(x.toBig(), y.toBig()))
+}
+
+// Or returns this bitwise-ORed with y.
+func (x FastInt) Or(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		return FastInt{small: x.small | y.small}
+	}
+	return fastIntFromBig(new(big.Int).Or(x.toBig(), y.toBig()))
+}
+
+// Xor returns this bitwise-XORed with y.
+func (x FastInt) Xor(y FastInt) FastInt {
+	if x.big == nil && y.big == nil {
+		return FastInt{small: x.small ^ y.small}
+	}
+	return fastIntFromBig(new(big.Int).Xor(x.toBig(), y.toBig()))
+}
+
+// Not returns this with all bits inverted, -this - 1.
+func (x FastInt) Not() FastInt {
+	if x.big == nil {
+		return FastInt{small: ^x.small}
+	}
+	return fastIntFromBig(new(big.Int).Not(x.big))
+}
+
+// QuoRem returns the quotient and remainder of this divided by y, with
+// truncated (Go) division. It panics if y is zero.
+func (x FastInt) QuoRem(y FastInt) (FastInt, FastInt) {
+	return x.Quo(y), x.Rem(y)
+}
+
+// DivMod returns the quotient and modulus of this divided by y, with
+// Euclidean division, as Div and Mod do. It panics if y is zero.
+func (x FastInt) DivMod(y FastInt) (FastInt, FastInt) {
+	return x.Div(y), x.Mod(y)
+}
+
+// Exp returns this**y, modulo |m| if m is not zero. For y <= 0 it returns
+// 1, unless m is not zero and y < 0, when it returns the inverse of
+// this**-y modulo m; it panics with ErrNoInverse if there is none.
+func (x FastInt) Exp(y, m FastInt) FastInt {
+	var mb *big.Int
+	if m.Sign() != 0 {
+		mb = m.toBig()
+	}
+	r := new(big.Int).Exp(x.toBig(), y.toBig(), mb)
+	if r == nil {
+		panic(ErrNoInverse)
+	}
+	return fastIntFromBig(r)
+}
+
+// ExtendedGCD returns the GCD g of this and y, and a and b with
+// this a + y b = g, like Int.ExtendedGCD.
+func (x FastInt) ExtendedGCD(y FastInt) (FastInt, FastInt, FastInt) {
+	a, b := new(big.Int), new(big.Int)
+	g := new(big.Int).GCD(a, b, x.toBig(), y.toBig())
+	return fastIntFromBig(g), fastIntFromBig(a), fastIntFromBig(b)
+}
+
+// ModInverse returns the inverse of this modulo n, and whether there is
+// one. (Int.ModInverse returns nil instead, which a FastInt cannot be.)
+func (x FastInt) ModInverse(n FastInt) (FastInt, bool) {
+	r := new(big.Int).ModInverse(x.toBig(), n.toBig())
+	if r == nil {
+		return FastInt{}, false
+	}
+	return fastIntFromBig(r), true
+}
+
+// ModSqrt returns a square root of this modulo the odd prime p, and
+// whether there is one, as for ModInverse.
+func (x FastInt) ModSqrt(p FastInt) (FastInt, bool) {
+	r := new(big.Int).ModSqrt(x.toBig(), p.toBig())
+	if r == nil {
+		return FastInt{}, false
+	}
+	return fastIntFromBig(r), true
+}
+
+// ProbablyPrime returns whether this is probably prime, as for
+// big.Int.ProbablyPrime.
+func (x FastInt) ProbablyPrime(n int) bool {
+	return x.toBig().ProbablyPrime(n)
+}
+
+// Text returns this in the base, from 2 to 62, like Int.Text.
+func (x FastInt) Text(base int) string {
+	if x.big == nil && base <= 36 {
+		return strconv.FormatInt(x.small, base)
+	}
+	return x.toBig().Text(base)
+}
+
+// Append appends this in the base, from 2 to 62, to buf.
+func (x FastInt) Append(buf []byte, base int) []byte {
+	if x.big == nil && base <= 36 {
+		return strconv.AppendInt(buf, x.small, base)
+	}
+	return x.toBig().Append(buf, base)
+}
+
+// Bits returns the absolute value of this as little-endian Words, in a
+// new slice.
+func (x FastInt) Bits() []big.Word {
+	if x.big != nil {
+		return append([]big.Word(nil), x.big.Bits()...)
+	}
+	return x.toBig().Bits()
+}
+
+// Bytes returns the absolute value of this as big-endian bytes.
+func (x FastInt) Bytes() []byte {
+	return x.toBig().Bytes()
+}
+
+// SetBytes returns the value of the big-endian unsigned bytes buf.
+func (x FastInt) SetBytes(buf []byte) FastInt {
+	return fastIntFromBig(new(big.Int).SetBytes(buf))
+}
+
+// MarshalJSON implements json.Marshaler, like Int.MarshalJSON.
+func (x FastInt) MarshalJSON() ([]byte, error) {
+	return x.toBig().MarshalJSON()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (x FastInt) MarshalText() ([]byte, error) {
+	return x.toBig().MarshalText()
+}
+
+// GobEncode implements gob.GobEncoder, in the format of Int.GobEncode.
+func (x FastInt) GobEncode() ([]byte, error) {
+	return x.toBig().GobEncode()
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It replaces the value of x,
+// which does not change other copies of it, as a FastInt never shares a
+// big.Int that can change.
+func (x *FastInt) UnmarshalJSON(text []byte) error {
+	b := new(big.Int)
+	if err := b.UnmarshalJSON(text); err != nil {
+		return err
+	}
+	*x = fastIntFromBig(b)
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, replacing the value
+// of x.
+func (x *FastInt) UnmarshalText(text []byte) error {
+	b := new(big.Int)
+	if err := b.UnmarshalText(text); err != nil {
+		return err
+	}
+	*x = fastIntFromBig(b)
+	return nil
+}
+
+// GobDecode implements gob.GobDecoder, replacing the value of x.
+func (x *FastInt) GobDecode(buf []byte) error {
+	b := new(big.Int)
+	if err := b.GobDecode(buf); err != nil {
+		return err
+	}
+	*x = fastIntFromBig(b)
+	return nil
+}
+
+// Scan implements fmt.Scanner, replacing the value of x.
+func (x *FastInt) Scan(s fmt.ScanState, ch rune) error {
+	b := new(big.Int)
+	if err := b.Scan(s, ch); err != nil {
+		return err
+	}
+	*x = fastIntFromBig(b)
+	return nil
+}
@@ -0,0 +1,219 @@
+package mathx
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+var fastIntTestValues = []int64{
+	0, 1, -1, 2, -2, 3, 7, -12, 1000, -65536, 1 << 31, -(1 << 31), 1<<32 + 1,
+	3037000499, 3037000500, -3037000500, 1 << 62, -(1 << 62),
+	math.MaxInt64, math.MaxInt64 - 1, math.MinInt64, math.MinInt64 + 1,
+}
+
+func fastIntTestOperands() []FastInt {
+	var out []FastInt
+	for _, v := range fastIntTestValues {
+		out = append(out, NewFastInt(v))
+	}
+	// a couple of values that need a big.Int
+	huge, _ := NewIntFromString("-93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	out = append(out, FastIntFromInt(huge), FastIntFromInt(NewInt(1).Lsh(64)), FastIntFromInt(NewInt(math.MinInt64).Sub64(1)))
+	return out
+}
+
+// checkFastInt checks that got has the value of expected, and is inline if
+// and only if it fits in an int64.
+func checkFastInt(t *testing.T, op string, x, y, got FastInt, expected *big.Int) {
+	if got.toBig().Cmp(expected) != 0 || got.IsInt64() != expected.IsInt64() {
+		t.Errorf("%v %s %v = %v (inline %v), expected %v", x, op, y, got, got.IsInt64(), expected)
+	}
+}
+
+func TestFastIntArithmetic(t *testing.T) {
+	operands := fastIntTestOperands()
+	for _, x := range operands {
+		bx := x.toBig()
+		checkFastInt(t, "neg", x, x, x.Neg(), new(big.Int).Neg(bx))
+		checkFastInt(t, "abs", x, x, x.Abs(), new(big.Int).Abs(bx))
+		checkFastInt(t, "<<", x, NewFastInt(5), x.Lsh(5), new(big.Int).Lsh(bx, 5))
+		checkFastInt(t, ">>", x, NewFastInt(3), x.Rsh(3), new(big.Int).Rsh(bx, 3))
+		checkFastInt(t, ">>", x, NewFastInt(100), x.Rsh(100), new(big.Int).Rsh(bx, 100))
+		if x.Int().Cmp((*Int)(bx)) != 0 || x.String() != bx.String() || x.BitLen() != bx.BitLen() || x.Sign() != bx.Sign() {
+			t.Errorf("conversions of %v are wrong", x)
+		}
+		for _, y := range operands {
+			by := y.toBig()
+			checkFastInt(t, "+", x, y, x.Add(y), new(big.Int).Add(bx, by))
+			checkFastInt(t, "-", x, y, x.Sub(y), new(big.Int).Sub(bx, by))
+			checkFastInt(t, "*", x, y, x.Mul(y), new(big.Int).Mul(bx, by))
+			checkFastInt(t, "gcd", x, y, x.GCD(y), new(big.Int).GCD(nil, nil, new(big.Int).Abs(bx), new(big.Int).Abs(by)))
+			if x.Cmp(y) != bx.Cmp(by) {
+				t.Errorf("Cmp(%v, %v) = %d", x, y, x.Cmp(y))
+			}
+			if y.Sign() == 0 {
+				continue
+			}
+			checkFastInt(t, "quo", x, y, x.Quo(y), new(big.Int).Quo(bx, by))
+			checkFastInt(t, "rem", x, y, x.Rem(y), new(big.Int).Rem(bx, by))
+			checkFastInt(t, "div", x, y, x.Div(y), new(big.Int).Div(bx, by))
+			checkFastInt(t, "mod", x, y, x.Mod(y), new(big.Int).Mod(bx, by))
+		}
+	}
+}
+
+func TestFastIntBigMethods(t *testing.T) {
+	operands := fastIntTestOperands()
+	for _, x := range operands {
+		bx := x.toBig()
+		checkFastInt(t, "not", x, x, x.Not(), new(big.Int).Not(bx))
+		for _, i := range []int{0, 5, 62, 63, 64, 200} {
+			if x.Bit(i) != bx.Bit(i) {
+				t.Errorf("Bit(%v, %d) = %d", x, i, x.Bit(i))
+			}
+			for b := uint(0); b < 2; b++ {
+				checkFastInt(t, "setbit", x, NewFastInt(int64(i)), x.SetBit(i, b), new(big.Int).SetBit(new(big.Int), i, b).SetBit(bx, i, b))
+			}
+		}
+		for _, base := range []int{2, 10, 16, 62} {
+			if x.Text(base) != bx.Text(base) || string(x.Append([]byte("x"), base)) != "x"+bx.Text(base) {
+				t.Errorf("Text(%v, %d) = %q", x, base, x.Text(base))
+			}
+		}
+		if !bytes.Equal(x.Bytes(), bx.Bytes()) || new(big.Int).SetBits(x.Bits()).CmpAbs(bx) != 0 {
+			t.Errorf("Bytes(%v) = %x", x, x.Bytes())
+		}
+		checkFastInt(t, "setbytes", x, x, x.SetBytes(bx.Bytes()), new(big.Int).Abs(bx))
+		if x.Sign() >= 0 && bx.IsUint64() && x.Uint64() != bx.Uint64() {
+			t.Errorf("Uint64(%v) = %d", x, x.Uint64())
+		}
+		if x.ProbablyPrime(10) != bx.ProbablyPrime(10) {
+			t.Errorf("ProbablyPrime(%v) = %v", x, x.ProbablyPrime(10))
+		}
+
+		for _, y := range operands {
+			by := y.toBig()
+			checkFastInt(t, "&", x, y, x.And(y), new(big.Int).And(bx, by))
+			checkFastInt(t, "&^", x, y, x.AndNot(y), new(big.Int).AndNot(bx, by))
+			checkFastInt(t, "|", x, y, x.Or(y), new(big.Int).Or(bx, by))
+			checkFastInt(t, "^", x, y, x.Xor(y), new(big.Int).Xor(bx, by))
+			a, b := new(big.Int), new(big.Int)
+			g := new(big.Int).GCD(a, b, bx, by)
+			// GCD can leave a as a negative zero, which Cmp does not equate with 0
+			a.Add(a, new(big.Int))
+			eg, ea, eb := x.ExtendedGCD(y)
+			checkFastInt(t, "xgcd", x, y, eg, g)
+			checkFastInt(t, "xgcd a", x, y, ea, a)
+			checkFastInt(t, "xgcd b", x, y, eb, b)
+			if y.Sign() == 0 {
+				continue
+			}
+			q, r := x.QuoRem(y)
+			checkFastInt(t, "quo", x, y, q, new(big.Int).Quo(bx, by))
+			checkFastInt(t, "rem", x, y, r, new(big.Int).Rem(bx, by))
+			d, m := x.DivMod(y)
+			checkFastInt(t, "div", x, y, d, new(big.Int).Div(bx, by))
+			checkFastInt(t, "mod", x, y, m, new(big.Int).Mod(bx, by))
+			inv, ok := x.ModInverse(y.Abs())
+			if want := new(big.Int).ModInverse(bx, new(big.Int).Abs(by)); ok != (want != nil) {
+				t.Errorf("ModInverse(%v, %v) = %v, %v", x, y, inv, ok)
+			} else if ok {
+				checkFastInt(t, "modinverse", x, y, inv, want)
+			}
+			if bx.Sign() >= 0 && bx.BitLen() < 40 {
+				checkFastInt(t, "exp", x, y, x.Exp(NewFastInt(3), y), new(big.Int).Exp(bx, big.NewInt(3), by))
+			}
+		}
+	}
+
+	checkFastInt(t, "exp", NewFastInt(3), NewFastInt(100), NewFastInt(3).Exp(NewFastInt(100), FastInt{}), new(big.Int).Exp(big.NewInt(3), big.NewInt(100), nil))
+	checkFastInt(t, "exp", NewFastInt(3), NewFastInt(-1), NewFastInt(3).Exp(NewFastInt(-1), NewFastInt(7)), big.NewInt(5))
+	func() {
+		defer func() {
+			if r := recover(); r != ErrNoInverse {
+				t.Errorf("2**-1 mod 4 panicked with %v", r)
+			}
+		}()
+		NewFastInt(2).Exp(NewFastInt(-1), NewFastInt(4))
+		t.Errorf("2**-1 mod 4 did not panic")
+	}()
+	if r, ok := NewFastInt(2).ModSqrt(NewFastInt(7)); !ok || r.Mul(r).Mod(NewFastInt(7)).Int64() != 2 {
+		t.Errorf("ModSqrt(2, 7) = %v, %v", r, ok)
+	}
+	if r, ok := NewFastInt(3).ModSqrt(NewFastInt(7)); ok {
+		t.Errorf("ModSqrt(3, 7) = %v", r)
+	}
+}
+
+func TestFastIntEncoding(t *testing.T) {
+	for _, x := range fastIntTestOperands() {
+		var j, tx, g, s FastInt
+		buf, err := json.Marshal(x)
+		if err == nil {
+			err = json.Unmarshal(buf, &j)
+		}
+		if err != nil || j.Cmp(x) != 0 || j.IsInt64() != x.IsInt64() {
+			t.Errorf("JSON round trip of %v gave %v, %v", x, j, err)
+		}
+		text, err := x.MarshalText()
+		if err == nil {
+			err = tx.UnmarshalText(text)
+		}
+		if err != nil || tx.Cmp(x) != 0 {
+			t.Errorf("text round trip of %v gave %v, %v", x, tx, err)
+		}
+		var w bytes.Buffer
+		err = gob.NewEncoder(&w).Encode(x)
+		if err == nil {
+			err = gob.NewDecoder(&w).Decode(&g)
+		}
+		if err != nil || g.Cmp(x) != 0 {
+			t.Errorf("gob round trip of %v gave %v, %v", x, g, err)
+		}
+		if _, err := fmt.Sscan(x.String(), &s); err != nil || s.Cmp(x) != 0 {
+			t.Errorf("Sscan(%q) = %v, %v", x.String(), s, err)
+		}
+	}
+	var x FastInt
+	if err := x.UnmarshalText([]byte("1x")); err == nil {
+		t.Errorf("UnmarshalText(1x) = %v", x)
+	}
+}
+
+func TestFastIntZeroValue(t *testing.T) {
+	var x FastInt
+	if x.Sign() != 0 || x.String() != "0" || x.Add64(3).Int64() != 3 {
+		t.Errorf("zero FastInt is %v", x)
+	}
+}
+
+func BenchmarkFastIntDiscriminant(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		acc := NewFastInt(0)
+		for a := int64(1); a < 10; a++ {
+			for c := int64(-10); c < 10; c++ {
+				bb := NewFastInt(a + c)
+				d := bb.Mul(bb).Sub(NewFastInt(4).Mul64(a).Mul64(c))
+				acc = acc.Add(d.GCD(bb))
+			}
+		}
+	}
+}
+
+func BenchmarkIntDiscriminant(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		acc := NewInt(0)
+		for a := int64(1); a < 10; a++ {
+			for c := int64(-10); c < 10; c++ {
+				bb := NewInt(a + c)
+				d := bb.Mul(bb).Sub(NewInt(4).Mul64(a).Mul64(c))
+				acc = acc.Add(d.GCD(bb))
+			}
+		}
+	}
+}
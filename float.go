@@ -1,6 +1,9 @@
 package mathx
 
-import "math/big"
+import (
+	"fmt"
+	"math/big"
+)
 
 // Float is an immutable arbitrary-precision floating-point type, wrapping
 // the built-in math/big.Float (which is mutable). This package
@@ -47,9 +50,12 @@ func (z *Float) Float64() (float64, big.Accuracy) {
 	return (*big.Float)(z).Float64()
 }
 
-// func (z *Float) Format(s fmt.State, format rune) {
-// 	(*big.Float)(z).Format(s, format)
-// }
+// Format implements fmt.Formatter, accepting the same verbs, flags, width,
+// and precision as big.Float: 'e', 'E', 'f', 'F', 'g', 'G', 'b', 'p',
+// 'x', 'X', and 'v'.
+func (z *Float) Format(s fmt.State, format rune) {
+	(*big.Float)(z).Format(s, format)
+}
 
 func (z *Float) Int() (*Int, big.Accuracy) {
 	i := new(big.Int)
@@ -95,8 +101,18 @@ func (z *Float) Neg() *Float {
 	return (*Float)(new(big.Float).Neg((*big.Float)(z)))
 }
 
-// func (z *Float) Parse(s string, base int) (f *Float, b int, err error) {
-// }
+// Parse returns the number represented by s in the given base, as in
+// big.Float.Parse, rounded to the precision and rounding mode of this
+// (or to 64 bits if this has precision 0). It also returns the actual
+// base used.
+func (z *Float) Parse(s string, base int) (f *Float, b int, err error) {
+	x := new(big.Float).SetPrec(z.Prec()).SetMode(z.Mode())
+	x, b, err = x.Parse(s, base)
+	if err != nil {
+		return nil, b, err
+	}
+	return (*Float)(x), b, nil
+}
 
 func (z *Float) Prec() uint {
 	return (*big.Float)(z).Prec()
@@ -110,10 +126,22 @@ func (z *Float) Rat(r *big.Rat) (*big.Rat, big.Accuracy) {
 	return (*big.Float)(z).Rat(r)
 }
 
-// func (z *Float) SetInf(signbit bool) *Float {
-// }
-//
-//
+// SetInf returns an infinity with the precision and rounding mode of this,
+// which is -Inf if signbit is set and +Inf otherwise.
+func (z *Float) SetInf(signbit bool) *Float {
+	return (*Float)(new(big.Float).SetPrec(z.Prec()).SetMode(z.Mode()).SetInf(signbit))
+}
+
+// Inf returns -Inf if signbit is set and +Inf otherwise.
+func Inf(signbit bool) *Float {
+	return (*Float)(new(big.Float).SetInf(signbit))
+}
+
+// FloatFromInt returns x as a Float, exactly: the precision is the bit
+// length of x, or 64 if that is larger.
+func FloatFromInt(x *Int) *Float {
+	return (*Float)(new(big.Float).SetInt((*big.Int)(x)))
+}
 
 func FloatFromInt64(x int64) *Float {
 	return (*Float)(new(big.Float).SetInt64(x))
@@ -156,6 +184,15 @@ func (z *Float) String() string {
 	return (*big.Float)(z).String()
 }
 
+// Scan implements the fmt.Scanner interface, and changes the underlying
+// big.Float.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that scanning works; see ScanFloat,
+// ReadFloat, and FloatValue for alternatives.
+func (z *Float) Scan(s fmt.ScanState, ch rune) error {
+	return (*big.Float)(z).Scan(s, ch)
+}
+
 func (z *Float) Sub(y *Float) *Float {
 	return (*Float)(new(big.Float).Sub((*big.Float)(z), (*big.Float)(y)))
 }
@@ -168,6 +205,10 @@ func (z *Float) Uint64() (uint64, big.Accuracy) {
 	return (*big.Float)(z).Uint64()
 }
 
+// UnmarshalText unmarshals the text buffer into this.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see ParseFloatText
+// and FloatValue for alternatives.
 func (z *Float) UnmarshalText(text []byte) error {
 	return (*big.Float)(z).UnmarshalText(text)
 }
@@ -0,0 +1,307 @@
+package mathx
+
+// This file is for mathematical constants to arbitrary precision. Each is
+// computed by binary splitting of a rapidly converging series, and the
+// most precise value computed so far is kept, so that a request for fewer
+// bits only has to round it.
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// constant is a mathematical constant, with the most precise value
+// computed so far.
+type constant struct {
+	mu      sync.Mutex
+	prec    uint
+	value   *big.Float
+	compute func(w uint) *big.Float // to w bits, within an ulp
+}
+
+var (
+	piConstant         = &constant{compute: computePi}
+	eConstant          = &constant{compute: computeE}
+	ln2Constant        = &constant{compute: computeLn2}
+	ln10Constant       = &constant{compute: computeLn10}
+	eulerGammaConstant = &constant{compute: computeEulerGamma}
+	catalanConstant    = &constant{compute: computeCatalan}
+	sqrt2Constant      = &constant{compute: computeSqrt2}
+	zeta3Constant      = &constant{compute: computeZeta3}
+)
+
+// at returns the constant to w bits, within 1.5 ulps. It is safe to call
+// concurrently; callers wait while a more precise value is computed.
+func (c *constant) at(w uint) *big.Float {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.prec < w {
+		// grow geometrically, so that slowly increasing requests do not
+		// each start over
+		p := w
+		if p < c.prec+c.prec/2 {
+			p = c.prec + c.prec/2
+		}
+		c.value = c.compute(p)
+		c.prec = p
+	}
+	return newFloat(w).Set(c.value)
+}
+
+// rounded returns the constant correctly rounded to nearest with prec
+// bits, or 64 bits if prec is 0.
+func (c *constant) rounded(prec uint) *Float {
+	if prec == 0 {
+		prec = 64
+	}
+	return zivRel(prec, big.ToNearestEven, c.at)
+}
+
+// Pi returns π rounded to nearest with prec bits, or 64 bits if prec is 0.
+func Pi(prec uint) *Float {
+	return piConstant.rounded(prec)
+}
+
+// E returns e, the base of the natural logarithm, rounded to nearest with
+// prec bits, or 64 bits if prec is 0.
+func E(prec uint) *Float {
+	return eConstant.rounded(prec)
+}
+
+// Ln2 returns log(2) rounded to nearest with prec bits, or 64 bits if prec
+// is 0.
+func Ln2(prec uint) *Float {
+	return ln2Constant.rounded(prec)
+}
+
+// Ln10 returns log(10) rounded to nearest with prec bits, or 64 bits if
+// prec is 0.
+func Ln10(prec uint) *Float {
+	return ln10Constant.rounded(prec)
+}
+
+// EulerGamma returns the Euler-Mascheroni constant γ rounded to nearest
+// with prec bits, or 64 bits if prec is 0.
+func EulerGamma(prec uint) *Float {
+	return eulerGammaConstant.rounded(prec)
+}
+
+// Catalan returns Catalan's constant G rounded to nearest with prec bits,
+// or 64 bits if prec is 0.
+func Catalan(prec uint) *Float {
+	return catalanConstant.rounded(prec)
+}
+
+// Sqrt2 returns the square root of 2 rounded to nearest with prec bits, or
+// 64 bits if prec is 0.
+func Sqrt2(prec uint) *Float {
+	return sqrt2Constant.rounded(prec)
+}
+
+// Zeta3 returns Apéry's constant ζ(3) rounded to nearest with prec bits,
+// or 64 bits if prec is 0.
+func Zeta3(prec uint) *Float {
+	return zeta3Constant.rounded(prec)
+}
+
+// seriesTerm returns a(n), b(n), p(n) and q(n) for a series
+//
+//	sum over n >= 0 of a(n)/b(n) p(0)...p(n) / (q(0)...q(n)),
+//
+// as new Ints, which the binary splitting reuses.
+type seriesTerm func(n int64) (a, b, p, q *big.Int)
+
+// split returns P, Q, B and T for the terms n1 <= n < n2 of a series, so
+// that their sum is T/(BQ), by binary splitting as in Haible and
+// Papanikolaou, "Fast multiprecision evaluation of series of rational
+// numbers".
+func split(f seriesTerm, n1, n2 int64) (p, q, b, t *big.Int) {
+	if n2-n1 == 1 {
+		a, b, p, q := f(n1)
+		return p, q, b, a.Mul(a, p)
+	}
+	m := (n1 + n2) / 2
+	pl, ql, bl, tl := split(f, n1, m)
+	pr, qr, br, tr := split(f, m, n2)
+	// T = Br Qr Tl + Bl Pl Tr
+	tl.Mul(tl, qr).Mul(tl, br)
+	tr.Mul(tr, pl).Mul(tr, bl)
+	return pl.Mul(pl, pr), ql.Mul(ql, qr), bl.Mul(bl, br), tl.Add(tl, tr)
+}
+
+// sumSeries returns the sum of the first n terms of a series to w bits.
+func sumSeries(f seriesTerm, n int64, w uint) *big.Float {
+	_, q, b, t := split(f, 0, n)
+	d := newFloat(w).SetInt(b.Mul(b, q))
+	return d.Quo(newFloat(w).SetInt(t), d)
+}
+
+// termsFor returns how many terms of a series whose terms shrink by
+// bitsPerTerm bits each are needed for w bits.
+func termsFor(w uint, bitsPerTerm float64) int64 {
+	return int64(float64(w)/bitsPerTerm) + 2
+}
+
+// computePi uses the Chudnovsky series,
+//
+//	1/π = 12 sum (-1)**k (6k)! (13591409 + 545140134k) / ((3k)! (k!)**3 640320**(3k+3/2)).
+func computePi(w uint) *big.Float {
+	wp := w + 16
+	c3 := big.NewInt(10939058860032000) // 640320**3 / 24
+	s := sumSeries(func(k int64) (a, b, p, q *big.Int) {
+		a = big.NewInt(545140134)
+		a.Mul(a, big.NewInt(k)).Add(a, big.NewInt(13591409))
+		if k == 0 {
+			return a, big.NewInt(1), big.NewInt(1), big.NewInt(1)
+		}
+		p = big.NewInt(-(6*k - 5))
+		p.Mul(p, big.NewInt(2*k-1)).Mul(p, big.NewInt(6*k-1))
+		q = big.NewInt(k)
+		q.Mul(q, q).Mul(q, big.NewInt(k)).Mul(q, c3)
+		return a, big.NewInt(1), p, q
+	}, termsFor(wp, 47), wp)
+	r := newFloat(wp).SetInt64(10005)
+	r.Sqrt(r).Mul(r, big.NewFloat(426880))
+	return newFloat(w).Quo(r, s)
+}
+
+// computeE sums 1/k!.
+func computeE(w uint) *big.Float {
+	wp := w + 16
+	// the terms after 1/n! add less than 2/(n+1)!
+	n, size := int64(1), 0.0
+	for size < float64(wp) {
+		n++
+		size += math.Log2(float64(n))
+	}
+	return newFloat(w).Set(sumSeries(func(k int64) (a, b, p, q *big.Int) {
+		if k == 0 {
+			k = 1
+		}
+		return big.NewInt(1), big.NewInt(1), big.NewInt(1), big.NewInt(k)
+	}, n+1, wp))
+}
+
+// atanhInv returns atanh(1/m) = sum 1/((2k+1) m**(2k+1)) to w bits.
+func atanhInv(m int64, w uint) *big.Float {
+	return sumSeries(func(k int64) (a, b, p, q *big.Int) {
+		q = big.NewInt(m)
+		if k > 0 {
+			q.Mul(q, q)
+		}
+		return big.NewInt(1), big.NewInt(2*k + 1), big.NewInt(1), q
+	}, termsFor(w, 2*math.Log2(float64(m))), w)
+}
+
+// computeLn2 uses log(2) = 18 atanh(1/26) - 2 atanh(1/4801) + 8 atanh(1/8749).
+func computeLn2(w uint) *big.Float {
+	wp := w + 16
+	x := newFloat(wp).Mul(atanhInv(26, wp), big.NewFloat(18))
+	x.Sub(x, newFloat(wp).Mul(atanhInv(4801, wp), floatTwo))
+	x.Add(x, newFloat(wp).Mul(atanhInv(8749, wp), big.NewFloat(8)))
+	return newFloat(w).Set(x)
+}
+
+// computeLn10 uses log(10) = 3 log(2) + log(5/4), with log(5/4) = 2 atanh(1/9).
+func computeLn10(w uint) *big.Float {
+	wp := w + 16
+	x := newFloat(wp).Mul(ln2Constant.at(wp), big.NewFloat(3))
+	y := atanhInv(9, wp)
+	x.Add(x, y.SetMantExp(y, 1))
+	return newFloat(w).Set(x)
+}
+
+// computeEulerGamma uses the method of Brent and McMillan: with n = 2**j,
+//
+//	γ = U/V - log(n) + O(exp(-4n)), U = sum (n**k/k!)**2 H(k), V = sum (n**k/k!)**2,
+//
+// where H(k) is the k-th harmonic number.
+func computeEulerGamma(w uint) *big.Float {
+	// U/V is about log(n), so a few bits are lost to cancellation
+	wp := w + 32
+	j := bits.Len64(uint64(float64(wp)/5.77) + 1)
+	n := int64(1) << uint(j)
+	_, q, d, _, t, v := gammaSplit(big.NewInt(n*n), 1, int64(3.5912*float64(n))+2)
+	// the k = 0 term is 1 in V and 0 in U, so U/V = v / (d (q + t))
+	t.Add(t, q).Mul(t, d)
+	g := newFloat(wp).Quo(newFloat(wp).SetInt(v), newFloat(wp).SetInt(t))
+	l := ln2Constant.at(wp)
+	g.Sub(g, l.Mul(l, new(big.Float).SetInt64(int64(j))))
+	return newFloat(w).Set(g)
+}
+
+// gammaSplit is the binary splitting for computeEulerGamma over the terms
+// k1 <= k < k2, with p(k) = n**2 and q(k) = k**2. It returns P and Q, the
+// products of p and q, D = k1...(k2-1), C with C/D = H(k2-1) - H(k1-1),
+// and T and V with
+//
+//	T/Q = sum over k of p(k1)...p(k) / (q(k1)...q(k)),
+//	V/(DQ) = sum over k of p(k1)...p(k) / (q(k1)...q(k)) (H(k) - H(k1-1)).
+func gammaSplit(n2 *big.Int, k1, k2 int64) (p, q, d, c, t, v *big.Int) {
+	if k2-k1 == 1 {
+		d = big.NewInt(k1)
+		q = new(big.Int).Mul(d, d)
+		return new(big.Int).Set(n2), q, d, big.NewInt(1), new(big.Int).Set(n2), new(big.Int).Set(n2)
+	}
+	m := (k1 + k2) / 2
+	pl, ql, dl, cl, tl, vl := gammaSplit(n2, k1, m)
+	pr, qr, dr, cr, tr, vr := gammaSplit(n2, m, k2)
+	// V = Dr (Qr Vl + Cl Pl Tr) + Dl Pl Vr
+	x := new(big.Int).Mul(cl, pl)
+	vl.Mul(vl, qr).Add(vl, x.Mul(x, tr)).Mul(vl, dr)
+	vr.Mul(vr, pl).Mul(vr, dl)
+	// C = Cl Dr + Cr Dl
+	cl.Mul(cl, dr).Add(cl, cr.Mul(cr, dl))
+	// T = Tl Qr + Pl Tr
+	tl.Mul(tl, qr).Add(tl, tr.Mul(tr, pl))
+	return pl.Mul(pl, pr), ql.Mul(ql, qr), dl.Mul(dl, dr), cl, tl, vl.Add(vl, vr)
+}
+
+// computeCatalan uses Ramanujan's series,
+//
+//	G = π/8 log(2 + sqrt(3)) + 3/8 sum (k!)**2 / ((2k)! (2k+1)**2).
+func computeCatalan(w uint) *big.Float {
+	wp := w + 16
+	s := sumSeries(func(k int64) (a, b, p, q *big.Int) {
+		b = big.NewInt(2*k + 1)
+		b.Mul(b, b)
+		if k == 0 {
+			return big.NewInt(1), b, big.NewInt(1), big.NewInt(1)
+		}
+		return big.NewInt(1), b, big.NewInt(k), big.NewInt(2 * (2*k - 1))
+	}, termsFor(wp, 2), wp)
+	s.Mul(s, big.NewFloat(3))
+	x := newFloat(wp).SetInt64(3)
+	x.Sqrt(x).Add(x, floatTwo)
+	x = logKernel(x, wp)
+	x.Mul(x, piConstant.at(wp))
+	x.Add(x, s)
+	return newFloat(w).SetMantExp(x, -3)
+}
+
+// computeSqrt2 uses Newton's method, through big.Float.
+func computeSqrt2(w uint) *big.Float {
+	return newFloat(w).Sqrt(floatTwo)
+}
+
+// computeZeta3 uses the series of Amdeberhan and Zeilberger,
+//
+//	ζ(3) = 1/64 sum (-1)**k (k!)**10 (205k**2 + 250k + 77) / ((2k+1)!)**5.
+func computeZeta3(w uint) *big.Float {
+	wp := w + 16
+	s := sumSeries(func(k int64) (a, b, p, q *big.Int) {
+		a = big.NewInt(205*k + 250)
+		a.Mul(a, big.NewInt(k)).Add(a, big.NewInt(77))
+		if k == 0 {
+			return a, big.NewInt(1), big.NewInt(1), big.NewInt(1)
+		}
+		p = big.NewInt(-k)
+		p.Exp(p, big.NewInt(5), nil)
+		q = big.NewInt(2*k + 1)
+		q.Exp(q, big.NewInt(5), nil).Lsh(q, 5)
+		return a, big.NewInt(1), p, q
+	}, termsFor(wp, 10), wp)
+	return newFloat(w).SetMantExp(s, -6)
+}
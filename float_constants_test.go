@@ -0,0 +1,88 @@
+package mathx
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// constantTestCases are values to 110 digits, computed independently with
+// other series.
+var constantTestCases = []struct {
+	name string
+	f    func(prec uint) *Float
+	want string
+}{
+	{"Pi", Pi, "3.14159265358979323846264338327950288419716939937510582097494459230781640628620899862803482534211706798214808651"},
+	{"E", E, "2.71828182845904523536028747135266249775724709369995957496696762772407663035354759457138217852516642742746639193"},
+	{"Ln2", Ln2, "0.69314718055994530941723212145817656807550013436025525412068000949339362196969471560586332699641868754200148102"},
+	{"Ln10", Ln10, "2.30258509299404568401799145468436420760110148862877297603332790096757260967735248023599720508959829834196778404"},
+	{"EulerGamma", EulerGamma, "0.57721566490153286060651209008240243104215933593992359880576723488486772677766467093694706329174674951463144725"},
+	{"Catalan", Catalan, "0.91596559417721901505460351493238411077414937428167213426649811962176301977625476947935651292611510624857442262"},
+	{"Sqrt2", Sqrt2, "1.41421356237309504880168872420969807856967187537694807317667973799073247846210703885038753432764157273501384623"},
+	{"Zeta3", Zeta3, "1.20205690315959428539973816151144999076498629234049888179227155534183820578631309018645587360933525814619915780"},
+}
+
+func TestConstants(t *testing.T) {
+	for _, c := range constantTestCases {
+		for _, prec := range []uint{1, 2, 24, 53, 64, 100, 300} {
+			got := c.f(prec)
+			want, _, _ := ParseFloat(c.want, 10, prec, big.ToNearestEven)
+			if got.Cmp(want) != 0 || got.Prec() != prec {
+				t.Errorf("%s(%d) = %v, expected %v", c.name, prec, got, want)
+			}
+		}
+		if got := c.f(0); got.Prec() != 64 {
+			t.Errorf("%s(0) has precision %d", c.name, got.Prec())
+		}
+	}
+}
+
+func TestConstantsHighPrecision(t *testing.T) {
+	// the cache must round a more precise value to the same result as
+	// computing directly
+	for _, c := range constantTestCases {
+		low := c.f(3000)
+		high := c.f(10000)
+		if want := rounded((*big.Float)(high), 3000, big.ToNearestEven); low.Cmp(want) != 0 {
+			t.Errorf("%s(3000) differs from %s(10000) rounded", c.name, c.name)
+		}
+	}
+	// other ways to the same values
+	one := NewFloat(1).SetPrec(3000)
+	if got, want := one.Atan().SetExp(2), Pi(3000); got.Cmp(want) != 0 {
+		t.Errorf("4 Atan(1) = %v, expected %v", got, want)
+	}
+	if got, want := one.Exp(), E(3000); got.Cmp(want) != 0 {
+		t.Errorf("Exp(1) = %v, expected %v", got, want)
+	}
+}
+
+func TestConstantsConcurrent(t *testing.T) {
+	c := &constant{compute: computePi}
+	want := Pi(4000)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(prec uint) {
+			defer wg.Done()
+			got := zivRel(prec, big.ToNearestEven, c.at)
+			if w := rounded((*big.Float)(want), prec, big.ToNearestEven); got.Cmp(w) != 0 {
+				t.Errorf("Pi(%d) = %v, expected %v", prec, got, w)
+			}
+		}(uint(500 * (i + 1)))
+	}
+	wg.Wait()
+}
+
+func BenchmarkPi10000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		computePi(10000)
+	}
+}
+
+func BenchmarkEulerGamma10000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		computeEulerGamma(10000)
+	}
+}
@@ -0,0 +1,321 @@
+package mathx
+
+// This file is for FloatContext, which rounds every result of a chain of
+// Float operations to one precision and rounding mode, and records how the
+// results were rounded.
+
+import (
+	"math/big"
+	"strings"
+)
+
+// FloatFlags records the conditions met by the operations of a
+// FloatContext since its flags were last cleared.
+type FloatFlags uint8
+
+const (
+	// FloatInexact is set when a result was rounded.
+	FloatInexact FloatFlags = 1 << iota
+	// FloatUnderflow is set when a nonzero result was rounded to zero,
+	// beyond the exponent range of big.Float.
+	FloatUnderflow
+	// FloatOverflow is set when a finite result was rounded to infinity,
+	// beyond the exponent range of big.Float.
+	FloatOverflow
+)
+
+// String returns the names of the flags that are set, such as
+// "inexact|overflow", or "none".
+func (f FloatFlags) String() string {
+	var names []string
+	for i, name := range []string{"inexact", "underflow", "overflow"} {
+		if f&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// FloatContext rounds the result of each of its operations to Prec bits
+// (or 64 if Prec is 0) with Mode, whatever the precision of the operands:
+//
+//	ctx := &mathx.FloatContext{Prec: 256}
+//	x := ctx.Exp(ctx.Quo(mathx.NewFloat(1), mathx.NewFloat(3)))
+//
+// rounds 1/3 to 256 bits, not to the 53 bits of the operands, and then
+// e**(1/3) to 256 bits. Operands are used exactly, and each result is
+// rounded once, so it is correctly rounded even if an operand has more
+// precision than the context. The context also collects FloatFlags across
+// its operations, so it is not safe for concurrent use.
+type FloatContext struct {
+	Prec  uint
+	Mode  big.RoundingMode
+	flags FloatFlags
+}
+
+// Flags returns the flags set by the operations since the last ClearFlags.
+func (c *FloatContext) Flags() FloatFlags {
+	return c.flags
+}
+
+// ClearFlags clears the flags.
+func (c *FloatContext) ClearFlags() {
+	c.flags = 0
+}
+
+// precision returns the precision results are rounded to.
+func (c *FloatContext) precision() uint {
+	if c.Prec != 0 {
+		return c.Prec
+	}
+	return 64
+}
+
+// result returns a new big.Float for the result of an operation.
+func (c *FloatContext) result() *big.Float {
+	return newFloat(c.precision()).SetMode(c.Mode)
+}
+
+// note records the flags for a result, and returns it.
+func (c *FloatContext) note(r *Float) *Float {
+	x := (*big.Float)(r)
+	if x.Acc() == big.Exact {
+		return r
+	}
+	c.flags |= FloatInexact
+	switch {
+	case x.IsInf():
+		c.flags |= FloatOverflow
+	case x.Sign() == 0:
+		c.flags |= FloatUnderflow
+	}
+	return r
+}
+
+// apply returns f(x) rounded in the context, where f rounds to the
+// precision and mode of its argument.
+func (c *FloatContext) apply(x *Float, f func(x *Float) *Float) *Float {
+	prec, xb := c.precision(), (*big.Float)(x)
+	if xb.Prec() <= prec {
+		return c.note(f((*Float)(c.result().Set(xb))))
+	}
+	// round toward zero with at least two more bits, and then to odd, so
+	// that rounding again is the same as rounding once
+	w := xb.Prec()
+	if w < prec+2 {
+		w = prec + 2
+	}
+	r := (*big.Float)(f((*Float)(newFloat(w).SetMode(big.ToZero).Set(xb))))
+	switch {
+	case r.IsInf() && r.Acc() != big.Exact:
+		return c.note(overflow(prec, c.Mode, r.Signbit()))
+	case r.Sign() == 0 && r.Acc() != big.Exact:
+		return c.note(underflow(prec, c.Mode, r.Signbit()))
+	case r.IsInf() || r.Sign() == 0:
+		return c.note(rounded(r, prec, c.Mode))
+	}
+	if r.Acc() != big.Exact && r.MinPrec() < w {
+		// the last bit is 0, so this does not carry
+		u := pow2(expo(r) - int(w))
+		if r.Sign() < 0 {
+			u.Neg(u)
+		}
+		r = newFloat(w).Add(r, u)
+	}
+	return c.note((*Float)(c.result().Set(r)))
+}
+
+// NewFloat returns x rounded in the context. It panics with ErrNaN if x is
+// NaN.
+func (c *FloatContext) NewFloat(x float64) *Float {
+	return c.note((*Float)(c.result().Set(bigFloat64(x))))
+}
+
+// Set returns x rounded in the context.
+func (c *FloatContext) Set(x *Float) *Float {
+	return c.note((*Float)(c.result().Set((*big.Float)(x))))
+}
+
+// Parse returns the number represented by s in the given base, rounded in
+// the context, as for Float.Parse.
+func (c *FloatContext) Parse(s string, base int) (*Float, int, error) {
+	f, b, err := c.result().Parse(s, base)
+	if err != nil {
+		return nil, b, err
+	}
+	return c.note((*Float)(f)), b, nil
+}
+
+// Add returns x + y rounded in the context. Like big.Float.Add, it panics
+// with big.ErrNaN for infinities of opposite signs.
+func (c *FloatContext) Add(x, y *Float) *Float {
+	return c.note((*Float)(c.result().Add((*big.Float)(x), (*big.Float)(y))))
+}
+
+// Sub returns x - y rounded in the context. Like big.Float.Sub, it panics
+// with big.ErrNaN for infinities of the same sign.
+func (c *FloatContext) Sub(x, y *Float) *Float {
+	return c.note((*Float)(c.result().Sub((*big.Float)(x), (*big.Float)(y))))
+}
+
+// Mul returns x y rounded in the context. Like big.Float.Mul, it panics
+// with big.ErrNaN for zero times infinity.
+func (c *FloatContext) Mul(x, y *Float) *Float {
+	return c.note((*Float)(c.result().Mul((*big.Float)(x), (*big.Float)(y))))
+}
+
+// Quo returns x / y rounded in the context. Like big.Float.Quo, it panics
+// with big.ErrNaN for 0/0 and Inf/Inf.
+func (c *FloatContext) Quo(x, y *Float) *Float {
+	return c.note((*Float)(c.result().Quo((*big.Float)(x), (*big.Float)(y))))
+}
+
+// Neg returns -x rounded in the context.
+func (c *FloatContext) Neg(x *Float) *Float {
+	return c.note((*Float)(c.result().Neg((*big.Float)(x))))
+}
+
+// Abs returns |x| rounded in the context.
+func (c *FloatContext) Abs(x *Float) *Float {
+	return c.note((*Float)(c.result().Abs((*big.Float)(x))))
+}
+
+// Sqrt returns the square root of x rounded in the context, as for
+// Float.Sqrt.
+func (c *FloatContext) Sqrt(x *Float) *Float {
+	return c.apply(x, (*Float).Sqrt)
+}
+
+// Cbrt returns the cube root of x rounded in the context, as for
+// Float.Cbrt.
+func (c *FloatContext) Cbrt(x *Float) *Float {
+	return c.apply(x, (*Float).Cbrt)
+}
+
+// NthRoot returns the k-th root of x rounded in the context, as for
+// Float.NthRoot.
+func (c *FloatContext) NthRoot(x *Float, k int) *Float {
+	return c.apply(x, func(x *Float) *Float { return x.NthRoot(k) })
+}
+
+// Hypot returns sqrt(x**2 + y**2) rounded in the context, as for
+// Float.Hypot.
+func (c *FloatContext) Hypot(x, y *Float) *Float {
+	return c.apply(x, func(x *Float) *Float { return x.Hypot(y) })
+}
+
+// Exp returns e**x rounded in the context, as for Float.Exp.
+func (c *FloatContext) Exp(x *Float) *Float {
+	return c.apply(x, (*Float).Exp)
+}
+
+// Expm1 returns e**x - 1 rounded in the context, as for Float.Expm1.
+func (c *FloatContext) Expm1(x *Float) *Float {
+	return c.apply(x, (*Float).Expm1)
+}
+
+// Log returns the natural logarithm of x rounded in the context, as for
+// Float.Log.
+func (c *FloatContext) Log(x *Float) *Float {
+	return c.apply(x, (*Float).Log)
+}
+
+// Log1p returns the natural logarithm of 1 + x rounded in the context, as
+// for Float.Log1p.
+func (c *FloatContext) Log1p(x *Float) *Float {
+	return c.apply(x, (*Float).Log1p)
+}
+
+// Log2 returns the binary logarithm of x rounded in the context, as for
+// Float.Log2.
+func (c *FloatContext) Log2(x *Float) *Float {
+	return c.apply(x, (*Float).Log2)
+}
+
+// Log10 returns the decimal logarithm of x rounded in the context, as for
+// Float.Log10.
+func (c *FloatContext) Log10(x *Float) *Float {
+	return c.apply(x, (*Float).Log10)
+}
+
+// Pow returns x**y rounded in the context, as for Float.Pow.
+func (c *FloatContext) Pow(x, y *Float) *Float {
+	return c.apply(x, func(x *Float) *Float { return x.Pow(y) })
+}
+
+// Sin returns the sine of x rounded in the context, as for Float.Sin.
+func (c *FloatContext) Sin(x *Float) *Float {
+	return c.apply(x, (*Float).Sin)
+}
+
+// Cos returns the cosine of x rounded in the context, as for Float.Cos.
+func (c *FloatContext) Cos(x *Float) *Float {
+	return c.apply(x, (*Float).Cos)
+}
+
+// Tan returns the tangent of x rounded in the context, as for Float.Tan.
+func (c *FloatContext) Tan(x *Float) *Float {
+	return c.apply(x, (*Float).Tan)
+}
+
+// Asin returns the arcsine of x rounded in the context, as for Float.Asin.
+func (c *FloatContext) Asin(x *Float) *Float {
+	return c.apply(x, (*Float).Asin)
+}
+
+// Acos returns the arccosine of x rounded in the context, as for
+// Float.Acos.
+func (c *FloatContext) Acos(x *Float) *Float {
+	return c.apply(x, (*Float).Acos)
+}
+
+// Atan returns the arctangent of x rounded in the context, as for
+// Float.Atan.
+func (c *FloatContext) Atan(x *Float) *Float {
+	return c.apply(x, (*Float).Atan)
+}
+
+// Atan2 returns the arctangent of y/x rounded in the context, as for
+// Float.Atan2.
+func (c *FloatContext) Atan2(y, x *Float) *Float {
+	return c.apply(y, func(y *Float) *Float { return y.Atan2(x) })
+}
+
+// Sinh returns the hyperbolic sine of x rounded in the context, as for
+// Float.Sinh.
+func (c *FloatContext) Sinh(x *Float) *Float {
+	return c.apply(x, (*Float).Sinh)
+}
+
+// Cosh returns the hyperbolic cosine of x rounded in the context, as for
+// Float.Cosh.
+func (c *FloatContext) Cosh(x *Float) *Float {
+	return c.apply(x, (*Float).Cosh)
+}
+
+// Tanh returns the hyperbolic tangent of x rounded in the context, as for
+// Float.Tanh.
+func (c *FloatContext) Tanh(x *Float) *Float {
+	return c.apply(x, (*Float).Tanh)
+}
+
+// Asinh returns the inverse hyperbolic sine of x rounded in the context,
+// as for Float.Asinh.
+func (c *FloatContext) Asinh(x *Float) *Float {
+	return c.apply(x, (*Float).Asinh)
+}
+
+// Acosh returns the inverse hyperbolic cosine of x rounded in the context,
+// as for Float.Acosh.
+func (c *FloatContext) Acosh(x *Float) *Float {
+	return c.apply(x, (*Float).Acosh)
+}
+
+// Atanh returns the inverse hyperbolic tangent of x rounded in the
+// context, as for Float.Atanh.
+func (c *FloatContext) Atanh(x *Float) *Float {
+	return c.apply(x, (*Float).Atanh)
+}
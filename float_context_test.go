@@ -0,0 +1,125 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestFloatContextArithmetic(t *testing.T) {
+	ctx := &FloatContext{Prec: 200}
+	third := ctx.Quo(NewFloat(1), NewFloat(3))
+	want, _, _ := ParseFloat("0.33333333333333333333333333333333333333333333333333333333333333", 10, 200, big.ToNearestEven)
+	if third.Cmp(want) != 0 || third.Prec() != 200 {
+		t.Errorf("1/3 = %v (%d bits), expected %v", third, third.Prec(), want)
+	}
+	if f := ctx.Flags(); f != FloatInexact {
+		t.Errorf("1/3 set flags %v", f)
+	}
+	ctx.ClearFlags()
+	if got := ctx.Mul(ctx.Add(NewFloat(1), NewFloat(2)), NewFloat(0.5)); !sameFloat64(got, 1.5) || ctx.Flags() != 0 {
+		t.Errorf("(1 + 2) 0.5 = %v with flags %v", got, ctx.Flags())
+	}
+
+	// every result is rounded to the context, whatever the operands
+	ctx = &FloatContext{Prec: 10, Mode: big.ToZero}
+	x, _, _ := ParseFloat("1.0009765625", 10, 100, big.ToNearestEven)
+	for name, got := range map[string]*Float{
+		"Set": ctx.Set(x), "Add": ctx.Add(x, NewFloat(0)), "Sub": ctx.Sub(x, NewFloat(0)),
+		"Neg": ctx.Neg(x.Neg()), "Abs": ctx.Abs(x), "Parse": func() *Float { f, _, _ := ctx.Parse("1.0009765625", 10); return f }(),
+	} {
+		if !sameFloat64(got, 1) || got.Prec() != 10 || got.Mode() != big.ToZero {
+			t.Errorf("%s(%v) = %v (%d bits, %v)", name, x, got, got.Prec(), got.Mode())
+		}
+	}
+	if got := (&FloatContext{}).NewFloat(0.1); got.Prec() != 64 {
+		t.Errorf("NewFloat(0.1) with Prec 0 has %d bits", got.Prec())
+	}
+}
+
+func TestFloatContextFunctions(t *testing.T) {
+	// a function of an operand with more bits than the context is rounded
+	// once; at a precision where the two differ, rounding the operand first
+	// would give a different result
+	funcs := map[string]func(*FloatContext, *Float) *Float{
+		"Exp": (*FloatContext).Exp, "Log": (*FloatContext).Log, "Sin": (*FloatContext).Sin,
+		"Atan": (*FloatContext).Atan, "Sqrt": (*FloatContext).Sqrt, "Cbrt": (*FloatContext).Cbrt,
+		"Cosh": (*FloatContext).Cosh, "Log1p": (*FloatContext).Log1p,
+		"Pow": func(c *FloatContext, x *Float) *Float { return c.Pow(x, NewFloat(2.5)) },
+	}
+	rnd := rand.New(rand.NewSource(7))
+	for name, f := range funcs {
+		for i := 0; i < 20; i++ {
+			x := NewFloat(4 * rnd.Float64()).SetPrec(200)
+			x = x.Add(NewFloat(1).SetExp(-150))
+			for _, mode := range allModes {
+				for _, prec := range []uint{2, 24, 150} {
+					got := f(&FloatContext{Prec: prec, Mode: mode}, x)
+					high := f(&FloatContext{Prec: 600, Mode: big.ToZero}, x)
+					// high is within an ulp of the value, which is not a
+					// Float at prec+2 bits
+					want := rounded((*big.Float)(high.NextUp()), prec, mode)
+					if lo := rounded((*big.Float)(high), prec, mode); lo.Cmp(want) != 0 {
+						continue
+					}
+					if got.Cmp(want) != 0 || got.Prec() != prec || got.Acc() != want.Acc() {
+						t.Errorf("%s(%v) at %d bits %v = %v (%v), expected %v (%v)", name, x, prec, mode, got, got.Acc(), want, want.Acc())
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFloatContextFlags(t *testing.T) {
+	huge := NewFloat(1).SetExp(1 << 30)
+	tiny := NewFloat(1).SetExp(-1<<30 - 10)
+	cases := []struct {
+		name string
+		f    func(c *FloatContext) *Float
+		want FloatFlags
+	}{
+		{"1 + 2", func(c *FloatContext) *Float { return c.Add(NewFloat(1), NewFloat(2)) }, 0},
+		{"1 + 2**-100", func(c *FloatContext) *Float { return c.Add(NewFloat(1), NewFloat(1).SetExp(-100)) }, FloatInexact},
+		{"huge huge", func(c *FloatContext) *Float { return c.Mul(huge, huge) }, FloatInexact | FloatOverflow},
+		{"tiny tiny", func(c *FloatContext) *Float { return c.Mul(tiny, tiny) }, FloatInexact | FloatUnderflow},
+		{"1/0", func(c *FloatContext) *Float { return c.Quo(NewFloat(1), NewFloat(0)) }, 0},
+		{"Exp(1e10)", func(c *FloatContext) *Float { return c.Exp(NewFloat(1e10)) }, FloatInexact | FloatOverflow},
+		{"Exp(-1e10)", func(c *FloatContext) *Float { return c.Exp(NewFloat(-1e10)) }, FloatInexact | FloatUnderflow},
+		{"Exp(2e9)", func(c *FloatContext) *Float { return c.Exp(NewFloat(2e9)) }, FloatInexact | FloatOverflow},
+		{"Pow(huge, 3)", func(c *FloatContext) *Float { return c.Pow(huge, NewFloat(3)) }, FloatInexact | FloatOverflow},
+		{"Sinh(-1e10)", func(c *FloatContext) *Float { return c.Sinh(NewFloat(-1e10)) }, FloatInexact | FloatOverflow},
+		{"Log(0)", func(c *FloatContext) *Float { return c.Log(NewFloat(0)) }, 0},
+		{"Log(1)", func(c *FloatContext) *Float { return c.Log(NewFloat(1)) }, 0},
+		{"Sqrt(2)", func(c *FloatContext) *Float { return c.Sqrt(NewFloat(2)) }, FloatInexact},
+		{"Exp(1e10) with more bits", func(c *FloatContext) *Float { return c.Exp(NewFloat(1e10).SetPrec(100)) }, FloatInexact | FloatOverflow},
+	}
+	for _, c := range cases {
+		ctx := &FloatContext{Prec: 53}
+		// the results are too large to format in decimal
+		c.f(ctx)
+		if ctx.Flags() != c.want {
+			t.Errorf("%s set flags %v, expected %v", c.name, ctx.Flags(), c.want)
+		}
+	}
+
+	// flags accumulate
+	ctx := &FloatContext{Prec: 53}
+	ctx.Mul(huge, huge)
+	ctx.Add(NewFloat(1), NewFloat(1))
+	ctx.Mul(tiny, tiny)
+	if want := FloatInexact | FloatUnderflow | FloatOverflow; ctx.Flags() != want {
+		t.Errorf("flags are %v, expected %v", ctx.Flags(), want)
+	}
+	if s := ctx.Flags().String(); s != "inexact|underflow|overflow" {
+		t.Errorf("flags are %q", s)
+	}
+	ctx.ClearFlags()
+	if s := ctx.Flags().String(); s != "none" {
+		t.Errorf("cleared flags are %q", s)
+	}
+	if got := ctx.Exp(NewFloat(math.Inf(-1))); !sameFloat64(got, 0) || ctx.Flags() != 0 {
+		t.Errorf("Exp(-Inf) = %v with flags %v", got, ctx.Flags())
+	}
+}
@@ -0,0 +1,608 @@
+package mathx
+
+// This file is for the elementary functions on Float. Each is correctly
+// rounded to the precision and rounding mode of its receiver (or to 64
+// bits if the receiver has precision 0), and follows the conventions of
+// the math package for infinities and signed zeros. Where the math package
+// returns NaN, these panic with ErrNaN.
+
+import "math/big"
+
+// same returns z rounded to the precision results are rounded to, for
+// functions that return their argument, such as Sin(±0).
+func (z *Float) same() *Float {
+	return rounded((*big.Float)(z), z.precision(), z.Mode())
+}
+
+// exactResult returns x rounded to the precision and mode of z.
+func (z *Float) exactResult(x *big.Float) *Float {
+	return rounded(x, z.precision(), z.Mode())
+}
+
+// inf returns an infinity with the precision of z.
+func (z *Float) inf(signbit bool) *Float {
+	return (*Float)(newFloat(z.precision()).SetMode(z.Mode()).SetInf(signbit))
+}
+
+// zero returns a zero with the precision of z.
+func (z *Float) zero(signbit bool) *Float {
+	f := newFloat(z.precision()).SetMode(z.Mode())
+	if signbit {
+		f.Neg(f)
+	}
+	return (*Float)(f)
+}
+
+// odd returns f(z) for an odd function with f(x) = x + c x**3 + ..., where
+// c has the sign dir, given its kernel.
+func (z *Float) odd(dir int, kernel func(x *big.Float, w uint) *big.Float) *Float {
+	return oddResult((*big.Float)(z), z.precision(), z.Mode(), dir, kernel)
+}
+
+// oddResult is odd for an argument x that may have more precision than
+// the result.
+func oddResult(x *big.Float, prec uint, mode big.RoundingMode, dir int, kernel func(x *big.Float, w uint) *big.Float) *Float {
+	if x.Sign() == 0 {
+		return rounded(x, prec, mode)
+	}
+	if r, ok := nearly(x, dir*x.Sign(), 3*expo(x), prec, mode); ok {
+		return r
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return kernel(x, w)
+	})
+}
+
+// piMultiple returns k pi / 2**log2Den, correctly rounded to the precision
+// and mode of z.
+func (z *Float) piMultiple(k int64, log2Den int) *Float {
+	return zivRel(z.precision(), z.Mode(), func(w uint) *big.Float {
+		p := piConstant.at(w + 8)
+		p.Mul(p, new(big.Float).SetInt64(k))
+		return newFloat(w).SetMantExp(p, -log2Den)
+	})
+}
+
+// Exp returns e**z. Exp(+Inf) = +Inf and Exp(-Inf) = +0.
+func (z *Float) Exp() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf():
+		if x.Signbit() {
+			return z.zero(false)
+		}
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.exactResult(floatOne)
+	}
+	prec, mode := z.precision(), z.Mode()
+	if r, ok := nearly(floatOne, x.Sign(), expo(x)+1, prec, mode); ok {
+		return r
+	}
+	if expo(x) > 32 {
+		// beyond the exponent range of big.Float
+		if x.Sign() > 0 {
+			return overflow(prec, mode, false)
+		}
+		return underflow(prec, mode, false)
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return expKernel(x, w)
+	})
+}
+
+// Expm1 returns e**z - 1, which is more accurate than z.Exp().Sub(1) for
+// z near zero. Expm1(+Inf) = +Inf and Expm1(-Inf) = -1.
+func (z *Float) Expm1() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf():
+		if x.Signbit() {
+			return z.exactResult(big.NewFloat(-1))
+		}
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.same()
+	}
+	prec, mode := z.precision(), z.Mode()
+	if r, ok := nearly(x, 1, 2*expo(x), prec, mode); ok {
+		return r
+	}
+	if x.Sign() < 0 && expo(x) > 0 {
+		// e**x - 1 = -1 + d with 0 < d = e**x < 2**(x log2(e) + 1)
+		f, _ := x.Float64()
+		dExp := -1 << 40
+		if f > -1<<39 {
+			dExp = int(f*1.4426950408889634) + 1
+		}
+		if r, ok := nearly(big.NewFloat(-1), 1, dExp, prec, mode); ok {
+			return r
+		}
+	}
+	if expo(x) > 32 {
+		return overflow(prec, mode, false)
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return expm1Kernel(x, w)
+	})
+}
+
+// checkLog panics if z is negative, and returns the result for +Inf and
+// zeros.
+func (z *Float) checkLog() (*Float, bool) {
+	x := (*big.Float)(z)
+	switch {
+	case x.Sign() == 0:
+		return z.inf(true), true
+	case x.Sign() < 0:
+		panic(ErrNaN)
+	case x.IsInf():
+		return z.inf(false), true
+	case x.Cmp(floatOne) == 0:
+		return z.zero(false), true
+	}
+	return nil, false
+}
+
+// Log returns the natural logarithm of z. Log(+Inf) = +Inf, Log(±0) =
+// -Inf, and Log panics with ErrNaN if z < 0.
+func (z *Float) Log() *Float {
+	if r, ok := z.checkLog(); ok {
+		return r
+	}
+	x := (*big.Float)(z)
+	prec, mode := z.precision(), z.Mode()
+	if expo(x) == 0 || expo(x) == 1 && x.Cmp(big.NewFloat(1.5)) < 0 {
+		// log(1 + d) = d - d**2/2 + ..., where d = z - 1 is exact
+		d := newFloat(x.Prec()+1).Sub(x, floatOne)
+		if r, ok := nearly(d, -1, 2*expo(d), prec, mode); ok {
+			return r
+		}
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return logKernel(x, w)
+	})
+}
+
+// Log1p returns the natural logarithm of 1 + z, which is more accurate than
+// z.Add(1).Log() for z near zero. Log1p(-1) = -Inf, and Log1p panics with
+// ErrNaN if z < -1.
+func (z *Float) Log1p() *Float {
+	x := (*big.Float)(z)
+	switch c := x.Cmp(big.NewFloat(-1)); {
+	case c < 0:
+		panic(ErrNaN)
+	case c == 0:
+		return z.inf(true)
+	case x.IsInf():
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.same()
+	}
+	prec, mode := z.precision(), z.Mode()
+	if r, ok := nearly(x, -1, 2*expo(x), prec, mode); ok {
+		return r
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return log1pKernel(x, w)
+	})
+}
+
+// Log2 returns the binary logarithm of z, which is exact for powers of
+// two. Special cases are as for Log.
+func (z *Float) Log2() *Float {
+	if r, ok := z.checkLog(); ok {
+		return r
+	}
+	x := (*big.Float)(z)
+	if x.MinPrec() == 1 {
+		return z.exactResult(new(big.Float).SetInt64(int64(expo(x) - 1)))
+	}
+	return zivRel(z.precision(), z.Mode(), func(w uint) *big.Float {
+		y := logKernel(x, w+4)
+		return newFloat(w).Quo(y, ln2Constant.at(w+4))
+	})
+}
+
+// Log10 returns the decimal logarithm of z, which is exact for powers of
+// ten. Special cases are as for Log.
+func (z *Float) Log10() *Float {
+	if r, ok := z.checkLog(); ok {
+		return r
+	}
+	x := (*big.Float)(z)
+	// z = m 2**e with m odd is 10**k exactly when e = k and m = 5**k
+	mant := new(big.Float)
+	e := x.MantExp(mant) - int(x.MinPrec())
+	if e > 0 && e < 1<<20 && absInt(int(x.MinPrec())-int(float64(e)*2.321928094887362)) <= 2 {
+		m, _ := mant.SetMantExp(mant, int(x.MinPrec())).Int(nil)
+		if m.Cmp(new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(e)), nil)) == 0 {
+			return z.exactResult(new(big.Float).SetInt64(int64(e)))
+		}
+	}
+	return zivRel(z.precision(), z.Mode(), func(w uint) *big.Float {
+		y := logKernel(x, w+4)
+		return newFloat(w).Quo(y, ln10Constant.at(w+4))
+	})
+}
+
+// isOddInt returns whether x is an odd integer.
+func isOddInt(x *big.Float) bool {
+	return x.Sign() != 0 && !x.IsInf() && int(x.MinPrec()) == expo(x)
+}
+
+// Pow returns z**y. Special cases follow math.Pow: Pow(x, ±0) = 1 and
+// Pow(1, y) = 1 for any x and y, zeros and infinities give zeros and
+// infinities, and Pow panics with ErrNaN for finite z < 0 and finite
+// non-integer y. Results that are exactly representable, such as
+// Pow(4, 1.5), are found exactly.
+func (z *Float) Pow(y *Float) *Float {
+	x, yb := (*big.Float)(z), (*big.Float)(y)
+	switch {
+	case yb.Sign() == 0 || x.Cmp(floatOne) == 0:
+		return z.exactResult(floatOne)
+	case yb.Cmp(floatOne) == 0:
+		return z.same()
+	case x.Sign() == 0:
+		switch {
+		case yb.Sign() < 0 && isOddInt(yb):
+			return z.inf(x.Signbit())
+		case yb.Sign() < 0:
+			return z.inf(false)
+		case isOddInt(yb):
+			return z.same()
+		}
+		return z.zero(false)
+	case yb.IsInf():
+		switch {
+		case x.Cmp(big.NewFloat(-1)) == 0:
+			return z.exactResult(floatOne)
+		case (cmpAbs(x, floatOne) < 0) == (yb.Sign() > 0):
+			return z.zero(false)
+		}
+		return z.inf(false)
+	case x.IsInf():
+		neg := x.Signbit() && isOddInt(yb)
+		if yb.Sign() < 0 {
+			return z.zero(neg)
+		}
+		return z.inf(neg)
+	case x.Sign() < 0 && !yb.IsInt():
+		panic(ErrNaN)
+	case x.Cmp(big.NewFloat(-1)) == 0:
+		if isOddInt(yb) {
+			return z.same()
+		}
+		return z.exactResult(floatOne)
+	}
+	prec, mode := z.precision(), z.Mode()
+
+	// y = n / 2**k with n odd; z**y can only be exact if z is a 2**k-th
+	// power
+	ymant := new(big.Float)
+	k := int(yb.MinPrec()) - yb.MantExp(ymant)
+	n, _ := ymant.SetMantExp(ymant, int(yb.MinPrec())).Int(nil)
+	xmant := new(big.Float)
+	e := x.MantExp(xmant) - int(x.MinPrec())
+	m, _ := xmant.SetMantExp(xmant, int(x.MinPrec())).Int(nil)
+	// a huge integer y overflows or underflows, which is found below
+	exact := k >= -64
+	if exact && k < 0 {
+		n.Lsh(n, uint(-k))
+	}
+	for ; exact && k > 0; k-- {
+		if e%2 != 0 {
+			exact = false
+			break
+		}
+		if m.Cmp(bigOne) != 0 {
+			r := new(big.Int).Sqrt(m)
+			if new(big.Int).Mul(r, r).Cmp(m) != 0 {
+				exact = false
+				break
+			}
+			m = r
+		}
+		e /= 2
+	}
+	if exact {
+		if r, ok := powExact(m, e, n, prec, mode); ok {
+			return r
+		}
+	}
+
+	// |z|**y = exp(t) with t = y log |z|; estimate the size of t first
+	a := new(big.Float).Abs(x)
+	negate := x.Sign() < 0 && isOddInt(yb)
+	t0 := newFloat(32).Mul(yb, logKernel(a, 32))
+	et := expo(t0) + 1
+	if et > 33 {
+		if t0.Sign() > 0 {
+			return overflow(prec, mode, negate)
+		}
+		return underflow(prec, mode, negate)
+	}
+	c := big.NewFloat(1)
+	if negate {
+		c.Neg(c)
+	}
+	dir := yb.Sign() * a.Cmp(floatOne)
+	if negate {
+		dir = -dir
+	}
+	if r, ok := nearly(c, dir, et+1, prec, mode); ok {
+		return r
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		wl := w + 8
+		if et > 0 {
+			wl += uint(et)
+		}
+		t := newFloat(wl).Mul(yb, logKernel(a, wl))
+		r := expKernel(t, w)
+		if negate {
+			r.Neg(r)
+		}
+		return r
+	})
+}
+
+// powExact returns (m 2**e)**n correctly rounded, if the exact result is
+// small enough to compute.
+func powExact(m *big.Int, e int, n *big.Int, prec uint, mode big.RoundingMode) (*Float, bool) {
+	// the exponent of the result, clamped to beyond the range of big.Float
+	exp := new(big.Int).Mul(n, big.NewInt(int64(e)))
+	if exp.CmpAbs(big.NewInt(1<<40)) > 0 {
+		exp.SetInt64(int64(exp.Sign()) << 40)
+	}
+	f := newFloat(prec).SetMode(mode)
+	if m.CmpAbs(bigOne) == 0 {
+		f.SetInt64(1)
+		if m.Sign() < 0 && n.Bit(0) == 1 {
+			f.Neg(f)
+		}
+		return (*Float)(f.SetMantExp(f, int(exp.Int64()))), true
+	}
+	limit := 2*int64(prec) + 4096
+	if !n.IsInt64() || n.Int64() > limit || n.Int64() < -limit || int64(m.BitLen())*absInt64(n.Int64()) > limit {
+		return nil, false
+	}
+	// scale before the one rounding, so that f.Acc() is right
+	p := new(big.Float).SetInt(new(big.Int).Exp(m, new(big.Int).Abs(n), nil))
+	if n.Sign() > 0 {
+		f.Set(p.SetMantExp(p, int(exp.Int64())))
+	} else {
+		f.Quo(pow2(int(exp.Int64())), p)
+	}
+	return (*Float)(f), true
+}
+
+func absInt64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Sin returns the sine of z. Sin(±0) = ±0, and Sin panics with ErrNaN for
+// infinite z.
+func (z *Float) Sin() *Float {
+	if z.IsInf() {
+		panic(ErrNaN)
+	}
+	return z.odd(-1, func(x *big.Float, w uint) *big.Float {
+		return sinCosKernel(x, false, false, w)
+	})
+}
+
+// Cos returns the cosine of z. Cos(±0) = 1, and Cos panics with ErrNaN
+// for infinite z.
+func (z *Float) Cos() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf():
+		panic(ErrNaN)
+	case x.Sign() == 0:
+		return z.exactResult(floatOne)
+	}
+	prec, mode := z.precision(), z.Mode()
+	if r, ok := nearly(floatOne, -1, 2*expo(x), prec, mode); ok {
+		return r
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return sinCosKernel(x, true, false, w)
+	})
+}
+
+// Tan returns the tangent of z. Tan(±0) = ±0, and Tan panics with ErrNaN
+// for infinite z.
+func (z *Float) Tan() *Float {
+	if z.IsInf() {
+		panic(ErrNaN)
+	}
+	return z.odd(1, func(x *big.Float, w uint) *big.Float {
+		return sinCosKernel(x, false, true, w)
+	})
+}
+
+// Asin returns the arcsine of z, in [-Pi/2, Pi/2]. Asin(±0) = ±0, and
+// Asin panics with ErrNaN if |z| > 1.
+func (z *Float) Asin() *Float {
+	x := (*big.Float)(z)
+	switch c := cmpAbs(x, floatOne); {
+	case c > 0:
+		panic(ErrNaN)
+	case c == 0:
+		return z.piMultiple(int64(x.Sign()), 1)
+	}
+	return z.odd(1, asinKernel)
+}
+
+// Acos returns the arccosine of z, in [0, Pi]. Acos(1) = +0, and Acos
+// panics with ErrNaN if |z| > 1.
+func (z *Float) Acos() *Float {
+	x := (*big.Float)(z)
+	switch c := cmpAbs(x, floatOne); {
+	case c > 0:
+		panic(ErrNaN)
+	case c == 0 && x.Sign() > 0:
+		return z.zero(false)
+	case c == 0:
+		return z.piMultiple(1, 0)
+	}
+	return zivRel(z.precision(), z.Mode(), func(w uint) *big.Float {
+		return acosKernel(x, w)
+	})
+}
+
+// Atan returns the arctangent of z, in [-Pi/2, Pi/2]. Atan(±0) = ±0 and
+// Atan(±Inf) = ±Pi/2.
+func (z *Float) Atan() *Float {
+	if z.IsInf() {
+		return z.piMultiple(int64(z.Sign()), 1)
+	}
+	return z.odd(-1, atanKernel)
+}
+
+// Atan2 returns the arctangent of z/x, using the signs of both to find the
+// quadrant, as math.Atan2(z, x) does. The result is in [-Pi, Pi], and is
+// rounded to the precision and mode of z.
+func (z *Float) Atan2(x *Float) *Float {
+	yb, xb := (*big.Float)(z), (*big.Float)(x)
+	sign := int64(1)
+	if yb.Signbit() {
+		sign = -1
+	}
+	switch {
+	case yb.Sign() == 0:
+		if xb.Signbit() {
+			return z.piMultiple(sign, 0)
+		}
+		return z.same()
+	case xb.Sign() == 0:
+		return z.piMultiple(sign, 1)
+	case xb.IsInf():
+		switch {
+		case yb.IsInf() && xb.Sign() > 0:
+			return z.piMultiple(sign, 2)
+		case yb.IsInf():
+			return z.piMultiple(3*sign, 2)
+		case xb.Sign() > 0:
+			return z.zero(yb.Signbit())
+		}
+		return z.piMultiple(sign, 0)
+	case yb.IsInf():
+		return z.piMultiple(sign, 1)
+	}
+
+	// when y/x is exact, the first quadrant is just Atan
+	q := newFloat(yb.Prec()+2).Quo(yb, xb)
+	if q.Acc() == big.Exact && xb.Sign() > 0 {
+		return oddResult(q, z.precision(), z.Mode(), -1, atanKernel)
+	}
+	return zivRel(z.precision(), z.Mode(), func(w uint) *big.Float {
+		wp := w + 8
+		r := atanKernel(newFloat(wp).Quo(yb, xb), wp)
+		if xb.Sign() < 0 {
+			p := piConstant.at(wp)
+			if sign < 0 {
+				p.Neg(p)
+			}
+			r.Add(r, p)
+		}
+		return newFloat(w).Set(r)
+	})
+}
+
+// Sinh returns the hyperbolic sine of z. Sinh(±0) = ±0 and Sinh(±Inf) =
+// ±Inf.
+func (z *Float) Sinh() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf():
+		return z.same()
+	case x.Sign() != 0 && expo(x) > 32:
+		return overflow(z.precision(), z.Mode(), x.Signbit())
+	}
+	return z.odd(1, sinhKernel)
+}
+
+// Cosh returns the hyperbolic cosine of z. Cosh(±0) = 1 and Cosh(±Inf) =
+// +Inf.
+func (z *Float) Cosh() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf():
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.exactResult(floatOne)
+	case expo(x) > 32:
+		return overflow(z.precision(), z.Mode(), false)
+	}
+	prec, mode := z.precision(), z.Mode()
+	if r, ok := nearly(floatOne, 1, 2*expo(x), prec, mode); ok {
+		return r
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return coshKernel(x, w)
+	})
+}
+
+// Tanh returns the hyperbolic tangent of z. Tanh(±0) = ±0 and Tanh(±Inf)
+// = ±1.
+func (z *Float) Tanh() *Float {
+	x := (*big.Float)(z)
+	prec, mode := z.precision(), z.Mode()
+	if x.Sign() != 0 && (x.IsInf() || expo(x) > 0) {
+		// tanh(x) = ±(1 - d) with 0 < d < 2 e**(-2|x|) < 2**(1 - 2|x|)
+		c := big.NewFloat(float64(x.Sign()))
+		f, _ := new(big.Float).Abs(x).Float64()
+		dExp := 1 - int(2*f)
+		if x.IsInf() || f > 1<<30 {
+			dExp = -1 << 31
+		}
+		if r, ok := nearly(c, -x.Sign(), dExp, prec, mode); ok {
+			return r
+		}
+	}
+	return z.odd(-1, tanhKernel)
+}
+
+// Asinh returns the inverse hyperbolic sine of z. Asinh(±0) = ±0 and
+// Asinh(±Inf) = ±Inf.
+func (z *Float) Asinh() *Float {
+	if z.IsInf() {
+		return z.same()
+	}
+	return z.odd(-1, asinhKernel)
+}
+
+// Acosh returns the inverse hyperbolic cosine of z. Acosh(1) = +0,
+// Acosh(+Inf) = +Inf, and Acosh panics with ErrNaN if z < 1.
+func (z *Float) Acosh() *Float {
+	x := (*big.Float)(z)
+	switch c := x.Cmp(floatOne); {
+	case c < 0:
+		panic(ErrNaN)
+	case c == 0:
+		return z.zero(false)
+	case x.IsInf():
+		return z.inf(false)
+	}
+	return zivRel(z.precision(), z.Mode(), func(w uint) *big.Float {
+		return acoshKernel(x, w)
+	})
+}
+
+// Atanh returns the inverse hyperbolic tangent of z. Atanh(±0) = ±0,
+// Atanh(±1) = ±Inf, and Atanh panics with ErrNaN if |z| > 1.
+func (z *Float) Atanh() *Float {
+	x := (*big.Float)(z)
+	switch c := cmpAbs(x, floatOne); {
+	case c > 0:
+		panic(ErrNaN)
+	case c == 0:
+		return z.inf(x.Signbit())
+	}
+	return z.odd(1, atanhKernel)
+}
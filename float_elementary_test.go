@@ -0,0 +1,414 @@
+package mathx
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// elementaryTestCases are values to 110 digits, from an independent
+// computation in decimal arithmetic. The arguments are exact in binary.
+var elementaryTestCases = []struct {
+	f    string
+	x    string
+	want string
+}{
+	{"Exp", "0.5", "1.64872127070012814684865078781416357165377610071014801157507931164066102119421560863277652005636664300286663776e+0"},
+	{"Exp", "-3.25", "3.87742078317220098868998352675961432601440619360201457006958609930934431984710807317697965094721781169756631126e-2"},
+	{"Exp", "10", "2.20264657948067165169579006452842443663535126185567810742354263552252028185707925751991209681645258954515555011e+4"},
+	{"Exp", "100.125", "3.04603578091137246159971837058594513214636919372274411669473225446440366595086872400440860451957078754787367660e+43"},
+	{"Exp", "-0.001953125", "9.98048781107475472710042659082673595255937528615535682186430746139718358447838978682864622620868745484709557092e-1"},
+	{"Expm1", "0.5", "6.48721270700128146848650787814163571653776100710148011575079311640661021194215608632776520056366643002866637756e-1"},
+	{"Expm1", "-0.75", "-5.27633447258985292861953449056732087029796420863523317604342055858799054366919050316412455740665536049857703254e-1"},
+	{"Expm1", "0.0078125", "7.84309720644797769345355976012357919339214988403723154210802894161158890447681571725748765762922605651516501143e-3"},
+	{"Expm1", "-20", "-9.99999997938846377561442172034059619844179023624192724400896307027755338370835976215440646720089072094418632964e-1"},
+	{"Log", "2", "6.93147180559945309417232121458176568075500134360255254120680009493393621969694715605863326996418687542001481021e-1"},
+	{"Log", "0.75", "-2.87682072451780927439219005993827431503509710897761056506665685349292950720780464338110899179105286296032932975e-1"},
+	{"Log", "1000000", "1.38155105579642741041079487281061852456066089317726378561999674058054356580641148814159832305375897900518067043e+1"},
+	{"Log", "1.0009765625", "9.76085973055458895960824908017186672611834333784536237758598274400372124025879163951627594159157217908285233099e-4"},
+	{"Log", "0.0001220703125", "-9.01091334727928902242401757895629538498150174668331830356884012341411708560603130287622325095344293804601925327e+0"},
+	{"Log1p", "0.5", "4.05465108108164381978013115464349136571990423462494197614014324144100671248914251267752427817313401245968548045e-1"},
+	{"Log1p", "-0.5", "-6.93147180559945309417232121458176568075500134360255254120680009493393621969694715605863326996418687542001481021e-1"},
+	{"Log1p", "0.0009765625", "9.76085973055458895960824908017186672611834333784536237758598274400372124025879163951627594159157217908285233099e-4"},
+	{"Log1p", "1000", "6.90875477931522058522078376297362763426405952827948548009700336973161815398971616026228787003099580717703561219e+0"},
+	{"Log2", "3", "1.58496250072115618145373894394781650875981440769248106045575265454109822779435856252228047491808824209098066248e+0"},
+	{"Log2", "0.1875", "-2.41503749927884381854626105605218349124018559230751893954424734545890177220564143747771952508191175790901933752e+0"},
+	{"Log2", "1000", "9.96578428466208704361095828846817052759449417907374183616426918744780432982587564755041923007811046529897211513e+0"},
+	{"Log10", "2", "3.01029995663981195213738894724493026768189881462108541310427461127108189274424509486927252118186172040684477191e-1"},
+	{"Log10", "0.5", "-3.01029995663981195213738894724493026768189881462108541310427461127108189274424509486927252118186172040684477191e-1"},
+	{"Log10", "12345", "4.09149109426795108184899676513017393756105641376818193307224963777803752501090242569368365584474539731517973373e+0"},
+	{"Sin", "0.5", "4.79425538604203000273287935215571388081803367940600675188616613125535000287814832209631274684348269086132091085e-1"},
+	{"Sin", "1", "8.41470984807896506652502321630298999622563060798371065672751709991910404391239668948639743543052695854349037908e-1"},
+	{"Sin", "-2.5", "-5.98472144103956494051854702186162271703597171577223573302627032638744272192737075040211471513876350746333242973e-1"},
+	{"Sin", "100", "-5.06365641109758793656557610459785432065032721290657323443392473594357913419476696499236664512927392207244089393e-1"},
+	{"Sin", "1000000", "-3.49993502171292952117652486780771469061406605328716273857059054644641226395450505065666897668894008112733169057e-1"},
+	{"Sin", "3.140625", "9.67653438782279464995290017679590108461057229927694426964171684984815310836114969397269258834712429691614233662e-4"},
+	{"Cos", "0.5", "8.77582561890372716116281582603829651991645197109744052997610868315950763274213947405794184084682258355478400593e-1"},
+	{"Cos", "1", "5.40302305868139717400936607442976603732310420617922227670097255381100394774471764517951856087183089343571731160e-1"},
+	{"Cos", "-2.5", "-8.01143615546933714833502790467351664428567848767820135074597991662024077171186391880105219563427274998970228811e-1"},
+	{"Cos", "100", "8.62318872287683934101938513950842535510084008535510829280162112692721088050926624103095105684277285067135607555e-1"},
+	{"Cos", "1.5703125", "4.83826776020248693804876290776874060940332270749732571958696977567873517092999463424024571376128338742549703716e-4"},
+	{"Tan", "0.5", "5.46302489843790513255179465780285383297551720179791246164091385932907510518025815715180648270656218589104862600e-1"},
+	{"Tan", "1.5", "1.41014199471717193876460836519877564456595435772358618661232675860896962704141552686487029263094422870458678386e+1"},
+	{"Tan", "-3", "1.42546543074277805295635410533913493226092284901804647633238976688858595221538538059106058347766911365259878246e-1"},
+	{"Tan", "100", "-5.87213915156929076677809635644587894258765986872919544126639683609894015550091914383740392041027458057165897532e-1"},
+	{"Asin", "0.5", "5.23598775598298873077107230546583814032861566562517636829157432051302734381034833104672470890352844663691347752e-1"},
+	{"Asin", "-0.75", "-8.48062078981481008052944338998418080073366213263112642860718163570200821228474234349189801731957230300995227265e-1"},
+	{"Asin", "0.9990234375", "1.52659855564918130130475500367699619896550059858049297556579123438453149239955869636727286633469923276563440828e+0"},
+	{"Asin", "0.0625", "6.25407617964913908006016368963807600413311640539283833188624919735663906830898334943453870588741005474466757626e-2"},
+	{"Acos", "0.5", "1.04719755119659774615421446109316762806572313312503527365831486410260546876206966620934494178070568932738269550e+0"},
+	{"Acos", "-0.75", "2.41885840577637762728426603063816952217195091295066555334819045972410902437157873366320721440301576429206927052e+0"},
+	{"Acos", "0.9990234375", "4.41977711457153179265666879627552431330841011070599349216810617693767107435458029467445463363593012254396349808e-2"},
+	{"Acos", "0", "1.57079632679489661923132169163975144209858469968755291048747229615390820314310449931401741267105853399107404326e+0"},
+	{"Acos", "-0.9990234375", "3.09739488244407792053607669531674764106408529826804588605326353053843969554266319568129027900575776675670845153e+0"},
+	{"Atan", "0.5", "4.63647609000806116214256231461214402028537054286120263810933088720197864165741705300600283984887892556529852251e-1"},
+	{"Atan", "-0.75", "-6.43501108793284386802809228717322638041510591115312382865606118713512474811621088712816844701282748878014338754e-1"},
+	{"Atan", "2", "1.10714871779409050301706546017853704007004764540143264667653920743371033897736279401341712868617064143454419101e+0"},
+	{"Atan", "1000", "1.56979632712822975256479788200483089808696376513328489739604124796626273080243493702274393776965013197786239404e+0"},
+	{"Atan", "0.0625", "6.24188099959573484739791129855051136062738877974991946075278168986902672168034578139693617234061160616458264244e-2"},
+	{"Atan", "1", "7.85398163397448309615660845819875721049292349843776455243736148076954101571552249657008706335529266995537021628e-1"},
+	{"Sinh", "0.5", "5.21095305493747361622425626411491559105928982611480527946093576452802250890233592317064454274188593488221423981e-1"},
+	{"Sinh", "-3", "-1.00178749274099018989745936194658280601781041231828634644056532510463926051808870905252214580081921788136031436e+1"},
+	{"Sinh", "0.0078125", "7.81257947310223279375816682325287456125093943571833817255760433425196661059262979918168849109510891894954720660e-3"},
+	{"Sinh", "50", "2.59235276429353623204372666146674269241373445385443080275613931603628761604626896159841263283890123875595749683e+21"},
+	{"Cosh", "0.5", "1.12762596520638078522622516140267201254784711809866748362898573518785877030398201631571206578217804951464521378e+0"},
+	{"Cosh", "-3", "1.00676619957777658419539360351158898368098037153712866799732809786524532729110866406792757022448255233400044723e+1"},
+	{"Cosh", "0.0078125", "1.00003051773334574489969539293687070463214121044831889336955042460735962229388418591807579916653411713756561780e+0"},
+	{"Cosh", "50", "2.59235276429353623204372666146674269241373464672941559914791761777056926874752643688167775586519232853686787888e+21"},
+	{"Tanh", "0.5", "4.62117157260009758502318483643672548730289280330113038552731815838080906140409278774949064151962490584348932986e-1"},
+	{"Tanh", "-3", "-9.95054753686730451331880185255488475097813854700282491823878815130664702782559176719395960022311757996999244472e-1"},
+	{"Tanh", "0.0078125", "7.81234105816101382139181619847605950062003673596484442552466755599419996102343065559163019534014130455314779589e-3"},
+	{"Tanh", "10", "9.99999995877692763619592837138275741050814618495019962261400695436801880898766826106513324950690231869725941954e-1"},
+	{"Asinh", "0.5", "4.81211825059603447497758913424368423135184334385660519661018168840163867608221774412009429122723474997231839958e-1"},
+	{"Asinh", "-3", "-1.81844645923206682348369896356070899378625394276812161745174416723305410786617575102608404436079269363084091947e+0"},
+	{"Asinh", "0.0078125", "7.81242052932300746507094733263108090791874579345118830464054487443267281132222073161094382823844473413229144792e-3"},
+	{"Asinh", "1000000", "1.45086577385244694135251807558143618137341923994661922639664807732415376133483441022301948531999310843676387808e+1"},
+	{"Acosh", "1.5", "9.62423650119206894995517826848736846270368668771321039322036337680327735216443548824018858245446949994463679917e-1"},
+	{"Acosh", "3", "1.76274717403908605046521864995958461805632065652327082150659121730675436844405217566741378382051208571347963238e+0"},
+	{"Acosh", "1.0009765625", "4.41905780831100944299637635287994671116916497867872322058681426155767250248032448545777461017092487323549775717e-2"},
+	{"Acosh", "1000000", "1.45086577385239694135251807558143618136300257327995255972998140573561209466816774355634982618680858462724006855e+1"},
+	{"Atanh", "0.5", "5.49306144334054845697622618461262852323745278911374725867347166818747146609304483436807877406866044393985014533e-1"},
+	{"Atanh", "-0.75", "-9.72955074527656652552676371721589864818542364790930594229695074968789931376034633893829249293935763496530847103e-1"},
+	{"Atanh", "0.0078125", "7.81265895154042091032347127604017266635880938910955200955719535158410267751001383265631664761302078151283124049e-3"},
+	{"Atanh", "0.9990234375", "3.81206529283064476456228418624002266892352035098669755305266647738547356938212727232273232205123527868574268990e+0"},
+	{"Pow", "2,0.5", "1.41421356237309504880168872420969807856967187537694807317667973799073247846210703885038753432764157273501384623e+0"},
+	{"Pow", "3,-1.25", "2.53278561883864182443729250218181778465591149629103439537096217900107411057015757198136871388345718988837436092e-1"},
+	{"Pow", "0.75,100.5", "2.77751856761335089146703622193274340596688337961864350057580093365805300685289534417862035564331586775682883462e-13"},
+	{"Pow", "10,0.3125", "2.05352502645714607460575234829561207657361429182956233752063944720231270821639589943406497296840591143550132797e+0"},
+	{"Pow", "1.0009765625,1000", "2.65404787101573300372082108477006208695073101541985299215299723250984920598957285725556137313844565264483746462e+0"},
+	{"Atan2", "1,2", "4.63647609000806116214256231461214402028537054286120263810933088720197864165741705300600283984887892556529852251e-1"},
+	{"Atan2", "-1,-2", "-2.67794504458898712224838715181828848216863234508898555716401150358761854212046729332743454135722917542561823426e+0"},
+	{"Atan2", "3,-0.5", "1.73594500420952345751044998128369489609697136015258072950550672616505386532290964078890455056975864188107060731e+0"},
+	{"Atan2", "-0.25,-4", "-3.07917384359383588998866427029399777059089551157760662636741677540912613906940554081406546361871095192050226009e+0"},
+}
+
+var elementaryFuncs = map[string]func(x *Float) *Float{
+	"Exp":   (*Float).Exp,
+	"Expm1": (*Float).Expm1,
+	"Log":   (*Float).Log,
+	"Log1p": (*Float).Log1p,
+	"Log2":  (*Float).Log2,
+	"Log10": (*Float).Log10,
+	"Sin":   (*Float).Sin,
+	"Cos":   (*Float).Cos,
+	"Tan":   (*Float).Tan,
+	"Asin":  (*Float).Asin,
+	"Acos":  (*Float).Acos,
+	"Atan":  (*Float).Atan,
+	"Sinh":  (*Float).Sinh,
+	"Cosh":  (*Float).Cosh,
+	"Tanh":  (*Float).Tanh,
+	"Asinh": (*Float).Asinh,
+	"Acosh": (*Float).Acosh,
+	"Atanh": (*Float).Atanh,
+}
+
+// evalElementary evaluates a test case function at the precision and mode.
+func evalElementary(f, x string, prec uint, mode big.RoundingMode) *Float {
+	args := strings.Split(x, ",")
+	a, _, _ := ParseFloat(args[0], 10, prec, mode)
+	switch f {
+	case "Pow":
+		b, _, _ := ParseFloat(args[1], 10, 64, mode)
+		return a.Pow(b)
+	case "Atan2":
+		b, _, _ := ParseFloat(args[1], 10, 64, mode)
+		return a.Atan2(b)
+	}
+	return elementaryFuncs[f](a)
+}
+
+func TestElementaryValues(t *testing.T) {
+	modes := []big.RoundingMode{big.ToNearestEven, big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf}
+	for _, c := range elementaryTestCases {
+		for _, prec := range []uint{24, 53, 100, 300} {
+			for _, mode := range modes {
+				got := evalElementary(c.f, c.x, prec, mode)
+				want, _, _ := ParseFloat(c.want, 10, prec, mode)
+				if got.Cmp(want) != 0 || got.Prec() != prec {
+					t.Errorf("%s(%s) at %d bits %v = %v, expected %v", c.f, c.x, prec, mode, got, want)
+				}
+				if acc := got.Acc(); acc != want.Acc() {
+					t.Errorf("%s(%s) at %d bits %v has accuracy %v, expected %v", c.f, c.x, prec, mode, acc, want.Acc())
+				}
+			}
+		}
+	}
+}
+
+func TestElementaryFloat64(t *testing.T) {
+	// the math package is accurate to within an ulp or so, except for
+	// Log2, Asin and Acos near 1, which are left to the table above
+	mathFuncs := map[string]func(float64) float64{
+		"Exp": math.Exp, "Expm1": math.Expm1, "Log": math.Log, "Log1p": math.Log1p,
+		"Log10": math.Log10, "Sin": math.Sin, "Cos": math.Cos,
+		"Tan": math.Tan, "Atan": math.Atan,
+		"Sinh": math.Sinh, "Cosh": math.Cosh, "Tanh": math.Tanh, "Asinh": math.Asinh,
+		"Acosh": math.Acosh, "Atanh": math.Atanh,
+	}
+	for name, f := range elementaryFuncs {
+		if mathFuncs[name] == nil {
+			continue
+		}
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 200; i++ {
+			x := math.Ldexp(rnd.Float64(), rnd.Intn(12)-6)
+			switch name {
+			case "Asin", "Acos", "Atanh":
+				x = 2*rnd.Float64() - 1
+			case "Acosh":
+				x++
+			case "Log", "Log1p", "Log2", "Log10":
+			default:
+				if rnd.Intn(2) == 0 {
+					x = -x
+				}
+			}
+			want := mathFuncs[name](x)
+			got, _ := f(NewFloat(x)).Float64()
+			if math.Abs(got-want) > 2*math.Abs(math.Nextafter(want, 0)-want) {
+				t.Errorf("%s(%v) = %v, expected %v", name, x, got, want)
+			}
+		}
+	}
+}
+
+// checkDirected checks that f is correctly rounded in the directed modes:
+// with the arguments x rounded to each of precs, f in each directed mode
+// must equal f of the same arguments at 100 more bits, rounded again to
+// the lower precision in that mode.
+func checkDirected(t *testing.T, name string, f func(x []*Float) *Float, x []float64, precs []uint) {
+	t.Helper()
+	modes := []big.RoundingMode{big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf}
+	for _, mode := range modes {
+		for _, prec := range precs {
+			low, high := make([]*Float, len(x)), make([]*Float, len(x))
+			for i, v := range x {
+				a := NewFloat(v).SetPrec(prec)
+				low[i], high[i] = a.SetMode(mode), a.SetPrec(prec+100).SetMode(mode)
+			}
+			got := f(low)
+			if want := rounded((*big.Float)(f(high)), prec, mode); got.Cmp(want) != 0 || got.Prec() != prec {
+				args := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(x)), ", "), "[]")
+				t.Errorf("%s(%s) at %d bits %v = %v, expected %v", name, args, prec, mode, got, want)
+			}
+		}
+	}
+}
+
+func TestElementaryDirected(t *testing.T) {
+	for name, f := range elementaryFuncs {
+		rnd := rand.New(rand.NewSource(2))
+		for i := 0; i < 20; i++ {
+			x := math.Ldexp(rnd.Float64(), rnd.Intn(8)-4)
+			switch name {
+			case "Asin", "Acos", "Atanh":
+				x = 2*rnd.Float64() - 1
+			case "Acosh":
+				x++
+			}
+			checkDirected(t, name, func(x []*Float) *Float { return f(x[0]) }, []float64{x}, []uint{2, 11, 64, 150})
+		}
+	}
+}
+
+func TestElementaryExact(t *testing.T) {
+	cases := []struct {
+		got  *Float
+		want float64
+	}{
+		{NewFloat(8).Log2(), 3},
+		{NewFloat(0.0625).Log2(), -4},
+		{NewFloat(1000).Log10(), 3},
+		{NewFloat(1).Log10(), 0},
+		{NewFloat(4).Pow(NewFloat(1.5)), 8},
+		{NewFloat(2).Pow(NewFloat(-3)), 0.125},
+		{NewFloat(-2).Pow(NewFloat(3)), -8},
+		{NewFloat(-2).Pow(NewFloat(-2)), 0.25},
+		{NewFloat(81).Pow(NewFloat(0.25)), 3},
+		{NewFloat(0.25).Pow(NewFloat(-0.5)), 2},
+		{NewFloat(-1).Pow(NewFloat(1e300)), 1},
+		{NewFloat(0).Exp(), 1},
+		{NewFloat(1).Acos(), 0},
+		{NewFloat(1).Acosh(), 0},
+	}
+	for i, c := range cases {
+		if f, acc := c.got.Float64(); f != c.want || c.got.Acc() != big.Exact || acc != big.Exact {
+			t.Errorf("case %d: got %v (%v), expected exactly %v", i, c.got, c.got.Acc(), c.want)
+		}
+	}
+	// 3**100 needs more than 64 bits, so it is rounded once
+	p := NewFloat(3).SetPrec(64).SetMode(big.ToZero).Pow(NewFloat(100))
+	want := new(big.Float).SetPrec(64).SetMode(big.ToZero).SetInt(new(big.Int).Exp(big.NewInt(3), big.NewInt(100), nil))
+	if p.Cmp((*Float)(want)) != 0 || p.Acc() != big.Below {
+		t.Errorf("3**100 = %v (%v), expected %v", p, p.Acc(), want)
+	}
+}
+
+func TestElementaryTiny(t *testing.T) {
+	// for tiny x, f(x) is just above or below x (or 1), which only the
+	// directed modes can see
+	x := NewFloat(1).SetExp(-10000)
+	below := new(big.Float).SetPrec(53).SetMantExp(big.NewFloat(1-0x1p-53), -10000)
+	cases := []struct {
+		name  string
+		f     func(*Float) *Float
+		c     *Float
+		above bool
+	}{
+		{"Sin", (*Float).Sin, x, false},
+		{"Tan", (*Float).Tan, x, true},
+		{"Asin", (*Float).Asin, x, true},
+		{"Atan", (*Float).Atan, x, false},
+		{"Sinh", (*Float).Sinh, x, true},
+		{"Tanh", (*Float).Tanh, x, false},
+		{"Asinh", (*Float).Asinh, x, false},
+		{"Atanh", (*Float).Atanh, x, true},
+		{"Expm1", (*Float).Expm1, x, true},
+		{"Log1p", (*Float).Log1p, x, false},
+		{"Exp", (*Float).Exp, NewFloat(1), true},
+		{"Cos", (*Float).Cos, NewFloat(1), false},
+		{"Cosh", (*Float).Cosh, NewFloat(1), true},
+	}
+	for _, c := range cases {
+		near := c.f(x)
+		up := c.f(x.SetMode(big.ToPositiveInf))
+		down := c.f(x.SetMode(big.ToNegativeInf))
+		if near.Cmp(c.c) != 0 {
+			t.Errorf("%s(2**-10000) = %v", c.name, near)
+		}
+		if c.above && (down.Cmp(c.c) != 0 || up.Cmp(c.c) <= 0) || !c.above && (up.Cmp(c.c) != 0 || down.Cmp(c.c) >= 0) {
+			t.Errorf("%s(2**-10000) rounds to %v and %v", c.name, down, up)
+		}
+	}
+	if got := x.SetMode(big.ToZero).Sin(); got.Cmp((*Float)(below)) != 0 {
+		t.Errorf("Sin(2**-10000) toward zero = %v, expected %v", got, below)
+	}
+	// tanh(100) is 1 to 288 bits
+	if got := NewFloat(100).SetPrec(200).SetMode(big.ToZero).Tanh(); got.CmpInt64(1) >= 0 {
+		t.Errorf("Tanh(100) toward zero = %v", got)
+	}
+	if got := NewFloat(-1e10).Expm1(); got.CmpInt64(-1) != 0 {
+		t.Errorf("Expm1(-1e10) = %v", got)
+	}
+}
+
+func TestElementarySpecial(t *testing.T) {
+	inf, negInf := Inf(false), Inf(true)
+	zero, negZero := NewFloat(0), NewFloat(0).Neg()
+	check := func(name string, got *Float, want float64) {
+		f, _ := got.Float64()
+		if f != want && !(math.IsNaN(want)) || math.Signbit(f) != math.Signbit(want) {
+			t.Errorf("%s = %v, expected %v", name, got, want)
+		}
+	}
+	check("Exp(+Inf)", inf.Exp(), math.Inf(1))
+	check("Exp(-Inf)", negInf.Exp(), 0)
+	check("Expm1(-Inf)", negInf.Expm1(), -1)
+	check("Expm1(-0)", negZero.Expm1(), math.Copysign(0, -1))
+	check("Log(+0)", zero.Log(), math.Inf(-1))
+	check("Log(-0)", negZero.Log(), math.Inf(-1))
+	check("Log(+Inf)", inf.Log(), math.Inf(1))
+	check("Log1p(-1)", NewFloat(-1).Log1p(), math.Inf(-1))
+	check("Log1p(-0)", negZero.Log1p(), math.Copysign(0, -1))
+	check("Sin(-0)", negZero.Sin(), math.Copysign(0, -1))
+	check("Tan(-0)", negZero.Tan(), math.Copysign(0, -1))
+	check("Cos(-0)", negZero.Cos(), 1)
+	check("Atan(-Inf)", negInf.Atan(), -math.Pi/2)
+	check("Asin(-1)", NewFloat(-1).Asin(), -math.Pi/2)
+	check("Acos(-1)", NewFloat(-1).Acos(), math.Pi)
+	check("Sinh(-Inf)", negInf.Sinh(), math.Inf(-1))
+	check("Cosh(-Inf)", negInf.Cosh(), math.Inf(1))
+	check("Tanh(-Inf)", negInf.Tanh(), -1)
+	check("Asinh(-Inf)", negInf.Asinh(), math.Inf(-1))
+	check("Acosh(+Inf)", inf.Acosh(), math.Inf(1))
+	check("Atanh(-1)", NewFloat(-1).Atanh(), math.Inf(-1))
+	check("Atanh(-0)", negZero.Atanh(), math.Copysign(0, -1))
+	check("Exp(1e20)", NewFloat(1e20).Exp(), math.Inf(1))
+	check("Exp(-1e20)", NewFloat(-1e20).Exp(), 0)
+
+	pows := []struct{ x, y float64 }{
+		{2, 0}, {math.Inf(-1), 0}, {1, math.Inf(1)}, {0, -3}, {math.Copysign(0, -1), -3},
+		{math.Copysign(0, -1), -2}, {math.Copysign(0, -1), 3}, {math.Copysign(0, -1), 2},
+		{-1, math.Inf(1)}, {0.5, math.Inf(1)}, {0.5, math.Inf(-1)}, {2, math.Inf(-1)},
+		{math.Inf(1), -1}, {math.Inf(1), 0.5}, {math.Inf(-1), 3}, {math.Inf(-1), -3},
+		{math.Inf(-1), 2}, {2, 1e10}, {2, -1e10}, {-2, 1e10 + 1}, {0.5, 1e300},
+	}
+	for _, p := range pows {
+		check("Pow", NewFloat(p.x).Pow(NewFloat(p.y)), math.Pow(p.x, p.y))
+	}
+	atan2s := []struct{ y, x float64 }{
+		{0, 1}, {math.Copysign(0, -1), 1}, {0, -1}, {math.Copysign(0, -1), math.Copysign(0, -1)},
+		{1, 0}, {-1, 0}, {math.Inf(1), math.Inf(1)}, {math.Inf(-1), math.Inf(-1)},
+		{1, math.Inf(1)}, {-1, math.Inf(1)}, {1, math.Inf(-1)}, {math.Inf(-1), 5},
+	}
+	for _, a := range atan2s {
+		check("Atan2", NewFloat(a.y).Atan2(NewFloat(a.x)), math.Atan2(a.y, a.x))
+	}
+
+	nans := map[string]func(){
+		"Log(-1)":       func() { NewFloat(-1).Log() },
+		"Log1p(-2)":     func() { NewFloat(-2).Log1p() },
+		"Sin(Inf)":      func() { inf.Sin() },
+		"Cos(-Inf)":     func() { negInf.Cos() },
+		"Asin(2)":       func() { NewFloat(2).Asin() },
+		"Acosh(0.5)":    func() { NewFloat(0.5).Acosh() },
+		"Atanh(-2)":     func() { NewFloat(-2).Atanh() },
+		"Pow(-2, 0.5)":  func() { NewFloat(-2).Pow(NewFloat(0.5)) },
+		"Pow(-1, -0.5)": func() { NewFloat(-1).Pow(NewFloat(-0.5)) },
+	}
+	for name, f := range nans {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func BenchmarkFloatExp256(b *testing.B) {
+	x, _, _ := ParseFloat("2.718281828", 10, 256, big.ToNearestEven)
+	for i := 0; i < b.N; i++ {
+		x.Exp()
+	}
+}
+
+func BenchmarkFloatLog256(b *testing.B) {
+	x, _, _ := ParseFloat("2.718281828", 10, 256, big.ToNearestEven)
+	for i := 0; i < b.N; i++ {
+		x.Log()
+	}
+}
+
+func BenchmarkFloatSin256(b *testing.B) {
+	x, _, _ := ParseFloat("2.718281828", 10, 256, big.ToNearestEven)
+	for i := 0; i < b.N; i++ {
+		x.Sin()
+	}
+}
@@ -1,44 +1,94 @@
 package mathx
 
-import "math"
+import "math/big"
 
-// Sqrt returns the square root of this number, computing using Newton's method.
+// Sqrt returns the square root of this number, correctly rounded to its
+// precision and rounding mode (or to 64 bits if it has precision 0), with
+// the accuracy of the result reported by Acc. Sqrt(±0) = ±0 and Sqrt(+Inf)
+// = +Inf, and Sqrt panics with ErrNaN if this is negative.
 func (z *Float) Sqrt() *Float {
-	if z.Sign() == 0 {
-		return z.copy()
-	}
-	if z.Sign() < 0 {
-		panic("square root of a negative number is undefined")
-	}
-	if z.IsInf() {
-		return z
-	}
+	return z.NthRoot(2)
+}
 
-	number := z.SetPrec(z.Prec() + 53) // this will make sure that the loop has higher accuracy
-	_, numberExp := number.MantExp()
+// Cbrt returns the cube root of this number, rounded as for Sqrt. Cbrt(±0)
+// = ±0 and Cbrt(±Inf) = ±Inf.
+func (z *Float) Cbrt() *Float {
+	return z.NthRoot(3)
+}
 
-	accuracy := NewFloat(1.0).SetPrec(number.Prec())
-	accuracy = accuracy.SetExp(numberExp - int(number.Prec()))
+// NthRoot returns the real k-th root of this number, rounded as for Sqrt.
+// For odd k, negative numbers have negative roots; for even k, NthRoot
+// panics with ErrNaN if this is less than zero. NthRoot(±0) = ±0 and
+// NthRoot(±Inf) = ±Inf where the sign allows. NthRoot also panics with
+// ErrNaN if k < 1.
+func (z *Float) NthRoot(k int) *Float {
+	x := (*big.Float)(z)
+	switch {
+	case k < 1 || k&1 == 0 && x.Sign() < 0:
+		panic(ErrNaN)
+	case x.Sign() == 0 || x.IsInf():
+		return z.same()
+	}
+	return root(x, k, 0, z.precision(), z.Mode())
+}
 
-	// start with a good guess
-	zf, _ := z.Float64()
-	var x *Float
-	if math.IsInf(zf, 0) || math.IsNaN(zf) {
-		x = NewFloat(1.0).SetPrec(number.Prec())
+// Hypot returns sqrt(z**2 + y**2), rounded to the precision and mode of z
+// as for Sqrt, without overflow or underflow in the squares. Hypot(±Inf,
+// y) = Hypot(z, ±Inf) = +Inf.
+func (z *Float) Hypot(y *Float) *Float {
+	xb, yb := (*big.Float)(z), (*big.Float)(y)
+	switch {
+	case xb.IsInf() || yb.IsInf():
+		return z.inf(false)
+	case yb.Sign() == 0:
+		return z.exactResult(new(big.Float).Abs(xb))
+	case xb.Sign() == 0:
+		return z.exactResult(new(big.Float).Abs(yb))
+	}
+	// scale both into [1/2, 1) or below, so that the squares are exact and
+	// in range
+	e := maxExp(expo(xb), expo(yb))
+	a := new(big.Float).SetMantExp(xb, -e)
+	b := new(big.Float).SetMantExp(yb, -e)
+	a.SetPrec(2*a.Prec()).Mul(a, a)
+	b.SetPrec(2*b.Prec()).Mul(b, b)
+	return root(exactAdd(a, b), 2, e, z.precision(), z.Mode())
+}
+
+// root returns the k-th root of x times 2**shift, correctly rounded, for
+// finite nonzero x, and x > 0 if k is even.
+func root(x *big.Float, k, shift int, prec uint, mode big.RoundingMode) *Float {
+	// |x| = m 2**l, so the root is that of n = m 2**s, which has enough
+	// bits for prec+1 in its root, times 2**((l-s)/k)
+	l := lsb(x)
+	n := intPart(x, l)
+	s := k*(int(prec)+3) - n.BitLen()
+	if s < 0 {
+		s = 0
+	}
+	if d := (l - s) % k; d != 0 {
+		if d < 0 {
+			d += k
+		}
+		s += d
+	}
+	n.Lsh(n, uint(s))
+	var r *big.Int
+	if k == 2 {
+		r = new(big.Int).Sqrt(n)
 	} else {
-		x = NewFloat(math.Sqrt(zf)).SetPrec(number.Prec())
+		r = rootFloor(n, k)
+	}
+	e := (l-s)/k + shift
+	// if the root is inexact, it is strictly between r and r+1, and r+1/2
+	// rounds the same way, as it has at least prec+2 bits
+	if new(big.Int).Exp(r, big.NewInt(int64(k)), nil).Cmp(n) != 0 {
+		r.Lsh(r, 1).Add(r, bigOne)
+		e--
 	}
-	two := NewFloat(2.0).SetPrec(number.Prec())
-	denominator := NewFloat(1.0).SetPrec(number.Prec())
-	delta := x.Mul(x).Sub(number).Abs()
-	for delta.Cmp(accuracy) == 1 { // if the difference between the correct answer and the current guess is larger than the required accuracy, repeat
-		prez := x
-		denominator = two.Mul(prez)
-		x = prez.Mul(prez)
-		x = x.Sub(number)
-		x = x.Quo(denominator)
-		x = prez.Sub(x)
-		delta = x.Mul(x).Sub(number).Abs()
+	if x.Sign() < 0 {
+		r.Neg(r)
 	}
-	return x
+	t := new(big.Float).SetInt(r)
+	return rounded(t.SetMantExp(t, e), prec, mode)
 }
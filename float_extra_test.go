@@ -2,6 +2,7 @@ package mathx
 
 import (
 	"fmt"
+	"math"
 	"math/big"
 	"testing"
 )
@@ -61,3 +62,181 @@ func TestFloatSqrt(t *testing.T) {
 		}
 	}
 }
+
+// checkRounded reports whether got is the correct rounding of a value v in
+// the mode, with the right accuracy, given cmp(y), the sign of y - v for
+// rationals y.
+func checkRounded(got *Float, mode big.RoundingMode, cmp func(y *big.Rat) int) bool {
+	rat := func(f *Float) *big.Rat {
+		r, _ := f.Rat(nil)
+		return r
+	}
+	c := cmp(rat(got))
+	if c == 0 {
+		return got.Acc() == big.Exact
+	}
+	// the neighbor on the other side of v
+	nb := got.NextUp()
+	if c > 0 {
+		nb = got.NextDown()
+	}
+	if nb.IsInf() || cmp(rat(nb)) != -c {
+		return false
+	}
+	below := c < 0
+	if below && got.Acc() != big.Below || !below && got.Acc() != big.Above {
+		return false
+	}
+	neg := got.Sign() < 0
+	switch mode {
+	case big.ToNearestEven, big.ToNearestAway:
+		mid := new(big.Rat).Add(rat(got), rat(nb))
+		switch cmp(mid.Quo(mid, big.NewRat(2, 1))) {
+		case -c:
+			return true
+		case 0:
+			// a tie, where a single bit is neither even nor odd
+			if mode == big.ToNearestAway {
+				return below == neg
+			}
+			return got.Prec() == 1 || got.MinPrec() < got.Prec()
+		}
+		return false
+	case big.ToZero:
+		return below != neg
+	case big.AwayFromZero:
+		return below == neg
+	case big.ToNegativeInf:
+		return below
+	}
+	return !below
+}
+
+// smallFloats returns every Float with prec bits and an exponent in
+// [-3, 3], with both signs if signed is set.
+func smallFloats(prec uint, signed bool) []*big.Float {
+	var r []*big.Float
+	for m := int64(1) << (prec - 1); m < 1<<prec; m++ {
+		for e := -3; e <= 3; e++ {
+			x := new(big.Float).SetPrec(prec).SetInt64(m)
+			x.SetMantExp(x, e-int(prec))
+			r = append(r, x)
+			if signed {
+				r = append(r, new(big.Float).Neg(x))
+			}
+		}
+	}
+	return r
+}
+
+var allModes = []big.RoundingMode{big.ToNearestEven, big.ToNearestAway, big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf}
+
+func TestFloatRootExhaustive(t *testing.T) {
+	for k := 1; k <= 5; k++ {
+		for prec := uint(1); prec <= 6; prec++ {
+			for _, xb := range smallFloats(prec, k&1 == 1) {
+				x, _ := xb.Rat(nil)
+				// y**k - x has the sign of y - x**(1/k)
+				cmp := func(y *big.Rat) int {
+					p := big.NewRat(1, 1)
+					for i := 0; i < k; i++ {
+						p.Mul(p, y)
+					}
+					return p.Cmp(x)
+				}
+				for _, mode := range allModes {
+					for _, rprec := range []uint{prec, prec + 3} {
+						z := (*Float)(new(big.Float).SetPrec(rprec).SetMode(mode).Set(xb))
+						f := z.NthRoot(k)
+						if k == 2 {
+							f = z.Sqrt()
+						} else if k == 3 {
+							f = z.Cbrt()
+						}
+						if f.Prec() != rprec || f.Mode() != mode || !checkRounded(f, mode, cmp) {
+							t.Errorf("NthRoot(%v, %d) at %d bits %v = %v (%v)", xb, k, rprec, mode, f, f.Acc())
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFloatHypotExhaustive(t *testing.T) {
+	for prec := uint(1); prec <= 4; prec++ {
+		xs := smallFloats(prec, true)
+		for _, xb := range xs {
+			for _, yb := range xs {
+				x, _ := xb.Rat(nil)
+				y, _ := yb.Rat(nil)
+				s := new(big.Rat).Add(new(big.Rat).Mul(x, x), new(big.Rat).Mul(y, y))
+				cmp := func(h *big.Rat) int {
+					return new(big.Rat).Mul(h, h).Cmp(s)
+				}
+				for _, mode := range allModes {
+					z := (*Float)(new(big.Float).SetPrec(prec).SetMode(mode).Set(xb))
+					f := z.Hypot((*Float)(yb))
+					if f.Prec() != prec || !checkRounded(f, mode, cmp) {
+						t.Errorf("Hypot(%v, %v) at %d bits %v = %v (%v)", xb, yb, prec, mode, f, f.Acc())
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFloatRootSpecial(t *testing.T) {
+	inf, negInf := Inf(false), Inf(true)
+	negZero := NewFloat(0).Neg()
+	check := func(name string, got *Float, want float64) {
+		f, _ := got.Float64()
+		if f != want || math.Signbit(f) != math.Signbit(want) {
+			t.Errorf("%s = %v, expected %v", name, got, want)
+		}
+	}
+	check("Sqrt(-0)", negZero.Sqrt(), math.Copysign(0, -1))
+	check("Sqrt(+Inf)", inf.Sqrt(), math.Inf(1))
+	check("Cbrt(-Inf)", negInf.Cbrt(), math.Inf(-1))
+	check("Cbrt(-27)", NewFloat(-27).Cbrt(), -3)
+	check("NthRoot(-0, 4)", negZero.NthRoot(4), math.Copysign(0, -1))
+	check("NthRoot(2**-1000, 5)", NewFloat(1).SetExp(-1000).NthRoot(5), math.Ldexp(1, -200))
+	check("Hypot(-Inf, 0)", negInf.Hypot(NewFloat(0)), math.Inf(1))
+	check("Hypot(3, -4)", NewFloat(3).Hypot(NewFloat(-4)), 5)
+	check("Hypot(-0, -0)", negZero.Hypot(negZero), 0)
+	check("Hypot(0, -2)", NewFloat(0).SetPrec(53).Hypot(NewFloat(-2)), 2)
+
+	// far outside the float64 range
+	big3 := NewFloat(3).SetExp(1 << 29)
+	h := big3.Hypot(NewFloat(4).SetExp(1 << 29))
+	if want := NewFloat(5).SetExp(1 << 29); h.Cmp(want) != 0 || h.Acc() != big.Exact {
+		t.Errorf("Hypot(3 2**(2**29), 4 2**(2**29)) = %v (%v)", h, h.Acc())
+	}
+	for _, f := range []float64{2, 1e-300, 12345.678} {
+		want := math.Sqrt(f)
+		if got, _ := NewFloat(f).Sqrt().Float64(); got != want {
+			t.Errorf("Sqrt(%v) = %v, expected %v", f, got, want)
+		}
+		want = math.Cbrt(f)
+		if got, _ := NewFloat(f).Cbrt().Float64(); math.Abs(got-want) > math.Abs(math.Nextafter(want, 0)-want) {
+			t.Errorf("Cbrt(%v) = %v, expected %v", f, got, want)
+		}
+	}
+
+	nans := map[string]func(){
+		"Sqrt(-1)":       func() { NewFloat(-1).Sqrt() },
+		"Sqrt(-Inf)":     func() { negInf.Sqrt() },
+		"NthRoot(-8, 4)": func() { NewFloat(-8).NthRoot(4) },
+		"NthRoot(8, 0)":  func() { NewFloat(8).NthRoot(0) },
+	}
+	for name, f := range nans {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,813 @@
+package mathx
+
+// This file is for the generalized hypergeometric function pFq on Float.
+// The series is summed either in floating point, with a term recurrence,
+// or exactly, by binary splitting, when the parameters are rationals with
+// small numerators and denominators. Either way the rest of the series is
+// bounded rigorously: once k > |b_j| for all j, the ratio of consecutive
+// terms is at most
+//
+//	R(k) = |z| k**(p-q-1) (1 + |a_1|/k) ... (1 + |a_p|/k) / ((1 - |b_1|/k) ... (1 - |b_q|/k))
+//
+// for every later term too, so if R(k) <= ρ < 1 the terms from k on sum to
+// at most |t(k)| / (1 - ρ).
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// hyperSeries is the series sum over k of t(k), with t(0) = 1 and
+//
+//	t(k+1) / t(k) = (a_1+k) ... (a_p+k) / ((b_1+k) ... (b_q+k)) z/(k+1).
+type hyperSeries struct {
+	a, b []*big.Float
+	z    *big.Float
+}
+
+// ratioBound returns R(k) from the comment above, or +Inf if k <= |b_j|
+// for some j. It is computed in float64 with a margin for rounding.
+func (h *hyperSeries) ratioBound(k float64) float64 {
+	r := math.Abs(float64Of(h.z)) * math.Pow(k, float64(len(h.a)-len(h.b)-1))
+	for _, a := range h.a {
+		r *= 1 + math.Abs(float64Of(a))/k
+	}
+	for _, b := range h.b {
+		d := 1 - math.Abs(float64Of(b))/k
+		if d <= 0 {
+			return math.Inf(1)
+		}
+		r /= d
+	}
+	return r * (1 + 1e-9)
+}
+
+// maxTerms and maxCost limit the work of summing a series: the terms up
+// to k0, and the terms summed times the working precision in bits. A
+// series beyond them, as for a huge |z| or parameter, or terms that cancel
+// to many thousands of bits, panics with ErrOverflow.
+const (
+	maxTerms = 1 << 20
+	maxCost  = 1 << 27
+)
+
+// tailStart returns k0 and ρ < 1 with R(k0) <= ρ, and the number of bits
+// the rest of the series can add to its first term, log2(1/(1-ρ)). The
+// series must converge: p <= q, or p = q+1 and |z| < 1. It panics with
+// ErrOverflow if k0 would be more than maxTerms.
+func (h *hyperSeries) tailStart() (int64, uint) {
+	// close to 1, so that k0 is not much past the largest term when |z|
+	// is large, which costs a few bits of the bound on the rest
+	rho := 15.0 / 16
+	if len(h.a) > len(h.b) {
+		rho = (1 + math.Abs(float64Of(h.z))) / 2
+	}
+	hi := int64(1)
+	// a bound of +Inf or NaN, from a value beyond float64, never holds
+	for !(h.ratioBound(float64(hi)) <= rho) {
+		if hi >= maxTerms {
+			panic(ErrOverflow)
+		}
+		hi *= 2
+	}
+	lo := hi / 2
+	for lo+1 < hi {
+		m := (lo + hi) / 2
+		if h.ratioBound(float64(m)) > rho {
+			lo = m
+		} else {
+			hi = m
+		}
+	}
+	return hi, uint(math.Ceil(-math.Log2(1-rho))) + 1
+}
+
+// terminates returns n if the series is a polynomial with terms up to
+// t(n), because a_i = -n for the least such n >= 0.
+func (h *hyperSeries) terminates() (int64, bool) {
+	n, ok := int64(0), false
+	for _, a := range h.a {
+		if m, isInt := smallInt(a); isInt && m <= 0 && (!ok || -m < n) {
+			n, ok = -m, true
+		}
+	}
+	return n, ok
+}
+
+// hasPole returns whether some term of the series divides by zero: b_j is
+// -m for an integer m >= 0 that the terms reach.
+func (h *hyperSeries) hasPole() bool {
+	n, ok := h.terminates()
+	for _, b := range h.b {
+		if m, isInt := smallInt(b); isInt && m <= 0 && (!ok || -m < n) {
+			return true
+		} else if !isInt && isNonPositiveInt(b) && !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// polynomial returns the sum of the terms up to t(n) exactly.
+func (h *hyperSeries) polynomial(n int64) *big.Rat {
+	rats := func(xs []*big.Float) []*big.Rat {
+		r := make([]*big.Rat, len(xs))
+		for i, x := range xs {
+			r[i], _ = x.Rat(nil)
+		}
+		return r
+	}
+	a, b := rats(h.a), rats(h.b)
+	z, _ := h.z.Rat(nil)
+	return ratPolynomial(a, b, z, n)
+}
+
+// ratPolynomial returns the sum of the terms up to t(n) of a series with
+// rational parameters.
+func ratPolynomial(a, b []*big.Rat, z *big.Rat, n int64) *big.Rat {
+	t := big.NewRat(1, 1)
+	sum := big.NewRat(1, 1)
+	u := new(big.Rat)
+	for k := int64(0); k < n; k++ {
+		kr := big.NewRat(k, 1)
+		for _, ai := range a {
+			t.Mul(t, u.Add(ai, kr))
+		}
+		for _, bj := range b {
+			t.Quo(t, u.Add(bj, kr))
+		}
+		t.Mul(t, z)
+		t.Quo(t, big.NewRat(k+1, 1))
+		sum.Add(sum, t)
+	}
+	return sum
+}
+
+// integerForm returns P and Q with t(k+1)/t(k) = P(k)/Q(k) for integer
+// polynomials P and Q, if the parameters and z are rationals small enough
+// for binary splitting to pay off.
+func (h *hyperSeries) integerForm() (func(m int64) *big.Int, func(m int64) *big.Int, bool) {
+	// a_i = α_i / 2**e with a common e, and z = ζ 2**s
+	e := 0
+	for _, x := range append(append([]*big.Float{}, h.a...), h.b...) {
+		if x.Sign() == 0 {
+			continue
+		}
+		if x.MinPrec() > 64 || expo(x) > 64 {
+			return nil, nil, false
+		}
+		if l := -lsb(x); l > e {
+			e = l
+		}
+	}
+	if e > 64 || h.z.MinPrec() > 64 {
+		return nil, nil, false
+	}
+	ints := func(xs []*big.Float) []*big.Int {
+		r := make([]*big.Int, len(xs))
+		for i, x := range xs {
+			r[i], _ = new(big.Float).SetMantExp(x, e).Int(nil)
+		}
+		return r
+	}
+	alpha, beta := ints(h.a), ints(h.b)
+	s := lsb(h.z)
+	zeta, _ := new(big.Float).SetMantExp(h.z, -s).Int(nil)
+	// the ratio is Π(α_i + mD) ζ 2**s D**(q-p) / (Π(β_j + mD) (m+1)),
+	// with D = 2**e, and the power of two goes to whichever side it fits
+	g := s + e*(len(h.b)-len(h.a))
+	d := new(big.Int).Lsh(bigOne, uint(e))
+	prod := func(xs []*big.Int, m int64) *big.Int {
+		r := big.NewInt(1)
+		md := new(big.Int).Mul(d, big.NewInt(m))
+		t := new(big.Int)
+		for _, x := range xs {
+			r.Mul(r, t.Add(x, md))
+		}
+		return r
+	}
+	p := func(m int64) *big.Int {
+		r := prod(alpha, m)
+		r.Mul(r, zeta)
+		if g > 0 {
+			r.Lsh(r, uint(g))
+		}
+		return r
+	}
+	q := func(m int64) *big.Int {
+		r := prod(beta, m)
+		r.Mul(r, big.NewInt(m+1))
+		if g < 0 {
+			r.Lsh(r, uint(-g))
+		}
+		return r
+	}
+	return p, q, true
+}
+
+// approx returns the sum of a convergent series that does not terminate
+// to about w bits, an exponent e with the error below 2**e, and the size
+// of the largest term, which shows how much the sum cancels.
+func (h *hyperSeries) approx(w uint) (*big.Float, int, *big.Float) {
+	k0, tb := h.tailStart()
+	if uint64(k0)*uint64(w) > maxCost {
+		panic(ErrOverflow)
+	}
+	// splitting pays off when the products of the term ratios are not
+	// much longer than w bits; for many terms at a low precision, as for a
+	// large |z|, summing in floating point is faster
+	if p, q, ok := h.integerForm(); ok && k0 <= int64(w) {
+		return h.split(w, k0, tb, p, q)
+	}
+	// each term has a relative rounding error of about c k 2**-wp, where c
+	// counts the operations per term (and the rounding of z, for the
+	// callers that round it)
+	c := int64(2*(len(h.a)+len(h.b)) + 6)
+	wp := w + 16
+	t := newFloat(wp).SetInt64(1)
+	sum := newFloat(wp).SetInt64(1)
+	abs := newFloat(64).SetInt64(1)
+	largest := newFloat(64).SetInt64(1)
+	u := newFloat(wp)
+	for k := int64(0); ; k++ {
+		if uint64(k)*uint64(wp) > maxCost {
+			panic(ErrOverflow)
+		}
+		kf := new(big.Float).SetInt64(k)
+		for _, a := range h.a {
+			t.Mul(t, u.Add(a, kf))
+		}
+		for _, b := range h.b {
+			t.Quo(t, u.Add(b, kf))
+		}
+		t.Mul(t, h.z)
+		t.Quo(t, new(big.Float).SetInt64(k+1))
+		// t is now t(k+1), and the rest from it on is at most 2**tb |t|
+		if k+1 >= k0 && (t.Sign() == 0 || expo(t)+int(tb) < expo(largest)-int(w)-4) {
+			e := expo(abs) + bits.Len64(uint64(c*(k+2))) + 1 - int(wp)
+			if t.Sign() != 0 {
+				e = maxExp(e, expo(t)+int(tb))
+			}
+			return sum, e + 1, largest
+		}
+		sum.Add(sum, t)
+		a := new(big.Float).Abs(t)
+		abs.Add(abs, a)
+		if a.Cmp(largest) > 0 {
+			largest = a.SetPrec(64)
+		}
+	}
+}
+
+// split is approx by binary splitting, with t(k+1)/t(k) = p(k)/q(k).
+func (h *hyperSeries) split(w uint, k0 int64, tb uint, p, q func(m int64) *big.Int) (*big.Float, int, *big.Float) {
+	// estimate log2 |t(k)| in float64 to find how many terms are needed
+	lt, largest := 0.0, 0.0
+	n := int64(1)
+	for ; ; n++ {
+		if uint64(n)*uint64(w) > maxCost {
+			panic(ErrOverflow)
+		}
+		r := new(big.Float).SetInt(p(n - 1))
+		r.Quo(r, new(big.Float).SetInt(q(n-1)))
+		if r.Sign() == 0 {
+			break
+		}
+		lt += float64(expo(r)) + math.Log2(math.Abs(float64Of(new(big.Float).SetMantExp(r, -expo(r)))))
+		if lt > largest {
+			largest = lt
+		}
+		if n > k0 && lt+float64(tb) < largest-float64(w)-8 {
+			break
+		}
+	}
+	// the sum of t(0) ... t(n-1), and t(n-1) = P/Q from the products
+	pp, qq, bb, tt := split(func(k int64) (a, b, pk, qk *big.Int) {
+		if k == 0 {
+			return big.NewInt(1), big.NewInt(1), big.NewInt(1), big.NewInt(1)
+		}
+		return big.NewInt(1), big.NewInt(1), p(k - 1), q(k - 1)
+	}, 0, n)
+	d := newFloat(w + 8).SetInt(bb.Mul(bb, qq))
+	sum := newFloat(w+8).Quo(newFloat(w+8).SetInt(tt), d)
+	e := -int(w) - 8
+	if sum.Sign() != 0 {
+		e += expo(sum)
+	}
+	// the rest after t(n-1) is at most 2**tb |t(n)| <= 2**tb |t(n-1)|,
+	// as n-1 >= k0
+	last := newFloat(64).Quo(newFloat(64).SetInt(pp), newFloat(64).SetInt(qq))
+	if last.Sign() != 0 {
+		e = maxExp(e, expo(last)+int(tb)+1)
+	}
+	return sum, e + 1, pow2(int(largest) + 1)
+}
+
+// hyperZiv returns the sum of a convergent series that does not terminate,
+// correctly rounded, given approx, which works as hyperSeries.approx does.
+// The working precision grows by the cancellation seen so far, and at
+// least doubles while the sum is lost in its error, so that a sum which
+// cancels too much reaches maxCost in a few steps.
+func hyperZiv(prec uint, mode big.RoundingMode, approx func(w uint) (*big.Float, int, *big.Float)) *Float {
+	extra := 0
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		y, e, largest := approx(w + uint(extra))
+		d := int(w) + 2*extra
+		if y.Sign() != 0 && e < expo(y)-1 {
+			d = 0
+			if largest.Sign() != 0 {
+				d = expo(largest) - expo(y)
+			}
+		}
+		if d > extra {
+			extra = d
+		}
+		return y, e
+	})
+}
+
+// Hypergeometric returns the generalized hypergeometric function
+//
+//	pFq(a; b; z) = sum over k >= 0 of (a_1)k ... (a_p)k / ((b_1)k ... (b_q)k) z**k / k!,
+//
+// where (x)k = x (x+1) ... (x+k-1), correctly rounded to prec bits (or 64
+// if prec is 0) in the rounding mode of z. Parameters that appear in both
+// a and b cancel. The series is summed exactly when it terminates, because
+// some a_i is 0 or a negative integer, and otherwise it must converge:
+// p <= q, or p = q+1 and |z| < 1. For 2F1, the result is continued
+// analytically to real z <= -1 and to z = 1, where it is Gauss's product
+// of Gamma functions if c - a - b > 0, and 1F0(a; ; z) = (1-z)**-a for all
+// z < 1. Hypergeometric panics with ErrNaN if a parameter or z is
+// infinite, if some b_j is a pole that the terms reach, and where the
+// series diverges or the result is not real, and with ErrOverflow if the
+// series needs too many terms or too much precision, as for a huge |z|.
+func Hypergeometric(a, b []*Float, z *Float, prec uint) *Float {
+	if prec == 0 {
+		prec = 64
+	}
+	mode := z.Mode()
+	h := &hyperSeries{z: (*big.Float)(z)}
+	for _, x := range a {
+		h.a = append(h.a, (*big.Float)(x))
+	}
+	// cancel equal parameters, except poles, which matter for where a
+	// polynomial stops
+	for _, x := range b {
+		xb := (*big.Float)(x)
+		found := false
+		if !isNonPositiveInt(xb) {
+			for i, y := range h.a {
+				if y.Cmp(xb) == 0 {
+					h.a = append(h.a[:i:i], h.a[i+1:]...)
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			h.b = append(h.b, xb)
+		}
+	}
+	for _, x := range append(append([]*big.Float{h.z}, h.a...), h.b...) {
+		if x.IsInf() {
+			panic(ErrNaN)
+		}
+	}
+	if h.hasPole() {
+		panic(ErrNaN)
+	}
+	if h.z.Sign() == 0 {
+		return rounded(floatOne, prec, mode)
+	}
+	if n, ok := h.terminates(); ok {
+		return (*Float)(newFloat(prec).SetMode(mode).SetRat(h.polynomial(n)))
+	}
+	p, q := len(h.a), len(h.b)
+	switch {
+	case p == 0 && q == 0:
+		return (&FloatContext{Prec: prec, Mode: mode}).Exp(z)
+	case p == 1 && q == 1 && h.z.Sign() < 0:
+		return kummer(h.a[0], h.b[0], h.z, prec, mode)
+	case p == 1 && q == 0:
+		if h.z.Cmp(floatOne) >= 0 {
+			panic(ErrNaN)
+		}
+		s := exactAdd(floatOne, new(big.Float).Neg(h.z))
+		return powTimes(big.NewRat(1, 1), s, new(big.Float).Neg(h.a[0]), prec, mode)
+	case p == 2 && q == 1:
+		return hyp2f1(h.a[0], h.a[1], h.b[0], h.z, prec, mode)
+	case p > q+1 || p == q+1 && cmpAbs(h.z, floatOne) >= 0:
+		panic(ErrNaN)
+	}
+	return hyperZiv(prec, mode, h.approx)
+}
+
+// kummer returns 1F1(a; b; z) for z < 0 by Kummer's transformation,
+// 1F1(a; b; z) = e**z 1F1(b-a; b; -z), whose terms have one sign when
+// b > a > 0, rather than summing terms that cancel. The series for 1F1
+// must not terminate, and b must not be a pole.
+func kummer(a, b, z *big.Float, prec uint, mode big.RoundingMode) *Float {
+	h := &hyperSeries{
+		a: []*big.Float{exactAdd(b, new(big.Float).Neg(a))},
+		b: []*big.Float{b},
+		z: new(big.Float).Neg(z),
+	}
+	if n, ok := h.terminates(); ok {
+		r := h.polynomial(n)
+		if expo(z) > 32 {
+			// e**z is below the exponent range of big.Float
+			return underflow(prec, mode, r.Sign() < 0)
+		}
+		return zivRel(prec, mode, func(w uint) *big.Float {
+			return newFloat(w).Mul(expKernel(z, w+4), newFloat(w+4).SetRat(r))
+		})
+	}
+	return hyperZiv(prec, mode, func(w uint) (*big.Float, int, *big.Float) {
+		// the series panics first if |z| is too large for expKernel
+		f, ef, largest := h.approx(w)
+		wp := w + 8
+		k := expKernel(z, wp)
+		y := newFloat(wp).Mul(k, f)
+		return y, combineErrors(y, k, ef, wp), newFloat(64).Mul(k, largest)
+	})
+}
+
+// powTimes returns r x**y correctly rounded, for exact x > 0.
+func powTimes(r *big.Rat, x, y *big.Float, prec uint, mode big.RoundingMode) *Float {
+	if r.Sign() == 0 {
+		return rounded(new(big.Float), prec, mode)
+	}
+	// x**y is rational only if Pow finds it exactly
+	base := func(w uint) *Float {
+		if m := x.MinPrec(); m > w {
+			w = m
+		}
+		return (*Float)(newFloat(w).Set(x))
+	}
+	if p := base(prec + 64).Pow((*Float)(y)); p.Acc() == big.Exact {
+		pr, _ := (*big.Float)(p).Rat(nil)
+		return (*Float)(newFloat(prec).SetMode(mode).SetRat(pr.Mul(pr, r)))
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		p := base(w + 4).Pow((*Float)(y))
+		return newFloat(w).Mul((*big.Float)(p), newFloat(w+4).SetRat(r))
+	})
+}
+
+// gammaHalf returns Gamma(x) = r sqrt(pi)**h, with h 0 or 1, for x an
+// integer or half an odd integer of moderate size, or false otherwise. At
+// the poles, r is nil.
+func gammaHalf(x *big.Float) (*big.Rat, int, bool) {
+	x2 := new(big.Float).SetMantExp(x, 1)
+	n2, ok := smallInt(x2)
+	if !ok || n2 > 1<<13 || n2 < -(1<<13) {
+		return nil, 0, false
+	}
+	if n2%2 == 0 {
+		n := n2 / 2
+		if n <= 0 {
+			return nil, 0, true
+		}
+		return new(big.Rat).SetInt(new(big.Int).MulRange(1, n-1)), 0, true
+	}
+	// Gamma(n + 1/2) = (2n)! / (4**n n!) sqrt(pi), and
+	// Gamma(1/2 - n) = (-4)**n n! / (2n)! sqrt(pi)
+	n, m := (n2-1)/2, (n2-1)/2
+	if n2 < 0 {
+		m = (1 - n2) / 2
+	}
+	f2 := new(big.Int).MulRange(1, 2*m)
+	f := new(big.Int).MulRange(1, m)
+	f.Lsh(f, uint(2*m))
+	if n >= 0 {
+		return new(big.Rat).SetFrac(f2, f), 1, true
+	}
+	r := new(big.Rat).SetFrac(f, f2)
+	if m%2 == 1 {
+		r.Neg(r)
+	}
+	return r, 1, true
+}
+
+// gammaRatio returns Gamma(x1) Gamma(x2) / (Gamma(y1) Gamma(y2)), where x1
+// and x2 are not poles, correctly rounded.
+func gammaRatio(x1, x2, y1, y2 *big.Float, prec uint, mode big.RoundingMode) *Float {
+	r, h := big.NewRat(1, 1), 0
+	exact := true
+	for i, x := range []*big.Float{x1, x2, y1, y2} {
+		g, hx, ok := gammaHalf(x)
+		if !ok {
+			exact = false
+			break
+		}
+		if g == nil {
+			// 1/Gamma is zero at a pole
+			return rounded(new(big.Float), prec, mode)
+		}
+		if i < 2 {
+			r.Mul(r, g)
+			h += hx
+		} else {
+			r.Quo(r, g)
+			h -= hx
+		}
+	}
+	if exact && h == 0 {
+		return (*Float)(newFloat(prec).SetMode(mode).SetRat(r))
+	}
+	for _, y := range []*big.Float{y1, y2} {
+		if isNonPositiveInt(y) {
+			return rounded(new(big.Float), prec, mode)
+		}
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		wp := w + 8
+		g := gammaKernel(x1, wp)
+		g.Mul(g, gammaKernel(x2, wp))
+		g.Quo(g, gammaKernel(y1, wp))
+		g.Quo(g, gammaKernel(y2, wp))
+		return newFloat(w).Set(g)
+	})
+}
+
+// hyp2f1 returns 2F1(a, b; c; z) correctly rounded, for a series that
+// does not terminate and has no poles.
+func hyp2f1(a, b, c, z *big.Float, prec uint, mode big.RoundingMode) *Float {
+	neg := func(x *big.Float) *big.Float { return new(big.Float).Neg(x) }
+	cma, cmb := exactAdd(c, neg(a)), exactAdd(c, neg(b))
+	switch z.Cmp(floatOne) {
+	case 1:
+		panic(ErrNaN)
+	case 0:
+		// Gauss: Gamma(c) Gamma(c-a-b) / (Gamma(c-a) Gamma(c-b))
+		s := exactAdd(cma, neg(b))
+		if s.Sign() <= 0 {
+			panic(ErrNaN)
+		}
+		return gammaRatio(c, s, cma, cmb, prec, mode)
+	}
+	if isNonPositiveInt(cma) {
+		a, b, cma, cmb = b, a, cmb, cma
+	}
+	omz := exactAdd(floatOne, neg(z))
+	if isNonPositiveInt(cmb) {
+		// Pfaff: 2F1(a, b; c; z) = (1-z)**-a 2F1(a, c-b; c; z/(z-1)), which
+		// terminates; the result is exact unless (1-z)**-a is irrational
+		ra, _ := a.Rat(nil)
+		rcmb, _ := cmb.Rat(nil)
+		rc, _ := c.Rat(nil)
+		rz, _ := z.Rat(nil)
+		rw := new(big.Rat).Quo(rz, new(big.Rat).Sub(rz, big.NewRat(1, 1)))
+		n, _ := smallInt(cmb)
+		r := ratPolynomial([]*big.Rat{ra, rcmb}, []*big.Rat{rc}, rw, -n)
+		return powTimes(r, omz, neg(a), prec, mode)
+	}
+	if z.Cmp(floatHalf) > 0 {
+		// around z = 1, with 1 - z exact
+		return hyperZiv(prec, mode, func(w uint) (*big.Float, int, *big.Float) {
+			return hyp2f1Near1(a, b, c, omz, w+16)
+		})
+	}
+	if z.Cmp(big.NewFloat(-0.5)) >= 0 {
+		h := &hyperSeries{a: []*big.Float{a, b}, b: []*big.Float{c}, z: z}
+		return hyperZiv(prec, mode, h.approx)
+	}
+	// Pfaff: 2F1(a, b; c; z) = (1-z)**-a 2F1(a, c-b; c; w) with
+	// w = z/(z-1) in (1/3, 1), summed directly for z >= -1, where w <= 1/2,
+	// and around 1 otherwise, where 1 - w = 1/(1-z)
+	return hyperZiv(prec, mode, func(w uint) (*big.Float, int, *big.Float) {
+		wp := w + 16
+		var f, largest *big.Float
+		var e int
+		if z.Cmp(big.NewFloat(-1)) >= 0 {
+			zw := newFloat(wp).Quo(z, newFloat(wp).Neg(omz))
+			h := &hyperSeries{a: []*big.Float{a, cmb}, b: []*big.Float{c}, z: zw}
+			f, e, largest = h.approx(wp)
+		} else {
+			f, e, largest = hyp2f1Near1(a, cmb, c, newFloat(wp).Quo(floatOne, omz), wp)
+		}
+		p := powKernel(omz, neg(a), wp)
+		y := newFloat(wp).Mul(p, f)
+		return y, combineErrors(y, p, e, wp), largest.Mul(largest, p)
+	})
+}
+
+// hyp2f1Near1 returns 2F1(a, b; c; 1-u) for 0 < u <= 1/2 to about w bits,
+// an error exponent, and the size of the largest part, by the connection
+// formula around z = 1. None of a, b, c, c-a and c-b may be 0 or a
+// negative integer. With s = c - a - b not an integer, it is
+//
+//	2F1(a, b; c; 1-u) = Gamma(c) Gamma(s) / (Gamma(c-a) Gamma(c-b)) 2F1(a, b; 1-s; u)
+//	                  + Gamma(c) Gamma(-s) / (Gamma(a) Gamma(b)) u**s 2F1(c-a, c-b; 1+s; u),
+//
+// and for an integer s, where both parts have poles, hyp2f1Log gives the
+// limit.
+func hyp2f1Near1(a, b, c, u *big.Float, w uint) (*big.Float, int, *big.Float) {
+	neg := func(x *big.Float) *big.Float { return new(big.Float).Neg(x) }
+	cma, cmb := exactAdd(c, neg(a)), exactAdd(c, neg(b))
+	s := exactAdd(cma, neg(b))
+	if m, ok := smallInt(s); ok {
+		if m >= 0 {
+			return hyp2f1Log(a, b, c, m, u, w)
+		}
+		// Euler: 2F1(a, b; c; z) = (1-z)**s 2F1(c-a, c-b; c; z), where
+		// c - (c-a) - (c-b) = -s > 0
+		f, e, largest := hyp2f1Log(cma, cmb, c, -m, u, w)
+		p := powKernel(u, s, w)
+		y := newFloat(w).Mul(p, f)
+		return y, combineErrors(y, p, e, w), largest.Mul(largest, p)
+	}
+	y := newFloat(w)
+	e := math.MinInt32
+	largest := newFloat(64)
+	one := func(x *big.Float) *big.Float { return exactAdd(floatOne, x) }
+	for i, t := range [][5]*big.Float{{a, b, one(neg(s)), cma, cmb}, {cma, cmb, one(s), a, b}} {
+		// t is the series parameters and the Gamma arguments below
+		h := &hyperSeries{a: []*big.Float{t[0], t[1]}, b: []*big.Float{t[2]}, z: u}
+		f, ef, lf := h.approx(w)
+		k := gammaKernel(c, w)
+		if i == 0 {
+			k.Mul(k, gammaKernel(s, w))
+		} else {
+			k.Mul(k, gammaKernel(neg(s), w))
+			k.Mul(k, powKernel(u, s, w))
+		}
+		k.Quo(k, gammaKernel(t[3], w))
+		k.Quo(k, gammaKernel(t[4], w))
+		part := newFloat(w).Mul(k, f)
+		y.Add(y, part)
+		e = maxExp(e, combineErrors(part, k, ef, w)+1)
+		if l := newFloat(64).Mul(lf, k); cmpAbs(l, largest) > 0 {
+			largest = l.Abs(l)
+		}
+	}
+	if y.Sign() != 0 {
+		e = maxExp(e, expo(y)-int(w)+1)
+	}
+	return y, e + 1, largest
+}
+
+// hyp2f1Log returns 2F1(a, b; a+b+m; 1-u) for an integer m >= 0 and
+// 0 < u <= 1/2 as hyp2f1Near1 does, by the logarithmic form of its
+// connection formula (Abramowitz and Stegun 15.3.11):
+//
+//	2F1(a, b; a+b+m; 1-u) / Gamma(a+b+m) =
+//	    1/(Gamma(a+m) Gamma(b+m)) sum over k < m of (a)k (b)k (m-k-1)! / k! (-u)**k
+//	    - (-u)**m / (Gamma(a) Gamma(b)) sum over k >= 0 of (a+m)k (b+m)k / (k! (k+m)!) u**k d(k),
+//
+// where d(k) = log(u) - ψ(k+1) - ψ(k+m+1) + ψ(a+m+k) + ψ(b+m+k).
+func hyp2f1Log(a, b, c *big.Float, m int64, u *big.Float, w uint) (*big.Float, int, *big.Float) {
+	wp := w + 16
+	am := exactAdd(a, new(big.Float).SetInt64(m))
+	bm := exactAdd(b, new(big.Float).SetInt64(m))
+
+	// the finite sum, with t = (a)k (b)k (m-k-1)! / k! (-u)**k
+	fin := newFloat(wp)
+	finAbs := newFloat(64)
+	if m > 0 {
+		t := newFloat(wp).SetInt(new(big.Int).MulRange(1, m-1))
+		v := newFloat(wp)
+		for k := int64(0); k < m; k++ {
+			fin.Add(fin, t)
+			finAbs.Add(finAbs, new(big.Float).Abs(t))
+			if k+1 < m {
+				kf := new(big.Float).SetInt64(k)
+				t.Mul(t, v.Add(a, kf))
+				t.Mul(t, v.Add(b, kf))
+				t.Mul(t, u)
+				t.Quo(t, new(big.Float).SetInt64(-(k+1)*(m-k-1)))
+			}
+		}
+	}
+
+	// the infinite sum, with t = (a+m)k (b+m)k / (k! (k+m)!) u**k and d(k)
+	// from d(0) = log(u) + 2γ - H(m) + ψ(a+m) + ψ(b+m) by
+	// d(k+1) = d(k) + 1/(a+m+k) + 1/(b+m+k) - 1/(k+1) - 1/(k+m+1)
+	hm := new(big.Rat)
+	for j := int64(1); j <= m; j++ {
+		hm.Add(hm, big.NewRat(1, j))
+	}
+	pa, ed := digammaApprox(am, wp)
+	pb, eb := digammaApprox(bm, wp)
+	parts := []*big.Float{pa, pb, logKernel(u, wp), newFloat(wp).SetMantExp(eulerGammaConstant.at(wp), 1), newFloat(wp).SetRat(hm)}
+	parts[4].Neg(parts[4])
+	d := newFloat(wp)
+	ed = maxExp(ed, eb)
+	for _, x := range parts {
+		d.Add(d, x)
+		// the log, the constant and each addition are off by a few ulps
+		if x.Sign() != 0 {
+			ed = maxExp(ed, expo(x)-int(wp)+4)
+		}
+	}
+
+	// once k >= k0, the terms fall by a factor ρ with 1/(1-ρ) <= 2**tb,
+	// and once k > 2 max(|a+m|, |b+m|) + 8 too, |d(k+i)| <= |d(k)| + i,
+	// so the rest from t(k) on is at most |t(k)| (2**tb |d(k)| + 2**2tb)
+	k0, tb := (&hyperSeries{a: []*big.Float{am, bm}, b: []*big.Float{new(big.Float).SetInt64(m + 1)}, z: u}).tailStart()
+	if k1 := 2*int64(math.Max(math.Abs(float64Of(am)), math.Abs(float64Of(bm)))) + 9; k1 > k0 {
+		k0 = k1
+	}
+	t := newFloat(wp).Quo(floatOne, newFloat(wp).SetInt(new(big.Int).MulRange(1, m)))
+	inf := newFloat(wp)
+	tAbs, tdAbs, dMax := newFloat(64), newFloat(64), newFloat(64)
+	largest := newFloat(64)
+	v, r := newFloat(wp), newFloat(wp)
+	var k int64
+	for k = 0; ; k++ {
+		td := newFloat(wp).Mul(t, d)
+		if k >= k0 {
+			rest := expo(t) + int(2*tb) + 1
+			if d.Sign() != 0 {
+				rest = maxExp(rest, expo(t)+expo(d)+int(tb)+1)
+			}
+			if t.Sign() == 0 || rest < expo(largest)-int(wp)-4 {
+				break
+			}
+		}
+		inf.Add(inf, td)
+		tAbs.Add(tAbs, new(big.Float).Abs(t))
+		tdAbs.Add(tdAbs, td.Abs(td))
+		if cmpAbs(td, largest) > 0 {
+			largest = newFloat(64).Set(td)
+		}
+		if cmpAbs(d, dMax) > 0 {
+			dMax.Abs(d)
+		}
+		kf := new(big.Float).SetInt64(k)
+		d.Add(d, r.Quo(floatOne, v.Add(am, kf)))
+		d.Add(d, r.Quo(floatOne, v.Add(bm, kf)))
+		d.Sub(d, r.Quo(floatOne, new(big.Float).SetInt64(k+1)))
+		d.Sub(d, r.Quo(floatOne, new(big.Float).SetInt64(k+m+1)))
+		t.Mul(t, v.Add(am, kf))
+		t.Mul(t, v.Add(bm, kf))
+		t.Mul(t, u)
+		t.Quo(t, new(big.Float).SetInt64((k+1)*(k+m+1)))
+	}
+	// the rest, the error of d(0) in every term, and the roundings: t(k)
+	// and d(k) gain a few ulps of error per step, and d(k) has at most
+	// |d| <= dMax
+	ei := expo(t) + int(2*tb) + 1
+	if d.Sign() != 0 {
+		ei = maxExp(ei, expo(t)+expo(d)+int(tb)+1)
+	}
+	ei = maxExp(ei, expo(tAbs)+ed+1)
+	round := newFloat(64).Mul(tAbs, dMax)
+	round.Add(round, tdAbs)
+	ei = maxExp(ei, expo(round)+bits.Len64(uint64(16*(k+2)))-int(wp)) + 1
+	ef := expo(finAbs) + bits.Len64(uint64(8*(m+2))) - int(wp)
+
+	// the Gamma factors, with (-u)**m
+	g := gammaKernel(c, wp)
+	k1 := newFloat(wp).Quo(g, gammaKernel(am, wp))
+	k1.Quo(k1, gammaKernel(bm, wp))
+	k2 := newFloat(wp).Quo(g, gammaKernel(a, wp))
+	k2.Quo(k2, gammaKernel(b, wp))
+	if m > 0 {
+		k2.Mul(k2, powKernel(u, new(big.Float).SetInt64(m), wp))
+	}
+	if m%2 == 0 {
+		k2.Neg(k2)
+	}
+	y := newFloat(wp).Mul(k1, fin)
+	e := math.MinInt32
+	if m > 0 {
+		e = combineErrors(y, k1, ef, wp)
+	}
+	p2 := newFloat(wp).Mul(k2, inf)
+	e = maxExp(e, combineErrors(p2, k2, ei, wp)) + 1
+	y.Add(y, p2)
+	if y.Sign() != 0 {
+		e = maxExp(e, expo(y)-int(wp)+1)
+	}
+	big1 := newFloat(64).Mul(k1, finAbs)
+	big2 := newFloat(64).Mul(k2, largest)
+	if cmpAbs(big2, big1) > 0 {
+		big1 = big2
+	}
+	return y, e + 1, big1.Abs(big1)
+}
+
+// powKernel returns x**y = exp(y log(x)) for finite x > 0, with a relative
+// error of a few units in the last place of w bits.
+func powKernel(x, y *big.Float, w uint) *big.Float {
+	t := newFloat(64).Mul(y, logKernel(x, 64))
+	wl := w + 8
+	if t.Sign() != 0 && expo(t) > 0 {
+		wl += uint(expo(t))
+	}
+	t = newFloat(wl).Mul(y, logKernel(x, wl))
+	return expKernel(t, w)
+}
+
+// combineErrors returns the error exponent of y = k f, where k has a
+// relative error of a few units in the last place of wp bits and f is
+// within 2**ef.
+func combineErrors(y, k *big.Float, ef int, wp uint) int {
+	e := expo(k) + ef + 1
+	if y.Sign() != 0 {
+		e = maxExp(e, expo(y)-int(wp)+5)
+	}
+	return e + 1
+}
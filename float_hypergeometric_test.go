@@ -0,0 +1,237 @@
+package mathx
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// floats returns the arguments as Floats with the precision.
+func floats(prec uint, xs ...float64) []*Float {
+	r := make([]*Float, len(xs))
+	for i, x := range xs {
+		r[i] = NewFloat(x).SetPrec(prec)
+	}
+	return r
+}
+
+func TestHypergeometricIdentities(t *testing.T) {
+	const prec = 200
+	x := func(f float64) *Float { return NewFloat(f).SetPrec(prec) }
+	hyp := func(a, b []float64, z float64) *Float {
+		return Hypergeometric(floats(prec, a...), floats(prec, b...), x(z), prec)
+	}
+	pi := Pi(prec)
+	cases := []struct {
+		name      string
+		got, want *Float
+	}{
+		{"0F0(;;1.5)", hyp(nil, nil, 1.5), x(1.5).Exp()},
+		{"0F0(;;-20)", hyp(nil, nil, -20), x(-20).Exp()},
+		{"1F1(1;2;0.75)", hyp([]float64{1}, []float64{2}, 0.75), x(0.75).Expm1().Quo(x(0.75))},
+		{"1F1(1/2;3/2;-4)", hyp([]float64{0.5}, []float64{1.5}, -4), x(2).Erf().Mul(pi.Sqrt()).Quo(x(4))},
+		{"0F1(;1;-2.25)", hyp(nil, []float64{1}, -2.25), Jn(0, x(3))},
+		{"2F1(1,1;2;0.5)", hyp([]float64{1, 1}, []float64{2}, 0.5), x(2).Log().Mul(x(2))},
+		{"2F1(1,1;2;-2)", hyp([]float64{1, 1}, []float64{2}, -2), x(3).Log().Quo(x(2))},
+		{"2F1(1,1;2;-10)", hyp([]float64{1, 1}, []float64{2}, -10), x(11).Log().Quo(x(10))},
+		{"2F1(1/2,1/2;3/2;0.25)", hyp([]float64{0.5, 0.5}, []float64{1.5}, 0.25), x(0.5).Asin().Mul(x(2))},
+		{"2F1(1/2,1;3/2;-9)", hyp([]float64{0.5, 1}, []float64{1.5}, -9), x(3).Atan().Quo(x(3))},
+		{"2F1(1/2,1/2;2;1)", hyp([]float64{0.5, 0.5}, []float64{2}, 1), x(4).Quo(pi)},
+		{"2F1(1/2,3;2;0.25)", hyp([]float64{0.5, 3}, []float64{2}, 0.25), x(13).Quo(x(3).Sqrt().Mul(x(6)))},
+		{"1F0(1/3;;0.5)", Hypergeometric([]*Float{x(1).Quo(x(3))}, nil, x(0.5), prec), x(2).Pow(x(1).Quo(x(3)))},
+		{"2F1(1/3,2/3;3/2;3/4)", Hypergeometric([]*Float{x(1).Quo(x(3)), x(2).Quo(x(3))}, floats(prec, 1.5), x(0.75), prec), x(3).Sqrt().Mul(pi.Quo(x(9)).Sin()).Mul(x(2))},
+		{"2F1(1/4,2;4;-5)", hyp([]float64{0.25, 2}, []float64{4}, -5), hyp([]float64{2, 0.25}, []float64{4}, -5)},
+		{"2F1(1/2,1/2;3/2;0.75)", hyp([]float64{0.5, 0.5}, []float64{1.5}, 0.75), pi.Quo(x(3)).Quo(x(0.75).Sqrt())},
+		{"2F1(1,1;2;0.875)", hyp([]float64{1, 1}, []float64{2}, 0.875), x(8).Log().Quo(x(0.875))},
+		{"2F1(1,1;3;0.75)", hyp([]float64{1, 1}, []float64{3}, 0.75), x(0.25).Mul(x(0.25).Log()).Add(x(0.75)).Mul(x(2)).Quo(x(0.5625))},
+		{"2F1(2,2;3;0.75)", hyp([]float64{2, 2}, []float64{3}, 0.75), x(0.25).Mul(x(0.25).Log()).Add(x(0.75)).Mul(x(2)).Quo(x(0.140625))},
+		{"2F1(1,2;3;-0.75)", hyp([]float64{1, 2}, []float64{3}, -0.75), x(0.75).Sub(x(1.75).Log()).Mul(x(2)).Quo(x(0.5625))},
+		{"2F1(1,1;3;-7)", hyp([]float64{1, 1}, []float64{3}, -7), x(8).Mul(x(8).Log()).Sub(x(7)).Mul(x(2)).Quo(x(49))},
+		{"2F1(1/2,1;3/2;-1e6)", hyp([]float64{0.5, 1}, []float64{1.5}, -1e6), x(1000).Atan().Quo(x(1000))},
+	}
+	for _, c := range cases {
+		// the right hand sides are rounded several times
+		d := c.got.Sub(c.want).Abs()
+		if d.Cmp(c.want.Abs().Mul(x(0x1p-190))) > 0 {
+			t.Errorf("%s = %v, expected %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestHypergeometricExact(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b []float64
+		z    float64
+		want *big.Rat
+	}{
+		{"0F0(;;0)", nil, nil, 0, big.NewRat(1, 1)},
+		{"2F1(-2,3;4;0.5)", []float64{-2, 3}, []float64{4}, 0.5, big.NewRat(2, 5)},
+		{"2F1(-1,1;-3;0.5)", []float64{-1, 1}, []float64{-3}, 0.5, big.NewRat(7, 6)},
+		{"3F2(-3,1,1;2,2;-1)", []float64{-3, 1, 1}, []float64{2, 2}, -1, big.NewRat(103, 48)},
+		{"1F0(2;;0.5)", []float64{2}, nil, 0.5, big.NewRat(4, 1)},
+		{"1F0(-0.5;;0.75)", []float64{-0.5}, nil, 0.75, big.NewRat(1, 2)},
+		{"2F1(1,1;3;1)", []float64{1, 1}, []float64{3}, 1, big.NewRat(2, 1)},
+		{"2F1(1,1/2;5/2;1)", []float64{1, 0.5}, []float64{2.5}, 1, big.NewRat(3, 2)},
+		{"2F1(1,3;2;0.25)", []float64{1, 3}, []float64{2}, 0.25, big.NewRat(14, 9)},
+	}
+	for _, c := range cases {
+		got := Hypergeometric(floats(64, c.a...), floats(64, c.b...), NewFloat(c.z), 64)
+		want := new(big.Float).SetPrec(64).SetRat(c.want)
+		if got.Cmp((*Float)(want)) != 0 || got.Acc() != want.Acc() {
+			t.Errorf("%s = %v (%v), expected %v (%v)", c.name, got, got.Acc(), want, want.Acc())
+		}
+	}
+}
+
+func TestHypergeometricDirected(t *testing.T) {
+	params := []struct{ a, b []float64 }{
+		{nil, []float64{2.5}},
+		{[]float64{0.75}, []float64{1.25}},
+		{[]float64{0.5, 1.25}, []float64{2.75}},
+		{[]float64{1.5, 0.25, 1}, []float64{2, 3.5}},
+		{[]float64{math.Pi}, []float64{math.E}},
+	}
+	rnd := rand.New(rand.NewSource(4))
+	for _, p := range params {
+		a, b := floats(64, p.a...), floats(64, p.b...)
+		name := fmt.Sprintf("%dF%d%v%v", len(a), len(b), p.a, p.b)
+		f := func(x []*Float) *Float { return Hypergeometric(a, b, x[0], x[0].Prec()) }
+		for i := 0; i < 3; i++ {
+			// z rounded to 2 bits stays inside the unit disk
+			z := 1.7*rnd.Float64() - 0.85
+			if len(p.a) <= len(p.b) {
+				z *= 8
+			}
+			checkDirected(t, name, f, []float64{z}, []uint{2, 11, 64, 150})
+		}
+	}
+}
+
+func TestHypergeometricNearOne(t *testing.T) {
+	// near z = 1, and for large negative z where a - b is an integer, the
+	// series converge slowly, and the connection formulas are needed
+	cases := []struct {
+		name string
+		a, b []float64
+		z    float64
+		want func(z *Float) *Float
+	}{
+		{"2F1(1/2,1/2;3/2;z)", []float64{0.5, 0.5}, []float64{1.5}, 0.9999, func(z *Float) *Float { return z.Sqrt().Asin().Quo(z.Sqrt()) }},
+		{"2F1(1,1;2;z)", []float64{1, 1}, []float64{2}, 0.9999, func(z *Float) *Float { return NewFloat(1).Sub(z).Log().Neg().Quo(z) }},
+		{"2F1(1,1;2;z)", []float64{1, 1}, []float64{2}, -1e6, func(z *Float) *Float { return NewFloat(1).Sub(z).Log().Neg().Quo(z) }},
+		{"2F1(1/2,1;3/2;z)", []float64{0.5, 1}, []float64{1.5}, -1e6, func(z *Float) *Float { return z.Neg().Sqrt().Atan().Quo(z.Neg().Sqrt()) }},
+		{"2F1(1/2,1;3/2;z)", []float64{0.5, 1}, []float64{1.5}, -0.9999, func(z *Float) *Float { return z.Neg().Sqrt().Atan().Quo(z.Neg().Sqrt()) }},
+	}
+	for _, c := range cases {
+		for _, prec := range []uint{53, 300} {
+			start := time.Now()
+			z := NewFloat(c.z).SetPrec(prec + 20)
+			got := Hypergeometric(floats(prec, c.a...), floats(prec, c.b...), z, prec)
+			if d := time.Since(start); d > time.Second {
+				t.Errorf("%s at z = %v to %d bits took %v", c.name, c.z, prec, d)
+			}
+			want := c.want(z)
+			if d := got.Sub(want).Abs(); d.Cmp(got.Ulp()) > 0 {
+				t.Errorf("%s at z = %v to %d bits = %v, expected %v", c.name, c.z, prec, got, want)
+			}
+		}
+	}
+}
+
+func TestHypergeometricLarge(t *testing.T) {
+	// 1F1 at large negative z sums the terms of one sign from Kummer's
+	// transformation, and 0F0 is exp
+	sqrtPi := Pi(300).Sqrt()
+	cases := []struct {
+		name string
+		a, b []float64
+		z    float64
+		want func(prec uint) *Float
+	}{
+		{"1F1(1;2;-1e5)", []float64{1}, []float64{2}, -1e5, func(prec uint) *Float { return NewFloat(1).SetPrec(prec).Quo(NewFloat(1e5)) }},
+		{"1F1(1/2;3/2;-1e5)", []float64{0.5}, []float64{1.5}, -1e5, func(prec uint) *Float { return sqrtPi.Quo(NewFloat(4e5).SetPrec(300).Sqrt()) }},
+		{"1F1(1;3;-7)", []float64{1}, []float64{3}, -7, func(prec uint) *Float {
+			// 2 (e**-7 + 6) / 49
+			return NewFloat(-7).SetPrec(prec + 20).Exp().Add(NewFloat(6)).Mul(NewFloat(2)).Quo(NewFloat(49))
+		}},
+		{"0F0(;;-1e5)", nil, nil, -1e5, func(prec uint) *Float { return NewFloat(-1e5).SetPrec(prec + 20).Exp() }},
+	}
+	for _, c := range cases {
+		for _, prec := range []uint{53, 200} {
+			start := time.Now()
+			got := Hypergeometric(floats(prec, c.a...), floats(prec, c.b...), NewFloat(c.z), prec)
+			if d := time.Since(start); d > time.Second {
+				t.Errorf("%s to %d bits took %v", c.name, prec, d)
+			}
+			if d := got.Sub(c.want(prec)).Abs(); d.Cmp(got.Ulp()) > 0 {
+				t.Errorf("%s to %d bits = %v, expected %v", c.name, prec, got, c.want(prec))
+			}
+		}
+	}
+
+	// and series that would need too many terms or bits fail quickly
+	huge := NewFloat(1).SetPrec(10).Mul(NewFloat(2).Pow(NewFloat(2000)))
+	overflows := map[string]func(){
+		"1F1(1;2;2**2000)":    func() { Hypergeometric(floats(53, 1), floats(53, 2), huge, 53) },
+		"1F1(1;2;-2**2000)":   func() { Hypergeometric(floats(53, 1), floats(53, 2), huge.Neg(), 53) },
+		"0F1(;2;-2**2000)":    func() { Hypergeometric(nil, floats(53, 2), huge.Neg(), 53) },
+		"2F2(1,1;2,2;1e19)":   func() { Hypergeometric(floats(53, 1, 1), floats(53, 2, 2), NewFloat(1e19), 53) },
+		"2F2(1,1;2,2;-1e5)":   func() { Hypergeometric(floats(53, 1, 1), floats(53, 2, 2), NewFloat(-1e5), 53) },
+		"2F1(1,1;2**2000;.5)": func() { Hypergeometric(floats(53, 1, 1), []*Float{huge}, NewFloat(0.5), 53) },
+	}
+	for name, f := range overflows {
+		start := time.Now()
+		func() {
+			defer func() {
+				if r := recover(); r != ErrOverflow {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+			t.Errorf("%s did not panic", name)
+		}()
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("%s took %v to fail", name, d)
+		}
+	}
+	if got := Hypergeometric(nil, nil, huge, 53); !got.IsInf() {
+		t.Errorf("0F0(;;2**2000) = %v", got)
+	}
+}
+
+func TestHypergeometricSpecial(t *testing.T) {
+	inf := Inf(false)
+	nans := map[string]func(){
+		"1F1(1;-2;1)":      func() { Hypergeometric(floats(64, 1), floats(64, -2), NewFloat(1), 0) },
+		"2F1(1,1;2;1)":     func() { Hypergeometric(floats(64, 1, 1), floats(64, 2), NewFloat(1), 0) },
+		"2F1(1,1;2;1.5)":   func() { Hypergeometric(floats(64, 1, 1), floats(64, 2), NewFloat(1.5), 0) },
+		"2F0(1,1;;0.5)":    func() { Hypergeometric(floats(64, 1, 1), nil, NewFloat(0.5), 0) },
+		"3F2(1,1,1;2,2;1)": func() { Hypergeometric(floats(64, 1, 1, 1), floats(64, 2, 2), NewFloat(1), 0) },
+		"1F0(1;;1)":        func() { Hypergeometric(floats(64, 1), nil, NewFloat(1), 0) },
+		"1F1(Inf;1;1)":     func() { Hypergeometric([]*Float{inf}, floats(64, 1), NewFloat(1), 0) },
+		"0F0(;;Inf)":       func() { Hypergeometric(nil, nil, inf, 0) },
+	}
+	for name, f := range nans {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func BenchmarkHypergeometric2F1(b *testing.B) {
+	a := floats(256, 0.5, 1.25)
+	c := floats(256, 2.75)
+	z := NewFloat(0.75).SetPrec(256)
+	for i := 0; i < b.N; i++ {
+		Hypergeometric(a, c, z, 256)
+	}
+}
@@ -0,0 +1,546 @@
+package mathx
+
+// This file is for the machinery behind the elementary functions on Float.
+// Each kernel computes its function at a working precision w, with a
+// relative error of at most a couple of units in the last place; ziv then
+// retries at higher precision until the result can be rounded correctly.
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+var floatOne = big.NewFloat(1)
+var floatTwo = big.NewFloat(2)
+
+// expo returns the exponent of x, which must be finite and nonzero, so
+// that 0.5 <= |x| / 2**expo(x) < 1.
+func expo(x *big.Float) int {
+	return x.MantExp(nil)
+}
+
+// pow2 returns 2**e.
+func pow2(e int) *big.Float {
+	return new(big.Float).SetMantExp(floatOne, e)
+}
+
+// newFloat returns a zero big.Float with the given precision.
+func newFloat(prec uint) *big.Float {
+	return new(big.Float).SetPrec(prec)
+}
+
+// guardBits is the extra precision used for argument reduction: the
+// kernels halve their arguments about sqrt(w) times, which balances the
+// number of series terms against the error of undoing the halving.
+func guardBits(w uint) uint {
+	return uint(math.Sqrt(float64(w)))/2 + 1
+}
+
+// precision returns the precision results are rounded to: that of z, or
+// 64 bits if z has precision 0.
+func (z *Float) precision() uint {
+	if p := z.Prec(); p != 0 {
+		return p
+	}
+	return 64
+}
+
+// rounded returns x rounded to prec with the mode.
+func rounded(x *big.Float, prec uint, mode big.RoundingMode) *Float {
+	return (*Float)(newFloat(prec).SetMode(mode).Set(x))
+}
+
+// overflow returns ±Inf for a result beyond the exponent range of
+// big.Float, with the accuracy that big.Float arithmetic gives it.
+func overflow(prec uint, mode big.RoundingMode, signbit bool) *Float {
+	m := floatOne
+	if signbit {
+		m = big.NewFloat(-1)
+	}
+	return (*Float)(newFloat(prec).SetMode(mode).SetMantExp(m, big.MaxExp))
+}
+
+// underflow returns ±0 for a nonzero result beyond the exponent range of
+// big.Float, with the accuracy that big.Float arithmetic gives it.
+func underflow(prec uint, mode big.RoundingMode, signbit bool) *Float {
+	m := big.NewFloat(0.25)
+	if signbit {
+		m.Neg(m)
+	}
+	return (*Float)(newFloat(prec).SetMode(mode).SetMantExp(m, big.MinExp))
+}
+
+// ziv returns a function value rounded correctly to prec with the mode.
+// approx(w) must return an approximation y and an exponent e with the
+// value within 2**e of y, where e shrinks as w grows. The value must not be
+// exactly representable in prec+1 bits, or the loop would not end.
+func ziv(prec uint, mode big.RoundingMode, approx func(w uint) (*big.Float, int)) *Float {
+	for w := prec + 32; ; w += w / 2 {
+		y, e := approx(w)
+		if y.IsInf() || y.Sign() == 0 {
+			// an inexact one has overflowed or underflowed
+			switch {
+			case y.Acc() == big.Exact:
+				return rounded(y, prec, mode)
+			case y.IsInf():
+				return overflow(prec, mode, y.Signbit())
+			}
+			return underflow(prec, mode, y.Signbit())
+		}
+		ey := expo(y)
+		if e >= ey-1 {
+			continue
+		}
+		q := uint(ey - e + 2)
+		if mp := y.MinPrec() + 2; mp > q {
+			q = mp
+		}
+		d := pow2(e)
+		lo := newFloat(q).Sub(y, d)
+		hi := newFloat(q).Add(y, d)
+		rlo := newFloat(prec).SetMode(mode).Set(lo)
+		rhi := newFloat(prec).SetMode(mode).Set(hi)
+		if rlo.Cmp(rhi) == 0 && (rlo.Cmp(lo) < 0 || rlo.Cmp(hi) > 0) {
+			return rounded(y, prec, mode)
+		}
+	}
+}
+
+// zivRel is ziv for kernels with a relative error of a few units in the
+// last place of w bits.
+func zivRel(prec uint, mode big.RoundingMode, kernel func(w uint) *big.Float) *Float {
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		y := kernel(w)
+		if y.IsInf() || y.Sign() == 0 {
+			return y, 0
+		}
+		return y, expo(y) - int(w) + 2
+	})
+}
+
+// nearly returns c + d rounded correctly to prec with the mode, where c is
+// exact and d has the sign dir and |d| < 2**dExp, if d is too small to
+// affect the rounding except for breaking a tie or choosing a direction.
+// Otherwise, it returns false.
+func nearly(c *big.Float, dir int, dExp int, prec uint, mode big.RoundingMode) (*Float, bool) {
+	// rounding boundaries (numbers of prec+1 bits) other than c are at
+	// least 2**(expo(c)-q-1) away from c
+	q := int(c.MinPrec())
+	if int(prec)+1 > q {
+		q = int(prec) + 1
+	}
+	off := expo(c) - q - 4
+	if dExp > off {
+		return nil, false
+	}
+	d := pow2(off)
+	if dir < 0 {
+		d.Neg(d)
+	}
+	y := newFloat(uint(q+8)).Add(c, d)
+	return rounded(y, prec, mode), true
+}
+
+// expKernel returns exp(x), for finite x with |x| < 2**32.
+func expKernel(x *big.Float, w uint) *big.Float {
+	if x.Sign() == 0 {
+		return newFloat(w).SetInt64(1)
+	}
+	s := guardBits(w)
+	wp := w + 2*s + 24
+	r := x
+	var k int64
+	if ex := expo(x); ex >= 0 {
+		// x = k log(2) + r, with |r| <= log(2)/2
+		wl := wp + uint(ex) + 4
+		l2 := ln2Constant.at(wl)
+		q, _ := newFloat(64).Quo(x, l2).Float64()
+		k = int64(math.Round(q))
+		r = newFloat(wl).Mul(l2, new(big.Float).SetInt64(k))
+		r.Sub(x, r)
+	}
+	r = newFloat(wp).SetMantExp(r, -int(s))
+	sum := newFloat(wp).SetInt64(1)
+	term := newFloat(wp).SetInt64(1)
+	for n := int64(1); ; n++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetInt64(n))
+		if term.Sign() == 0 || expo(term) < -int(wp) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	for i := uint(0); i < s; i++ {
+		sum.Mul(sum, sum)
+	}
+	return newFloat(w).SetMantExp(sum, int(k))
+}
+
+// expm1Kernel returns exp(x) - 1, for finite nonzero x with |x| < 2**32.
+func expm1Kernel(x *big.Float, w uint) *big.Float {
+	if expo(x) >= 0 {
+		// |x| >= 0.5, so at most 2 bits cancel
+		y := expKernel(x, w+4)
+		return newFloat(w).Sub(y, floatOne)
+	}
+	s := guardBits(w)
+	wp := w + 2*s + 24
+	r := newFloat(wp).SetMantExp(x, -int(s))
+	sum := newFloat(wp).Set(r)
+	term := newFloat(wp).Set(r)
+	for n := int64(2); ; n++ {
+		term.Mul(term, r)
+		term.Quo(term, new(big.Float).SetInt64(n))
+		if term.Sign() == 0 || expo(term) < expo(sum)-int(wp) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	// expm1(2a) = expm1(a) (expm1(a) + 2)
+	t := newFloat(wp)
+	for i := uint(0); i < s; i++ {
+		t.Add(sum, floatTwo)
+		sum.Mul(sum, t)
+	}
+	return newFloat(w).Set(sum)
+}
+
+// atanhSeries returns atanh(t) = t + t**3/3 + t**5/5 + ..., or atan(t) if
+// alternate is set, for small t.
+func atanhSeries(t *big.Float, alternate bool, wp uint) *big.Float {
+	sum := newFloat(wp).Set(t)
+	t2 := newFloat(wp).Mul(t, t)
+	pw := newFloat(wp).Set(t)
+	term := newFloat(wp)
+	for n := int64(3); ; n += 2 {
+		pw.Mul(pw, t2)
+		term.Quo(pw, new(big.Float).SetInt64(n))
+		if term.Sign() == 0 || expo(term) < expo(sum)-int(wp) {
+			break
+		}
+		if alternate && n%4 == 3 {
+			sum.Sub(sum, term)
+		} else {
+			sum.Add(sum, term)
+		}
+	}
+	return sum
+}
+
+// logKernel returns log(x), for finite x > 0.
+func logKernel(x *big.Float, w uint) *big.Float {
+	m := new(big.Float)
+	e := x.MantExp(m)
+	if m.Cmp(big.NewFloat(0.7071)) < 0 {
+		m.SetMantExp(m, 1)
+		e--
+	}
+	// m is in [0.7, 1.42), and log(m) = 2 atanh((m-1)/(m+1))
+	k := guardBits(w)
+	wp := w + k + 24 + uint(bits.Len(uint(absInt(e))))
+	num := newFloat(wp).Sub(m, floatOne)
+	logm := newFloat(wp)
+	if num.Sign() != 0 {
+		den := newFloat(wp).Add(m, floatOne)
+		t := newFloat(wp).Quo(num, den)
+		s := 0
+		if et := expo(t); et > -int(k) {
+			// take square roots of m until t is small; these lose about
+			// k bits, as m - 1 is then about 2**-k
+			s = et + int(k)
+			mm := newFloat(wp).Set(m)
+			for i := 0; i < s; i++ {
+				mm.Sqrt(mm)
+			}
+			num.Sub(mm, floatOne)
+			den.Add(mm, floatOne)
+			t.Quo(num, den)
+		}
+		logm.SetMantExp(atanhSeries(t, false, wp), s+1)
+	}
+	if e == 0 {
+		return newFloat(w).Set(logm)
+	}
+	l2 := ln2Constant.at(wp + uint(bits.Len(uint(absInt(e)))))
+	l2.Mul(l2, new(big.Float).SetInt64(int64(e)))
+	return newFloat(w).Add(l2, logm)
+}
+
+// log1pKernel returns log(1 + x), for finite nonzero x > -1.
+func log1pKernel(x *big.Float, w uint) *big.Float {
+	k := guardBits(w)
+	if expo(x) > -int(k) {
+		wp := w + k + 8
+		if x.Sign() < 0 && x.Prec()+2 > wp {
+			// 1 + x is then exact, even close to -1
+			wp = x.Prec() + 2
+		}
+		return logKernel(newFloat(wp).Add(x, floatOne), w)
+	}
+	// log(1 + x) = 2 atanh(x / (2 + x))
+	wp := w + 8
+	t := newFloat(wp).Add(x, floatTwo)
+	t.Quo(x, t)
+	return newFloat(w).SetMantExp(atanhSeries(t, false, wp), 1)
+}
+
+// reduceHalfPi returns r and q with x = q pi/2 + r, |r| <= pi/4 (about),
+// and r accurate to a relative error of 2**-(w+8). Only q mod 4 is
+// returned.
+func reduceHalfPi(x *big.Float, w uint) (*big.Float, int) {
+	ex := expo(x)
+	if ex < 0 {
+		return x, 0
+	}
+	wp := w + uint(ex) + 16
+	for {
+		hp := piConstant.at(wp)
+		hp.SetMantExp(hp, -1)
+		q := newFloat(wp).Quo(x, hp)
+		if q.Sign() > 0 {
+			q.Add(q, big.NewFloat(0.5))
+		} else {
+			q.Sub(q, big.NewFloat(0.5))
+		}
+		k, _ := q.Int(nil)
+		r := newFloat(wp).Mul(new(big.Float).SetInt(k), hp)
+		r.Sub(x, r)
+		// the error in r is less than 2**(ex+3-wp)
+		if r.Sign() == 0 {
+			wp += wp / 2
+			continue
+		}
+		lost := ex + 3 - expo(r) + int(w) + 8 - int(wp)
+		if lost <= 0 {
+			return r, int(new(big.Int).And(k, big.NewInt(3)).Int64())
+		}
+		wp += uint(lost) + 32
+	}
+}
+
+// sinSeries returns sin(r), or cos(r) if cos is set, for |r| < 1.
+func sinSeries(r *big.Float, cos bool, wp uint) *big.Float {
+	r2 := newFloat(wp).Mul(r, r)
+	term := newFloat(wp)
+	n := int64(1)
+	if cos {
+		term.SetInt64(1)
+		n = 0
+	} else {
+		term.Set(r)
+	}
+	sum := newFloat(wp).Set(term)
+	for {
+		term.Mul(term, r2)
+		term.Quo(term, new(big.Float).SetInt64((n+1)*(n+2)))
+		term.Neg(term)
+		n += 2
+		if term.Sign() == 0 || expo(term) < expo(sum)-int(wp) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	return sum
+}
+
+// sinCosKernel returns sin(x), or cos(x) if cos is set, for finite x. If
+// tan is set, it returns tan(x) instead.
+func sinCosKernel(x *big.Float, cos, tan bool, w uint) *big.Float {
+	wp := w + 24
+	r, q := reduceHalfPi(x, wp)
+	if tan {
+		s, c := sinSeries(r, false, wp), sinSeries(r, true, wp)
+		if q%2 == 1 {
+			return newFloat(w).Quo(c, s.Neg(s))
+		}
+		return newFloat(w).Quo(s, c)
+	}
+	if cos {
+		q++
+	}
+	y := sinSeries(r, q%2 == 1, wp)
+	if q%4 >= 2 {
+		y.Neg(y)
+	}
+	return newFloat(w).Set(y)
+}
+
+// atanKernel returns atan(x), for finite nonzero x.
+func atanKernel(x *big.Float, w uint) *big.Float {
+	k := guardBits(w)
+	wp := w + 3*k + 24
+	a := newFloat(wp)
+	invert := cmpAbs(x, floatOne) > 0
+	if invert {
+		a.Quo(floatOne, x)
+	} else {
+		a.Set(x)
+	}
+	a.Abs(a)
+	// atan(a) = 2 atan(a / (1 + sqrt(1 + a**2)))
+	s := 0
+	t := newFloat(wp)
+	for expo(a) > -int(k) {
+		t.Mul(a, a)
+		t.Add(t, floatOne)
+		t.Sqrt(t)
+		t.Add(t, floatOne)
+		a.Quo(a, t)
+		s++
+	}
+	y := atanhSeries(a, true, wp)
+	y.SetMantExp(y, s)
+	if invert {
+		hp := piConstant.at(wp)
+		y.Sub(hp.SetMantExp(hp, -1), y)
+	}
+	if x.Sign() < 0 {
+		y.Neg(y)
+	}
+	return newFloat(w).Set(y)
+}
+
+// asinKernel returns asin(x), for nonzero x with |x| < 1.
+func asinKernel(x *big.Float, w uint) *big.Float {
+	// asin(x) = atan(x / sqrt((1 - x)(1 + x))), where 1 - x and 1 + x are
+	// exact near 1 and -1
+	wp := w + 16
+	p := wp
+	if x.Prec()+2 > p {
+		p = x.Prec() + 2
+	}
+	d := newFloat(p).Sub(floatOne, x)
+	d.Mul(d, newFloat(p).Add(floatOne, x))
+	t := newFloat(wp).Sqrt(d)
+	t.Quo(x, t)
+	return atanKernel(t, w)
+}
+
+// acosKernel returns acos(x), for |x| < 1.
+func acosKernel(x *big.Float, w uint) *big.Float {
+	// acos(x) = 2 atan(sqrt((1 - x) / (1 + x)))
+	wp := w + 16
+	p := wp
+	if x.Prec()+2 > p {
+		p = x.Prec() + 2
+	}
+	t := newFloat(wp).Quo(newFloat(p).Sub(floatOne, x), newFloat(p).Add(floatOne, x))
+	t.Sqrt(t)
+	y := atanKernel(t, w)
+	return y.SetMantExp(y, 1)
+}
+
+// sinhKernel returns sinh(x), for finite nonzero x with |x| < 2**31.
+func sinhKernel(x *big.Float, w uint) *big.Float {
+	// sinh(|x|) = (E + E/(E+1)) / 2 with E = expm1(|x|), which does not
+	// cancel
+	wp := w + 8
+	e := expm1Kernel(new(big.Float).Abs(x), wp)
+	if e.IsInf() {
+		return e.Neg(e)
+	}
+	t := newFloat(wp).Add(e, floatOne)
+	t.Quo(e, t)
+	t.Add(t, e)
+	if x.Sign() < 0 {
+		t.Neg(t)
+	}
+	return newFloat(w).SetMantExp(t, -1)
+}
+
+// coshKernel returns cosh(x), for finite x with |x| < 2**31.
+func coshKernel(x *big.Float, w uint) *big.Float {
+	wp := w + 8
+	e := expKernel(new(big.Float).Abs(x), wp)
+	t := newFloat(wp).Quo(floatOne, e)
+	t.Add(t, e)
+	return newFloat(w).SetMantExp(t, -1)
+}
+
+// tanhKernel returns tanh(x), for finite nonzero x with |x| < 2**30.
+func tanhKernel(x *big.Float, w uint) *big.Float {
+	// tanh(|x|) = E / (E + 2) with E = expm1(2|x|)
+	wp := w + 8
+	a := new(big.Float).Abs(x)
+	e := expm1Kernel(a.SetMantExp(a, 1), wp)
+	t := newFloat(wp).Add(e, floatTwo)
+	t.Quo(e, t)
+	if x.Sign() < 0 {
+		t.Neg(t)
+	}
+	return newFloat(w).Set(t)
+}
+
+// asinhKernel returns asinh(x), for finite nonzero x.
+func asinhKernel(x *big.Float, w uint) *big.Float {
+	wp := w + 8
+	a := new(big.Float).Abs(x)
+	var y *big.Float
+	if expo(a) > int(w)/2+4 {
+		// asinh(a) = log(2a) + 1/(4a**2) - ..., and the rest is negligible
+		y = logKernel(a.SetMantExp(a, 1), wp)
+	} else {
+		// asinh(a) = log1p(a + a**2 / (1 + sqrt(1 + a**2)))
+		a2 := newFloat(wp).Mul(a, a)
+		t := newFloat(wp).Add(a2, floatOne)
+		t.Sqrt(t)
+		t.Add(t, floatOne)
+		t.Quo(a2, t)
+		t.Add(t, a)
+		y = log1pKernel(t, wp)
+	}
+	if x.Sign() < 0 {
+		y.Neg(y)
+	}
+	return newFloat(w).Set(y)
+}
+
+// acoshKernel returns acosh(x), for finite x > 1.
+func acoshKernel(x *big.Float, w uint) *big.Float {
+	wp := w + 8
+	if expo(x) > int(w)/2+4 {
+		return logKernel(new(big.Float).SetMantExp(x, 1), w)
+	}
+	// acosh(x) = log1p((x - 1) + sqrt((x - 1)(x + 1)))
+	p := wp
+	if x.Prec()+2 > p {
+		p = x.Prec() + 2
+	}
+	d := newFloat(p).Sub(x, floatOne)
+	t := newFloat(wp).Add(x, floatOne)
+	t.Mul(t, d)
+	t.Sqrt(t)
+	t.Add(t, d)
+	return log1pKernel(t, w)
+}
+
+// atanhKernel returns atanh(x), for nonzero x with |x| < 1.
+func atanhKernel(x *big.Float, w uint) *big.Float {
+	// atanh(|x|) = log1p(2|x| / (1 - |x|)) / 2
+	wp := w + 8
+	a := new(big.Float).Abs(x)
+	p := wp
+	if x.Prec()+2 > p {
+		p = x.Prec() + 2
+	}
+	t := newFloat(p).Sub(floatOne, a)
+	t = newFloat(wp).Quo(a, t)
+	y := log1pKernel(t.SetMantExp(t, 1), wp)
+	if x.Sign() < 0 {
+		y.Neg(y)
+	}
+	return newFloat(w).SetMantExp(y, -1)
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// cmpAbs compares |x| and |y|.
+func cmpAbs(x, y *big.Float) int {
+	return new(big.Float).Abs(x).Cmp(new(big.Float).Abs(y))
+}
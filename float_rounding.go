@@ -0,0 +1,270 @@
+package mathx
+
+// This file is for rounding a Float to an integer, to a precision or to a
+// number of decimal places, and for the operations built on them. Results
+// that are integers or exact are returned with the precision and rounding
+// mode of the receiver (or 64 bits if the receiver has precision 0), and
+// follow the conventions of the math package for infinities and signed
+// zeros. Where the math package returns NaN, these panic with ErrNaN.
+
+import "math/big"
+
+// Round returns z rounded to an integer with the rounding mode: to nearest
+// with ties to even or away from zero, toward zero, away from zero, or
+// toward -Inf or +Inf. The result is exact, and has the sign of z if it
+// is zero. Round(±Inf) = ±Inf.
+func (z *Float) Round(mode big.RoundingMode) *Float {
+	x := (*big.Float)(z)
+	if x.IsInf() || x.IsInt() {
+		return z.same()
+	}
+	neg := x.Sign() < 0
+	if e := expo(x); e > 0 {
+		// e bits are exactly the integer part of x
+		return z.exactResult(newFloat(uint(e)).SetMode(mode).Set(x))
+	}
+	// 0 < |x| < 1, so the result is 0 or ±1
+	up := false
+	switch mode {
+	case big.ToNearestEven:
+		up = expo(x) == 0 && cmpAbs(x, floatHalf) > 0
+	case big.ToNearestAway:
+		up = expo(x) == 0
+	case big.AwayFromZero:
+		up = true
+	case big.ToNegativeInf:
+		up = neg
+	case big.ToPositiveInf:
+		up = !neg
+	}
+	if !up {
+		return z.zero(neg)
+	}
+	if neg {
+		return z.exactResult(big.NewFloat(-1))
+	}
+	return z.exactResult(floatOne)
+}
+
+// Floor returns the greatest integer that is at most z. Floor(±0) = ±0,
+// Floor(±Inf) = ±Inf, and Floor(-0.5) = -1.
+func (z *Float) Floor() *Float {
+	return z.Round(big.ToNegativeInf)
+}
+
+// Ceil returns the least integer that is at least z. Ceil(±0) = ±0,
+// Ceil(±Inf) = ±Inf, and Ceil(-0.5) = -0.
+func (z *Float) Ceil() *Float {
+	return z.Round(big.ToPositiveInf)
+}
+
+// Trunc returns the integer part of z, rounding toward zero. Trunc(±0) =
+// ±0, Trunc(±Inf) = ±Inf, and Trunc(-0.5) = -0.
+func (z *Float) Trunc() *Float {
+	return z.Round(big.ToZero)
+}
+
+// Frac returns the fractional part of z, z - z.Trunc(), which has the sign
+// of z and is exact, as math.Modf does. Frac panics with ErrNaN if z is
+// infinite.
+func (z *Float) Frac() *Float {
+	x := (*big.Float)(z)
+	if x.IsInf() {
+		panic(ErrNaN)
+	}
+	if x.IsInt() {
+		return z.zero(x.Signbit())
+	}
+	t := z.Trunc()
+	return z.exactResult(newFloat(x.Prec()).Sub(x, (*big.Float)(t)))
+}
+
+// RoundToPrec returns z rounded to prec bits with the rounding mode. As
+// for big.Float.SetPrec, a prec of 0 rounds finite values to ±0.
+func (z *Float) RoundToPrec(prec uint, mode big.RoundingMode) *Float {
+	// Set would adopt the precision of z if prec were 0, so copy z exactly
+	// and then round
+	f := new(big.Float).SetMode(mode).Set((*big.Float)(z))
+	return (*Float)(f.SetPrec(prec))
+}
+
+// RoundToDecimalPlaces returns the multiple of 10**-n nearest to z in the
+// rounding mode of z, for example to cents with n = 2 and a mode of
+// big.ToNearestAway; n may be negative. The decimal result is then
+// rounded to the precision of z in the same mode, so it is exact only if
+// it is a binary fraction. A zero result has the sign of z, and
+// RoundToDecimalPlaces(±Inf) = ±Inf.
+func (z *Float) RoundToDecimalPlaces(n int) *Float {
+	x := (*big.Float)(z)
+	if x.IsInf() || x.Sign() == 0 {
+		return z.same()
+	}
+	r, _ := x.Rat(nil)
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absInt(n))), nil)
+	num, den := r.Num(), r.Denom()
+	if n >= 0 {
+		num = new(big.Int).Mul(num, p)
+	} else {
+		den = new(big.Int).Mul(den, p)
+	}
+	q := roundQuo(num, den, z.Mode())
+	if q.Sign() == 0 {
+		return z.zero(x.Signbit())
+	}
+	d := new(big.Rat).SetInt(q)
+	if n >= 0 {
+		d.Quo(d, new(big.Rat).SetInt(p))
+	} else {
+		d.Mul(d, new(big.Rat).SetInt(p))
+	}
+	return (*Float)(newFloat(z.precision()).SetMode(z.Mode()).SetRat(d))
+}
+
+// roundQuo returns num/den rounded to an integer with the mode, for den >
+// 0.
+func roundQuo(num, den *big.Int, mode big.RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	up := false
+	switch mode {
+	case big.ToNearestEven, big.ToNearestAway:
+		c := new(big.Int).Lsh(new(big.Int).Abs(r), 1).Cmp(den)
+		up = c > 0 || c == 0 && (mode == big.ToNearestAway || q.Bit(0) == 1)
+	case big.AwayFromZero:
+		up = true
+	case big.ToNegativeInf:
+		up = num.Sign() < 0
+	case big.ToPositiveInf:
+		up = num.Sign() > 0
+	}
+	if up {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+	return q
+}
+
+// smallestFloat returns the least positive Float with the precision.
+func smallestFloat(prec uint) *big.Float {
+	return newFloat(prec).SetMantExp(floatHalf, big.MinExp)
+}
+
+// NextUp returns the least Float with the precision of z that is greater
+// than z. NextUp(±0) is the least positive Float, NextUp(+Inf) = +Inf,
+// and NextUp(-Inf) is the most negative finite Float.
+func (z *Float) NextUp() *Float {
+	x := (*big.Float)(z)
+	prec := z.precision()
+	switch {
+	case x.Sign() == 0:
+		return (*Float)(smallestFloat(prec).SetMode(z.Mode()))
+	case x.IsInf() && x.Sign() > 0:
+		return z.same()
+	case x.IsInf():
+		// -(1 - 2**-prec) 2**MaxExp
+		m := newFloat(prec).Sub(floatOne, pow2(-int(prec)))
+		return (*Float)(m.SetMantExp(m, big.MaxExp).Neg(m).SetMode(z.Mode()))
+	}
+	// x = m 2**(e-prec) for an integer m with prec bits, and the next Float
+	// up is m+1 at the same exponent, except below a negative power of
+	// two, where the gap halves
+	e := expo(x) - int(prec)
+	m, _ := newFloat(prec).SetMantExp(x, -e).Int(nil)
+	if m.Sign() < 0 && new(big.Int).Neg(m).Cmp(new(big.Int).Lsh(bigOne, prec-1)) == 0 {
+		m.Lsh(m, 1)
+		e--
+	}
+	y := newFloat(prec).SetInt(m.Add(m, bigOne))
+	// underflow gives -0 and overflow gives +Inf, as they should
+	return (*Float)(y.SetMantExp(y, e).SetMode(z.Mode()))
+}
+
+// NextDown returns the greatest Float with the precision of z that is
+// less than z. NextDown(±0) is the greatest negative Float,
+// NextDown(-Inf) = -Inf, and NextDown(+Inf) is the greatest finite Float.
+func (z *Float) NextDown() *Float {
+	y := (*big.Float)(z.Neg().NextUp())
+	return (*Float)(y.Neg(y).SetMode(z.Mode()))
+}
+
+// Ulp returns the unit in the last place of z at its precision, the gap
+// between |z| and the next Float away from zero. Ulp(±0) is the least
+// positive Float and Ulp(±Inf) = +Inf.
+func (z *Float) Ulp() *Float {
+	x := (*big.Float)(z)
+	prec := z.precision()
+	switch {
+	case x.Sign() == 0:
+		return (*Float)(smallestFloat(prec).SetMode(z.Mode()))
+	case x.IsInf():
+		return z.inf(false)
+	}
+	return z.exactResult(pow2(expo(x) - int(prec)))
+}
+
+// Mod returns the remainder of z/y truncated toward zero, z - n y for the
+// integer n = (z/y).Trunc(), as math.Mod does. The result has the sign of
+// z and is less than |y| in magnitude, and it is rounded to the precision
+// and mode of z, which it fits in unless y has a lower bit than z. Mod(z,
+// ±Inf) = z, and Mod panics with ErrNaN if z is infinite or y is zero.
+func (z *Float) Mod(y *Float) *Float {
+	return z.remainder(y, false)
+}
+
+// Remainder returns the IEEE 754 remainder of z/y, z - n y for the integer
+// n nearest to z/y, with ties to even, as math.Remainder does. The result
+// is at most |y|/2 in magnitude, is rounded as for Mod, and has the sign
+// of z if it is zero. Remainder(z, ±Inf) = z, and Remainder panics with
+// ErrNaN if z is infinite or y is zero.
+func (z *Float) Remainder(y *Float) *Float {
+	return z.remainder(y, true)
+}
+
+// remainder returns Mod, or Remainder if nearest is set.
+func (z *Float) remainder(y *Float, nearest bool) *Float {
+	x, yb := (*big.Float)(z), (*big.Float)(y)
+	switch {
+	case x.IsInf() || yb.Sign() == 0:
+		panic(ErrNaN)
+	case yb.IsInf() || x.Sign() == 0:
+		return z.same()
+	}
+	// with l the lower of the lowest bits, |x| = mx 2**ex and |y| = my
+	// for integers mx and my, after scaling by 2**-l
+	l := lsb(x)
+	if ly := lsb(yb); ly < l {
+		l = ly
+	}
+	my := intPart(yb, l)
+	mx := intPart(x, lsb(x))
+	ex := lsb(x) - l
+	// r = |x| mod 2|y|, which also gives the parity of the quotient, with
+	// 2**ex reduced first so that huge x are cheap
+	m2 := new(big.Int).Lsh(my, 1)
+	r := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(ex)), m2)
+	r.Mul(r, mx).Mod(r, m2)
+	odd := r.Cmp(my) >= 0
+	if odd {
+		r.Sub(r, my)
+	}
+	if nearest {
+		if c := new(big.Int).Lsh(r, 1).Cmp(my); c > 0 || c == 0 && odd {
+			r.Sub(r, my)
+		}
+	}
+	if r.Sign() == 0 {
+		return z.zero(x.Signbit())
+	}
+	if x.Signbit() {
+		r.Neg(r)
+	}
+	f := new(big.Float).SetInt(r)
+	return z.exactResult(f.SetMantExp(f, l))
+}
+
+// intPart returns |x| 2**-l as an Int, for l at most the lowest bit of x.
+func intPart(x *big.Float, l int) *big.Int {
+	i, _ := new(big.Float).SetMantExp(x, -l).Int(nil)
+	return i.Abs(i)
+}
@@ -0,0 +1,272 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// sameFloat64 returns whether f is want, with the same sign if it is zero.
+func sameFloat64(f *Float, want float64) bool {
+	got, _ := f.Float64()
+	return got == want && math.Signbit(got) == math.Signbit(want)
+}
+
+// randFloat64 returns a float64 whose magnitude is around 2**-4 to 2**12,
+// often an integer or half an integer.
+func randFloat64(rnd *rand.Rand) float64 {
+	x := math.Ldexp(rnd.Float64(), rnd.Intn(16)-4)
+	switch rnd.Intn(4) {
+	case 0:
+		x = math.Floor(x)
+	case 1:
+		x = math.Floor(x) + 0.5
+	}
+	if rnd.Intn(2) == 0 {
+		x = -x
+	}
+	return x
+}
+
+func TestFloatRoundFloat64(t *testing.T) {
+	funcs := map[string][2]func(float64) float64{
+		"Floor":       {math.Floor, func(x float64) float64 { f, _ := NewFloat(x).Floor().Float64(); return f }},
+		"Ceil":        {math.Ceil, func(x float64) float64 { f, _ := NewFloat(x).Ceil().Float64(); return f }},
+		"Trunc":       {math.Trunc, func(x float64) float64 { f, _ := NewFloat(x).Trunc().Float64(); return f }},
+		"RoundAway":   {math.Round, func(x float64) float64 { f, _ := NewFloat(x).Round(big.ToNearestAway).Float64(); return f }},
+		"RoundToEven": {math.RoundToEven, func(x float64) float64 { f, _ := NewFloat(x).Round(big.ToNearestEven).Float64(); return f }},
+		"Frac": {func(x float64) float64 { _, f := math.Modf(x); return f },
+			func(x float64) float64 { f, _ := NewFloat(x).Frac().Float64(); return f }},
+		"NextUp": {func(x float64) float64 { return math.Nextafter(x, math.Inf(1)) },
+			func(x float64) float64 { f, _ := NewFloat(x).NextUp().Float64(); return f }},
+		"NextDown": {func(x float64) float64 { return math.Nextafter(x, math.Inf(-1)) },
+			func(x float64) float64 { f, _ := NewFloat(x).NextDown().Float64(); return f }},
+	}
+	specials := []float64{0, math.Copysign(0, -1), 0.5, -0.5, 1.5, -2.5, 0.25, -0.75, 1, -1, 0.5000000000000001, math.Ldexp(1, 60) + 2048}
+	for name, fs := range funcs {
+		rnd := rand.New(rand.NewSource(5))
+		xs := append([]float64{}, specials...)
+		for i := 0; i < 200; i++ {
+			xs = append(xs, randFloat64(rnd))
+		}
+		for _, x := range xs {
+			if (name == "NextUp" || name == "NextDown") && x == 0 {
+				continue
+			}
+			want, got := fs[0](x), fs[1](x)
+			if got != want || math.Signbit(got) != math.Signbit(want) {
+				t.Errorf("%s(%v) = %v, expected %v", name, x, got, want)
+			}
+		}
+	}
+}
+
+func TestFloatRoundModes(t *testing.T) {
+	cases := []struct {
+		x                                    float64
+		even, away, zero, awayZero, neg, pos float64
+	}{
+		{2.5, 2, 3, 2, 3, 2, 3},
+		{-2.5, -2, -3, -2, -3, -3, -2},
+		{3.5, 4, 4, 3, 4, 3, 4},
+		{0.5, 0, 1, 0, 1, 0, 1},
+		{-0.25, math.Copysign(0, -1), math.Copysign(0, -1), math.Copysign(0, -1), -1, -1, math.Copysign(0, -1)},
+		{0.75, 1, 1, 0, 1, 0, 1},
+		{7, 7, 7, 7, 7, 7, 7},
+	}
+	for _, c := range cases {
+		wants := map[big.RoundingMode]float64{
+			big.ToNearestEven: c.even, big.ToNearestAway: c.away, big.ToZero: c.zero,
+			big.AwayFromZero: c.awayZero, big.ToNegativeInf: c.neg, big.ToPositiveInf: c.pos,
+		}
+		for mode, want := range wants {
+			got := NewFloat(c.x).Round(mode)
+			if !sameFloat64(got, want) || got.Prec() != 53 {
+				t.Errorf("Round(%v, %v) = %v (%d bits), expected %v", c.x, mode, got, got.Prec(), want)
+			}
+		}
+	}
+	// a result that carries into a new power of two still fits
+	x := NewFloat(7.5).SetPrec(4)
+	if got := x.Round(big.ToNearestEven); !sameFloat64(got, 8) || got.Acc() != big.Exact {
+		t.Errorf("Round(7.5) = %v (%v)", got, got.Acc())
+	}
+}
+
+func TestFloatRoundToDecimalPlaces(t *testing.T) {
+	cases := []struct {
+		x    string
+		n    int
+		mode big.RoundingMode
+		want string
+	}{
+		{"2.625", 2, big.ToNearestEven, "2.62"},
+		{"2.625", 2, big.ToNearestAway, "2.63"},
+		{"-2.625", 2, big.ToNearestAway, "-2.63"},
+		{"2.665", 2, big.ToZero, "2.66"},
+		{"2.665", 2, big.AwayFromZero, "2.67"},
+		{"1234.5", -2, big.ToNearestEven, "1200"},
+		{"1250", -2, big.ToNearestEven, "1200"},
+		{"1250", -2, big.ToNearestAway, "1300"},
+		{"0.125", 2, big.ToNearestEven, "0.12"},
+		{"0.001", 2, big.ToPositiveInf, "0.01"},
+		{"-0.001", 2, big.ToNegativeInf, "-0.01"},
+		{"-0.001", 2, big.ToZero, "-0"},
+		{"19.999", 0, big.ToZero, "19"},
+	}
+	for _, c := range cases {
+		x, _, _ := ParseFloat(c.x, 10, 200, c.mode)
+		got := x.RoundToDecimalPlaces(c.n)
+		want, _, _ := ParseFloat(c.want, 10, 200, c.mode)
+		if got.Cmp(want) != 0 || got.Signbit() != want.Signbit() || got.Prec() != 200 {
+			t.Errorf("RoundToDecimalPlaces(%s, %d, %v) = %v, expected %v", c.x, c.n, c.mode, got, want)
+		}
+	}
+	// 2.675 as a float64 is below the tie, as in strconv
+	if got := NewFloat(2.675).RoundToDecimalPlaces(2); !sameFloat64(got, 2.67) {
+		t.Errorf("RoundToDecimalPlaces(2.675, 2) = %v, expected 2.67", got)
+	}
+}
+
+func TestFloatRoundToPrec(t *testing.T) {
+	x := NewFloat(1.0 / 3)
+	for _, prec := range []uint{1, 2, 10, 24, 53, 100} {
+		for _, mode := range []big.RoundingMode{big.ToNearestEven, big.ToZero, big.ToPositiveInf} {
+			got := x.RoundToPrec(prec, mode)
+			want := new(big.Float).SetPrec(prec).SetMode(mode).Set((*big.Float)(x))
+			if got.Cmp((*Float)(want)) != 0 || got.Prec() != prec || got.Mode() != mode || got.Acc() != want.Acc() {
+				t.Errorf("RoundToPrec(1/3, %d, %v) = %v, expected %v", prec, mode, got, want)
+			}
+		}
+	}
+	if f32, _ := x.RoundToPrec(24, big.ToNearestEven).Float64(); f32 != float64(float32(1.0/3)) {
+		t.Errorf("RoundToPrec(1/3, 24) = %v", f32)
+	}
+	for _, v := range []float64{1.0 / 3, -1.0 / 3} {
+		got := NewFloat(v).RoundToPrec(0, big.ToPositiveInf)
+		if got.Sign() != 0 || got.Signbit() != (v < 0) || got.Prec() != 0 || got.Mode() != big.ToPositiveInf {
+			t.Errorf("RoundToPrec(%v, 0) = %v (%d bits), expected a signed zero", v, got, got.Prec())
+		}
+	}
+	if got := Inf(true).RoundToPrec(0, big.ToZero); !got.IsInf() || got.Sign() > 0 {
+		t.Errorf("RoundToPrec(-Inf, 0) = %v", got)
+	}
+}
+
+func TestFloatNextUlp(t *testing.T) {
+	x := NewFloat(1).SetPrec(10)
+	if got := x.NextUp(); !sameFloat64(got, 1+1.0/512) || got.Prec() != 10 {
+		t.Errorf("NextUp(1) = %v", got)
+	}
+	if got := x.NextDown(); !sameFloat64(got, 1-1.0/1024) {
+		t.Errorf("NextDown(1) = %v", got)
+	}
+	if got := x.Neg().NextUp(); !sameFloat64(got, -1+1.0/1024) {
+		t.Errorf("NextUp(-1) = %v", got)
+	}
+	if got := x.Ulp(); !sameFloat64(got, 1.0/512) {
+		t.Errorf("Ulp(1) = %v", got)
+	}
+	if got := NewFloat(-0.75).Ulp(); !sameFloat64(got, math.Ldexp(1, -53)) {
+		t.Errorf("Ulp(-0.75) = %v", got)
+	}
+	for _, f := range []float64{1.5, -3.25, 1e300, -1e-300} {
+		want := math.Nextafter(math.Abs(f), math.Inf(1)) - math.Abs(f)
+		if got := NewFloat(f).Ulp(); !sameFloat64(got, want) {
+			t.Errorf("Ulp(%v) = %v, expected %v", f, got, want)
+		}
+	}
+
+	// at the ends of the exponent range
+	tiny := NewFloat(0).SetPrec(8).NextUp()
+	if _, e := tiny.MantExp(); e != big.MinExp || tiny.Sign() <= 0 {
+		t.Errorf("NextUp(0) = %v", tiny)
+	}
+	if got := tiny.NextDown(); got.Sign() != 0 || got.Signbit() {
+		t.Errorf("NextDown(NextUp(0)) = %v", got)
+	}
+	if got := tiny.Neg().NextUp(); got.Sign() != 0 || !got.Signbit() {
+		t.Errorf("NextUp(-NextUp(0)) = %v", got)
+	}
+	if got := NewFloat(0).SetPrec(8).NextDown(); got.Cmp(tiny.Neg()) != 0 {
+		t.Errorf("NextDown(0) = %v", got)
+	}
+	huge := Inf(false).SetPrec(8).NextDown()
+	if _, e := huge.MantExp(); e != big.MaxExp || huge.IsInf() || huge.MinPrec() != 8 {
+		t.Errorf("NextDown(+Inf) = %v", huge)
+	}
+	if got := huge.NextUp(); !got.IsInf() || got.Sign() < 0 {
+		t.Errorf("NextUp(NextDown(+Inf)) = %v", got)
+	}
+	if got := Inf(true).SetPrec(8).NextUp(); got.Cmp(huge.Neg()) != 0 {
+		t.Errorf("NextUp(-Inf) = %v", got)
+	}
+	if got := Inf(true).NextDown(); !got.IsInf() || got.Sign() > 0 {
+		t.Errorf("NextDown(-Inf) = %v", got)
+	}
+	if got := Inf(true).Ulp(); !got.IsInf() || got.Sign() < 0 {
+		t.Errorf("Ulp(-Inf) = %v", got)
+	}
+}
+
+func TestFloatModRemainder(t *testing.T) {
+	rnd := rand.New(rand.NewSource(6))
+	ys := []float64{1, -1, 0.5, 3, -2.5, 0.1, 1e-3, math.Inf(1), math.Inf(-1)}
+	xs := []float64{0, math.Copysign(0, -1), 7, -7, 5.5, -5.5, 2.5, 1e300, -1e-300, 0.3}
+	for i := 0; i < 50; i++ {
+		xs = append(xs, randFloat64(rnd))
+		ys = append(ys, randFloat64(rnd))
+	}
+	for _, x := range xs {
+		for _, y := range ys {
+			if y == 0 {
+				continue
+			}
+			if got := NewFloat(x).Mod(NewFloat(y)); !sameFloat64(got, math.Mod(x, y)) {
+				t.Errorf("Mod(%v, %v) = %v, expected %v", x, y, got, math.Mod(x, y))
+			}
+			if got := NewFloat(x).Remainder(NewFloat(y)); !sameFloat64(got, math.Remainder(x, y)) {
+				t.Errorf("Remainder(%v, %v) = %v, expected %v", x, y, got, math.Remainder(x, y))
+			}
+		}
+	}
+	// an exponent too large to expand into an Int
+	x := NewFloat(1).SetExp(1 << 30)
+	want := new(big.Int).Exp(big.NewInt(2), big.NewInt(1<<30), big.NewInt(1000003))
+	if got := x.Mod(NewFloat(1000003)); got.CmpInt((*Int)(want)) != 0 {
+		t.Errorf("Mod(2**(2**30), 1000003) = %v, expected %v", got, want)
+	}
+}
+
+func TestFloatRoundingSpecial(t *testing.T) {
+	inf, negInf := Inf(false), Inf(true)
+	check := func(name string, got *Float, want float64) {
+		if !sameFloat64(got, want) {
+			t.Errorf("%s = %v, expected %v", name, got, want)
+		}
+	}
+	check("Floor(-Inf)", negInf.Floor(), math.Inf(-1))
+	check("Round(+Inf)", inf.Round(big.ToNearestAway), math.Inf(1))
+	check("RoundToDecimalPlaces(-Inf)", negInf.RoundToDecimalPlaces(2), math.Inf(-1))
+	check("Mod(3, +Inf)", NewFloat(3).Mod(inf), 3)
+	check("Remainder(-0, 2)", NewFloat(0).Neg().Remainder(NewFloat(2)), math.Copysign(0, -1))
+	check("Frac(-3)", NewFloat(-3).Frac(), math.Copysign(0, -1))
+	nans := map[string]func(){
+		"Frac(Inf)":          func() { inf.Frac() },
+		"Mod(Inf, 1)":        func() { inf.Mod(NewFloat(1)) },
+		"Mod(1, 0)":          func() { NewFloat(1).Mod(NewFloat(0)) },
+		"Remainder(-Inf, 1)": func() { negInf.Remainder(NewFloat(1)) },
+		"Remainder(1, -0)":   func() { NewFloat(1).Remainder(NewFloat(0).Neg()) },
+	}
+	for name, f := range nans {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,102 @@
+package mathx
+
+// This file is for arithmetic between a Float and a float64, int64, or
+// Int. The other argument is converted exactly, and the result is rounded
+// to the precision and rounding mode of this, so that, for example,
+// x.AddFloat64(0.1) has the precision of x. If this has precision 0 (it
+// is a zero value), the precision of the converted argument is used.
+
+import "math/big"
+
+// result returns a new big.Float with the precision and mode of this, for
+// the result of an operation.
+func (z *Float) result() *big.Float {
+	return new(big.Float).SetPrec(z.Prec()).SetMode(z.Mode())
+}
+
+// bigFloat64 returns x as a big.Float. It panics with ErrNaN if x is NaN.
+func bigFloat64(x float64) *big.Float {
+	if x != x {
+		panic(ErrNaN)
+	}
+	return new(big.Float).SetFloat64(x)
+}
+
+// AddFloat64 returns this plus the argument.
+func (z *Float) AddFloat64(y float64) *Float {
+	return (*Float)(z.result().Add((*big.Float)(z), bigFloat64(y)))
+}
+
+// AddInt64 returns this plus the argument.
+func (z *Float) AddInt64(y int64) *Float {
+	return (*Float)(z.result().Add((*big.Float)(z), new(big.Float).SetInt64(y)))
+}
+
+// AddInt returns this plus the argument.
+func (z *Float) AddInt(y *Int) *Float {
+	return (*Float)(z.result().Add((*big.Float)(z), (*big.Float)(FloatFromInt(y))))
+}
+
+// SubFloat64 returns this minus the argument.
+func (z *Float) SubFloat64(y float64) *Float {
+	return (*Float)(z.result().Sub((*big.Float)(z), bigFloat64(y)))
+}
+
+// SubInt64 returns this minus the argument.
+func (z *Float) SubInt64(y int64) *Float {
+	return (*Float)(z.result().Sub((*big.Float)(z), new(big.Float).SetInt64(y)))
+}
+
+// SubInt returns this minus the argument.
+func (z *Float) SubInt(y *Int) *Float {
+	return (*Float)(z.result().Sub((*big.Float)(z), (*big.Float)(FloatFromInt(y))))
+}
+
+// MulFloat64 returns this times the argument.
+func (z *Float) MulFloat64(y float64) *Float {
+	return (*Float)(z.result().Mul((*big.Float)(z), bigFloat64(y)))
+}
+
+// MulInt64 returns this times the argument.
+func (z *Float) MulInt64(y int64) *Float {
+	return (*Float)(z.result().Mul((*big.Float)(z), new(big.Float).SetInt64(y)))
+}
+
+// MulInt returns this times the argument.
+func (z *Float) MulInt(y *Int) *Float {
+	return (*Float)(z.result().Mul((*big.Float)(z), (*big.Float)(FloatFromInt(y))))
+}
+
+// QuoFloat64 returns this divided by the argument. Like Quo, it panics
+// with big.ErrNaN for 0/0 and Inf/Inf.
+func (z *Float) QuoFloat64(y float64) *Float {
+	return (*Float)(z.result().Quo((*big.Float)(z), bigFloat64(y)))
+}
+
+// QuoInt64 returns this divided by the argument. Like Quo, it panics with
+// big.ErrNaN for 0/0.
+func (z *Float) QuoInt64(y int64) *Float {
+	return (*Float)(z.result().Quo((*big.Float)(z), new(big.Float).SetInt64(y)))
+}
+
+// QuoInt returns this divided by the argument. Like Quo, it panics with
+// big.ErrNaN for 0/0.
+func (z *Float) QuoInt(y *Int) *Float {
+	return (*Float)(z.result().Quo((*big.Float)(z), (*big.Float)(FloatFromInt(y))))
+}
+
+// CmpFloat64 compares this to the argument, returning -1, 0, or +1. It
+// panics with ErrNaN if the argument is NaN.
+func (z *Float) CmpFloat64(y float64) int {
+	return (*big.Float)(z).Cmp(bigFloat64(y))
+}
+
+// CmpInt64 compares this to the argument, returning -1, 0, or +1.
+func (z *Float) CmpInt64(y int64) int {
+	return (*big.Float)(z).Cmp(new(big.Float).SetInt64(y))
+}
+
+// CmpInt compares this to the argument, returning -1, 0, or +1.
+func (z *Float) CmpInt(y *Int) int {
+	return (*big.Float)(z).Cmp((*big.Float)(FloatFromInt(y)))
+}
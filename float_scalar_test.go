@@ -0,0 +1,63 @@
+package mathx
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFloatScalar(t *testing.T) {
+	x := NewFloat(1.5)
+	cases := []struct {
+		got  *Float
+		want float64
+	}{
+		{x.AddFloat64(2.25), 3.75},
+		{x.AddInt64(-4), -2.5},
+		{x.AddInt(NewInt(10)), 11.5},
+		{x.SubFloat64(0.5), 1},
+		{x.SubInt64(2), -0.5},
+		{x.SubInt(NewInt(-1)), 2.5},
+		{x.MulFloat64(-2), -3},
+		{x.MulInt64(4), 6},
+		{x.MulInt(NewInt(3)), 4.5},
+		{x.QuoFloat64(0.5), 3},
+		{x.QuoInt64(-3), -0.5},
+		{x.QuoInt(NewInt(6)), 0.25},
+	}
+	for i, c := range cases {
+		if f, _ := c.got.Float64(); f != c.want || c.got.Prec() != 53 {
+			t.Errorf("case %d: got %v (prec %d), expected %v", i, c.got, c.got.Prec(), c.want)
+		}
+	}
+	if x.CmpFloat64(1.5) != 0 || x.CmpFloat64(1.25) != 1 || x.CmpInt64(2) != -1 || x.CmpInt(NewInt(1)) != 1 {
+		t.Errorf("comparisons to 1.5 are wrong")
+	}
+
+	// results are rounded to the precision and mode of this
+	third := FloatFromInt64(1).SetPrec(200).SetMode(big.ToZero).QuoInt64(3)
+	if third.Prec() != 200 || third.Mode() != big.ToZero {
+		t.Errorf("1/3 has prec %d and mode %v", third.Prec(), third.Mode())
+	}
+	if back := third.MulInt64(3); back.CmpInt64(1) != -1 {
+		t.Errorf("3 * (1/3 rounded toward zero) = %v, should be less than 1", back)
+	}
+	huge := NewInt(1).Lsh(300).Add64(1)
+	if got := FloatFromInt64(0).SetPrec(400).AddInt(huge); got.CmpInt(huge) != 0 {
+		t.Errorf("0 + 2^300 + 1 = %v at 400 bits", got)
+	}
+	if got := NewFloat(0).AddInt(huge); got.CmpInt(huge) != -1 {
+		t.Errorf("0 + 2^300 + 1 at 53 bits should round down, got %v", got.Text('g', -1))
+	}
+	// a zero value takes the precision of the argument
+	if got := new(Float).AddInt(huge); got.CmpInt(huge) != 0 {
+		t.Errorf("zero value + 2^300 + 1 = %v", got)
+	}
+
+	defer func() {
+		if r := recover(); r != ErrNaN {
+			t.Errorf("AddFloat64(NaN) panicked with %v", r)
+		}
+	}()
+	nan := 0.0
+	x.AddFloat64(nan / nan)
+}
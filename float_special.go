@@ -0,0 +1,480 @@
+package mathx
+
+// This file is for the special functions on Float. Like the elementary
+// functions, each is correctly rounded to the precision and rounding mode
+// of its receiver (or of its Float argument, for the functions of an
+// integer order), or to 64 bits if that has precision 0. The error bounds
+// of the approximations are documented with their kernels; ziv only
+// rounds once the bound puts the result on one side of every rounding
+// boundary, so the bounds are what make the results correct.
+
+import (
+	"math"
+	"math/big"
+)
+
+// Gamma returns the Gamma function of z. Gamma(+Inf) = +Inf and
+// Gamma(±0) = ±Inf, and Gamma panics with ErrNaN for -Inf and the negative
+// integers, where math.Gamma returns NaN. Results beyond the exponent
+// range of big.Float are ±Inf or ±0, as for big.Float arithmetic.
+func (z *Float) Gamma() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf() && x.Signbit():
+		panic(ErrNaN)
+	case x.IsInf():
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.inf(x.Signbit())
+	case isNonPositiveInt(x):
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	if n, ok := smallInt(x); ok && n <= 4*int64(prec)+64 {
+		// (n-1)! exactly; beyond this it has too many bits to be exact
+		f := new(big.Int).MulRange(1, n-1)
+		return z.exactResult(new(big.Float).SetInt(f))
+	}
+	if expo(x) > 27 {
+		if x.Sign() > 0 {
+			return overflow(prec, mode, false)
+		}
+		return underflow(prec, mode, logGammaSign(x) < 0)
+	}
+	if x.MinPrec() == 1 {
+		// Gamma(x) = 1/x - γ + O(x), where 1/x is exact
+		c := new(big.Float).Quo(floatOne, x)
+		if r, ok := nearly(c, -1, 0, prec, mode); ok {
+			return r
+		}
+	}
+	return zivRel(prec, mode, func(w uint) *big.Float {
+		return gammaKernel(x, w)
+	})
+}
+
+// LogGamma returns the natural logarithm of |Gamma(z)| and the sign of
+// Gamma(z), -1 or +1, as math.Lgamma does. LogGamma(1) = LogGamma(2) = +0,
+// the result is +Inf for ±0, +Inf and the negative integers, and -Inf for
+// -Inf.
+func (z *Float) LogGamma() (*Float, int) {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf() && x.Signbit():
+		return z.inf(true), 1
+	case x.IsInf():
+		return z.inf(false), 1
+	case isNonPositiveInt(x):
+		return z.inf(false), 1
+	case x.Cmp(floatOne) == 0 || x.Cmp(floatTwo) == 0:
+		return z.zero(false), 1
+	}
+	r := ziv(z.precision(), z.Mode(), func(w uint) (*big.Float, int) {
+		return logGammaApprox(x, w)
+	})
+	return r, logGammaSign(x)
+}
+
+// Digamma returns the digamma function ψ(z), the derivative of
+// log(Gamma(z)). Digamma(+Inf) = +Inf and Digamma(±0) = ∓Inf, and Digamma
+// panics with ErrNaN for -Inf and the negative integers.
+func (z *Float) Digamma() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf() && x.Signbit():
+		panic(ErrNaN)
+	case x.IsInf():
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.inf(!x.Signbit())
+	case isNonPositiveInt(x):
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	if x.MinPrec() == 1 && expo(x) < 0 {
+		// ψ(x) = -1/x - γ + O(x), where 1/x is exact
+		c := new(big.Float).Quo(big.NewFloat(-1), x)
+		if r, ok := nearly(c, -1, 0, prec, mode); ok {
+			return r
+		}
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		return digammaApprox(x, w)
+	})
+}
+
+// Beta returns the beta function B(z, y) = Gamma(z) Gamma(y) / Gamma(z+y),
+// rounded to the precision and mode of z. It is found exactly, as a
+// rational, when either argument is a small positive integer. Beta is +0
+// when z+y is a pole of Gamma and neither z nor y is, and it panics with
+// ErrNaN if either argument is infinite or a pole of Gamma.
+func (z *Float) Beta(y *Float) *Float {
+	x, yb := (*big.Float)(z), (*big.Float)(y)
+	if x.IsInf() || yb.IsInf() || isNonPositiveInt(x) || isNonPositiveInt(yb) {
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	s := exactAdd(x, yb)
+	if isNonPositiveInt(s) {
+		return z.zero(false)
+	}
+	for _, p := range [][2]*big.Float{{x, yb}, {yb, x}} {
+		if n, ok := smallInt(p[1]); ok && n <= int64(prec)+64 {
+			// B(x, n) = (n-1)! / (x (x+1) ... (x+n-1))
+			r, _ := p[0].Rat(nil)
+			d := big.NewRat(1, 1)
+			t := new(big.Rat)
+			for i := int64(0); i < n; i++ {
+				d.Mul(d, t.Add(r, big.NewRat(i, 1)))
+			}
+			q := new(big.Rat).SetInt(new(big.Int).MulRange(1, n-1))
+			return (*Float)(newFloat(prec).SetMode(mode).SetRat(q.Quo(q, d)))
+		}
+	}
+	sign := logGammaSign(x) * logGammaSign(yb) * logGammaSign(s)
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		// exp(log|Gamma(x)| + log|Gamma(y)| - log|Gamma(x+y)|), where an
+		// error of δ in the logarithm is a relative error of about δ
+		wp := w + 8
+		a, ea := logGammaApprox(x, wp)
+		b, eb := logGammaApprox(yb, wp)
+		c, ec := logGammaApprox(s, wp)
+		l := newFloat(wp).Add(a, b)
+		l.Sub(l, c)
+		e := maxExp(maxExp(ea, eb), ec) + 2
+		if l.Sign() != 0 {
+			e = maxExp(e, expo(l)-int(wp)+1)
+			if expo(l) > 32 {
+				// far beyond the exponent range
+				r := newFloat(w)
+				if l.Sign() > 0 {
+					r.SetInf(sign < 0)
+				} else if sign < 0 {
+					r.Neg(r)
+				}
+				return r, 0
+			}
+		}
+		r := expKernel(l, wp)
+		if sign < 0 {
+			r.Neg(r)
+		}
+		return r, expo(r) + maxExp(e, -int(wp)+2) + 1
+	})
+}
+
+// erfcExp returns an exponent e with erfc(a) < 2**e, for a >= 1.
+func erfcExp(a *big.Float) int {
+	if expo(a) > 500 {
+		return math.MinInt32
+	}
+	f := float64Of(a)
+	// erfc(a) < exp(-a**2) / (a sqrt(pi)) < exp(-a**2)
+	if v := f * f * math.Log2E; v < 1<<30 {
+		return -int(v)
+	}
+	return math.MinInt32
+}
+
+// Erf returns the error function of z. Erf(±0) = ±0 and Erf(±Inf) = ±1.
+func (z *Float) Erf() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf():
+		return z.exactResult(big.NewFloat(float64(x.Sign())))
+	case x.Sign() == 0:
+		return z.same()
+	}
+	prec, mode := z.precision(), z.Mode()
+	sign := x.Sign()
+	a := new(big.Float).Abs(x)
+	if expo(a) <= 0 {
+		return zivRel(prec, mode, func(w uint) *big.Float {
+			y := erfSeries(a, w)
+			if sign < 0 {
+				y.Neg(y)
+			}
+			return y
+		})
+	}
+	// erf(x) = ±(1 - erfc(|x|))
+	if r, ok := nearly(big.NewFloat(float64(sign)), -sign, erfcExp(a), prec, mode); ok {
+		return r
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		y, e := erfcApprox(a, w)
+		r := newFloat(w+8).Sub(floatOne, y)
+		if sign < 0 {
+			r.Neg(r)
+		}
+		return r, maxExp(e, -int(w)-6) + 1
+	})
+}
+
+// Erfc returns the complementary error function of z, 1 - Erf(z), which
+// is accurate where Erf(z) is close to 1. Erfc(+Inf) = 0 and
+// Erfc(-Inf) = 2.
+func (z *Float) Erfc() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf() && x.Signbit():
+		return z.exactResult(floatTwo)
+	case x.IsInf():
+		return z.zero(false)
+	case x.Sign() == 0:
+		return z.exactResult(floatOne)
+	}
+	prec, mode := z.precision(), z.Mode()
+	a := new(big.Float).Abs(x)
+	if x.Sign() < 0 {
+		// erfc(x) = 1 + erf(|x|) = 2 - erfc(|x|)
+		if expo(a) > 0 {
+			if r, ok := nearly(floatTwo, -1, erfcExp(a), prec, mode); ok {
+				return r
+			}
+		}
+		if r, ok := nearly(floatOne, 1, expo(a)+1, prec, mode); ok {
+			return r
+		}
+		return ziv(prec, mode, func(w uint) (*big.Float, int) {
+			if expo(a) <= 0 {
+				return newFloat(w).Add(floatOne, erfSeries(a, w+8)), -int(w) + 2
+			}
+			y, e := erfcApprox(a, w)
+			return newFloat(w+8).Sub(floatTwo, y), maxExp(e, -int(w)-6) + 1
+		})
+	}
+	// erfc(x) = 1 - 2x/sqrt(pi) + O(x**3)
+	if r, ok := nearly(floatOne, -1, expo(a)+1, prec, mode); ok {
+		return r
+	}
+	if expo(a) > 16 {
+		// erfc(x) < exp(-x**2) < 2**(-2**31), below the exponent range
+		return z.zero(false)
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		return erfcApprox(a, w)
+	})
+}
+
+// Zeta returns the Riemann zeta function ζ(z), for real z. Zeta(+Inf) = 1,
+// Zeta(0) = -1/2, Zeta is +0 at the negative even integers and exact at
+// small negative odd integers, and it panics with ErrNaN at the pole
+// z = 1 and for -Inf.
+func (z *Float) Zeta() *Float {
+	s := (*big.Float)(z)
+	switch {
+	case s.IsInf() && s.Signbit():
+		panic(ErrNaN)
+	case s.IsInf():
+		return z.exactResult(floatOne)
+	case s.Sign() == 0:
+		return z.exactResult(big.NewFloat(-0.5))
+	case s.Cmp(floatOne) == 0:
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	if n, ok := smallInt(s); ok && n < 0 {
+		if n%2 == 0 {
+			return z.zero(false)
+		}
+		if -n < 1<<12 {
+			return (*Float)(newFloat(prec).SetMode(mode).SetRat(zetaNegInt(-n)))
+		}
+	}
+	return zeta(s, prec, mode)
+}
+
+// zeta returns ζ(s) rounded to prec with the mode, for finite s other
+// than 1 and the negative even integers.
+func zeta(s *big.Float, prec uint, mode big.RoundingMode) *Float {
+	if s.Sign() > 0 && expo(s) > 1 {
+		// ζ(s) = 1 + 2**-s + 3**-s + ... < 1 + 2**(1-s) for s >= 2
+		dExp := math.MinInt32
+		if expo(s) < 31 {
+			n, _ := s.Int64()
+			dExp = 1 - int(n)
+		}
+		if r, ok := nearly(floatOne, 1, dExp, prec, mode); ok {
+			return r
+		}
+	}
+	if expo(s) < -8 {
+		// ζ(s) = -1/2 - s log(2 pi)/2 + O(s**2)
+		if r, ok := nearly(big.NewFloat(-0.5), -s.Sign(), expo(s)+1, prec, mode); ok {
+			return r
+		}
+	}
+	if s.Sign() < 0 && expo(s) > 27 {
+		// |ζ(s)| > Gamma(1-s) / (2 pi)**(1-s), beyond the exponent range,
+		// with the sign of sin(pi s/2)
+		h := new(big.Float).SetMantExp(s, -1)
+		return overflow(prec, mode, sinPi(h, 8).Sign() < 0)
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		return zetaApprox(s, w)
+	})
+}
+
+// Jn returns the order-n Bessel function of the first kind, J_n(x),
+// rounded to the precision and mode of x, as math.Jn does. Jn(n, ±Inf) = 0,
+// Jn(0, ±0) = 1 and Jn(n, ±0) = ±0 for n != 0.
+func Jn(n int, x *Float) *Float {
+	b := (*big.Float)(x)
+	// J(-n, x) = J(n, -x) = (-1)**n J(n, x)
+	neg := false
+	if n < 0 {
+		n = -n
+		neg = n%2 == 1
+	}
+	if b.Signbit() && n%2 == 1 {
+		neg = !neg
+	}
+	switch {
+	case b.IsInf():
+		return x.zero(false)
+	case b.Sign() == 0:
+		if n == 0 {
+			return x.exactResult(floatOne)
+		}
+		return x.zero(neg)
+	}
+	prec, mode := x.precision(), x.Mode()
+	a := new(big.Float).Abs(b)
+	switch n {
+	case 0:
+		// J_0(x) = 1 - x**2/4 + O(x**4)
+		if r, ok := nearly(floatOne, -1, 2*expo(a)-1, prec, mode); ok {
+			return r
+		}
+	case 1:
+		// J_1(x) = x/2 - x**3/16 + O(x**5)
+		c := new(big.Float).SetMantExp(b, -1)
+		if r, ok := nearly(c, -b.Sign(), 3*expo(a)-3, prec, mode); ok {
+			return r
+		}
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		y, e := besselJApprox(n, a, w)
+		if neg {
+			y.Neg(y)
+		}
+		return y, e
+	})
+}
+
+// Yn returns the order-n Bessel function of the second kind, Y_n(x),
+// rounded to the precision and mode of x, as math.Yn does.
+// Yn(n, +Inf) = 0 and Yn(n, ±0) = -Inf, or +Inf for odd n < 0, and Yn
+// panics with ErrNaN for x < 0.
+func Yn(n int, x *Float) *Float {
+	b := (*big.Float)(x)
+	// Y(-n, x) = (-1)**n Y(n, x)
+	neg := false
+	if n < 0 {
+		n = -n
+		neg = n%2 == 1
+	}
+	switch {
+	case b.Sign() < 0:
+		panic(ErrNaN)
+	case b.IsInf():
+		return x.zero(false)
+	case b.Sign() == 0:
+		return x.inf(!neg)
+	}
+	return ziv(x.precision(), x.Mode(), func(w uint) (*big.Float, int) {
+		y, e := besselYApprox(n, b, w)
+		if neg {
+			y.Neg(y)
+		}
+		return y, e
+	})
+}
+
+// belowBranch returns whether x < -1/e, for finite x.
+func belowBranch(x *big.Float) bool {
+	if x.Sign() >= 0 {
+		return false
+	}
+	for w := x.Prec() + 64; ; w *= 2 {
+		// e x + 1, within 2**(expo(x)+2-w)
+		d := newFloat(w).Mul(x, eConstant.at(w+8))
+		d.Add(d, floatOne)
+		if d.Sign() != 0 && expo(d) > expo(x)+3-int(w) {
+			return d.Sign() < 0
+		}
+	}
+}
+
+// LambertW returns the principal branch of the Lambert W function, the
+// solution w >= -1 of w e**w = z. LambertW(±0) = ±0 and
+// LambertW(+Inf) = +Inf, and LambertW panics with ErrNaN for z < -1/e.
+func (z *Float) LambertW() *Float {
+	x := (*big.Float)(z)
+	switch {
+	case x.IsInf() && x.Signbit():
+		panic(ErrNaN)
+	case x.IsInf():
+		return z.inf(false)
+	case x.Sign() == 0:
+		return z.same()
+	case belowBranch(x):
+		panic(ErrNaN)
+	}
+	prec, mode := z.precision(), z.Mode()
+	// W(x) = x - x**2 + 3x**3/2 - ...
+	if r, ok := nearly(x, -1, 2*expo(x)+1, prec, mode); ok {
+		return r
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		return lambertWApprox(x, w)
+	})
+}
+
+// Polylog returns the polylogarithm of integer order s,
+// Li_s(x) = sum x**k / k**s, rounded to the precision and mode of x.
+// For s <= 0 it is a rational function of x, which is found exactly before
+// rounding. Polylog(1, 1) = +Inf and Polylog(s, 1) = Zeta(s) for s >= 2,
+// and Polylog panics with ErrNaN for infinite x, at the pole x = 1 for
+// s <= 0, and for x > 1 and s >= 1, where the result is not real.
+func Polylog(s int, x *Float) *Float {
+	b := (*big.Float)(x)
+	switch {
+	case b.IsInf():
+		panic(ErrNaN)
+	case b.Sign() == 0:
+		return x.same()
+	}
+	prec, mode := x.precision(), x.Mode()
+	c := b.Cmp(floatOne)
+	if s <= 0 {
+		if c == 0 {
+			panic(ErrNaN)
+		}
+		r, _ := b.Rat(nil)
+		return (*Float)(newFloat(prec).SetMode(mode).SetRat(polylogNonPositive(-s, r)))
+	}
+	switch {
+	case c > 0:
+		panic(ErrNaN)
+	case c == 0 && s == 1:
+		return x.inf(false)
+	case c == 0:
+		return zeta(new(big.Float).SetInt64(int64(s)), prec, mode)
+	}
+	// Li_s(x) = x + x**2/2**s + ..., where the rest is below 2 x**2/2**s
+	if r, ok := nearly(b, 1, 2*expo(b)-s+1, prec, mode); ok {
+		return r
+	}
+	if s == 1 {
+		// Li_1(x) = -log(1 - x)
+		return zivRel(prec, mode, func(w uint) *big.Float {
+			y := log1pKernel(new(big.Float).Neg(b), w)
+			return y.Neg(y)
+		})
+	}
+	return ziv(prec, mode, func(w uint) (*big.Float, int) {
+		return polylogApprox(s, b, w)
+	})
+}
@@ -0,0 +1,1018 @@
+package mathx
+
+// This file is for the machinery behind the special functions on Float.
+// Most of these functions are sums that can cancel, so their kernels
+// return an approximation y and an exponent e with the exact value within
+// 2**e of y, for ziv. The bounds come from two places: the rest of each
+// series, which is bounded by its first omitted term for the series used
+// here (Stirling's series and the asymptotic expansions of ψ, erfc and
+// the Bessel functions are enveloping for real arguments, and the others
+// are dominated by geometric series), and rounding, which is bounded by a
+// few units in the last place of the working precision for each term, at
+// the size of the largest term.
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+var floatHalf = big.NewFloat(0.5)
+
+// bernoulliCache holds the Bernoulli numbers B(2), B(4), ... computed so
+// far.
+var bernoulliCache struct {
+	mu sync.Mutex
+	b  []*big.Rat
+}
+
+// bernoulli returns the Bernoulli number B(2k), for k >= 1. The result
+// must not be modified.
+func bernoulli(k int) *big.Rat {
+	c := &bernoulliCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if k > len(c.b) {
+		n := k
+		if n < 2*len(c.b) {
+			n = 2 * len(c.b)
+		}
+		c.b = bernoulliNumbers(n)
+	}
+	return c.b[k-1]
+}
+
+// bernoulliNumbers returns B(2), B(4), ..., B(2n), from the tangent
+// numbers, as in Brent and Harvey, "Fast computation of Bernoulli, tangent
+// and secant numbers".
+func bernoulliNumbers(n int) []*big.Rat {
+	t := make([]*big.Int, n+1)
+	t[1] = big.NewInt(1)
+	for k := 2; k <= n; k++ {
+		t[k] = new(big.Int).Mul(t[k-1], big.NewInt(int64(k-1)))
+	}
+	x := new(big.Int)
+	for k := 2; k <= n; k++ {
+		for j := k; j <= n; j++ {
+			// T(j) = (j-k) T(j-1) + (j-k+2) T(j)
+			x.Mul(t[j-1], big.NewInt(int64(j-k)))
+			t[j].Mul(t[j], big.NewInt(int64(j-k+2))).Add(t[j], x)
+		}
+	}
+	b := make([]*big.Rat, n)
+	for k := 1; k <= n; k++ {
+		// B(2k) = (-1)**(k-1) 2k T(k) / (4**k (4**k - 1))
+		num := new(big.Int).Mul(t[k], big.NewInt(int64(2*k)))
+		if k%2 == 0 {
+			num.Neg(num)
+		}
+		den := new(big.Int).Lsh(bigOne, uint(2*k))
+		den.Mul(den, new(big.Int).Sub(den, bigOne))
+		b[k-1] = new(big.Rat).SetFrac(num, den)
+	}
+	return b
+}
+
+// lsb returns the exponent of the lowest set bit of x, which must be
+// finite and nonzero.
+func lsb(x *big.Float) int {
+	return expo(x) - int(x.MinPrec())
+}
+
+// exactAdd returns x + y without rounding, for finite x and y. The
+// precision needed is the distance between the highest and lowest bits.
+func exactAdd(x, y *big.Float) *big.Float {
+	if x.Sign() == 0 {
+		return new(big.Float).Set(y)
+	}
+	if y.Sign() == 0 {
+		return new(big.Float).Set(x)
+	}
+	hi, lo := expo(x), lsb(x)
+	if e := expo(y); e > hi {
+		hi = e
+	}
+	if l := lsb(y); l < lo {
+		lo = l
+	}
+	return newFloat(uint(hi-lo)+1).Add(x, y)
+}
+
+// isNonPositiveInt returns whether x is 0, -1, -2, ..., where Gamma has
+// its poles.
+func isNonPositiveInt(x *big.Float) bool {
+	return x.Sign() <= 0 && !x.IsInf() && x.IsInt()
+}
+
+// smallInt returns x as an int64, if it is an integer of magnitude less
+// than 2**62.
+func smallInt(x *big.Float) (int64, bool) {
+	if x.IsInf() || !x.IsInt() || (x.Sign() != 0 && expo(x) > 62) {
+		return 0, false
+	}
+	n, _ := x.Int64()
+	return n, true
+}
+
+// reduceTwo returns x - 2n exactly, for an integer n, in [-1, 1].
+func reduceTwo(x *big.Float) *big.Float {
+	if x.Sign() == 0 || expo(x) <= 0 {
+		return x
+	}
+	n, _ := new(big.Float).SetMantExp(x, -1).Int(nil)
+	r := newFloat(x.MinPrec()+2).Sub(x, new(big.Float).SetInt(n.Lsh(n, 1)))
+	if r.Cmp(floatOne) > 0 {
+		r.Sub(r, floatTwo)
+	} else if r.Cmp(big.NewFloat(-1)) < 0 {
+		r.Add(r, floatTwo)
+	}
+	return r
+}
+
+// sinPi returns sin(pi x) to w bits, for finite x. The reduction is exact,
+// so the relative error is small even near the zeros at the integers,
+// where the result is exactly zero.
+func sinPi(x *big.Float, w uint) *big.Float {
+	r := reduceTwo(x)
+	a := new(big.Float).Abs(r)
+	if a.Cmp(floatHalf) > 0 {
+		// sin(pi a) = sin(pi (1 - a))
+		a = newFloat(a.Prec()+1).Sub(floatOne, a)
+	}
+	if a.Sign() == 0 {
+		return newFloat(w)
+	}
+	t := newFloat(w+8).Mul(piConstant.at(w+8), a)
+	y := sinCosKernel(t, false, false, w)
+	if r.Sign() < 0 {
+		y.Neg(y)
+	}
+	return y
+}
+
+// cosPi returns cos(pi x) to w bits, for finite x, with a small relative
+// error as for sinPi.
+func cosPi(x *big.Float, w uint) *big.Float {
+	a := new(big.Float).Abs(reduceTwo(x))
+	neg := false
+	if a.Cmp(big.NewFloat(0.75)) >= 0 {
+		// cos(pi a) = -cos(pi (1 - a))
+		a = newFloat(a.Prec()+1).Sub(floatOne, a)
+		neg = true
+	}
+	var y *big.Float
+	if a.Cmp(big.NewFloat(0.25)) <= 0 {
+		t := newFloat(w+8).Mul(piConstant.at(w+8), a)
+		y = sinCosKernel(t, true, false, w)
+	} else {
+		// cos(pi a) = sin(pi (1/2 - a))
+		y = sinPi(newFloat(a.Prec()+2).Sub(floatHalf, a), w)
+	}
+	if neg {
+		y.Neg(y)
+	}
+	return y
+}
+
+// float64Of returns x as a float64, for estimates.
+func float64Of(x *big.Float) float64 {
+	f, _ := x.Float64()
+	return f
+}
+
+// maxExp returns the larger of a and b.
+func maxExp(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// logTwoPiHalf returns log(2 pi)/2 to w bits.
+func logTwoPiHalf(w uint) *big.Float {
+	l := logKernel(piConstant.at(w+8), w+8)
+	l.Add(l, ln2Constant.at(w+8))
+	return newFloat(w).SetMantExp(l, -1)
+}
+
+// stirlingShift returns how far x > 0 must be shifted up for the
+// asymptotic series of log Gamma and ψ to reach wp bits with few terms.
+func stirlingShift(x *big.Float, wp uint) int64 {
+	if x.Cmp(new(big.Float).SetInt64(int64(wp))) >= 0 {
+		return 0
+	}
+	return int64(wp) - int64(float64Of(x)) + 1
+}
+
+// logGammaStirling returns log(Gamma(y)), for y >= wp, by Stirling's
+// series,
+//
+//	log(Gamma(y)) = (y - 1/2) log(y) - y + log(2 pi)/2 + sum B(2k) / (2k (2k-1) y**(2k-1)),
+//
+// and an exponent e with the error less than 2**e.
+func logGammaStirling(y *big.Float, wp uint) (*big.Float, int) {
+	s := newFloat(wp).Sub(y, floatHalf)
+	s.Mul(s, logKernel(y, wp))
+	s.Sub(s, y)
+	s.Add(s, logTwoPiHalf(wp))
+	inv := newFloat(wp).Quo(floatOne, y)
+	inv2 := newFloat(wp).Mul(inv, inv)
+	p := newFloat(wp).Set(inv)
+	t := newFloat(wp)
+	k := 1
+	for ; ; k++ {
+		t.SetRat(bernoulli(k))
+		t.Mul(t, p)
+		t.Quo(t, new(big.Float).SetInt64(int64(2*k*(2*k-1))))
+		if expo(t) < expo(s)-int(wp) {
+			break
+		}
+		s.Add(s, t)
+		p.Mul(p, inv2)
+	}
+	return s, maxExp(expo(s)-int(wp)+bits.Len(uint(k))+3, expo(t)+1)
+}
+
+// gammaKernel returns Gamma(x), for finite x that is not a pole, with
+// |x| < 2**27 so that the result is within the range of big.Float.
+func gammaKernel(x *big.Float, w uint) *big.Float {
+	if x.Sign() < 0 {
+		// Gamma(x) = pi / (sin(pi x) Gamma(1 - x))
+		wp := w + 8
+		g := gammaKernel(exactAdd(floatOne, new(big.Float).Neg(x)), wp)
+		g.Mul(g, sinPi(x, wp))
+		return newFloat(w).Quo(piConstant.at(wp), g)
+	}
+	// Gamma(x) = Gamma(x + m) / (x (x+1) ... (x+m-1)), and the error in
+	// log(Gamma(x + m)) is magnified by its size, about (x+m) log(x+m)
+	m := stirlingShift(x, w+16)
+	yf := float64Of(x) + float64(m)
+	wp := w + 16 + uint(bits.Len64(uint64(m))) + uint(bits.Len(uint(yf*math.Log(yf)+1)))
+	y := newFloat(wp).Add(x, new(big.Float).SetInt64(m))
+	l, _ := logGammaStirling(y, wp)
+	g := expKernel(l, wp)
+	if m > 0 {
+		prod := newFloat(wp).Set(x)
+		t := newFloat(wp)
+		for i := int64(1); i < m; i++ {
+			prod.Mul(prod, t.Add(x, new(big.Float).SetInt64(i)))
+		}
+		g.Quo(g, prod)
+	}
+	return newFloat(w).Set(g)
+}
+
+// logGammaApprox returns log|Gamma(x)|, for finite x that is not a pole,
+// and an error exponent for ziv.
+func logGammaApprox(x *big.Float, w uint) (*big.Float, int) {
+	if x.Sign() < 0 {
+		// log|Gamma(x)| = log(pi) - log|sin(pi x)| - log(Gamma(1 - x))
+		wp := w + 8
+		l, e := logGammaApprox(exactAdd(floatOne, new(big.Float).Neg(x)), wp)
+		s := sinPi(x, wp)
+		ls := logKernel(s.Abs(s), wp)
+		lp := logKernel(piConstant.at(wp), wp)
+		y := newFloat(wp).Sub(lp, ls)
+		e = maxExp(e, maxExp(expo(ls), 1)-int(wp)+2)
+		y.Sub(y, l)
+		return y, maxExp(e, expo(l)-int(wp)+2) + 1
+	}
+	m := stirlingShift(x, w+16)
+	yf := float64Of(x) + float64(m)
+	wp := w + 16 + uint(bits.Len64(uint64(m)))
+	y := newFloat(wp).Add(x, new(big.Float).SetInt64(m))
+	l, e := logGammaStirling(y, wp)
+	// the rounding of x + m moves the result by at most psi(y) ulps of y
+	e = maxExp(e, expo(y)+bits.Len(uint(math.Log(yf)+2))-int(wp))
+	if m > 0 {
+		prod := newFloat(wp).Set(x)
+		t := newFloat(wp)
+		for i := int64(1); i < m; i++ {
+			prod.Mul(prod, t.Add(x, new(big.Float).SetInt64(i)))
+		}
+		lp := logKernel(prod, wp)
+		e = maxExp(e, expo(lp)-int(wp)+bits.Len64(uint64(m))+2)
+		l.Sub(l, lp)
+	}
+	return l, e + 1
+}
+
+// digammaApprox returns ψ(x), for finite x that is not a pole, and an
+// error exponent for ziv.
+func digammaApprox(x *big.Float, w uint) (*big.Float, int) {
+	if x.Sign() < 0 {
+		// ψ(x) = ψ(1 - x) - pi cot(pi x)
+		wp := w + 8
+		d, e := digammaApprox(exactAdd(floatOne, new(big.Float).Neg(x)), wp)
+		c := cosPi(x, wp)
+		c.Quo(c, sinPi(x, wp))
+		c.Mul(c, piConstant.at(wp))
+		d.Sub(d, c)
+		return d, maxExp(e, expo(c)-int(wp)+3) + 1
+	}
+	// ψ(x) = ψ(x + m) - 1/x - 1/(x+1) - ... - 1/(x+m-1), and
+	// ψ(y) = log(y) - 1/(2y) - sum B(2k) / (2k y**2k)
+	m := stirlingShift(x, w+16)
+	wp := w + 16 + uint(bits.Len64(uint64(m)))
+	y := newFloat(wp).Add(x, new(big.Float).SetInt64(m))
+	s := logKernel(y, wp)
+	inv := newFloat(wp).Quo(floatOne, y)
+	s.Sub(s, newFloat(wp).SetMantExp(inv, -1))
+	inv2 := newFloat(wp).Mul(inv, inv)
+	p := newFloat(wp).Set(inv2)
+	t := newFloat(wp)
+	k := 1
+	for ; ; k++ {
+		t.SetRat(bernoulli(k))
+		t.Mul(t, p)
+		t.Quo(t, new(big.Float).SetInt64(int64(2*k)))
+		if expo(t) < expo(s)-int(wp) {
+			break
+		}
+		s.Sub(s, t)
+		p.Mul(p, inv2)
+	}
+	e := maxExp(expo(s)-int(wp)+bits.Len(uint(k))+3, expo(t)+1)
+	if m > 0 {
+		r := newFloat(wp)
+		sum := newFloat(wp)
+		for i := int64(0); i < m; i++ {
+			sum.Add(sum, r.Quo(floatOne, r.Add(x, new(big.Float).SetInt64(i))))
+		}
+		e = maxExp(e, expo(sum)-int(wp)+bits.Len64(uint64(m))+2)
+		s.Sub(s, sum)
+	}
+	return s, e + 1
+}
+
+// logGammaSign returns the sign of Gamma(x), for finite x that is not a
+// pole: -1 if x < 0 and floor(x) is odd, and 1 otherwise.
+func logGammaSign(x *big.Float) int {
+	if x.Sign() > 0 {
+		return 1
+	}
+	if sinPi(x, 8).Sign() < 0 {
+		return -1
+	}
+	return 1
+}
+
+// erfSeries returns erf(x) for finite x > 0, by
+//
+//	erf(x) = 2x exp(-x**2) / sqrt(pi) sum (2x**2)**k / (1 3 5 ... (2k+1)),
+//
+// which has positive terms, with a relative error of a few ulps.
+func erfSeries(x *big.Float, w uint) *big.Float {
+	wp := w + 16 + uint(bits.Len(uint(float64Of(x)+1)))
+	x2 := newFloat(wp).Mul(x, x)
+	x2f := float64Of(x2)
+	a := newFloat(wp).SetMantExp(x2, 1)
+	sum := newFloat(wp).SetInt64(1)
+	term := newFloat(wp).SetInt64(1)
+	for k := int64(1); ; k++ {
+		term.Mul(term, a)
+		term.Quo(term, new(big.Float).SetInt64(2*k+1))
+		// once the terms shrink by half each, the rest is below term
+		if float64(k) > 2*x2f+1 && expo(term) < expo(sum)-int(wp) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	e := expKernel(new(big.Float).Neg(x2), wp)
+	sum.Mul(sum, e)
+	sum.Mul(sum, x)
+	sp := newFloat(wp).Sqrt(piConstant.at(wp))
+	sum.Quo(sum, sp)
+	return newFloat(w).SetMantExp(sum, 1)
+}
+
+// erfcAsymptotic returns erfc(x) for x > 0 with x**2 > (wp + 8) log(2), by
+//
+//	erfc(x) = exp(-x**2) / (x sqrt(pi)) sum (-1)**k (2k-1)!! / (2x**2)**k,
+//
+// and an error exponent.
+func erfcAsymptotic(x *big.Float, wp uint) (*big.Float, int) {
+	x2 := newFloat(wp).Mul(x, x)
+	a := newFloat(wp).SetMantExp(x2, 1)
+	sum := newFloat(wp).SetInt64(1)
+	term := newFloat(wp).SetInt64(1)
+	for k := int64(1); ; k++ {
+		term.Mul(term, new(big.Float).SetInt64(2*k-1))
+		term.Quo(term, a)
+		term.Neg(term)
+		if expo(term) < -int(wp) {
+			break
+		}
+		sum.Add(sum, term)
+	}
+	f := expKernel(new(big.Float).Neg(x2), wp)
+	f.Quo(f, x)
+	f.Quo(f, newFloat(wp).Sqrt(piConstant.at(wp)))
+	// the relative error of the sum is below 2**-wp from its rest, and a
+	// few more ulps from rounding
+	sum.Mul(sum, f)
+	return sum, expo(sum) - int(wp) + 3
+}
+
+// erfcLarge returns whether x**2 > (wp + 8) log(2), where erfcAsymptotic
+// is accurate.
+func erfcLarge(x *big.Float, wp uint) bool {
+	if expo(x) > 512 {
+		return true
+	}
+	xf := float64Of(x)
+	return xf*xf > float64(wp+8)*math.Ln2
+}
+
+// erfcApprox returns erfc(x) for finite x > 0, and an error exponent.
+func erfcApprox(x *big.Float, w uint) (*big.Float, int) {
+	if erfcLarge(x, w+8) {
+		return erfcAsymptotic(x, w+8)
+	}
+	// erfc(x) = 1 - erf(x), which cancels about x**2 log2(e) bits
+	xf := float64Of(x)
+	wp := w + 16 + uint(xf*xf*math.Log2E)
+	y := newFloat(wp).Sub(floatOne, erfSeries(x, wp))
+	return y, -int(wp) + 2
+}
+
+// besselTerms returns the terms (x/2)**(2k+n) / (k! (n+k)!) of the series
+// for J_n, from k = 0, until they are small compared to the largest
+// (times the factor f(k), if not nil), with the absolute value of the
+// first omitted term.
+func besselTerms(n int, x *big.Float, wp uint, f func(k int64) *big.Float) ([]*big.Float, *big.Float, *big.Float) {
+	h := newFloat(wp).SetMantExp(x, -1)
+	h2 := newFloat(wp).Mul(h, h)
+	t := newFloat(wp).SetInt64(1)
+	for i := 1; i <= n; i++ {
+		t.Mul(t, h)
+		t.Quo(t, new(big.Float).SetInt64(int64(i)))
+	}
+	hf := float64Of(h)
+	var terms []*big.Float
+	largest := newFloat(wp)
+	for k := int64(0); ; k++ {
+		m := new(big.Float).Set(t)
+		if f != nil {
+			m.Mul(m, f(k))
+		}
+		m.Abs(m)
+		if float64(k) > hf+1 && expo(m) < expo(largest)-int(wp) {
+			return terms, largest, m
+		}
+		if m.Cmp(largest) > 0 {
+			largest = m
+		}
+		terms = append(terms, new(big.Float).Set(t))
+		t.Mul(t, h2)
+		t.Quo(t, new(big.Float).SetInt64((k+1)*(k+1+int64(n))))
+	}
+}
+
+// besselLarge returns whether x is large enough for the asymptotic
+// expansions of J_n and Y_n to reach wp bits.
+func besselLarge(n int, x *big.Float, wp uint) bool {
+	if expo(x) > 62 {
+		return true
+	}
+	xf := float64Of(x)
+	return xf > 0.35*float64(wp)+16 && xf > float64(n)*float64(n)
+}
+
+// besselAsymptotic returns J_n(x), or Y_n(x) if y is set, for large x > 0,
+// and an error exponent, by Hankel's expansion,
+//
+//	J_n(x) = sqrt(2/(pi x)) (P cos(χ) - Q sin(χ)),
+//	Y_n(x) = sqrt(2/(pi x)) (P sin(χ) + Q cos(χ)),
+//
+// with χ = x - (2n+1) pi/4, P = sum (-1)**k a(2k) / x**2k and
+// Q = sum (-1)**k a(2k+1) / x**(2k+1), where
+// a(k) = (4n**2 - 1) (4n**2 - 9) ... (4n**2 - (2k-1)**2) / (k! 8**k).
+func besselAsymptotic(n int, x *big.Float, y bool, wp uint) (*big.Float, int) {
+	mu := new(big.Float).SetInt64(4 * int64(n) * int64(n))
+	p := newFloat(wp).SetInt64(1)
+	q := newFloat(wp)
+	a := newFloat(wp).SetInt64(1)
+	c := newFloat(wp)
+	var omitted *big.Float
+	for k := int64(1); ; k++ {
+		// a(k) / x**k from a(k-1) / x**(k-1)
+		c.SetInt64((2*k - 1) * (2*k - 1))
+		c.Sub(mu, c)
+		a.Mul(a, c)
+		a.Quo(a, new(big.Float).SetInt64(8*k))
+		a.Quo(a, x)
+		if a.Sign() == 0 || (2*k > int64(n)+1 && expo(a) < -int(wp)) {
+			omitted = new(big.Float).Abs(a)
+			break
+		}
+		switch k % 4 {
+		case 0:
+			p.Add(p, a)
+		case 1:
+			q.Add(q, a)
+		case 2:
+			p.Sub(p, a)
+		case 3:
+			q.Sub(q, a)
+		}
+	}
+	// cos(χ) = (σc cos(x) + σs sin(x)) / sqrt(2) and
+	// sin(χ) = (σc sin(x) - σs cos(x)) / sqrt(2), with the signs of
+	// cos((2n+1) pi/4) and sin((2n+1) pi/4)
+	sc, ss := 1, 1
+	switch (2*n + 1) % 8 {
+	case 3:
+		sc = -1
+	case 5:
+		sc, ss = -1, -1
+	case 7:
+		ss = -1
+	}
+	cx := sinCosKernel(x, true, false, wp)
+	sx := sinCosKernel(x, false, false, wp)
+	chiC := newFloat(wp).Add(signed(cx, sc), signed(sx, ss))
+	chiS := newFloat(wp).Sub(signed(sx, sc), signed(cx, ss))
+	var r *big.Float
+	if y {
+		r = newFloat(wp).Mul(p, chiS)
+		r.Add(r, newFloat(wp).Mul(q, chiC))
+	} else {
+		r = newFloat(wp).Mul(p, chiC)
+		r.Sub(r, newFloat(wp).Mul(q, chiS))
+	}
+	// sqrt(2/(pi x)) / sqrt(2) = 1/sqrt(pi x)
+	s := newFloat(wp).Mul(piConstant.at(wp), x)
+	s.Sqrt(s)
+	r.Quo(r, s)
+	// P and Q are at most 2, and their rests at most twice the omitted term
+	e := maxExp(-int(wp)+4, expo(omitted)+3) - expo(s)
+	return r, e
+}
+
+// signed returns x, negated if s < 0.
+func signed(x *big.Float, s int) *big.Float {
+	if s < 0 {
+		return new(big.Float).Neg(x)
+	}
+	return x
+}
+
+// besselJApprox returns J_n(x) for n >= 0 and finite x > 0, and an error
+// exponent.
+func besselJApprox(n int, x *big.Float, w uint) (*big.Float, int) {
+	if besselLarge(n, x, w+8) {
+		return besselAsymptotic(n, x, false, w+8)
+	}
+	// the terms grow to about exp(x) before they shrink
+	wp := w + 16 + uint(float64Of(x)*math.Log2E)
+	terms, largest, omitted := besselTerms(n, x, wp, nil)
+	sum := newFloat(wp)
+	for k, t := range terms {
+		if k%2 == 0 {
+			sum.Add(sum, t)
+		} else {
+			sum.Sub(sum, t)
+		}
+	}
+	e := maxExp(expo(largest)-int(wp)+bits.Len(uint(len(terms)))+2, expo(omitted)+1)
+	return sum, e
+}
+
+// besselYApprox returns Y_n(x) for n >= 0 and finite x > 0, and an error
+// exponent, from
+//
+//	pi Y_n(x) = 2 J_n(x) (log(x/2) + γ) - sum over k < n of (n-k-1)!/k! (x/2)**(2k-n)
+//	          - sum (-1)**k (H(k) + H(n+k)) (x/2)**(2k+n) / (k! (n+k)!),
+//
+// where H(k) is the k-th harmonic number.
+func besselYApprox(n int, x *big.Float, w uint) (*big.Float, int) {
+	if besselLarge(n, x, w+8) {
+		return besselAsymptotic(n, x, true, w+8)
+	}
+	wp := w + 16 + uint(float64Of(x)*math.Log2E)
+	h := newFloat(wp).SetMantExp(x, -1)
+	lh := logKernel(h, wp)
+	// harmonic numbers H(k) + H(n+k), as needed by k
+	hk := newFloat(wp)
+	hnk := newFloat(wp)
+	for i := 1; i <= n; i++ {
+		hnk.Add(hnk, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(int64(i))))
+	}
+	lastK := int64(0)
+	hsum := func(k int64) *big.Float {
+		for ; lastK < k; lastK++ {
+			hk.Add(hk, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(lastK+1)))
+			hnk.Add(hnk, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(lastK+1+int64(n))))
+		}
+		f := newFloat(wp).Add(hk, hnk)
+		// the factor for the J_n part too, so that the terms cover both
+		g := newFloat(wp).Add(lh, eulerGammaConstant.at(wp))
+		f.Add(f, g.Abs(g))
+		return f.Add(f, floatOne)
+	}
+	terms, largest, omitted := besselTerms(n, x, wp, hsum)
+	// the harmonic numbers again, now for each term in turn
+	j := newFloat(wp)
+	s := newFloat(wp)
+	hk.SetInt64(0)
+	hnk.SetInt64(0)
+	for i := 1; i <= n; i++ {
+		hnk.Add(hnk, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(int64(i))))
+	}
+	u := newFloat(wp)
+	for k, t := range terms {
+		if k > 0 {
+			hk.Add(hk, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(int64(k))))
+			hnk.Add(hnk, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(int64(k+n))))
+		}
+		u.Add(hk, hnk)
+		u.Mul(u, t)
+		if k%2 == 0 {
+			j.Add(j, t)
+			s.Add(s, u)
+		} else {
+			j.Sub(j, t)
+			s.Sub(s, u)
+		}
+	}
+	g := newFloat(wp).Add(lh, eulerGammaConstant.at(wp))
+	r := newFloat(wp).Mul(j, g)
+	r.SetMantExp(r, 1)
+	r.Sub(r, s)
+	// the finite sum, (n-k-1)!/k! (x/2)**(2k-n) for k from n-1 down to 0
+	e := maxExp(expo(largest)-int(wp)+bits.Len(uint(len(terms)))+3, expo(omitted)+2)
+	if n > 0 {
+		f := newFloat(wp)
+		t := newFloat(wp).SetInt64(1)
+		for i := 1; i < n; i++ {
+			t.Quo(t, h)
+			t.Mul(t, new(big.Float).SetInt64(int64(i)))
+		}
+		t.Quo(t, h)
+		// t is (n-1)! (x/2)**-n, the k = 0 term
+		for k := 0; k < n; k++ {
+			f.Add(f, t)
+			if k+1 < n {
+				t.Mul(t, newFloat(wp).Mul(h, h))
+				t.Quo(t, new(big.Float).SetInt64(int64((k+1)*(n-k-1))))
+			}
+		}
+		e = maxExp(e, expo(f)-int(wp)+bits.Len(uint(n))+2)
+		r.Sub(r, f)
+	}
+	r.Quo(r, piConstant.at(wp))
+	return r, e
+}
+
+// lambertWApprox returns the principal branch of the Lambert W function,
+// the solution of w exp(w) = x with w >= -1, for finite nonzero x > -1/e,
+// and an error exponent, by Halley's iteration.
+func lambertWApprox(x *big.Float, w uint) (*big.Float, int) {
+	// near the branch point x = -1/e, about half of the bits of e x + 1
+	// are lost
+	wp := w + 16
+	xf := float64Of(x)
+	if d := math.E*xf + 1; d < 0.25 {
+		wp += uint(-math.Log2(d+1e-300)) + 8
+	}
+	var y *big.Float
+	switch {
+	case xf < -0.25:
+		// W(x) = -1 + p - p**2/3 + 11 p**3/72, p = sqrt(2 (e x + 1))
+		p := math.Sqrt(2 * math.Max(math.E*xf+1, 0))
+		y = big.NewFloat(-1 + p - p*p/3 + 11*p*p*p/72)
+	case xf < 3:
+		y = big.NewFloat(math.Log1p(xf) * (1 - math.Log1p(math.Log1p(xf))/(2+math.Log1p(xf))))
+	default:
+		// W(x) = L - log(L) + log(L)/L, L = log(x)
+		l := logKernel(x, 64)
+		ll := logKernel(l, 64)
+		y = newFloat(64).Sub(l, ll)
+		y.Add(y, newFloat(64).Quo(ll, l))
+	}
+	y = newFloat(wp).Set(y)
+	if y.Sign() == 0 {
+		y.Set(x)
+	}
+	ex := newFloat(wp)
+	f := newFloat(wp)
+	fp := newFloat(wp)
+	t := newFloat(wp)
+	for i := 0; ; i++ {
+		ex = expKernel(y, wp)
+		// f = y e**y - x, f' = e**y (y + 1)
+		f.Mul(y, ex)
+		f.Sub(f, x)
+		fp.Add(y, floatOne)
+		fp.Mul(fp, ex)
+		if f.Sign() == 0 || fp.Sign() == 0 {
+			break
+		}
+		// Halley: y -= f / (f' - (y + 2) f / (2y + 2))
+		t.Add(y, floatTwo)
+		t.Mul(t, f)
+		t.Quo(t, newFloat(wp).SetMantExp(newFloat(wp).Add(y, floatOne), 1))
+		t.Sub(fp, t)
+		t.Quo(f, t)
+		y.Sub(y, t)
+		if t.Sign() == 0 || expo(t) < expo(y)-int(wp)/3 || i > 100 {
+			// the error is now about |f/f'|, which shrinks cubically
+			break
+		}
+	}
+	// a last Newton step, whose size bounds the error before it
+	ex = expKernel(y, wp)
+	f.Mul(y, ex)
+	round := expo(x) - int(wp) + 3
+	f.Sub(f, x)
+	fp.Add(y, floatOne)
+	fp.Mul(fp, ex)
+	if f.Sign() == 0 {
+		return y, round - expo(fp) + 2
+	}
+	t.Quo(f, fp)
+	y.Sub(y, t)
+	return y, maxExp(expo(t)+1, round-expo(fp)+2)
+}
+
+// zetaBorwein returns ζ(s) for finite s >= 1/2 with s != 1, and an error
+// exponent, by the second algorithm of Borwein, "An efficient algorithm
+// for the Riemann zeta function":
+//
+//	ζ(s) = -1 / (d(n) (1 - 2**(1-s))) sum over k < n of (-1)**k (d(k) - d(n)) / (k+1)**s
+//
+// with an error below 3 / ((3 + sqrt(8))**n |1 - 2**(1-s)|), where
+// d(k) = n sum over i <= k of (n+i-1)! 4**i / ((n-i)! (2i)!).
+func zetaBorwein(s *big.Float, w uint) (*big.Float, int) {
+	// 1 - 2**(1-s) = -expm1((1-s) log 2) loses bits near s = 1
+	sm1 := exactAdd(s, big.NewFloat(-1))
+	loss := 0
+	if e := expo(sm1); e < 0 {
+		loss = -e + 1
+	}
+	wp := w + 16 + uint(loss)
+	n := int64(float64(wp+4)/math.Log2(3+math.Sqrt(8))) + 2
+	wp += uint(bits.Len64(uint64(n)))
+	d := make([]*big.Int, n+1)
+	term := big.NewInt(1)
+	sum := big.NewInt(1)
+	d[0] = new(big.Int).Set(sum)
+	for i := int64(1); i <= n; i++ {
+		term.Mul(term, big.NewInt(4*(n+i-1)*(n-i+1)))
+		term.Quo(term, big.NewInt(2*i*(2*i-1)))
+		sum.Add(sum, term)
+		d[i] = new(big.Int).Set(sum)
+	}
+	si, isInt := smallInt(s)
+	acc := newFloat(wp)
+	t := newFloat(wp)
+	diff := new(big.Int)
+	for k := int64(0); k < n; k++ {
+		diff.Sub(d[k], d[n])
+		t.SetInt(diff)
+		if isInt && si < 1<<16 {
+			p := new(big.Int).Exp(big.NewInt(k+1), big.NewInt(si), nil)
+			t.Quo(t, new(big.Float).SetInt(p))
+		} else if k > 0 {
+			l := logKernel(new(big.Float).SetInt64(k+1), wp+uint(bits.Len(uint(float64Of(s)+1)))+8)
+			l.Mul(l, s)
+			t.Quo(t, expKernel(l, wp))
+		}
+		if k%2 == 0 {
+			acc.Add(acc, t)
+		} else {
+			acc.Sub(acc, t)
+		}
+	}
+	// 1 - 2**(1-s)
+	a := newFloat(wp).Mul(newFloat(wp+8).Neg(sm1), ln2Constant.at(wp+8))
+	a = expm1Kernel(a, wp)
+	acc.Quo(acc, new(big.Float).SetInt(d[n]))
+	acc.Quo(acc, a)
+	// the rounding error is at most n ulps of d(n) before the division,
+	// and the truncation error is 2**-(wp+4) or so, both over |a|
+	e := -int(wp) + bits.Len64(uint64(n)) + 3 - expo(a) + 1
+	return acc, e
+}
+
+// zetaApprox returns ζ(s) for finite s that is not 1 or a negative even
+// integer, and an error exponent, using the functional equation
+//
+//	ζ(s) = 2**s pi**(s-1) sin(pi s/2) Gamma(1-s) ζ(1-s)
+//
+// for s < 1/2.
+func zetaApprox(s *big.Float, w uint) (*big.Float, int) {
+	if s.Cmp(floatHalf) >= 0 {
+		return zetaBorwein(s, w)
+	}
+	wp := w + 16
+	t := exactAdd(floatOne, new(big.Float).Neg(s))
+	z, e := zetaBorwein(t, wp)
+	// relative error of the product, about 2**-wp for each factor, and
+	// the error of ζ(1-s) relative to it
+	rel := e - expo(z)
+	sf := float64Of(s)
+	// 2**s pi**(s-1) = exp(s log(2) + (s-1) log(pi)), where the argument
+	// has to be accurate to |s| ulps
+	wl := wp + uint(bits.Len(uint(-sf+1))) + 8
+	l := newFloat(wl).Mul(s, ln2Constant.at(wl))
+	lp := logKernel(piConstant.at(wl), wl)
+	lp.Mul(lp, exactAdd(s, big.NewFloat(-1)))
+	l.Add(l, lp)
+	r := expKernel(l, wp)
+	r.Mul(r, sinPi(new(big.Float).SetMantExp(s, -1), wp))
+	r.Mul(r, gammaKernel(t, wp))
+	r.Mul(r, z)
+	if r.Sign() == 0 || r.IsInf() {
+		return r, 0
+	}
+	return r, expo(r) + maxExp(rel, -int(wp)+4) + 1
+}
+
+// zetaNegInt returns ζ(-n) = (-1)**n B(n+1) / (n+1) for n >= 1.
+func zetaNegInt(n int64) *big.Rat {
+	if n%2 == 0 {
+		return new(big.Rat)
+	}
+	r := new(big.Rat).Quo(bernoulli(int(n+1)/2), big.NewRat(n+1, 1))
+	return r.Neg(r)
+}
+
+// polylogSeries returns Li_s(x) = sum x**k / k**s for |x| <= 1/2 and
+// s >= 1, and an error exponent.
+func polylogSeries(s int, x *big.Float, wp uint) (*big.Float, int) {
+	sum := newFloat(wp).Set(x)
+	p := newFloat(wp).Set(x)
+	t := newFloat(wp)
+	for k := int64(2); ; k++ {
+		p.Mul(p, x)
+		t.Quo(p, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(k), big.NewInt(int64(s)), nil)))
+		if t.Sign() == 0 || expo(t) < expo(x)-int(wp) {
+			break
+		}
+		sum.Add(sum, t)
+	}
+	// the terms at least halve, so the rest is below the last one
+	return sum, expo(x) - int(wp) + bits.Len(wp) + 2
+}
+
+// polylogLog returns Li_s(x) for 1/2 < x < 1 and s >= 2, and an error
+// exponent, from the expansion in μ = log(x),
+//
+//	Li_s(e**μ) = μ**(s-1) / (s-1)! (H(s-1) - log(-μ)) + sum over k != s-1 of ζ(s-k) μ**k / k!,
+//
+// whose terms for k > s shrink by a factor of about |μ| / (2 pi) < 1/8.
+func polylogLog(s int, x *big.Float, wp uint) (*big.Float, int) {
+	mu := logKernel(x, wp)
+	mf := math.Log2(-float64Of(mu))
+	sum := newFloat(wp)
+	// e bounds the error loosely, and p is μ**k / k!
+	e := -int(wp)
+	p := newFloat(wp).SetInt64(1)
+	for k := 0; ; k++ {
+		if k > 0 {
+			p.Mul(p, mu)
+			p.Quo(p, new(big.Float).SetInt64(int64(k)))
+		}
+		var t *big.Float
+		switch {
+		case k < s-1:
+			z, ez := zetaBorwein(new(big.Float).SetInt64(int64(s-k)), wp)
+			t = z.Mul(z, p)
+			e = maxExp(e, ez+expo(p)+1)
+		case k == s-1:
+			h := newFloat(wp)
+			for i := 1; i < s; i++ {
+				h.Add(h, newFloat(wp).Quo(floatOne, new(big.Float).SetInt64(int64(i))))
+			}
+			h.Sub(h, logKernel(new(big.Float).Neg(mu), wp))
+			t = h.Mul(h, p)
+		case k == s:
+			t = newFloat(wp).SetMantExp(p, -1)
+			t.Neg(t)
+		default:
+			// |ζ(-j)| < 4 j! / (2 pi)**(j+1), so the rest after this term
+			// is below 8 |μ|**k / (2 pi)**(j+1)
+			j := int64(k - s)
+			if float64(k)*mf-float64(j+1)*math.Log2(2*math.Pi)+3 < float64(expo(sum)-int(wp)) {
+				return sum, maxExp(e, expo(sum)-int(wp)+bits.Len(uint(k))+3)
+			}
+			z := zetaNegInt(j)
+			if z.Sign() == 0 {
+				continue
+			}
+			t = newFloat(wp).SetRat(z)
+			t.Mul(t, p)
+		}
+		sum.Add(sum, t)
+		e = maxExp(e, expo(t)-int(wp)+2)
+	}
+}
+
+// polylogApprox returns Li_s(x) for s >= 2 and finite nonzero x < 1, and
+// an error exponent.
+func polylogApprox(s int, x *big.Float, w uint) (*big.Float, int) {
+	wp := w + 16
+	switch {
+	case x.Cmp(big.NewFloat(-0.5)) >= 0 && x.Cmp(floatHalf) <= 0:
+		return polylogSeries(s, x, wp)
+	case x.Sign() > 0:
+		return polylogLog(s, x, wp)
+	case x.Cmp(big.NewFloat(-1)) >= 0:
+		// Li_s(x) = 2**(1-s) Li_s(x**2) - Li_s(-x)
+		x2 := newFloat(2*x.Prec()).Mul(x, x)
+		var a *big.Float
+		var ea int
+		if x2.Cmp(floatOne) == 0 {
+			a, ea = zetaBorwein(new(big.Float).SetInt64(int64(s)), wp)
+		} else {
+			a, ea = polylogApprox(s, x2, wp)
+		}
+		a.SetMantExp(a, 1-s)
+		nx := new(big.Float).Neg(x)
+		var b *big.Float
+		var eb int
+		if nx.Cmp(floatOne) == 0 {
+			b, eb = zetaBorwein(new(big.Float).SetInt64(int64(s)), wp)
+		} else {
+			b, eb = polylogApprox(s, nx, wp)
+		}
+		a.Sub(a, b)
+		return a, maxExp(ea+1-s, eb) + 1
+	}
+	// x < -1: with y = -x,
+	// Li_s(-y) = -(-1)**s Li_s(-1/y) - log(y)**s / s! - 2 sum over 1 <= k <= s/2 of log(y)**(s-2k) / (s-2k)! η(2k),
+	// where η(2k) = (1 - 2**(1-2k)) ζ(2k) = (1 - 2**(1-2k)) |B(2k)| (2 pi)**2k / (2 (2k)!)
+	y := new(big.Float).Neg(x)
+	l := logKernel(y, wp)
+	a, e := polylogApprox(s, newFloat(wp).Quo(big.NewFloat(-1), y), wp)
+	if s%2 == 0 {
+		a.Neg(a)
+	}
+	// the powers of log(y) / j! for j = s, s-2, ..., and η
+	pw := func(j int) *big.Float {
+		p := newFloat(wp).SetInt64(1)
+		for i := 1; i <= j; i++ {
+			p.Mul(p, l)
+			p.Quo(p, new(big.Float).SetInt64(int64(i)))
+		}
+		return p
+	}
+	t := pw(s)
+	largest := expo(t)
+	a.Sub(a, t)
+	tp := newFloat(wp).SetMantExp(piConstant.at(wp), 1)
+	for k := 1; 2*k <= s; k++ {
+		eta := newFloat(wp).SetRat(bernoulli(k))
+		eta.Abs(eta)
+		for i := 1; i <= 2*k; i++ {
+			eta.Mul(eta, tp)
+			eta.Quo(eta, new(big.Float).SetInt64(int64(i)))
+		}
+		eta.SetMantExp(eta, -1)
+		eta.Mul(eta, newFloat(wp).Sub(floatOne, pow2(1-2*k)))
+		t := pw(s - 2*k)
+		t.Mul(t, eta)
+		t.SetMantExp(t, 1)
+		if expo(t) > largest {
+			largest = expo(t)
+		}
+		a.Sub(a, t)
+	}
+	return a, maxExp(e, largest-int(wp)+bits.Len(uint(s))+3) + 1
+}
+
+// polylogNonPositive returns Li_s(x) = x A_n(x) / (1 - x)**(n+1) exactly,
+// for s = -n <= 0 and x != 1, where A_n is the n-th Eulerian polynomial.
+func polylogNonPositive(n int, x *big.Rat) *big.Rat {
+	// the coefficients of A_n, from A(n, m) = (m+1) A(n-1, m) + (n-m) A(n-1, m-1)
+	a := []*big.Int{big.NewInt(1)}
+	for i := 2; i <= n; i++ {
+		b := make([]*big.Int, i)
+		for m := 0; m < i; m++ {
+			b[m] = new(big.Int)
+			if m < len(a) {
+				b[m].Mul(a[m], big.NewInt(int64(m+1)))
+			}
+			if m > 0 {
+				b[m].Add(b[m], new(big.Int).Mul(a[m-1], big.NewInt(int64(i-m))))
+			}
+		}
+		a = b
+	}
+	// Horner's rule
+	p := new(big.Rat)
+	for m := len(a) - 1; m >= 0; m-- {
+		p.Mul(p, x)
+		p.Add(p, new(big.Rat).SetInt(a[m]))
+	}
+	p.Mul(p, x)
+	d := new(big.Rat).Sub(big.NewRat(1, 1), x)
+	q := big.NewRat(1, 1)
+	for i := 0; i <= n; i++ {
+		q.Mul(q, d)
+	}
+	return p.Quo(p, q)
+}
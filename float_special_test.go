@@ -0,0 +1,363 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// specialTestCases are values to 110 digits, from an independent
+// computation in decimal arithmetic. The arguments are exact in binary.
+var specialTestCases = []struct {
+	f    string
+	x    string
+	want string
+}{
+	{"Gamma", "0.375", "2.37043618441660090864647350417665250988740080335892498777512693467316153135800179179864766837101480650850720128e+0"},
+	{"Gamma", "-2.5", "-9.45308720482941881225689324448610764158693043265273135047364154588219351781883830066640350260557154888654305933e-1"},
+	{"Gamma", "10.25", "6.39232598779576794283758401876084967153425284996821146812097993778926038418199917923591586191563983720449982368e+5"},
+	{"LogGamma", "0.0009765625", "6.93090890241946188954061906466008053572727254818864996493200158166151100594198399526907828948444197150597022542e+0"},
+	{"LogGamma", "1000.5", "5.90867417584867748868387473406262488049701546825861975468913636578363629160282029532290785374628062038057953820e+3"},
+	{"LogGamma", "-3.75", "-1.31726794244636367385007871659441823774999541343839240559456731933621082237692511817709816192218674026088317267e+0"},
+	{"Digamma", "0.375", "-2.75399904914513957576401921880456810525149539368810231054628322799041139896904762839870827951749232823410113024e+0"},
+	{"Digamma", "100.5", "4.60517435258184521186867878560471454857266876169160040870628267898688213181007576434556711752589330952912848811e+0"},
+	{"Beta", "1.5,2.25", "2.27017970068581801771847583540356659193312117007066940503620926735999185027741493526432513676314207224773658240e-1"},
+	{"Erf", "0.5", "5.20499877813046537682746653891964528736451575757963700058805725647193521716853570914788218734787757032966124386e-1"},
+	{"Erf", "2.5", "9.99593047982555041060435784260025087279651322596286579860879221230902993970150334358038455921236775741438807571e-1"},
+	{"Erfc", "5", "1.53745979442803485018834348538337889011805031472337993068791405592039136455869146471814385946418876931242961217e-12"},
+	{"Zeta", "0.5", "-1.46035450880958681288949915251529801246722933101258149054288608782553052947450062527641937546335681951449637468e+0"},
+	{"Zeta", "2.5", "1.34148725725091717975676969334861213662303762950598651125379672834091892381318544158176108599869799447029069011e+0"},
+	{"Zeta", "1.25", "4.59511182584294338068537803969462565228102978060480484602718927795579606040809096338134266143858423877732266887e+0"},
+	{"Jn", "0,1", "7.65197686557966551449717526102663220909274289755325241861547549119278912215272440167180600098915633974929259828e-1"},
+	{"Jn", "3,2.5", "2.16600391039113524766689003515963721716843423576959926777214713241227098282161971006081033267964375270976705429e-1"},
+	{"Jn", "1,20", "6.68331241758500455789929741936467199829944446210624594233724062081152069971239688236160603697544763176336794016e-2"},
+	{"Yn", "0,1", "8.82569642156769579829267660235151628278175230906755467110438476119997893235133713010772003592199368022027613672e-2"},
+	{"Yn", "1,3.5", "4.10188417887511882872119683407401068916219812002539158641357166556304512362998428047449958947740390790174228999e-1"},
+	{"Yn", "4,0.75", "-1.01271994954914691160611510443493488580429679140344514970805285489235916492433701444947433467125789837714879668e+2"},
+	{"LambertW", "1", "5.67143290409783872999968662210355549753815787186512508135131079223045793086684566693219446961752294557638024973e-1"},
+	{"LambertW", "-0.25", "-3.57402956181388903068811104055904753316590555076012043627620448589671402596145796289616851344441185149725100031e-1"},
+	{"LambertW", "100", "3.38563014029005018488824436452972686749169417015780668038617465488520654491303927768673523621365078128463003485e+0"},
+	{"Polylog", "2,-0.6875", "-5.95663881417414112444869506517925892649108772175256854672238912356764587569868566861258077721995865611081647342e-1"},
+	{"Polylog", "3,0.375", "3.94916523433221705900698334712117811780920747850808866699878810040549633660465031165176258686504940051311216114e-1"},
+	{"Polylog", "2,0.875", "1.23812348179648023468305075555926046441586597469204472346883152062098927122292135947918105344404526923212347146e+0"},
+}
+
+var specialFuncs = map[string]func(x *Float) *Float{
+	"Gamma":    (*Float).Gamma,
+	"LogGamma": func(x *Float) *Float { l, _ := x.LogGamma(); return l },
+	"Digamma":  (*Float).Digamma,
+	"Erf":      (*Float).Erf,
+	"Erfc":     (*Float).Erfc,
+	"Zeta":     (*Float).Zeta,
+	"LambertW": (*Float).LambertW,
+}
+
+// evalSpecial evaluates a test case function at the precision and mode.
+// Orders come first, as in Jn(n, x).
+func evalSpecial(f, x string, prec uint, mode big.RoundingMode) *Float {
+	args := strings.Split(x, ",")
+	a, _, _ := ParseFloat(args[len(args)-1], 10, prec, mode)
+	switch f {
+	case "Beta":
+		a, _, _ = ParseFloat(args[0], 10, prec, mode)
+		b, _, _ := ParseFloat(args[1], 10, 64, mode)
+		return a.Beta(b)
+	case "Jn", "Yn", "Polylog":
+		n, _ := strconv.Atoi(args[0])
+		return map[string]func(int, *Float) *Float{"Jn": Jn, "Yn": Yn, "Polylog": Polylog}[f](n, a)
+	}
+	return specialFuncs[f](a)
+}
+
+func TestSpecialValues(t *testing.T) {
+	modes := []big.RoundingMode{big.ToNearestEven, big.ToZero, big.AwayFromZero, big.ToNegativeInf, big.ToPositiveInf}
+	for _, c := range specialTestCases {
+		for _, prec := range []uint{24, 53, 100, 300} {
+			for _, mode := range modes {
+				got := evalSpecial(c.f, c.x, prec, mode)
+				want, _, _ := ParseFloat(c.want, 10, prec, mode)
+				if got.Cmp(want) != 0 || got.Prec() != prec {
+					t.Errorf("%s(%s) at %d bits %v = %v, expected %v", c.f, c.x, prec, mode, got, want)
+				}
+				if acc := got.Acc(); acc != want.Acc() {
+					t.Errorf("%s(%s) at %d bits %v has accuracy %v, expected %v", c.f, c.x, prec, mode, acc, want.Acc())
+				}
+			}
+		}
+	}
+}
+
+func TestSpecialFloat64(t *testing.T) {
+	// the math package is accurate to within a few ulps here, except for
+	// Lgamma below 3, which is left to the table above
+	mathFuncs := map[string]func(float64) float64{
+		"Gamma": math.Gamma,
+		"LogGamma": func(x float64) float64 {
+			l, _ := math.Lgamma(x)
+			return l
+		},
+		"Erf": math.Erf, "Erfc": math.Erfc,
+	}
+	for name, f := range mathFuncs {
+		rnd := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			x := math.Ldexp(rnd.Float64(), rnd.Intn(8)-3)
+			if name == "Gamma" && rnd.Intn(2) == 0 {
+				x = -x
+			}
+			if name == "LogGamma" && x < 3 {
+				continue
+			}
+			want := f(x)
+			got, _ := specialFuncs[name](NewFloat(x)).Float64()
+			if math.Abs(got-want) > 8*math.Abs(math.Nextafter(want, 0)-want) {
+				t.Errorf("%s(%v) = %v, expected %v", name, x, got, want)
+			}
+		}
+	}
+	besselFuncs := map[string][2]func(int, float64) float64{
+		"J": {math.Jn, nil}, "Y": {math.Yn, nil},
+	}
+	for name, fs := range besselFuncs {
+		ours := Jn
+		if name == "Y" {
+			ours = Yn
+		}
+		rnd := rand.New(rand.NewSource(2))
+		for i := 0; i < 100; i++ {
+			n := rnd.Intn(4)
+			x := 12 * rnd.Float64()
+			want := fs[0](n, x)
+			got, _ := ours(n, NewFloat(x)).Float64()
+			// the math package works to an absolute error near zeros
+			if math.Abs(got-want) > 1e-14*math.Max(1, math.Abs(want)) {
+				t.Errorf("%sn(%d, %v) = %v, expected %v", name, n, x, got, want)
+			}
+		}
+	}
+}
+
+func TestSpecialIdentities(t *testing.T) {
+	const prec = 200
+	x := func(f float64) *Float { return NewFloat(f).SetPrec(prec) }
+	gamma := EulerGamma(prec)
+	ln2 := Ln2(prec)
+	pi := Pi(prec)
+	cases := []struct {
+		name      string
+		got, want *Float
+	}{
+		{"Gamma(1/2)**2", x(0.5).Gamma().Mul(x(0.5).Gamma()), pi},
+		{"Gamma(3.5)", x(3.5).Gamma(), x(2.5).Mul(x(2.5).Gamma())},
+		{"Digamma(1)", x(1).Digamma(), gamma.Neg()},
+		{"Digamma(1/2)", x(0.5).Digamma(), gamma.Neg().Sub(ln2.Mul(x(2)))},
+		{"Digamma(1/4)", x(0.25).Digamma(), gamma.Neg().Sub(pi.Quo(x(2))).Sub(ln2.Mul(x(3)))},
+		{"Beta(1/2, 1/2)", x(0.5).Beta(x(0.5)), pi},
+		{"Zeta(2)", x(2).Zeta(), pi.Mul(pi).Quo(x(6))},
+		{"Zeta(4)", x(4).Zeta(), pi.Mul(pi).Mul(pi).Mul(pi).Quo(x(90))},
+		{"Zeta(3)", x(3).Zeta(), Zeta3(prec)},
+		{"Polylog(2, 1)", Polylog(2, x(1)), pi.Mul(pi).Quo(x(6))},
+		{"Polylog(2, -1)", Polylog(2, x(-1)), pi.Mul(pi).Quo(x(-12))},
+		{"Polylog(2, 1/2)", Polylog(2, x(0.5)), pi.Mul(pi).Quo(x(12)).Sub(ln2.Mul(ln2).Quo(x(2)))},
+		{"Polylog(1, 1/2)", Polylog(1, x(0.5)), ln2},
+		{"Erf(1) + Erfc(1)", x(1).Erf().Add(x(1).Erfc()), x(1)},
+		{"Erfc(-1)", x(-1).Erfc(), x(1).Add(x(1).Erf())},
+		{"LambertW(e)", x(0).Add(E(prec)).LambertW(), x(1)},
+		{"Jn(-3, 2)", Jn(-3, x(2)), Jn(3, x(2)).Neg()},
+		{"Jn(3, -2)", Jn(3, x(-2)), Jn(3, x(2)).Neg()},
+		{"Yn(-3, 2)", Yn(-3, x(2)), Yn(3, x(2)).Neg()},
+	}
+	for _, c := range cases {
+		// the right hand sides are rounded several times
+		d := c.got.Sub(c.want).Abs()
+		if d.Cmp(c.want.Abs().Mul(x(0x1p-190))) > 0 {
+			t.Errorf("%s = %v, expected %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestSpecialExact(t *testing.T) {
+	cases := []struct {
+		name string
+		got  *Float
+		want *big.Rat
+	}{
+		{"Gamma(1)", NewFloat(1).Gamma(), big.NewRat(1, 1)},
+		{"Gamma(6)", NewFloat(6).Gamma(), big.NewRat(120, 1)},
+		{"LogGamma(2)", func() *Float { l, _ := NewFloat(2).LogGamma(); return l }(), new(big.Rat)},
+		{"Beta(2.5, 3)", NewFloat(2.5).Beta(NewFloat(3)), big.NewRat(16, 315)},
+		{"Beta(2, -0.5)", NewFloat(2).Beta(NewFloat(-0.5)), big.NewRat(-4, 1)},
+		{"Beta(1.5, -2.5)", NewFloat(1.5).Beta(NewFloat(-2.5)), new(big.Rat)},
+		{"Zeta(0)", NewFloat(0).Zeta(), big.NewRat(-1, 2)},
+		{"Zeta(-1)", NewFloat(-1).Zeta(), big.NewRat(-1, 12)},
+		{"Zeta(-3)", NewFloat(-3).Zeta(), big.NewRat(1, 120)},
+		{"Zeta(-4)", NewFloat(-4).Zeta(), new(big.Rat)},
+		{"Polylog(0, 0.5)", Polylog(0, NewFloat(0.5)), big.NewRat(1, 1)},
+		{"Polylog(-1, 0.5)", Polylog(-1, NewFloat(0.5)), big.NewRat(2, 1)},
+		{"Polylog(-3, 0.25)", Polylog(-3, NewFloat(0.25)), big.NewRat(44, 27)},
+		{"Polylog(-2, -1)", Polylog(-2, NewFloat(-1)), new(big.Rat)},
+		{"Jn(0, 0)", Jn(0, NewFloat(0)), big.NewRat(1, 1)},
+	}
+	for _, c := range cases {
+		want := new(big.Float).SetPrec(c.got.Prec()).SetRat(c.want)
+		if c.got.Cmp((*Float)(want)) != 0 || c.got.Acc() != want.Acc() {
+			t.Errorf("%s = %v (%v), expected %v (%v)", c.name, c.got, c.got.Acc(), want, want.Acc())
+		}
+	}
+	// 30! has 108 bits, so Gamma(31) is rounded once
+	g := NewFloat(31).SetPrec(64).SetMode(big.ToZero).Gamma()
+	want := new(big.Float).SetPrec(64).SetMode(big.ToZero).SetInt(new(big.Int).MulRange(1, 30))
+	if g.Cmp((*Float)(want)) != 0 || g.Acc() != big.Below {
+		t.Errorf("Gamma(31) = %v (%v), expected %v", g, g.Acc(), want)
+	}
+}
+
+func TestSpecialDirected(t *testing.T) {
+	funcs := map[string]func(x *Float) *Float{
+		"Jn": func(x *Float) *Float { return Jn(2, x) },
+		"Yn": func(x *Float) *Float { return Yn(1, x) },
+		"Polylog": func(x *Float) *Float {
+			// Neg would round to nearest
+			y := new(big.Float).SetPrec(x.Prec()).SetMode(x.Mode())
+			return Polylog(3, (*Float)(y.Neg((*big.Float)(x))))
+		},
+	}
+	for name, f := range specialFuncs {
+		funcs[name] = f
+	}
+	for name, f := range funcs {
+		rnd := rand.New(rand.NewSource(3))
+		for i := 0; i < 5; i++ {
+			x := math.Ldexp(rnd.Float64(), rnd.Intn(6)-2)
+			checkDirected(t, name, func(x []*Float) *Float { return f(x[0]) }, []float64{x}, []uint{2, 11, 64, 150})
+		}
+	}
+}
+
+func TestSpecialTiny(t *testing.T) {
+	// near their limits, these are just above or below exact values,
+	// which only the directed modes can see
+	x := NewFloat(1).SetExp(-10000)
+	cases := []struct {
+		name  string
+		f     func(*Float) *Float
+		c     *Float
+		above bool
+	}{
+		{"Gamma", (*Float).Gamma, NewFloat(1).SetExp(10000), false},
+		{"Digamma", (*Float).Digamma, NewFloat(-1).SetExp(10000), false},
+		{"Erfc", (*Float).Erfc, NewFloat(1), false},
+		{"Zeta", (*Float).Zeta, NewFloat(-0.5), false},
+		{"LambertW", (*Float).LambertW, x, false},
+		{"Jn(0)", func(x *Float) *Float { return Jn(0, x) }, NewFloat(1), false},
+		{"Jn(1)", func(x *Float) *Float { return Jn(1, x) }, x.Quo(NewFloat(2)), false},
+		{"Polylog(2)", func(x *Float) *Float { return Polylog(2, x) }, x, true},
+		{"Erf(large)", func(y *Float) *Float { return NewFloat(30).SetMode(y.Mode()).Erf() }, NewFloat(1), false},
+		{"Zeta(large)", func(y *Float) *Float { return NewFloat(100).SetMode(y.Mode()).Zeta() }, NewFloat(1), true},
+	}
+	for _, c := range cases {
+		near := c.f(x)
+		up := c.f(x.SetMode(big.ToPositiveInf))
+		down := c.f(x.SetMode(big.ToNegativeInf))
+		if near.Cmp(c.c) != 0 {
+			t.Errorf("%s(2**-10000) = %v", c.name, near)
+		}
+		if c.above && (down.Cmp(c.c) != 0 || up.Cmp(c.c) <= 0) || !c.above && (up.Cmp(c.c) != 0 || down.Cmp(c.c) >= 0) {
+			t.Errorf("%s(2**-10000) rounds to %v and %v", c.name, down, up)
+		}
+	}
+}
+
+func TestSpecialSpecial(t *testing.T) {
+	inf, negInf := Inf(false), Inf(true)
+	zero, negZero := NewFloat(0), NewFloat(0).Neg()
+	check := func(name string, got *Float, want float64) {
+		f, _ := got.Float64()
+		if f != want || math.Signbit(f) != math.Signbit(want) {
+			t.Errorf("%s = %v, expected %v", name, got, want)
+		}
+	}
+	check("Gamma(+Inf)", inf.Gamma(), math.Inf(1))
+	check("Gamma(-0)", negZero.Gamma(), math.Inf(-1))
+	check("Gamma(1e10)", NewFloat(1e10).Gamma(), math.Inf(1))
+	check("Gamma(-1e10-0.5)", NewFloat(-1e10-0.5).Gamma(), math.Copysign(0, -1))
+	check("Digamma(+0)", zero.Digamma(), math.Inf(-1))
+	check("Digamma(-0)", negZero.Digamma(), math.Inf(1))
+	check("Erf(-Inf)", negInf.Erf(), -1)
+	check("Erf(-0)", negZero.Erf(), math.Copysign(0, -1))
+	check("Erfc(-Inf)", negInf.Erfc(), 2)
+	check("Erfc(+Inf)", inf.Erfc(), 0)
+	check("Erfc(1e10)", NewFloat(1e10).Erfc(), 0)
+	check("Zeta(+Inf)", inf.Zeta(), 1)
+	check("Zeta(-2)", NewFloat(-2).Zeta(), 0)
+	check("Jn(2, -Inf)", Jn(2, negInf), 0)
+	check("Jn(1, -0)", Jn(1, negZero), math.Copysign(0, -1))
+	check("Yn(0, +Inf)", Yn(0, inf), 0)
+	check("Yn(2, 0)", Yn(2, zero), math.Inf(-1))
+	check("Yn(-1, 0)", Yn(-1, zero), math.Inf(1))
+	check("LambertW(+Inf)", inf.LambertW(), math.Inf(1))
+	check("LambertW(-0)", negZero.LambertW(), math.Copysign(0, -1))
+	check("Polylog(1, 1)", Polylog(1, NewFloat(1)), math.Inf(1))
+	check("Polylog(3, -0)", Polylog(3, negZero), math.Copysign(0, -1))
+	for _, x := range []float64{0, math.Copysign(0, -1), -3, math.Inf(1), math.Inf(-1)} {
+		got, sign := NewFloat(x).LogGamma()
+		want, wantSign := math.Lgamma(x)
+		check("LogGamma", got, want)
+		if sign != wantSign {
+			t.Errorf("LogGamma(%v) has sign %d, expected %d", x, sign, wantSign)
+		}
+	}
+
+	nans := map[string]func(){
+		"Gamma(-Inf)":       func() { negInf.Gamma() },
+		"Gamma(-3)":         func() { NewFloat(-3).Gamma() },
+		"Digamma(-1)":       func() { NewFloat(-1).Digamma() },
+		"Beta(-1, 0.5)":     func() { NewFloat(-1).Beta(NewFloat(0.5)) },
+		"Beta(Inf, 1)":      func() { inf.Beta(NewFloat(1)) },
+		"Zeta(1)":           func() { NewFloat(1).Zeta() },
+		"Zeta(-Inf)":        func() { negInf.Zeta() },
+		"Yn(0, -1)":         func() { Yn(0, NewFloat(-1)) },
+		"LambertW(-0.3679)": func() { NewFloat(-0.3679).LambertW() },
+		"Polylog(2, 1.5)":   func() { Polylog(2, NewFloat(1.5)) },
+		"Polylog(-1, 1)":    func() { Polylog(-1, NewFloat(1)) },
+		"Polylog(0, Inf)":   func() { Polylog(0, inf) },
+	}
+	for name, f := range nans {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+	// -1/e is -0.36787944117144233, so this is just inside the domain
+	if w, _ := NewFloat(-0.3678794411714423).LambertW().Float64(); w > -0.99999 || w < -1 {
+		t.Errorf("LambertW(-0.3678794411714423) = %v", w)
+	}
+}
+
+func BenchmarkFloatGamma256(b *testing.B) {
+	x, _, _ := ParseFloat("2.718281828", 10, 256, big.ToNearestEven)
+	for i := 0; i < b.N; i++ {
+		x.Gamma()
+	}
+}
+
+func BenchmarkFloatZeta256(b *testing.B) {
+	x, _, _ := ParseFloat("2.718281828", 10, 256, big.ToNearestEven)
+	for i := 0; i < b.N; i++ {
+		x.Zeta()
+	}
+}
+
+func BenchmarkFloatJn256(b *testing.B) {
+	x, _, _ := ParseFloat("2.718281828", 10, 256, big.ToNearestEven)
+	for i := 0; i < b.N; i++ {
+		Jn(3, x)
+	}
+}
@@ -0,0 +1,86 @@
+package mathx
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestFloatFormat(t *testing.T) {
+	x, _, _ := ParseFloat("1234.5678", 10, 64, big.ToNearestEven)
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "1234.5678"},
+		{"%.2f", "1234.57"},
+		{"%10.1f", "    1234.6"},
+		{"%-10.1f|", "1234.6    |"},
+		{"%+.3e", "+1.235e+03"},
+		{"%g", "1234.5678"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, x); got != c.want {
+			t.Errorf("Sprintf(%q) = %q, expected %q", c.format, got, c.want)
+		}
+	}
+	if got := fmt.Sprintf("%v %v", Inf(false), Inf(true)); got != "+Inf -Inf" {
+		t.Errorf("infinities formatted as %q", got)
+	}
+}
+
+func TestFloatScan(t *testing.T) {
+	var x Float
+	if _, err := fmt.Sscan("-2.5e3", &x); err != nil || x.CmpInt64(-2500) != 0 {
+		t.Errorf("scanned %v, %v", &x, err)
+	}
+	var v FloatValue
+	if _, err := fmt.Fscan(strings.NewReader(" 0.125 "), &v); err != nil || v.CmpFloat64(0.125) != 0 {
+		t.Errorf("scanned %v, %v", v.Float, err)
+	}
+}
+
+func TestFloatParse(t *testing.T) {
+	z := FloatFromInt64(0).SetPrec(100).SetMode(big.AwayFromZero)
+	f, b, err := z.Parse("0x1.8p1", 0)
+	if err != nil || b != 16 || f.CmpInt64(3) != 0 || f.Prec() != 100 || f.Mode() != big.AwayFromZero {
+		t.Errorf("Parse gave %v, %d, %v", f, b, err)
+	}
+	if z.Sign() != 0 {
+		t.Errorf("Parse changed the receiver to %v", z)
+	}
+	if _, _, err := z.Parse("1.2.3", 10); err == nil {
+		t.Errorf("Parse(\"1.2.3\") should fail")
+	}
+	if f, _, _ := new(Float).Parse("0.1", 10); f.Prec() != 64 {
+		t.Errorf("Parse on a zero value gave precision %d", f.Prec())
+	}
+}
+
+func TestFloatInf(t *testing.T) {
+	z := NewFloat(2).SetPrec(99)
+	for _, signbit := range []bool{false, true} {
+		for _, f := range []*Float{Inf(signbit), z.SetInf(signbit)} {
+			if !f.IsInf() || f.Signbit() != signbit {
+				t.Errorf("SetInf(%v) = %v", signbit, f)
+			}
+		}
+		if z.SetInf(signbit).Prec() != 99 {
+			t.Errorf("SetInf should keep the precision")
+		}
+	}
+	if z.IsInf() {
+		t.Errorf("SetInf changed the receiver")
+	}
+}
+
+func TestFloatFromInt(t *testing.T) {
+	for _, s := range []string{"0", "-1", "123456789012345678901234567890123456789"} {
+		x, _ := NewIntFromString(s, 10)
+		f := FloatFromInt(x)
+		if y, acc := f.Int(); acc != big.Exact || y.Cmp(x) != 0 {
+			t.Errorf("FloatFromInt(%s) = %v", s, f)
+		}
+	}
+}
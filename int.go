@@ -149,6 +149,221 @@ func Binomial(n, k int64) *Int {
 	return (*Int)(new(big.Int).Binomial(n, k))
 }
 
+// Multinomial returns the multinomial coefficient (k1 + k2 + ...)! / (k1! k2! ...),
+// computed as a product of binomial coefficients.
+func Multinomial(ks ...int64) *Int {
+	m := big.NewInt(1)
+	n := int64(0)
+	for _, k := range ks {
+		if k < 0 {
+			panic("mathx: Multinomial of negative number")
+		}
+		n += k
+		m.Mul(m, new(big.Int).Binomial(n, k))
+	}
+	return (*Int)(m)
+}
+
+// smallFactorials are the factorials that fit in a uint64.
+var smallFactorials = [...]uint64{
+	1, 1, 2, 6, 24, 120, 720, 5040, 40320, 362880, 3628800, 39916800,
+	479001600, 6227020800, 87178291200, 1307674368000, 20922789888000,
+	355687428096000, 6402373705728000, 121645100408832000,
+	2432902008176640000,
+}
+
+// Factorial returns n!, computed with Luschny's prime-swing algorithm,
+// which is fast enough for n in the millions. It panics if n < 0.
+func Factorial(n int64) *Int {
+	if n < 0 {
+		panic("mathx: Factorial of negative number")
+	}
+	if n < int64(len(smallFactorials)) {
+		return (*Int)(new(big.Int).SetUint64(smallFactorials[n]))
+	}
+	return (*Int)(factorial(n, sievePrimes(n)))
+}
+
+// factorial computes n! = (n/2)!^2 * swing(n), given the primes up to n.
+func factorial(n int64, primes []int64) *big.Int {
+	if n < int64(len(smallFactorials)) {
+		return new(big.Int).SetUint64(smallFactorials[n])
+	}
+	f := factorial(n/2, primes)
+	f.Mul(f, f)
+	return f.Mul(f, primeSwing(n, primes))
+}
+
+// primeSwing returns the swinging factorial n!/(n/2)!^2, which is the
+// product of the primes up to n with easily computed exponents.
+func primeSwing(n int64, primes []int64) *big.Int {
+	var factors []uint64
+	for _, p := range primes {
+		if p > n {
+			break
+		}
+		switch {
+		case p > n/2:
+			factors = append(factors, uint64(p))
+		case p > n/3:
+			// exponent is 0
+		case p*p > n:
+			if (n/p)&1 == 1 {
+				factors = append(factors, uint64(p))
+			}
+		default:
+			e := uint64(1)
+			for q := n / p; q > 0; q /= p {
+				if q&1 == 1 {
+					e *= uint64(p)
+				}
+			}
+			if e > 1 {
+				factors = append(factors, e)
+			}
+		}
+	}
+	return productTree(factors)
+}
+
+// productTree multiplies the factors by binary splitting, so that the
+// big multiplications are between numbers of similar size.
+func productTree(factors []uint64) *big.Int {
+	switch len(factors) {
+	case 0:
+		return big.NewInt(1)
+	case 1:
+		return new(big.Int).SetUint64(factors[0])
+	}
+	if len(factors) <= 8 {
+		p := new(big.Int).SetUint64(factors[0])
+		t := new(big.Int)
+		for _, f := range factors[1:] {
+			p.Mul(p, t.SetUint64(f))
+		}
+		return p
+	}
+	h := len(factors) / 2
+	p := productTree(factors[:h])
+	return p.Mul(p, productTree(factors[h:]))
+}
+
+// sievePrimes returns the primes up to and including n, using the Sieve
+// of Eratosthenes on odd numbers.
+func sievePrimes(n int64) []int64 {
+	if n < 2 {
+		return nil
+	}
+	primes := []int64{2}
+	composite := make([]bool, (n+1)/2)
+	for i := int64(1); i < int64(len(composite)); i++ {
+		if composite[i] {
+			continue
+		}
+		p := 2*i + 1
+		primes = append(primes, p)
+		for j := p * p / 2; j < int64(len(composite)); j += p {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// DoubleFactorial returns n!! = n(n-2)(n-4)..., ending in 1 or 2. It
+// returns 1 for n = 0 and n = -1, and panics for n < -1.
+func DoubleFactorial(n int64) *Int {
+	if n < -1 {
+		panic("mathx: DoubleFactorial of number less than -1")
+	}
+	if n <= 0 {
+		return NewInt(1)
+	}
+	k := n / 2
+	if n&1 == 0 {
+		// (2k)!! = 2^k k!
+		return Factorial(k).Lsh(uint(k))
+	}
+	// (2k+1)!! = (2k+1)! / (2^k k!)
+	f := (*big.Int)(Factorial(n))
+	return (*Int)(f.Quo(f, new(big.Int).Lsh((*big.Int)(Factorial(k)), uint(k))))
+}
+
+// Primorial returns n#, the product of all primes less than or equal to n.
+func Primorial(n int64) *Int {
+	primes := sievePrimes(n)
+	factors := make([]uint64, len(primes))
+	for i, p := range primes {
+		factors[i] = uint64(p)
+	}
+	return (*Int)(productTree(factors))
+}
+
+// Fibonacci returns the n-th Fibonacci number, with F(0) = 0 and F(1) = 1,
+// using the fast doubling method. For negative n, F(n) = (-1)^(n+1) F(-n).
+func Fibonacci(n int64) *Int {
+	f, _ := fibonacciPair(n, nil)
+	return (*Int)(f)
+}
+
+// Lucas returns the n-th Lucas number, with L(0) = 2 and L(1) = 1.
+// For negative n, L(n) = (-1)^n L(-n).
+func Lucas(n int64) *Int {
+	f, f1 := fibonacciPair(n, nil)
+	// L(n) = 2F(n+1) - F(n)
+	f1.Lsh(f1, 1)
+	return (*Int)(f1.Sub(f1, f))
+}
+
+// FibonacciMod returns the n-th Fibonacci number modulo m, without
+// computing the full Fibonacci number. The result is in [0, m).
+func FibonacciMod(n int64, m *Int) *Int {
+	f, _ := fibonacciPair(n, (*big.Int)(m))
+	return (*Int)(f.Mod(f, (*big.Int)(m)))
+}
+
+// fibonacciPair returns F(n) and F(n+1), reduced modulo m if m is not nil.
+func fibonacciPair(n int64, m *big.Int) (*big.Int, *big.Int) {
+	neg := n < 0
+	un := uint64(n)
+	if neg {
+		// F(n) = (-1)^(n+1) F(-n), and we need F(n+1) = F(-(-n-1)) too
+		un = uint64(-(n + 1))
+	}
+	a, b := big.NewInt(0), big.NewInt(1) // F(k), F(k+1)
+	t := new(big.Int)
+	for i := 63; i >= 0; i-- {
+		if un>>uint(i) == 0 {
+			continue
+		}
+		// F(2k) = F(k)(2F(k+1) - F(k)), F(2k+1) = F(k)^2 + F(k+1)^2
+		t.Lsh(b, 1)
+		t.Sub(t, a)
+		t.Mul(t, a)
+		a.Mul(a, a)
+		b.Mul(b, b)
+		b.Add(b, a)
+		a, t = t, a
+		if m != nil {
+			a.Mod(a, m)
+			b.Mod(b, m)
+		}
+		if (un>>uint(i))&1 == 1 {
+			a.Add(a, b)
+			a, b = b, a
+		}
+	}
+	if !neg {
+		return a, b
+	}
+	// with k = -n-1, F(n) = F(-k-1) = (-1)^k F(k+1), F(n+1) = (-1)^(k+1) F(k)
+	if un&1 == 1 {
+		b.Neg(b)
+	} else {
+		a.Neg(a)
+	}
+	return b, a
+}
+
 // Bits returns (a copy of) the underlying raw data.
 func (z *Int) Bits() []big.Word {
 	return (*big.Int)(z.copy()).Bits()
@@ -192,7 +407,8 @@ func (z *Int) ExtendedGCD(b *Int) (*Int, *Int, *Int) {
 
 // GobDecode decodes the data from the buffer, and changes this.
 // WARNING: this breaks immutability since it can change the underlying data.
-// This is unavoidable with the way that Gob works.
+// This is unavoidable with the way that Gob works; see DecodeIntGob and
+// IntValue for alternatives.
 func (z *Int) GobDecode(buf []byte) error {
 	return (*big.Int)(z).GobDecode(buf)
 }
@@ -217,7 +433,8 @@ func (z *Int) Mod(y *Int) *Int {
 	return (*Int)(new(big.Int).Mod((*big.Int)(z), (*big.Int)(y)))
 }
 
-// ModInverse returns the inverse of this modulo the argument.
+// ModInverse returns the inverse of this modulo the argument, or nil if
+// there is none. See ModInverseChecked for a version that reports why.
 func (z *Int) ModInverse(n *Int) *Int {
 	return (*Int)(new(big.Int).ModInverse((*big.Int)(z), (*big.Int)(n)))
 }
@@ -276,7 +493,8 @@ func (z *Int) Rem(y *Int) *Int {
 
 // Scan implements the fmt.Scanner interface, and changes the underling big.Int.
 // WARNING: this breaks immutability since it can change the underlying data.
-// This is unavoidable with the way that scanning works.
+// This is unavoidable with the way that scanning works; see ScanInt, ReadInt,
+// and IntValue for alternatives.
 func (z *Int) Scan(s fmt.ScanState, ch rune) error {
 	return (*big.Int)(z).Scan(s, ch)
 }
@@ -308,14 +526,16 @@ func (z *Int) Uint64() uint64 {
 
 // UnmarshalJSON unmarshals the JSON buffer into this.
 // WARNING: this breaks immutability since it can change the underlying data.
-// This is unavoidable with the way that unmarshaling works.
+// This is unavoidable with the way that unmarshaling works; see ParseIntJSON
+// and IntValue for alternatives.
 func (z *Int) UnmarshalJSON(text []byte) error {
 	return (*big.Int)(z).UnmarshalJSON(text)
 }
 
 // UnmarshalText unmarshals the text buffer into this.
 // WARNING: this breaks immutability since it can change the underlying data.
-// This is unavoidable with the way that unmarshaling works.
+// This is unavoidable with the way that unmarshaling works; see ParseIntText
+// and IntValue for alternatives.
 func (z *Int) UnmarshalText(text []byte) error {
 	return (*big.Int)(z).UnmarshalText(text)
 }
@@ -0,0 +1,183 @@
+package mathx
+
+import "math/big"
+
+// IntBuilder is a mutable accumulator for building up an Int without
+// allocating a new value for every intermediate result. It is the
+// companion of the immutable Int, for use in tight loops. For example:
+//
+//	var b mathx.IntBuilder
+//	for i := range xs {
+//	  b.AddMul(xs[i], ys[i])
+//	}
+//	sum := b.Freeze()
+//
+// The zero value is ready to use and holds 0. An IntBuilder must not be
+// copied after first use, and is not safe for concurrent use.
+type IntBuilder struct {
+	x big.Int
+	t big.Int // scratch space for fused operations
+}
+
+// NewIntBuilder returns a new builder holding the value of x.
+func NewIntBuilder(x *Int) *IntBuilder {
+	b := new(IntBuilder)
+	b.x.Set((*big.Int)(x))
+	return b
+}
+
+// Set sets the builder to x and returns the builder.
+func (b *IntBuilder) Set(x *Int) *IntBuilder {
+	b.x.Set((*big.Int)(x))
+	return b
+}
+
+// SetInt64 sets the builder to x and returns the builder.
+func (b *IntBuilder) SetInt64(x int64) *IntBuilder {
+	b.x.SetInt64(x)
+	return b
+}
+
+// Add adds x to the builder and returns the builder.
+func (b *IntBuilder) Add(x *Int) *IntBuilder {
+	b.x.Add(&b.x, (*big.Int)(x))
+	return b
+}
+
+// Add64 adds x to the builder and returns the builder.
+func (b *IntBuilder) Add64(x int64) *IntBuilder {
+	b.x.Add(&b.x, b.t.SetInt64(x))
+	return b
+}
+
+// Sub subtracts x from the builder and returns the builder.
+func (b *IntBuilder) Sub(x *Int) *IntBuilder {
+	b.x.Sub(&b.x, (*big.Int)(x))
+	return b
+}
+
+// Sub64 subtracts x from the builder and returns the builder.
+func (b *IntBuilder) Sub64(x int64) *IntBuilder {
+	b.x.Sub(&b.x, b.t.SetInt64(x))
+	return b
+}
+
+// Mul multiplies the builder by x and returns the builder.
+func (b *IntBuilder) Mul(x *Int) *IntBuilder {
+	b.x.Mul(&b.x, (*big.Int)(x))
+	return b
+}
+
+// Mul64 multiplies the builder by x and returns the builder.
+func (b *IntBuilder) Mul64(x int64) *IntBuilder {
+	b.x.Mul(&b.x, b.t.SetInt64(x))
+	return b
+}
+
+// AddMul adds x*y to the builder and returns the builder.
+func (b *IntBuilder) AddMul(x, y *Int) *IntBuilder {
+	b.t.Mul((*big.Int)(x), (*big.Int)(y))
+	b.x.Add(&b.x, &b.t)
+	return b
+}
+
+// SubMul subtracts x*y from the builder and returns the builder.
+func (b *IntBuilder) SubMul(x, y *Int) *IntBuilder {
+	b.t.Mul((*big.Int)(x), (*big.Int)(y))
+	b.x.Sub(&b.x, &b.t)
+	return b
+}
+
+// Quo divides the builder by x with truncated (Go) division and returns
+// the builder.
+func (b *IntBuilder) Quo(x *Int) *IntBuilder {
+	b.x.Quo(&b.x, (*big.Int)(x))
+	return b
+}
+
+// Div divides the builder by x with Euclidean division and returns the
+// builder.
+func (b *IntBuilder) Div(x *Int) *IntBuilder {
+	b.x.Div(&b.x, (*big.Int)(x))
+	return b
+}
+
+// Mod sets the builder to its Euclidean modulus by m and returns the builder.
+func (b *IntBuilder) Mod(m *Int) *IntBuilder {
+	b.x.Mod(&b.x, (*big.Int)(m))
+	return b
+}
+
+// MulMod multiplies the builder by x modulo m and returns the builder.
+func (b *IntBuilder) MulMod(x, m *Int) *IntBuilder {
+	b.x.Mul(&b.x, (*big.Int)(x))
+	b.x.Mod(&b.x, (*big.Int)(m))
+	return b
+}
+
+// Exp raises the builder to the power y, modulo m if m != nil and
+// m != 0, and returns the builder.
+func (b *IntBuilder) Exp(y, m *Int) *IntBuilder {
+	b.x.Exp(&b.x, (*big.Int)(y), (*big.Int)(m))
+	return b
+}
+
+// Lsh shifts the builder left by n bits and returns the builder.
+func (b *IntBuilder) Lsh(n uint) *IntBuilder {
+	b.x.Lsh(&b.x, n)
+	return b
+}
+
+// Rsh shifts the builder right by n bits and returns the builder.
+func (b *IntBuilder) Rsh(n uint) *IntBuilder {
+	b.x.Rsh(&b.x, n)
+	return b
+}
+
+// Neg flips the sign of the builder and returns the builder.
+func (b *IntBuilder) Neg() *IntBuilder {
+	b.x.Neg(&b.x)
+	return b
+}
+
+// Abs sets the builder to its absolute value and returns the builder.
+func (b *IntBuilder) Abs() *IntBuilder {
+	b.x.Abs(&b.x)
+	return b
+}
+
+// Cmp compares the builder's current value to x, as in Int.Cmp.
+func (b *IntBuilder) Cmp(x *Int) int {
+	return b.x.Cmp((*big.Int)(x))
+}
+
+// Sign returns the sign of the builder's current value.
+func (b *IntBuilder) Sign() int {
+	return b.x.Sign()
+}
+
+// BitLen returns the size of the builder's current value in bits.
+func (b *IntBuilder) BitLen() int {
+	return b.x.BitLen()
+}
+
+// String returns the builder's current value in base 10.
+func (b *IntBuilder) String() string {
+	return b.x.String()
+}
+
+// Int returns a copy of the builder's current value. The builder is
+// unchanged.
+func (b *IntBuilder) Int() *Int {
+	return (*Int)(new(big.Int).Set(&b.x))
+}
+
+// Freeze returns the builder's current value as an immutable Int, and
+// resets the builder to 0. Unlike Int, it does not copy the value, so
+// it is the cheapest way to finish a computation.
+func (b *IntBuilder) Freeze() *Int {
+	z := new(big.Int)
+	*z = b.x
+	b.x = big.Int{}
+	return (*Int)(z)
+}
@@ -0,0 +1,70 @@
+package mathx
+
+import "testing"
+
+func TestIntBuilder(t *testing.T) {
+	var b IntBuilder
+	if b.Sign() != 0 || b.String() != "0" {
+		t.Fatalf("zero IntBuilder is %s", b.String())
+	}
+
+	xs := []int64{3, -5, 7, 11, -13}
+	ys := []int64{2, 4, -6, 8, 10}
+	expected := NewInt(0)
+	for i := range xs {
+		x, y := NewInt(xs[i]), NewInt(ys[i])
+		b.AddMul(x, y)
+		expected = expected.Add(x.Mul(y))
+	}
+	if b.Cmp(expected) != 0 {
+		t.Errorf("AddMul sum is %s, expected %s", b.String(), expected)
+	}
+
+	b.Mul64(1000).Add64(7).Sub(NewInt(3)).Lsh(5).Rsh(2).Neg().Abs()
+	expected = expected.Mul64(1000).Add64(7).Sub64(3).Lsh(5).Rsh(2).Neg().Abs()
+	if b.Cmp(expected) != 0 {
+		t.Errorf("chained ops gave %s, expected %s", b.String(), expected)
+	}
+
+	m := NewInt(1000003)
+	b.Exp(NewInt(65537), m).MulMod(NewInt(12345), m)
+	expected = expected.Exp(NewInt(65537), m).Mul64(12345).Mod(m)
+	if b.Cmp(expected) != 0 {
+		t.Errorf("Exp/MulMod gave %s, expected %s", b.String(), expected)
+	}
+
+	snapshot := b.Int()
+	frozen := b.Freeze()
+	if frozen.Cmp(expected) != 0 || snapshot.Cmp(expected) != 0 {
+		t.Errorf("Freeze gave %s, Int gave %s, expected %s", frozen, snapshot, expected)
+	}
+	if b.Sign() != 0 {
+		t.Errorf("builder should be reset after Freeze, got %s", b.String())
+	}
+	b.Add64(1)
+	if frozen.Cmp(expected) != 0 {
+		t.Errorf("frozen value changed after builder reuse: %s", frozen)
+	}
+
+	nb := NewIntBuilder(frozen)
+	nb.SubMul(frozen, NewInt(1))
+	if nb.Sign() != 0 || frozen.Cmp(expected) != 0 {
+		t.Errorf("NewIntBuilder aliases its argument")
+	}
+}
+
+func BenchmarkIntBuilderAddMul(b *testing.B) {
+	x, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	var acc IntBuilder
+	for i := 0; i < b.N; i++ {
+		acc.AddMul(x, x).Mod(x)
+	}
+}
+
+func BenchmarkIntAddMul(b *testing.B) {
+	x, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	acc := NewInt(0)
+	for i := 0; i < b.N; i++ {
+		acc = acc.Add(x.Mul(x)).Mod(x)
+	}
+}
@@ -0,0 +1,122 @@
+package mathx
+
+// This file is for solving systems of congruences with the Chinese
+// Remainder Theorem.
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrInconsistentCongruences is returned when a system of congruences has
+// no solution, which can only happen if the moduli are not pairwise coprime.
+var ErrInconsistentCongruences = errors.New("mathx: inconsistent system of congruences")
+
+// CRTBasis holds the precomputed data for reconstructing integers from
+// their residues modulo a fixed list of moduli, which need not be pairwise
+// coprime. It is immutable and safe for concurrent use.
+type CRTBasis struct {
+	moduli  []*big.Int
+	partial []*big.Int // lcm of the first i moduli
+	gcds    []*big.Int // gcd(partial[i], moduli[i])
+	steps   []*big.Int // moduli[i] / gcds[i]
+	invs    []*big.Int // (partial[i] / gcds[i])^-1 mod steps[i]
+	modulus *big.Int   // lcm of all moduli
+}
+
+// NewCRTBasis precomputes a basis for the given (positive) moduli.
+func NewCRTBasis(moduli []*Int) (*CRTBasis, error) {
+	if len(moduli) == 0 {
+		return nil, errors.New("mathx: CRT needs at least one modulus")
+	}
+	b := &CRTBasis{
+		moduli:  make([]*big.Int, len(moduli)),
+		partial: make([]*big.Int, len(moduli)),
+		gcds:    make([]*big.Int, len(moduli)),
+		steps:   make([]*big.Int, len(moduli)),
+		invs:    make([]*big.Int, len(moduli)),
+	}
+	m := big.NewInt(1)
+	for i, mi := range moduli {
+		if mi.Sign() <= 0 {
+			return nil, errors.New("mathx: CRT moduli must be positive")
+		}
+		b.moduli[i] = new(big.Int).Set((*big.Int)(mi))
+		b.partial[i] = m
+		g := new(big.Int).GCD(nil, nil, m, b.moduli[i])
+		b.gcds[i] = g
+		b.steps[i] = new(big.Int).Quo(b.moduli[i], g)
+		q := new(big.Int).Quo(m, g)
+		if b.steps[i].Cmp(bigOne) == 0 {
+			b.invs[i] = new(big.Int)
+		} else {
+			b.invs[i] = new(big.Int).ModInverse(q, b.steps[i])
+		}
+		m = new(big.Int).Mul(m, b.steps[i])
+	}
+	b.modulus = m
+	return b, nil
+}
+
+// Modulus returns the least common multiple of the moduli, which is the
+// modulus of the reconstructed values.
+func (b *CRTBasis) Modulus() *Int {
+	return (*Int)(new(big.Int).Set(b.modulus))
+}
+
+// Reconstruct returns the unique x with 0 <= x < b.Modulus() that is
+// congruent to residues[i] modulo the i-th modulus for every i. It returns
+// ErrInconsistentCongruences if there is no such x.
+func (b *CRTBasis) Reconstruct(residues []*Int) (*Int, error) {
+	if len(residues) != len(b.moduli) {
+		return nil, errors.New("mathx: CRT residues and moduli differ in length")
+	}
+	x := new(big.Int)
+	t := new(big.Int)
+	r := new(big.Int)
+	for i, ri := range residues {
+		// x is correct modulo partial[i]; find t so that
+		// x + partial[i]*t = ri (mod moduli[i])
+		t.Sub((*big.Int)(ri), x)
+		t.Mod(t, b.moduli[i])
+		t.QuoRem(t, b.gcds[i], r)
+		if r.Sign() != 0 {
+			return nil, ErrInconsistentCongruences
+		}
+		t.Mul(t, b.invs[i])
+		t.Mod(t, b.steps[i])
+		t.Mul(t, b.partial[i])
+		x.Add(x, t)
+	}
+	return (*Int)(x), nil
+}
+
+// ReconstructSigned is like Reconstruct, but returns the representative
+// x with -M/2 < x <= M/2, where M = b.Modulus(). This is the usual choice
+// when the result of a multi-modular computation may be negative.
+func (b *CRTBasis) ReconstructSigned(residues []*Int) (*Int, error) {
+	x, err := b.Reconstruct(residues)
+	if err != nil {
+		return nil, err
+	}
+	if x.Lsh(1).Cmp((*Int)(b.modulus)) > 0 {
+		return x.Sub((*Int)(b.modulus)), nil
+	}
+	return x, nil
+}
+
+// CRT returns the unique x with 0 <= x < lcm(moduli) such that
+// x = residues[i] (mod moduli[i]) for every i, along with lcm(moduli).
+// The moduli must be positive, but need not be pairwise coprime; if the
+// system has no solution, ErrInconsistentCongruences is returned.
+func CRT(residues, moduli []*Int) (*Int, *Int, error) {
+	b, err := NewCRTBasis(moduli)
+	if err != nil {
+		return nil, nil, err
+	}
+	x, err := b.Reconstruct(residues)
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, (*Int)(b.modulus), nil
+}
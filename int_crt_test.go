@@ -0,0 +1,90 @@
+package mathx
+
+import "testing"
+
+func int64s(xs ...int64) []*Int {
+	out := make([]*Int, len(xs))
+	for i, x := range xs {
+		out[i] = NewInt(x)
+	}
+	return out
+}
+
+func TestCRT(t *testing.T) {
+	cases := []struct {
+		residues []int64
+		moduli   []int64
+		x        int64
+		m        int64
+		err      error
+	}{
+		{[]int64{2, 3, 2}, []int64{3, 5, 7}, 23, 105, nil},
+		{[]int64{-1, -1}, []int64{4, 9}, 35, 36, nil},
+		{[]int64{3, 5}, []int64{4, 6}, 11, 12, nil},
+		{[]int64{3, 4}, []int64{4, 6}, 0, 0, ErrInconsistentCongruences},
+		{[]int64{1, 1, 1}, []int64{6, 10, 15}, 1, 30, nil},
+		{[]int64{5}, []int64{7}, 5, 7, nil},
+		{[]int64{0, 0}, []int64{12, 12}, 0, 12, nil},
+	}
+	for _, c := range cases {
+		x, m, err := CRT(int64s(c.residues...), int64s(c.moduli...))
+		if err != c.err {
+			t.Errorf("CRT(%v, %v) gave error %v, expected %v", c.residues, c.moduli, err, c.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if x.Int64() != c.x || m.Int64() != c.m {
+			t.Errorf("CRT(%v, %v) = %v mod %v, expected %d mod %d", c.residues, c.moduli, x, m, c.x, c.m)
+		}
+	}
+
+	if _, _, err := CRT(int64s(1), int64s(0)); err == nil {
+		t.Errorf("CRT with zero modulus should fail")
+	}
+	if _, _, err := CRT(int64s(1, 2), int64s(3)); err == nil {
+		t.Errorf("CRT with mismatched lengths should fail")
+	}
+}
+
+func TestCRTBasisBruteForce(t *testing.T) {
+	moduli := []int64{4, 6, 9, 10}
+	b, err := NewCRTBasis(int64s(moduli...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := b.Modulus().Int64()
+	if m != 180 {
+		t.Fatalf("modulus is %d, expected 180", m)
+	}
+	for x := int64(-m); x < 2*m; x++ {
+		residues := make([]int64, len(moduli))
+		for i, mi := range moduli {
+			residues[i] = x % mi
+		}
+		got, err := b.Reconstruct(int64s(residues...))
+		if err != nil || got.Int64() != ((x%m)+m)%m {
+			t.Fatalf("Reconstruct(%v) = %v, %v, expected %d", residues, got, err, x)
+		}
+		signed, _ := b.ReconstructSigned(int64s(residues...))
+		if s := signed.Int64(); s <= -m/2 || s > m/2 || ((s-x)%m) != 0 {
+			t.Fatalf("ReconstructSigned(%v) = %d", residues, s)
+		}
+	}
+	// 1 mod 4 and 0 mod 6 disagree modulo 2
+	if _, err := b.Reconstruct(int64s(1, 0, 0, 0)); err != ErrInconsistentCongruences {
+		t.Errorf("expected inconsistent system, got %v", err)
+	}
+}
+
+func TestCRTLarge(t *testing.T) {
+	p1, _ := NewIntFromString("170141183460469231731687303715884105727", 10)
+	p2, _ := NewIntFromString("618970019642690137449562111", 10)
+	x, _ := NewIntFromString("-12345678901234567890123456789012345678901234567890", 10)
+	b, _ := NewCRTBasis([]*Int{p1, p2, NewInt(1 << 20)})
+	got, err := b.ReconstructSigned([]*Int{x.Mod(p1), x.Mod(p2), x.Mod(NewInt(1 << 20))})
+	if err != nil || got.Cmp(x) != 0 {
+		t.Errorf("ReconstructSigned gave %v, %v, expected %v", got, err, x)
+	}
+}
@@ -0,0 +1,195 @@
+package mathx
+
+// This file is for integer logarithms and digit-level operations.
+
+import (
+	"math"
+	"math/big"
+)
+
+// Log2 returns floor(log2(|this|)). It panics if this is 0.
+func (z *Int) Log2() int {
+	if z.Sign() == 0 {
+		panic("mathx: Log2 of zero")
+	}
+	return z.BitLen() - 1
+}
+
+// Log returns floor(log_base(|this|)), without converting this to a
+// string. It panics if this is 0 or base < 2.
+func (z *Int) Log(base int) int {
+	if z.Sign() == 0 {
+		panic("mathx: Log of zero")
+	}
+	checkBase(base)
+	x := new(big.Int).Abs((*big.Int)(z))
+	if base&(base-1) == 0 {
+		return (x.BitLen() - 1) / log2Exact(base)
+	}
+	// estimate from the bit length, which is off by at most one, then
+	// correct by comparing with the power of the base.
+	e := int(float64(x.BitLen()-1) / math.Log2(float64(base)))
+	b := big.NewInt(int64(base))
+	p := new(big.Int).Exp(b, big.NewInt(int64(e)), nil)
+	for p.Cmp(x) > 0 {
+		p.Quo(p, b)
+		e--
+	}
+	for p.Mul(p, b).Cmp(x) <= 0 {
+		e++
+	}
+	return e
+}
+
+// log2Exact returns k such that base = 2^k, for base a power of 2.
+func log2Exact(base int) int {
+	k := 0
+	for base > 1 {
+		base >>= 1
+		k++
+	}
+	return k
+}
+
+func checkBase(base int) {
+	if base < 2 {
+		panic("mathx: invalid base")
+	}
+}
+
+// DigitCount returns the number of digits of this in the given base,
+// ignoring the sign. Zero has one digit.
+func (z *Int) DigitCount(base int) int {
+	checkBase(base)
+	if z.Sign() == 0 {
+		return 1
+	}
+	return z.Log(base) + 1
+}
+
+// Digits returns the digits of |this| in the given base, most
+// significant first. Zero has the single digit 0. Any base >= 2 is
+// allowed; large numbers are split recursively by powers of the base, so
+// this is subquadratic.
+func (z *Int) Digits(base int) []int {
+	checkBase(base)
+	x := new(big.Int).Abs((*big.Int)(z))
+	if x.Sign() == 0 {
+		return []int{0}
+	}
+	n := z.DigitCount(base)
+	digits := make([]int, n)
+	// powers[i] = base^(2^i)
+	powers := []*big.Int{big.NewInt(int64(base))}
+	for 1<<uint(len(powers)) < n {
+		last := powers[len(powers)-1]
+		powers = append(powers, new(big.Int).Mul(last, last))
+	}
+	fillDigits(digits, x, base, powers)
+	return digits
+}
+
+// fillDigits writes the digits of x into the end of the digits slice, most
+// significant first, leaving any leading positions untouched (they are
+// zero). x must have at most len(digits) digits.
+func fillDigits(digits []int, x *big.Int, base int, powers []*big.Int) {
+	if x.BitLen() < 64 {
+		v := x.Uint64()
+		b := uint64(base)
+		for i := len(digits) - 1; v > 0; i-- {
+			digits[i] = int(v % b)
+			v /= b
+		}
+		return
+	}
+	// split x = hi * base^(2^k) + lo with 2^k < len(digits)
+	k := 0
+	for 1<<uint(k+1) < len(digits) {
+		k++
+	}
+	hi, lo := new(big.Int).QuoRem(x, powers[k], new(big.Int))
+	m := len(digits) - 1<<uint(k)
+	fillDigits(digits[:m], hi, base, powers)
+	fillDigits(digits[m:], lo, base, powers)
+}
+
+// FromDigits returns the integer with the given digits in the given base,
+// most significant first. It is the inverse of Digits.
+func FromDigits(digits []int, base int) *Int {
+	checkBase(base)
+	if len(digits) <= 16 {
+		x := new(big.Int)
+		b := big.NewInt(int64(base))
+		t := new(big.Int)
+		for _, d := range digits {
+			x.Mul(x, b)
+			x.Add(x, t.SetInt64(int64(d)))
+		}
+		return (*Int)(x)
+	}
+	// combine halves, so that the big multiplications are balanced
+	m := len(digits) / 2
+	hi := (*big.Int)(FromDigits(digits[:len(digits)-m], base))
+	lo := (*big.Int)(FromDigits(digits[len(digits)-m:], base))
+	p := new(big.Int).Exp(big.NewInt(int64(base)), big.NewInt(int64(m)), nil)
+	hi.Mul(hi, p)
+	return (*Int)(hi.Add(hi, lo))
+}
+
+// DigitSum returns the sum of the digits of |this| in the given base.
+func (z *Int) DigitSum(base int) int {
+	s := 0
+	for _, d := range z.Digits(base) {
+		s += d
+	}
+	return s
+}
+
+// TrailingZeros returns the number of trailing zero digits of this in the
+// given base, that is, the largest v such that base^v divides this. It
+// returns 0 if this is 0.
+func (z *Int) TrailingZeros(base int) int {
+	checkBase(base)
+	if z.Sign() == 0 {
+		return 0
+	}
+	x := new(big.Int).Abs((*big.Int)(z))
+	if base&(base-1) == 0 {
+		return int(x.TrailingZeroBits()) / log2Exact(base)
+	}
+	// powers[i] = base^(2^i), up to about the size of x; the multiplicity
+	// is then found bit by bit from the top.
+	powers := []*big.Int{big.NewInt(int64(base))}
+	for {
+		last := powers[len(powers)-1]
+		if 2*last.BitLen()-1 > x.BitLen() {
+			break
+		}
+		powers = append(powers, new(big.Int).Mul(last, last))
+	}
+	v := 0
+	q, r := new(big.Int), new(big.Int)
+	for i := len(powers) - 1; i >= 0; i-- {
+		q.QuoRem(x, powers[i], r)
+		if r.Sign() == 0 {
+			x, q = q, x
+			v += 1 << uint(i)
+		}
+	}
+	return v
+}
+
+// Reverse returns the number formed by reversing the digits of this in the
+// given base, keeping the sign. Trailing zeros become leading zeros, and so
+// disappear.
+func (z *Int) Reverse(base int) *Int {
+	digits := z.Digits(base)
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	r := FromDigits(digits, base)
+	if z.Sign() < 0 {
+		return r.Neg()
+	}
+	return r
+}
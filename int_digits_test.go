@@ -0,0 +1,119 @@
+package mathx
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var digitTestNumbers = []string{
+	"0", "1", "-1", "9", "10", "11", "99", "100", "-1000", "12345678900",
+	"9223372036854775807", "9223372036854775808", "18446744073709551616",
+	"1000000000000000000000000000000000000000",
+	"999999999999999999999999999999999999999",
+	"-93845895110924997939619620205961794350920309182366517272043413179685324014761",
+	"114580143581984719060280282563142542662414835420671277376461092447281111712587",
+}
+
+func TestIntLogAndDigitCount(t *testing.T) {
+	for _, s := range digitTestNumbers {
+		n, _ := NewIntFromString(s, 10)
+		for base := 2; base <= 36; base++ {
+			text := strings.TrimPrefix(n.Text(base), "-")
+			if got := n.DigitCount(base); got != len(text) {
+				t.Errorf("DigitCount(%s, %d) = %d, expected %d", s, base, got, len(text))
+			}
+			if n.Sign() == 0 {
+				continue
+			}
+			if got := n.Log(base); got != len(text)-1 {
+				t.Errorf("Log(%s, %d) = %d, expected %d", s, base, got, len(text)-1)
+			}
+		}
+		if n.Sign() != 0 && n.Log2() != n.Log(2) {
+			t.Errorf("Log2(%s) = %d, expected %d", s, n.Log2(), n.Log(2))
+		}
+	}
+}
+
+func TestIntDigits(t *testing.T) {
+	for _, s := range digitTestNumbers {
+		n, _ := NewIntFromString(s, 10)
+		for base := 2; base <= 36; base++ {
+			text := strings.TrimPrefix(n.Text(base), "-")
+			digits := n.Digits(base)
+			sum := 0
+			if len(digits) != len(text) {
+				t.Fatalf("Digits(%s, %d) = %v, expected %s", s, base, digits, text)
+			}
+			for i, d := range digits {
+				c := text[i]
+				var expected int
+				if c >= 'a' {
+					expected = int(c-'a') + 10
+				} else {
+					expected = int(c - '0')
+				}
+				if d != expected {
+					t.Fatalf("Digits(%s, %d) = %v, expected %s", s, base, digits, text)
+				}
+				sum += d
+			}
+			if got := n.DigitSum(base); got != sum {
+				t.Errorf("DigitSum(%s, %d) = %d, expected %d", s, base, got, sum)
+			}
+			if got := FromDigits(digits, base); got.Cmp(n.Abs()) != 0 {
+				t.Errorf("FromDigits(Digits(%s, %d)) = %v", s, base, got)
+			}
+			zeros := len(text) - len(strings.TrimRight(text, "0"))
+			if n.Sign() == 0 {
+				zeros = 0
+			}
+			if got := n.TrailingZeros(base); got != zeros {
+				t.Errorf("TrailingZeros(%s, %d) = %d, expected %d", s, base, got, zeros)
+			}
+		}
+	}
+}
+
+func TestIntDigitsLargeBase(t *testing.T) {
+	base := 1000000007
+	x, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	x = x.Mul(x).Mul(x)
+	digits := x.Digits(base)
+	for _, d := range digits {
+		if d < 0 || d >= base {
+			t.Fatalf("digit %d out of range", d)
+		}
+	}
+	if FromDigits(digits, base).Cmp(x) != 0 {
+		t.Errorf("FromDigits(Digits(x)) != x in base %d", base)
+	}
+	if x.Mul(NewInt(int64(base)).Exp(NewInt(7), nil)).TrailingZeros(base) != 7 {
+		t.Errorf("TrailingZeros in base %d is wrong", base)
+	}
+}
+
+func TestIntReverse(t *testing.T) {
+	cases := []struct {
+		n, base  int64
+		expected int64
+	}{
+		{0, 10, 0},
+		{123, 10, 321},
+		{-1200, 10, -21},
+		{6, 2, 3},
+		{255, 16, 255},
+		{0x1f0, 16, 0xf1},
+	}
+	for _, c := range cases {
+		if got := NewInt(c.n).Reverse(int(c.base)); got.Int64() != c.expected {
+			t.Errorf("Reverse(%d, %d) = %v, expected %d", c.n, c.base, got, c.expected)
+		}
+	}
+	x := new(big.Int).Exp(big.NewInt(10), big.NewInt(1000), nil)
+	x.Sub(x, big.NewInt(1))
+	if got := (*Int)(x).Reverse(10); got.Cmp((*Int)(x)) != 0 {
+		t.Errorf("Reverse(10^1000 - 1) should be itself")
+	}
+}
@@ -12,33 +12,153 @@ func (z *Int) Float() *Float {
 	return (*Float)(new(big.Float).SetInt((*big.Int)(z)))
 }
 
-// Sqrt computes the square root of this number.
-// Uses Newton's Method.
+// Sqrt computes the square root of this number, rounded down.
+// Uses Newton's Method. Returns nil if this is negative.
 func (z *Int) Sqrt() *Int {
-	if z.BitLen() <= 52 {
-		sqrt := int64(math.Sqrt(float64(z.Int64())))
-		return NewInt(sqrt)
+	if z.Sign() < 0 {
+		return nil
 	}
+	return (*Int)(rootFloor((*big.Int)(z), 2))
+}
+
+// SqrtRem returns the square root of this number, rounded down, and the
+// remainder, so that this = s*s + r. Returns nil, nil if this is negative.
+func (z *Int) SqrtRem() (*Int, *Int) {
 	if z.Sign() < 0 {
+		return nil, nil
+	}
+	s := rootFloor((*big.Int)(z), 2)
+	r := new(big.Int).Mul(s, s)
+	r.Sub((*big.Int)(z), r)
+	return (*Int)(s), (*Int)(r)
+}
+
+// NthRoot returns the largest integer r such that r**k <= this, that is,
+// the floor of the real k-th root. If this is negative, k must be odd;
+// otherwise, nil is returned. NthRoot panics if k < 1.
+func (z *Int) NthRoot(k int) *Int {
+	if k < 1 {
+		panic("mathx: NthRoot with k < 1")
+	}
+	if z.Sign() >= 0 {
+		return (*Int)(rootFloor((*big.Int)(z), k))
+	}
+	if k&1 == 0 {
 		return nil
-	} else if z.Sign() == 0 {
-		return NewInt(0)
-	} else if z.Cmp(NewInt(1)) == 0 {
-		return NewInt(1)
 	}
+	// floor(-x^(1/k)) = -ceil(x^(1/k))
+	x := new(big.Int).Neg((*big.Int)(z))
+	r := rootFloor(x, k)
+	if new(big.Int).Exp(r, big.NewInt(int64(k)), nil).Cmp(x) != 0 {
+		r.Add(r, bigOne)
+	}
+	return (*Int)(r.Neg(r))
+}
 
-	// initial guess
-	s := z.Rsh(uint(z.BitLen() / 2))
-	t := NewInt(0)
+var bigOne = big.NewInt(1)
 
-	for i := 0; s.Cmp(t) != 0 && i < z.BitLen()/2+10; i++ {
-		// compute iteration
-		t = z.Div(s)
-		t = t.Add(s)
-		t = t.Rsh(1)
+// rootFloor computes floor(x^(1/k)) for x >= 0 and k >= 1 using Newton's
+// method, starting from an overestimate so that the iterates decrease
+// monotonically to the answer.
+func rootFloor(x *big.Int, k int) *big.Int {
+	if x.Sign() == 0 || k == 1 {
+		return new(big.Int).Set(x)
+	}
+	if x.BitLen() <= 52 {
+		// a float64 guess is within one of the root; correct it exactly.
+		n := x.Uint64()
+		r := uint64(math.Pow(float64(n), 1/float64(k)))
+		for r > 0 && powExceeds(r, k, n) {
+			r--
+		}
+		for !powExceeds(r+1, k, n) {
+			r++
+		}
+		return new(big.Int).SetUint64(r)
+	}
+	if k >= x.BitLen() {
+		// 1 <= x < 2^k, so the root is 1
+		return big.NewInt(1)
+	}
+
+	bk := big.NewInt(int64(k))
+	bk1 := big.NewInt(int64(k - 1))
+	// initial guess: 2^ceil(bitlen/k) > root
+	s := new(big.Int).Lsh(bigOne, uint((x.BitLen()+k-1)/k))
+	t := new(big.Int)
+	p := new(big.Int)
+	for {
+		// t = ((k-1)*s + x / s^(k-1)) / k
+		p.Exp(s, bk1, nil)
+		t.Quo(x, p)
+		p.Mul(s, bk1)
+		t.Add(t, p)
+		t.Quo(t, bk)
+		if t.Cmp(s) >= 0 {
+			return s
+		}
 		s, t = t, s
 	}
-	return s
+}
+
+// powExceeds returns true if r**k > n, without overflowing.
+func powExceeds(r uint64, k int, n uint64) bool {
+	p := uint64(1)
+	for i := 0; i < k; i++ {
+		if r != 0 && p > n/r {
+			return true
+		}
+		p *= r
+	}
+	return p > n
+}
+
+// Residue filters: isSquareMod[m][r] is true if r is a square modulo m,
+// and similarly for cubes. Most non-squares and non-cubes are rejected by
+// these tables before any root is computed.
+var (
+	squareFilterMods = []uint64{64, 63, 65, 11}
+	cubeFilterMods   = []uint64{63, 13, 19, 37}
+	isSquareMod      = makePowerResidues(squareFilterMods, 2)
+	isCubeMod        = makePowerResidues(cubeFilterMods, 3)
+)
+
+func makePowerResidues(mods []uint64, k int) [][]bool {
+	tables := make([][]bool, len(mods))
+	for i, m := range mods {
+		tables[i] = make([]bool, m)
+		for r := uint64(0); r < m; r++ {
+			p := uint64(1)
+			for j := 0; j < k; j++ {
+				p = p * r % m
+			}
+			tables[i][p] = true
+		}
+	}
+	return tables
+}
+
+// passesResidueFilter returns false if x is certainly not a k-th power,
+// judging only by its residues against the given tables.
+func passesResidueFilter(x *big.Int, mods []uint64, tables [][]bool) bool {
+	// 64 * 63 * 65 * 11 and 63 * 13 * 19 * 37 both fit in a word, so a
+	// single big division gives all of the residues.
+	prod := uint64(1)
+	for _, m := range mods {
+		prod *= m
+	}
+	var r uint64
+	if x.BitLen() <= 64 {
+		r = x.Uint64() % prod
+	} else {
+		r = new(big.Int).Mod(x, new(big.Int).SetUint64(prod)).Uint64()
+	}
+	for i, m := range mods {
+		if !tables[i][r%m] {
+			return false
+		}
+	}
+	return true
 }
 
 // IsSquare returns true if this number is a perfect square.
@@ -46,7 +166,84 @@ func IsSquare(z *Int) bool {
 	if z.Sign() < 0 {
 		return false
 	}
-	s := z.Sqrt()
-	s = s.Mul(s)
-	return s.Cmp(z) == 0
+	x := (*big.Int)(z)
+	if !passesResidueFilter(x, squareFilterMods, isSquareMod) {
+		return false
+	}
+	s := rootFloor(x, 2)
+	return s.Mul(s, s).Cmp(x) == 0
+}
+
+// IsCube returns true if this number is a perfect cube (possibly of a
+// negative number).
+func IsCube(z *Int) bool {
+	x := new(big.Int).Abs((*big.Int)(z))
+	if !passesResidueFilter(x, cubeFilterMods, isCubeMod) {
+		return false
+	}
+	s := rootFloor(x, 3)
+	return s.Exp(s, big.NewInt(3), nil).Cmp(x) == 0
+}
+
+// IsPerfectPower returns whether this number can be written as b**e for
+// some integer b and e >= 2. If so, it returns the smallest such base (in
+// absolute value) and the corresponding largest exponent. Negative numbers
+// are only perfect powers with odd exponents. 0, 1, and -1 are reported as
+// themselves squared (or cubed, for -1).
+func (z *Int) IsPerfectPower() (*Int, int, bool) {
+	x := new(big.Int).Abs((*big.Int)(z))
+	neg := z.Sign() < 0
+	if x.Cmp(bigOne) <= 0 {
+		if neg {
+			return z.copy(), 3, true
+		}
+		return z.copy(), 2, true
+	}
+
+	// repeatedly take prime roots until none are exact, so that the
+	// accumulated exponent is maximal.
+	base := x
+	exp := 1
+	for changed := true; changed; {
+		changed = false
+		for p := 2; p <= base.BitLen(); p++ {
+			if !isSmallPrime(p) || (neg && p == 2) {
+				continue
+			}
+			if p == 2 && !passesResidueFilter(base, squareFilterMods, isSquareMod) {
+				continue
+			}
+			if p == 3 && !passesResidueFilter(base, cubeFilterMods, isCubeMod) {
+				continue
+			}
+			r := rootFloor(base, p)
+			if new(big.Int).Exp(r, big.NewInt(int64(p)), nil).Cmp(base) == 0 {
+				base = r
+				exp *= p
+				changed = true
+				break
+			}
+		}
+	}
+	if exp == 1 {
+		return z.copy(), 1, false
+	}
+	if neg {
+		base = new(big.Int).Neg(base)
+	}
+	return (*Int)(base), exp, true
+}
+
+// isSmallPrime is trial division, which is plenty for exponents bounded
+// by a bit length.
+func isSmallPrime(p int) bool {
+	if p < 2 {
+		return false
+	}
+	for d := 2; d*d <= p; d++ {
+		if p%d == 0 {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,122 @@
+package mathx
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIntSqrtRem(t *testing.T) {
+	for n := int64(0); n < 5000; n++ {
+		s, r := NewInt(n).SqrtRem()
+		si, ri := s.Int64(), r.Int64()
+		if si*si+ri != n || ri < 0 || (si+1)*(si+1) <= n {
+			t.Fatalf("SqrtRem(%d) = %d, %d", n, si, ri)
+		}
+		if NewInt(n).Sqrt().Int64() != si {
+			t.Fatalf("Sqrt(%d) = %v, expected %d", n, NewInt(n).Sqrt(), si)
+		}
+	}
+	if s, r := NewInt(-4).SqrtRem(); s != nil || r != nil {
+		t.Errorf("SqrtRem(-4) should be nil")
+	}
+	if NewInt(-4).Sqrt() != nil {
+		t.Errorf("Sqrt(-4) should be nil")
+	}
+}
+
+func TestIntNthRoot(t *testing.T) {
+	cases := []string{
+		"0", "1", "2", "7", "8", "9", "4503599627370495", "4503599627370496",
+		"18446744073709551615", "18446744073709551616",
+		"93845895110924997939619620205961794350920309182366517272043413179685324014761",
+		"-1", "-7", "-8", "-9", "-27", "-28",
+	}
+	for _, c := range cases {
+		n, _ := NewIntFromString(c, 10)
+		for k := 1; k <= 9; k++ {
+			r := n.NthRoot(k)
+			if n.Sign() < 0 && k%2 == 0 {
+				if r != nil {
+					t.Errorf("NthRoot(%s, %d) should be nil", n, k)
+				}
+				continue
+			}
+			bk := NewInt(int64(k))
+			lo := r.Exp(bk, nil)
+			hi := r.Add64(1).Exp(bk, nil)
+			if lo.Cmp(n) > 0 || hi.Cmp(n) <= 0 {
+				t.Errorf("NthRoot(%s, %d) = %s", n, k, r)
+			}
+		}
+	}
+}
+
+func TestIsSquareAndCube(t *testing.T) {
+	squares := make(map[int64]bool)
+	cubes := make(map[int64]bool)
+	for i := int64(0); i*i <= 100000; i++ {
+		squares[i*i] = true
+	}
+	for i := int64(-50); i <= 50; i++ {
+		cubes[i*i*i] = true
+	}
+	for n := int64(-100000); n <= 100000; n++ {
+		if IsSquare(NewInt(n)) != squares[n] {
+			t.Fatalf("IsSquare(%d) != %v", n, squares[n])
+		}
+		if IsCube(NewInt(n)) != cubes[n] {
+			t.Fatalf("IsCube(%d) != %v", n, cubes[n])
+		}
+	}
+
+	big1, _ := NewIntFromString("114580143581984719060280282563142542662414835420671277376461092447281111712587", 10)
+	if !IsSquare(big1.Mul(big1)) || IsSquare(big1.Mul(big1).Add64(1)) {
+		t.Errorf("IsSquare failed for large square")
+	}
+	if !IsCube(big1.Mul(big1).Mul(big1).Neg()) || IsCube(big1.Mul(big1).Mul(big1).Sub64(1)) {
+		t.Errorf("IsCube failed for large cube")
+	}
+}
+
+func TestIsPerfectPower(t *testing.T) {
+	cases := []struct {
+		n    int64
+		base int64
+		exp  int
+		ok   bool
+	}{
+		{0, 0, 2, true},
+		{1, 1, 2, true},
+		{-1, -1, 3, true},
+		{2, 2, 1, false},
+		{4, 2, 2, true},
+		{8, 2, 3, true},
+		{-8, -2, 3, true},
+		{-4, -4, 1, false},
+		{64, 2, 6, true},
+		{-64, -4, 3, true},
+		{72, 72, 1, false},
+		{1 << 60, 2, 60, true},
+		{3486784401, 3, 20, true},
+		{1000000, 10, 6, true},
+		{1000001, 1000001, 1, false},
+	}
+	for _, c := range cases {
+		b, e, ok := NewInt(c.n).IsPerfectPower()
+		if b.Int64() != c.base || e != c.exp || ok != c.ok {
+			t.Errorf("IsPerfectPower(%d) = %v, %d, %v; expected %d, %d, %v", c.n, b, e, ok, c.base, c.exp, c.ok)
+		}
+	}
+	x := new(big.Int).Exp(big.NewInt(12), big.NewInt(35), nil)
+	b, e, ok := (*Int)(x).IsPerfectPower()
+	if !ok || b.Int64() != 12 || e != 35 {
+		t.Errorf("IsPerfectPower(12^35) = %v, %d, %v", b, e, ok)
+	}
+}
+
+func BenchmarkIsSquareNonSquare(b *testing.B) {
+	n, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	for i := 0; i < b.N; i++ {
+		IsSquare(n)
+	}
+}
@@ -0,0 +1,141 @@
+package mathx
+
+// This file is for quadratic residue symbols.
+
+import "math/big"
+
+// Jacobi returns the Jacobi symbol (a/n), which is -1, 0, or 1.
+// n must be odd and positive, otherwise Jacobi panics.
+func Jacobi(a, n *Int) int {
+	if n.Sign() <= 0 || n.Bit(0) == 0 {
+		panic("mathx: Jacobi with even or non-positive modulus")
+	}
+	if n.BitLen() < 63 && a.BitLen() < 64 {
+		return Jacobi64(a.Int64(), n.Int64())
+	}
+	return big.Jacobi((*big.Int)(a), (*big.Int)(n))
+}
+
+// Legendre returns the Legendre symbol (a/p), which is -1, 0, or 1.
+// p must be an odd prime; this is not checked, and for odd composite p
+// the result is the Jacobi symbol.
+func Legendre(a, p *Int) int {
+	return Jacobi(a, p)
+}
+
+// Kronecker returns the Kronecker symbol (a/n), which is -1, 0, or 1.
+// This extends the Jacobi symbol to all integers n, and is the symbol
+// used to describe the splitting of primes in quadratic fields of
+// discriminant a.
+func Kronecker(a, n *Int) int {
+	if n.BitLen() < 63 && a.BitLen() < 64 {
+		return Kronecker64(a.Int64(), n.Int64())
+	}
+	if n.Sign() == 0 {
+		if a.Abs().Cmp(NewInt(1)) == 0 {
+			return 1
+		}
+		return 0
+	}
+	k := 1
+	if n.Sign() < 0 {
+		if a.Sign() < 0 {
+			k = -k
+		}
+		n = n.Neg()
+	}
+	v := (*big.Int)(n).TrailingZeroBits()
+	if v > 0 {
+		if a.Bit(0) == 0 {
+			return 0
+		}
+		if v&1 == 1 {
+			k *= kronecker2(int64(a.Bit(0) | a.Bit(1)<<1 | a.Bit(2)<<2))
+		}
+		n = n.Rsh(v)
+	}
+	return k * big.Jacobi((*big.Int)(a), (*big.Int)(n))
+}
+
+// kronecker2 returns (a/2) given a mod 8.
+func kronecker2(a int64) int {
+	switch a & 7 {
+	case 1, 7:
+		return 1
+	case 3, 5:
+		return -1
+	}
+	return 0
+}
+
+// Jacobi64 returns the Jacobi symbol (a/n) for 64-bit arguments.
+// n must be odd and positive, otherwise Jacobi64 panics.
+func Jacobi64(a, n int64) int {
+	if n <= 0 || n&1 == 0 {
+		panic("mathx: Jacobi64 with even or non-positive modulus")
+	}
+	a %= n
+	if a < 0 {
+		a += n
+	}
+	return jacobiUint64(uint64(a), uint64(n))
+}
+
+// jacobiUint64 is the binary Jacobi symbol algorithm, for 0 <= a < n
+// and n odd.
+func jacobiUint64(a, n uint64) int {
+	j := 1
+	for a != 0 {
+		for a&1 == 0 {
+			a >>= 1
+			if r := n & 7; r == 3 || r == 5 {
+				j = -j
+			}
+		}
+		a, n = n, a
+		if a&3 == 3 && n&3 == 3 {
+			j = -j
+		}
+		a %= n
+	}
+	if n == 1 {
+		return j
+	}
+	return 0
+}
+
+// Legendre64 returns the Legendre symbol (a/p) for 64-bit arguments.
+// p must be an odd prime; this is not checked.
+func Legendre64(a, p int64) int {
+	return Jacobi64(a, p)
+}
+
+// Kronecker64 returns the Kronecker symbol (a/n) for 64-bit arguments.
+func Kronecker64(a, n int64) int {
+	if n == 0 {
+		if a == 1 || a == -1 {
+			return 1
+		}
+		return 0
+	}
+	k := 1
+	un := uint64(n)
+	if n < 0 {
+		if a < 0 {
+			k = -k
+		}
+		un = -un
+	}
+	for un&1 == 0 {
+		if a&1 == 0 {
+			return 0
+		}
+		un >>= 1
+		k *= kronecker2(a)
+	}
+	ua := uint64(a) % un
+	if a < 0 {
+		ua = (un - uint64(-(a+1))%un - 1) % un
+	}
+	return k * jacobiUint64(ua, un)
+}
@@ -0,0 +1,126 @@
+package mathx
+
+import "testing"
+
+var smallOddPrimes = []int64{3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47}
+
+// bruteLegendre computes (a/p) by listing the squares modulo p.
+func bruteLegendre(a, p int64) int {
+	a = ((a % p) + p) % p
+	if a == 0 {
+		return 0
+	}
+	for x := int64(1); x < p; x++ {
+		if x*x%p == a {
+			return 1
+		}
+	}
+	return -1
+}
+
+// bruteJacobi computes (a/n) from the factorization of odd n.
+func bruteJacobi(a, n int64) int {
+	j := 1
+	for p := int64(3); n > 1; p += 2 {
+		for n%p == 0 {
+			j *= bruteLegendre(a, p)
+			n /= p
+		}
+	}
+	return j
+}
+
+// bruteKronecker computes (a/n) from the definition.
+func bruteKronecker(a, n int64) int {
+	if n == 0 {
+		if a == 1 || a == -1 {
+			return 1
+		}
+		return 0
+	}
+	k := 1
+	if n < 0 {
+		if a < 0 {
+			k = -1
+		}
+		n = -n
+	}
+	for n%2 == 0 {
+		switch ((a % 8) + 8) % 8 {
+		case 1, 7:
+		case 3, 5:
+			k = -k
+		default:
+			return 0
+		}
+		n /= 2
+	}
+	return k * bruteJacobi(a, n)
+}
+
+func TestLegendre(t *testing.T) {
+	for _, p := range smallOddPrimes {
+		for a := -2 * p; a <= 2*p; a++ {
+			expected := bruteLegendre(a, p)
+			if got := Legendre64(a, p); got != expected {
+				t.Errorf("Legendre64(%d, %d) = %d, expected %d", a, p, got, expected)
+			}
+			if got := Legendre(NewInt(a), NewInt(p)); got != expected {
+				t.Errorf("Legendre(%d, %d) = %d, expected %d", a, p, got, expected)
+			}
+		}
+	}
+}
+
+func TestJacobi(t *testing.T) {
+	for n := int64(1); n < 200; n += 2 {
+		for a := int64(-60); a <= 60; a++ {
+			expected := bruteJacobi(a, n)
+			if got := Jacobi64(a, n); got != expected {
+				t.Errorf("Jacobi64(%d, %d) = %d, expected %d", a, n, got, expected)
+			}
+			if got := Jacobi(NewInt(a), NewInt(n)); got != expected {
+				t.Errorf("Jacobi(%d, %d) = %d, expected %d", a, n, got, expected)
+			}
+		}
+	}
+}
+
+func TestKronecker(t *testing.T) {
+	for n := int64(-100); n <= 100; n++ {
+		for a := int64(-60); a <= 60; a++ {
+			expected := bruteKronecker(a, n)
+			if got := Kronecker64(a, n); got != expected {
+				t.Errorf("Kronecker64(%d, %d) = %d, expected %d", a, n, got, expected)
+			}
+			if got := Kronecker(NewInt(a), NewInt(n)); got != expected {
+				t.Errorf("Kronecker(%d, %d) = %d, expected %d", a, n, got, expected)
+			}
+		}
+	}
+}
+
+func TestKroneckerLarge(t *testing.T) {
+	// multiplying the modulus by a large prime squared leaves the symbol
+	// unchanged for a coprime to it, and exercises the big path
+	p, _ := NewIntFromString("170141183460469231731687303715884105727", 10) // 2^127 - 1
+	p2 := p.Mul(p)
+	for n := int64(-40); n <= 40; n++ {
+		if n == 0 {
+			continue
+		}
+		for a := int64(-20); a <= 20; a++ {
+			expected := bruteKronecker(a, n)
+			if got := Kronecker(NewInt(a), NewInt(n).Mul(p2)); a != 0 && got != expected {
+				t.Errorf("Kronecker(%d, %d * p^2) = %d, expected %d", a, n, got, expected)
+			}
+			if n < 0 {
+				continue
+			}
+			// for n > 0, (a/n) is periodic in a with period 4n
+			if got := Kronecker(NewInt(a).Add(p2.Mul64(4*n)), NewInt(n)); got != expected {
+				t.Errorf("Kronecker(%d + 4*%d*p^2, %d) = %d, expected %d", a, n, n, got, expected)
+			}
+		}
+	}
+}
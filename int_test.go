@@ -0,0 +1,104 @@
+package mathx
+
+import "testing"
+
+func TestFactorial(t *testing.T) {
+	for n := int64(0); n < 500; n++ {
+		if got, expected := Factorial(n), MulRange(1, n); got.Cmp(expected) != 0 {
+			t.Fatalf("Factorial(%d) = %v, expected %v", n, got, expected)
+		}
+	}
+	for _, n := range []int64{1000, 4099, 10007} {
+		if Factorial(n).Cmp(MulRange(1, n)) != 0 {
+			t.Errorf("Factorial(%d) is wrong", n)
+		}
+	}
+}
+
+func TestDoubleFactorial(t *testing.T) {
+	for n := int64(-1); n < 200; n++ {
+		expected := NewInt(1)
+		for k := n; k > 1; k -= 2 {
+			expected = expected.Mul64(k)
+		}
+		if got := DoubleFactorial(n); got.Cmp(expected) != 0 {
+			t.Errorf("DoubleFactorial(%d) = %v, expected %v", n, got, expected)
+		}
+	}
+}
+
+func TestPrimorial(t *testing.T) {
+	expected := NewInt(1)
+	for n := int64(0); n < 1000; n++ {
+		if n > 1 && NewInt(n).ProbablyPrime(10) {
+			expected = expected.Mul64(n)
+		}
+		if got := Primorial(n); got.Cmp(expected) != 0 {
+			t.Fatalf("Primorial(%d) = %v, expected %v", n, got, expected)
+		}
+	}
+}
+
+func TestMultinomial(t *testing.T) {
+	cases := []struct {
+		ks       []int64
+		expected int64
+	}{
+		{[]int64{}, 1},
+		{[]int64{5}, 1},
+		{[]int64{2, 3}, 10},
+		{[]int64{1, 1, 1}, 6},
+		{[]int64{2, 2, 2}, 90},
+		{[]int64{0, 4, 0, 3}, 35},
+		{[]int64{1, 4, 4, 2}, 34650}, // MISSISSIPPI
+	}
+	for _, c := range cases {
+		if got := Multinomial(c.ks...); got.Int64() != c.expected {
+			t.Errorf("Multinomial(%v) = %v, expected %d", c.ks, got, c.expected)
+		}
+	}
+}
+
+func TestFibonacciLucas(t *testing.T) {
+	// F and L by the recurrence, for n in [-300, 300]
+	fib := make(map[int64]*Int)
+	luc := make(map[int64]*Int)
+	fib[0], fib[1] = NewInt(0), NewInt(1)
+	luc[0], luc[1] = NewInt(2), NewInt(1)
+	for n := int64(2); n <= 300; n++ {
+		fib[n] = fib[n-1].Add(fib[n-2])
+		luc[n] = luc[n-1].Add(luc[n-2])
+	}
+	for n := int64(-1); n >= -300; n-- {
+		fib[n] = fib[n+2].Sub(fib[n+1])
+		luc[n] = luc[n+2].Sub(luc[n+1])
+	}
+	m := NewInt(1000000007)
+	for n := int64(-300); n <= 300; n++ {
+		if got := Fibonacci(n); got.Cmp(fib[n]) != 0 {
+			t.Errorf("Fibonacci(%d) = %v, expected %v", n, got, fib[n])
+		}
+		if got := Lucas(n); got.Cmp(luc[n]) != 0 {
+			t.Errorf("Lucas(%d) = %v, expected %v", n, got, luc[n])
+		}
+		if got := FibonacciMod(n, m); got.Cmp(fib[n].Mod(m)) != 0 {
+			t.Errorf("FibonacciMod(%d, %v) = %v, expected %v", n, m, got, fib[n].Mod(m))
+		}
+	}
+	// the Pisano period of 10 is 60
+	if got := FibonacciMod(1000000000000000060, NewInt(10)); got.Cmp(FibonacciMod(1000000000000000000, NewInt(10))) != 0 {
+		t.Errorf("FibonacciMod does not have period 60 modulo 10")
+	}
+}
+
+func BenchmarkFactorial100000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Factorial(100000)
+	}
+}
+
+func BenchmarkMulRange100000(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		MulRange(1, 100000)
+	}
+}
@@ -0,0 +1,528 @@
+package mathx
+
+// This file is for Interval, closed intervals of Floats with outward
+// rounding, which give rigorous enclosures of real results.
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Tribool is the result of comparing intervals: TriTrue if the comparison
+// holds for every pair of points in them, TriFalse if it holds for none,
+// and TriUnknown otherwise.
+type Tribool uint8
+
+const (
+	// TriFalse is returned when a comparison holds for no points.
+	TriFalse Tribool = iota
+	// TriTrue is returned when a comparison holds for every point.
+	TriTrue
+	// TriUnknown is returned when a comparison holds for some points, but
+	// not others.
+	TriUnknown
+)
+
+// String returns "false", "true" or "unknown".
+func (t Tribool) String() string {
+	switch t {
+	case TriFalse:
+		return "false"
+	case TriTrue:
+		return "true"
+	}
+	return "unknown"
+}
+
+// tribool returns TriTrue if all holds, TriFalse if none holds, and
+// TriUnknown otherwise.
+func tribool(all, none bool) Tribool {
+	switch {
+	case all:
+		return TriTrue
+	case none:
+		return TriFalse
+	}
+	return TriUnknown
+}
+
+// Interval is an immutable closed interval [lo, hi] of real numbers with
+// Float endpoints, where lo may be -Inf and hi may be +Inf. Every
+// operation rounds its lower endpoint down and its upper endpoint up, so
+// that the result contains the value of the operation at every point of
+// the operands:
+//
+//	x := mathx.IntervalFromRat(mathx.NewRat(1, 10), 53) // 0.1 is not a Float
+//	y := x.Mul(x).Sub(mathx.IntervalFromRat(mathx.NewRat(1, 100), 53))
+//	y.Contains(mathx.NewFloat(0))                      // true
+//
+// Arithmetic rounds to the larger precision of the operands, and the
+// functions of an Interval to its precision, the larger of those of its
+// endpoints (or 64 bits if it has precision 0). The zero value is [0, 0].
+type Interval struct {
+	lo, hi *Float
+}
+
+// NewInterval returns [lo, hi]. It panics with ErrDomain if lo > hi, if lo
+// is +Inf or if hi is -Inf.
+func NewInterval(lo, hi *Float) *Interval {
+	if lo.Cmp(hi) > 0 || lo.IsInf() && lo.Sign() > 0 || hi.IsInf() && hi.Sign() < 0 {
+		panic(ErrDomain)
+	}
+	return &Interval{lo, hi}
+}
+
+// IntervalFromFloat returns [x, x]. It panics with ErrDomain if x is
+// infinite.
+func IntervalFromFloat(x *Float) *Interval {
+	return NewInterval(x, x)
+}
+
+// IntervalFromRat returns the least interval with prec-bit endpoints that
+// contains x.
+func IntervalFromRat(x *Rat, prec uint) *Interval {
+	return &Interval{
+		lower(x.Float(prec, big.ToNegativeInf)),
+		upper(x.Float(prec, big.ToPositiveInf)),
+	}
+}
+
+// Lo returns the lower endpoint of this.
+func (z *Interval) Lo() *Float {
+	if z.lo == nil {
+		return new(Float)
+	}
+	return z.lo
+}
+
+// Hi returns the upper endpoint of this.
+func (z *Interval) Hi() *Float {
+	if z.hi == nil {
+		return new(Float)
+	}
+	return z.hi
+}
+
+// Prec returns the precision of this, the larger of the precisions of its
+// endpoints.
+func (z *Interval) Prec() uint {
+	p, q := z.Lo().Prec(), z.Hi().Prec()
+	if q > p {
+		return q
+	}
+	return p
+}
+
+// precision returns the precision results are rounded to: that of z, or
+// 64 bits if z has precision 0.
+func (z *Interval) precision() uint {
+	if p := z.Prec(); p != 0 {
+		return p
+	}
+	return 64
+}
+
+// arithPrec returns the precision of the result of arithmetic on z and y.
+func (z *Interval) arithPrec(y *Interval) uint {
+	p := z.Prec()
+	if q := y.Prec(); q > p {
+		p = q
+	}
+	if p == 0 {
+		return 64
+	}
+	return p
+}
+
+// SetPrec returns the least interval with prec-bit endpoints that contains
+// this.
+func (z *Interval) SetPrec(prec uint) *Interval {
+	down, up := outward(prec)
+	return &Interval{lower(down.Set(z.Lo())), upper(up.Set(z.Hi()))}
+}
+
+// outward returns contexts rounding down and up to prec bits.
+func outward(prec uint) (down, up *FloatContext) {
+	return &FloatContext{Prec: prec, Mode: big.ToNegativeInf}, &FloatContext{Prec: prec, Mode: big.ToPositiveInf}
+}
+
+// lower returns x as a lower bound. Beyond the exponent range, big.Float
+// rounds to ±Inf or ±0 whatever the mode, so a result rounded up is moved
+// to the next Float down.
+func lower(x *Float) *Float {
+	if x.Acc() == big.Above {
+		return x.NextDown()
+	}
+	return x
+}
+
+// upper returns x as an upper bound, as lower does for a lower bound.
+func upper(x *Float) *Float {
+	if x.Acc() == big.Below {
+		return x.NextUp()
+	}
+	return x
+}
+
+// entire returns (-Inf, +Inf) with the precision.
+func entire(prec uint) *Interval {
+	return &Interval{(*Float)(newFloat(prec).SetInf(true)), (*Float)(newFloat(prec).SetInf(false))}
+}
+
+// IsBounded returns whether both endpoints of this are finite.
+func (z *Interval) IsBounded() bool {
+	return !z.Lo().IsInf() && !z.Hi().IsInf()
+}
+
+// IsPoint returns whether the endpoints of this are equal.
+func (z *Interval) IsPoint() bool {
+	return z.Lo().Cmp(z.Hi()) == 0
+}
+
+// Contains returns whether x is in this.
+func (z *Interval) Contains(x *Float) bool {
+	return z.Lo().Cmp(x) <= 0 && x.Cmp(z.Hi()) <= 0
+}
+
+// ContainsInterval returns whether every point of y is in this.
+func (z *Interval) ContainsInterval(y *Interval) bool {
+	return z.Lo().Cmp(y.Lo()) <= 0 && y.Hi().Cmp(z.Hi()) <= 0
+}
+
+// Width returns hi - lo, rounded up to the precision of this.
+func (z *Interval) Width() *Float {
+	_, up := outward(z.precision())
+	if !z.IsBounded() {
+		return up.NewFloat(0).inf(false)
+	}
+	return upper(up.Sub(z.Hi(), z.Lo()))
+}
+
+// Midpoint returns (lo + hi)/2, rounded to nearest at the precision of
+// this, which is a point of this. The midpoint of (-Inf, +Inf) is 0, and
+// that of a half-bounded interval is its infinite endpoint.
+func (z *Interval) Midpoint() *Float {
+	ctx := &FloatContext{Prec: z.precision()}
+	lo, hi := z.Lo(), z.Hi()
+	switch {
+	case lo.IsInf() && hi.IsInf():
+		return ctx.NewFloat(0)
+	case lo.IsInf():
+		return ctx.Set(lo)
+	case hi.IsInf():
+		return ctx.Set(hi)
+	}
+	// halving is exact, so rounding the sum is rounding the midpoint, unless
+	// the sum overflows
+	m := (*big.Float)(ctx.Add(lo, hi))
+	if m.IsInf() {
+		l, h := (*big.Float)(lo), (*big.Float)(hi)
+		return ctx.Add((*Float)(new(big.Float).SetMantExp(l, -1)), (*Float)(new(big.Float).SetMantExp(h, -1)))
+	}
+	return (*Float)(m.SetMantExp(m, -1))
+}
+
+// minFloat returns the least of xs.
+func minFloat(xs ...*Float) *Float {
+	m := xs[0]
+	for _, x := range xs[1:] {
+		if x.Cmp(m) < 0 {
+			m = x
+		}
+	}
+	return m
+}
+
+// maxFloat returns the greatest of xs.
+func maxFloat(xs ...*Float) *Float {
+	m := xs[0]
+	for _, x := range xs[1:] {
+		if x.Cmp(m) > 0 {
+			m = x
+		}
+	}
+	return m
+}
+
+// Intersect returns the intersection of this and y, and whether it is
+// nonempty; if it is empty, the interval is nil.
+func (z *Interval) Intersect(y *Interval) (*Interval, bool) {
+	lo, hi := maxFloat(z.Lo(), y.Lo()), minFloat(z.Hi(), y.Hi())
+	if lo.Cmp(hi) > 0 {
+		return nil, false
+	}
+	return &Interval{lo, hi}, true
+}
+
+// Hull returns the least interval containing this and y.
+func (z *Interval) Hull(y *Interval) *Interval {
+	return &Interval{minFloat(z.Lo(), y.Lo()), maxFloat(z.Hi(), y.Hi())}
+}
+
+// Less returns whether x < y for the points x of this and y of y.
+func (z *Interval) Less(y *Interval) Tribool {
+	return tribool(z.Hi().Cmp(y.Lo()) < 0, z.Lo().Cmp(y.Hi()) >= 0)
+}
+
+// LessEqual returns whether x <= y for the points x of this and y of y.
+func (z *Interval) LessEqual(y *Interval) Tribool {
+	return tribool(z.Hi().Cmp(y.Lo()) <= 0, z.Lo().Cmp(y.Hi()) > 0)
+}
+
+// Greater returns whether x > y for the points x of this and y of y.
+func (z *Interval) Greater(y *Interval) Tribool {
+	return y.Less(z)
+}
+
+// GreaterEqual returns whether x >= y for the points x of this and y of y.
+func (z *Interval) GreaterEqual(y *Interval) Tribool {
+	return y.LessEqual(z)
+}
+
+// Equal returns whether x == y for the points x of this and y of y, which
+// is TriTrue only if both are the same point.
+func (z *Interval) Equal(y *Interval) Tribool {
+	_, meet := z.Intersect(y)
+	return tribool(z.IsPoint() && y.IsPoint() && z.Lo().Cmp(y.Lo()) == 0, !meet)
+}
+
+// Add returns this + y.
+func (z *Interval) Add(y *Interval) *Interval {
+	down, up := outward(z.arithPrec(y))
+	return &Interval{lower(down.Add(z.Lo(), y.Lo())), upper(up.Add(z.Hi(), y.Hi()))}
+}
+
+// Sub returns this - y.
+func (z *Interval) Sub(y *Interval) *Interval {
+	down, up := outward(z.arithPrec(y))
+	return &Interval{lower(down.Sub(z.Lo(), y.Hi())), upper(up.Sub(z.Hi(), y.Lo()))}
+}
+
+// Neg returns -this.
+func (z *Interval) Neg() *Interval {
+	return &Interval{z.Hi().Neg(), z.Lo().Neg()}
+}
+
+// Abs returns the absolute values of the points of this.
+func (z *Interval) Abs() *Interval {
+	lo, hi := z.Lo(), z.Hi()
+	switch {
+	case lo.Sign() >= 0:
+		return z
+	case hi.Sign() <= 0:
+		return z.Neg()
+	}
+	return &Interval{new(Float).SetPrec(z.precision()), maxFloat(lo.Neg(), hi)}
+}
+
+// mulBounds returns x y rounded down and up, with 0 for zero times
+// infinity, which bounds the products of the finite points near them.
+func mulBounds(down, up *FloatContext, x, y *Float) (*Float, *Float) {
+	if x.Sign() == 0 || y.Sign() == 0 {
+		return down.NewFloat(0), up.NewFloat(0)
+	}
+	return lower(down.Mul(x, y)), upper(up.Mul(x, y))
+}
+
+// Mul returns this y.
+func (z *Interval) Mul(y *Interval) *Interval {
+	down, up := outward(z.arithPrec(y))
+	var los, his []*Float
+	for _, a := range []*Float{z.Lo(), z.Hi()} {
+		for _, b := range []*Float{y.Lo(), y.Hi()} {
+			lo, hi := mulBounds(down, up, a, b)
+			los, his = append(los, lo), append(his, hi)
+		}
+	}
+	return &Interval{minFloat(los...), maxFloat(his...)}
+}
+
+// Sqr returns the squares of the points of this, which is narrower than
+// this times this if this contains 0.
+func (z *Interval) Sqr() *Interval {
+	a := z.Abs()
+	down, up := outward(z.precision())
+	lo, _ := mulBounds(down, up, a.Lo(), a.Lo())
+	_, hi := mulBounds(down, up, a.Hi(), a.Hi())
+	return &Interval{lo, hi}
+}
+
+// Quo returns this / y. If y contains 0, the quotient is (-Inf, +Inf).
+func (z *Interval) Quo(y *Interval) *Interval {
+	prec := z.arithPrec(y)
+	if y.Contains(new(Float)) {
+		return entire(prec)
+	}
+	down, up := outward(prec)
+	var los, his []*Float
+	for _, a := range []*Float{z.Lo(), z.Hi()} {
+		for _, b := range []*Float{y.Lo(), y.Hi()} {
+			if a.IsInf() && b.IsInf() {
+				// the quotients of the points near them are 0 to ±Inf
+				inf := up.NewFloat(0).inf(a.Signbit() != b.Signbit())
+				los, his = append(los, minFloat(inf, down.NewFloat(0))), append(his, maxFloat(inf, up.NewFloat(0)))
+				continue
+			}
+			los, his = append(los, lower(down.Quo(a, b))), append(his, upper(up.Quo(a, b)))
+		}
+	}
+	return &Interval{minFloat(los...), maxFloat(his...)}
+}
+
+// increasing returns [f(lo), f(hi)] rounded outward to prec, for an
+// increasing f.
+func increasing(lo, hi *Float, prec uint, f func(c *FloatContext, x *Float) *Float) *Interval {
+	down, up := outward(prec)
+	return &Interval{lower(f(down, lo)), upper(f(up, hi))}
+}
+
+// Sqrt returns the square roots of the points of this that are at least 0.
+// It panics with ErrNaN if every point of this is negative.
+func (z *Interval) Sqrt() *Interval {
+	lo, hi := z.Lo(), z.Hi()
+	if hi.Sign() < 0 {
+		panic(ErrNaN)
+	}
+	if lo.Sign() < 0 {
+		lo = new(Float)
+	}
+	return increasing(lo, hi, z.precision(), (*FloatContext).Sqrt)
+}
+
+// Exp returns e**x for the points x of this.
+func (z *Interval) Exp() *Interval {
+	return increasing(z.Lo(), z.Hi(), z.precision(), (*FloatContext).Exp)
+}
+
+// Log returns the natural logarithms of the points of this that are
+// greater than 0, with a lower endpoint of -Inf if this contains 0. It
+// panics with ErrNaN if no point of this is greater than 0.
+func (z *Interval) Log() *Interval {
+	lo, hi := z.Lo(), z.Hi()
+	if hi.Sign() <= 0 {
+		panic(ErrNaN)
+	}
+	if lo.Sign() < 0 {
+		lo = new(Float)
+	}
+	return increasing(lo, hi, z.precision(), (*FloatContext).Log)
+}
+
+// clip returns the endpoints of this clipped to [-1, 1], and panics with
+// ErrNaN if this is disjoint from it.
+func (z *Interval) clip() (*Float, *Float) {
+	one := NewFloat(1)
+	unit := &Interval{one.Neg(), one}
+	x, ok := z.Intersect(unit)
+	if !ok {
+		panic(ErrNaN)
+	}
+	return x.Lo(), x.Hi()
+}
+
+// Asin returns the arcsines of the points of this in [-1, 1]. It panics
+// with ErrNaN if no point of this is in [-1, 1].
+func (z *Interval) Asin() *Interval {
+	lo, hi := z.clip()
+	return increasing(lo, hi, z.precision(), (*FloatContext).Asin)
+}
+
+// Acos returns the arccosines of the points of this in [-1, 1]. It panics
+// with ErrNaN if no point of this is in [-1, 1].
+func (z *Interval) Acos() *Interval {
+	lo, hi := z.clip()
+	// Acos decreases
+	down, up := outward(z.precision())
+	return &Interval{lower(down.Acos(hi)), upper(up.Acos(lo))}
+}
+
+// Atan returns the arctangents of the points of this.
+func (z *Interval) Atan() *Interval {
+	return increasing(z.Lo(), z.Hi(), z.precision(), (*FloatContext).Atan)
+}
+
+// Sinh returns the hyperbolic sines of the points of this.
+func (z *Interval) Sinh() *Interval {
+	return increasing(z.Lo(), z.Hi(), z.precision(), (*FloatContext).Sinh)
+}
+
+// Tanh returns the hyperbolic tangents of the points of this.
+func (z *Interval) Tanh() *Interval {
+	return increasing(z.Lo(), z.Hi(), z.precision(), (*FloatContext).Tanh)
+}
+
+// Cosh returns the hyperbolic cosines of the points of this.
+func (z *Interval) Cosh() *Interval {
+	a := z.Abs()
+	return increasing(a.Lo(), a.Hi(), z.precision(), (*FloatContext).Cosh)
+}
+
+// piInterval returns an interval of w-bit Floats containing Pi.
+func piInterval(w uint) *Interval {
+	p := piConstant.at(w)
+	// two ulps, more than the error of at
+	u := pow2(expo(p) - int(w) + 1)
+	return &Interval{(*Float)(newFloat(w).Sub(p, u)), (*Float)(newFloat(w).Add(p, u))}
+}
+
+// turns returns whether this, which must be bounded, contains a point
+// (c + m k) Pi/2 for an integer k.
+func (z *Interval) turns(c, m int64) bool {
+	w := z.precision() + 32
+	for _, x := range []*Float{z.Lo(), z.Hi()} {
+		if e := (*big.Float)(x).MantExp(nil); e > 0 {
+			w += uint(e)
+		}
+	}
+	// with t = (x/(Pi/2) - c)/m, the points are those where t is an integer
+	halfPi := piInterval(w).Quo(IntervalFromFloat(NewFloat(2)))
+	t := z.SetPrec(w).Quo(halfPi).Sub(IntervalFromFloat(NewFloat(float64(c)))).Quo(IntervalFromFloat(NewFloat(float64(m))))
+	return t.Lo().Ceil().Cmp(t.Hi().Floor()) <= 0
+}
+
+// sinCos returns f of the points of this, where f is sin with c = 1 or cos
+// with c = 0, which has maxima at (c + 4k) Pi/2 and minima at
+// (c + 2 + 4k) Pi/2.
+func (z *Interval) sinCos(c int64, f func(c *FloatContext, x *Float) *Float) *Interval {
+	down, up := outward(z.precision())
+	one := up.NewFloat(1)
+	if !z.IsBounded() {
+		return &Interval{one.Neg(), one}
+	}
+	lo := minFloat(lower(f(down, z.Lo())), lower(f(down, z.Hi())))
+	hi := maxFloat(upper(f(up, z.Lo())), upper(f(up, z.Hi())))
+	if z.turns(c, 4) {
+		hi = one
+	}
+	if z.turns(c+2, 4) {
+		lo = one.Neg()
+	}
+	return &Interval{lo, hi}
+}
+
+// Sin returns the sines of the points of this.
+func (z *Interval) Sin() *Interval {
+	return z.sinCos(1, (*FloatContext).Sin)
+}
+
+// Cos returns the cosines of the points of this.
+func (z *Interval) Cos() *Interval {
+	return z.sinCos(0, (*FloatContext).Cos)
+}
+
+// Tan returns the tangents of the points of this, or (-Inf, +Inf) if this
+// contains a pole, an odd multiple of Pi/2. As no Float is a pole, the
+// tangent is finite at every point of this, but an interval containing a
+// pole contains points with tangents of every size.
+func (z *Interval) Tan() *Interval {
+	if !z.IsBounded() || z.turns(1, 2) {
+		return entire(z.precision())
+	}
+	return increasing(z.Lo(), z.Hi(), z.precision(), (*FloatContext).Tan)
+}
+
+// String returns this as "[lo, hi]", with the endpoints formatted as by
+// Float.String.
+func (z *Interval) String() string {
+	return fmt.Sprintf("[%v, %v]", z.Lo(), z.Hi())
+}
@@ -0,0 +1,293 @@
+package mathx
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// randInterval returns an interval with 53-bit endpoints from randFloat64,
+// sometimes a point.
+func randInterval(rnd *rand.Rand) *Interval {
+	a, b := randFloat64(rnd), randFloat64(rnd)
+	if rnd.Intn(4) == 0 {
+		b = a
+	}
+	return NewInterval(NewFloat(math.Min(a, b)), NewFloat(math.Max(a, b)))
+}
+
+// points returns the endpoints of z and a point between them.
+func (z *Interval) points() []*Float {
+	return []*Float{z.Lo(), z.Midpoint(), z.Hi()}
+}
+
+func TestIntervalArithmetic(t *testing.T) {
+	ops := map[string]struct {
+		f     func(x, y *Interval) *Interval
+		exact func(x, y *big.Rat) *big.Rat
+	}{
+		"Add": {(*Interval).Add, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Add(x, y) }},
+		"Sub": {(*Interval).Sub, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Sub(x, y) }},
+		"Mul": {(*Interval).Mul, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) }},
+		"Quo": {(*Interval).Quo, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Quo(x, y) }},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for name, op := range ops {
+		for i := 0; i < 200; i++ {
+			x, y := randInterval(rnd), randInterval(rnd).SetPrec(uint(2+rnd.Intn(60)))
+			got := op.f(x, y)
+			if name == "Quo" && y.Contains(new(Float)) {
+				if got.Lo().Sign() > 0 || !got.Lo().IsInf() || !got.Hi().IsInf() {
+					t.Errorf("%v / %v = %v", x, y, got)
+				}
+				continue
+			}
+			lo, hi := RatFromFloat(got.Lo()), RatFromFloat(got.Hi())
+			for _, a := range x.points() {
+				for _, b := range y.points() {
+					v := (*Rat)(op.exact((*big.Rat)(RatFromFloat(a)), (*big.Rat)(RatFromFloat(b))))
+					if v.Cmp(lo) < 0 || v.Cmp(hi) > 0 {
+						t.Errorf("%s(%v, %v) = %v does not contain %s(%v, %v)", name, x, y, got, name, a, b)
+					}
+				}
+			}
+			// for points, the endpoints are the value rounded down and up
+			if x.IsPoint() && y.IsPoint() {
+				if got.Prec() != x.arithPrec(y) || got.Hi().Cmp(got.Lo().NextUp()) > 0 {
+					t.Errorf("%s(%v, %v) = %v (%d bits)", name, x, y, got, got.Prec())
+				}
+			}
+		}
+	}
+
+	// squares are narrower than products
+	x := NewInterval(NewFloat(-1), NewFloat(2))
+	if got := x.Sqr(); !sameFloat64(got.Lo(), 0) || !sameFloat64(got.Hi(), 4) {
+		t.Errorf("Sqr(%v) = %v", x, got)
+	}
+	if got := x.Mul(x); !sameFloat64(got.Lo(), -2) || !sameFloat64(got.Hi(), 4) {
+		t.Errorf("%v %v = %v", x, x, got)
+	}
+	if got := x.Abs(); !sameFloat64(got.Lo(), 0) || !sameFloat64(got.Hi(), 2) {
+		t.Errorf("Abs(%v) = %v", x, got)
+	}
+
+	// 0.1 is not a Float, but an interval contains it
+	tenth := IntervalFromRat(NewRat(1, 10), 53)
+	if got := tenth.Hi().Sub(tenth.Lo()); got.Cmp(tenth.Lo().Ulp()) != 0 {
+		t.Errorf("1/10 is in %v", tenth)
+	}
+	if got := tenth.Mul(tenth).Sub(IntervalFromRat(NewRat(1, 100), 53)); !got.Contains(new(Float)) {
+		t.Errorf("0.1**2 - 0.01 is in %v", got)
+	}
+}
+
+func TestIntervalFunctions(t *testing.T) {
+	funcs := map[string]struct {
+		f func(z *Interval) *Interval
+		g func(c *FloatContext, x *Float) *Float
+	}{
+		"Exp":  {(*Interval).Exp, (*FloatContext).Exp},
+		"Sin":  {(*Interval).Sin, (*FloatContext).Sin},
+		"Cos":  {(*Interval).Cos, (*FloatContext).Cos},
+		"Tan":  {(*Interval).Tan, (*FloatContext).Tan},
+		"Atan": {(*Interval).Atan, (*FloatContext).Atan},
+		"Sinh": {(*Interval).Sinh, (*FloatContext).Sinh},
+		"Cosh": {(*Interval).Cosh, (*FloatContext).Cosh},
+		"Tanh": {(*Interval).Tanh, (*FloatContext).Tanh},
+		"Sqrt": {(*Interval).Sqrt, (*FloatContext).Sqrt},
+		"Log":  {(*Interval).Log, (*FloatContext).Log},
+		"Asin": {(*Interval).Asin, (*FloatContext).Asin},
+		"Acos": {(*Interval).Acos, (*FloatContext).Acos},
+	}
+	rnd := rand.New(rand.NewSource(2))
+	down, up := outward(200)
+	for name, fn := range funcs {
+		for i := 0; i < 40; i++ {
+			z := randInterval(rnd)
+			switch name {
+			case "Exp", "Sinh", "Cosh":
+				z = z.Quo(IntervalFromFloat(NewFloat(64)))
+			case "Sqrt", "Log":
+				z = z.Abs()
+			case "Asin", "Acos":
+				z = z.Atan().Quo(IntervalFromFloat(NewFloat(2)))
+			}
+			if (name == "Log") && z.Lo().Sign() == 0 {
+				continue
+			}
+			got := fn.f(z)
+			// the value is between the values rounded down and up at 200 bits,
+			// so these hold however close it is to an endpoint
+			for _, x := range z.points() {
+				if got.Lo().Cmp(fn.g(up, x)) > 0 || got.Hi().Cmp(fn.g(down, x)) < 0 {
+					t.Errorf("%s(%v) = %v does not contain %s(%v) = %v", name, z, got, name, x, fn.g(up, x))
+				}
+			}
+			if z.IsPoint() && z.Lo().Sign() != 0 && got.Hi().Cmp(got.Lo().NextUp()) > 0 {
+				t.Errorf("%s(%v) = %v", name, z, got)
+			}
+		}
+	}
+}
+
+func TestIntervalTurns(t *testing.T) {
+	cases := []struct {
+		name   string
+		got    *Interval
+		lo, hi float64
+	}{
+		{"Sin([1, 2])", NewInterval(NewFloat(1), NewFloat(2)).Sin(), math.Sin(1), 1},
+		{"Sin([-2, 2])", NewInterval(NewFloat(-2), NewFloat(2)).Sin(), -1, 1},
+		{"Sin([4, 5])", NewInterval(NewFloat(4), NewFloat(5)).Sin(), -1, math.Sin(4)},
+		{"Cos([-1, 1])", NewInterval(NewFloat(-1), NewFloat(1)).Cos(), math.Cos(1), 1},
+		{"Cos([3, 4])", NewInterval(NewFloat(3), NewFloat(4)).Cos(), -1, math.Cos(4)},
+		{"Cos([0, 7])", NewInterval(NewFloat(0), NewFloat(7)).Cos(), -1, 1},
+		{"Sin([0, +Inf])", NewInterval(NewFloat(0), NewFloat(math.Inf(1))).Sin(), -1, 1},
+		{"Tan([1, 2])", NewInterval(NewFloat(1), NewFloat(2)).Tan(), math.Inf(-1), math.Inf(1)},
+		{"Tan([-1, 1])", NewInterval(NewFloat(-1), NewFloat(1)).Tan(), math.Tan(-1), math.Tan(1)},
+	}
+	for _, c := range cases {
+		lo, _ := c.got.Lo().Float64()
+		hi, _ := c.got.Hi().Float64()
+		if math.Abs(lo-c.lo) > 1e-15 || math.Abs(hi-c.hi) > 1e-15 || lo > c.lo || hi < c.hi && !math.IsInf(hi, 1) {
+			t.Errorf("%s = %v, expected [%v, %v]", c.name, c.got, c.lo, c.hi)
+		}
+	}
+
+	// the float64 nearest Pi/2 is below it, so the interval does not
+	// contain the zero of the cosine or the pole of the tangent
+	x := IntervalFromFloat(NewFloat(math.Pi / 2))
+	if got := x.Cos(); got.Lo().Sign() <= 0 {
+		t.Errorf("Cos(%v) = %v", x, got)
+	}
+	if got := x.Tan(); got.Hi().IsInf() {
+		t.Errorf("Tan(%v) = %v", x, got)
+	}
+	// far from 0, finding the turning points needs more precision
+	big := IntervalFromFloat(NewFloat(1e22))
+	if got := big.Sin(); got.Hi().Cmp(got.Lo().NextUp()) > 0 {
+		t.Errorf("Sin(%v) = %v", big, got)
+	}
+}
+
+func TestIntervalSpecial(t *testing.T) {
+	inf := NewFloat(math.Inf(1))
+	whole := NewInterval(inf.Neg(), inf)
+	pos := NewInterval(NewFloat(1), inf)
+	cases := []struct {
+		name   string
+		got    *Interval
+		lo, hi float64
+	}{
+		{"[1, Inf] + [1, Inf]", pos.Add(pos), 2, math.Inf(1)},
+		{"[1, Inf] - [1, Inf]", pos.Sub(pos), math.Inf(-1), math.Inf(1)},
+		{"[0, 1] [1, Inf]", NewInterval(NewFloat(0), NewFloat(1)).Mul(pos), 0, math.Inf(1)},
+		{"[1, Inf] / [1, Inf]", pos.Quo(pos), 0, math.Inf(1)},
+		{"1 / [1, Inf]", IntervalFromFloat(NewFloat(1)).Quo(pos), 0, 1},
+		{"1 / [-1, 1]", IntervalFromFloat(NewFloat(1)).Quo(NewInterval(NewFloat(-1), NewFloat(1))), math.Inf(-1), math.Inf(1)},
+		{"Exp(-Inf, Inf)", whole.Exp(), 0, math.Inf(1)},
+		{"Atan(-Inf, Inf)", whole.Atan(), -math.Pi / 2, math.Pi / 2},
+		{"Log([-1, 1])", NewInterval(NewFloat(-1), NewFloat(1)).Log(), math.Inf(-1), 0},
+		{"Sqrt([-1, 4])", NewInterval(NewFloat(-1), NewFloat(4)).Sqrt(), 0, 2},
+		{"Asin([0, 2])", NewInterval(NewFloat(0), NewFloat(2)).Asin(), 0, math.Pi / 2},
+		{"Acos([-2, 1])", NewInterval(NewFloat(-2), NewFloat(1)).Acos(), 0, math.Pi},
+		{"Cosh([-1, 2])", NewInterval(NewFloat(-1), NewFloat(2)).Cosh(), 1, math.Cosh(2)},
+		{"zero value", new(Interval).Add(IntervalFromFloat(NewFloat(1))), 1, 1},
+	}
+	for _, c := range cases {
+		lo, _ := c.got.Lo().Float64()
+		hi, _ := c.got.Hi().Float64()
+		if math.Abs(lo-c.lo) > 1e-15 || hi != c.hi && math.Abs(hi-c.hi) > 1e-15 {
+			t.Errorf("%s = %v, expected [%v, %v]", c.name, c.got, c.lo, c.hi)
+		}
+	}
+
+	// beyond the exponent range, the endpoints stay on the right side
+	huge := IntervalFromFloat(NewFloat(1e10))
+	if got := huge.Exp(); got.Lo().IsInf() || !got.Hi().IsInf() {
+		t.Errorf("Exp(%v) = %v", huge, got)
+	}
+	if got := huge.Neg().Exp(); got.Lo().Sign() != 0 || got.Hi().Sign() <= 0 {
+		t.Errorf("Exp(%v) = %v", huge.Neg(), got)
+	}
+
+	for name, f := range map[string]func(){
+		"NewInterval(2, 1)":     func() { NewInterval(NewFloat(2), NewFloat(1)) },
+		"NewInterval(Inf, Inf)": func() { IntervalFromFloat(inf) },
+		"Sqrt([-2, -1])":        func() { NewInterval(NewFloat(-2), NewFloat(-1)).Sqrt() },
+		"Log([-1, 0])":          func() { NewInterval(NewFloat(-1), NewFloat(0)).Log() },
+		"Asin([2, 3])":          func() { NewInterval(NewFloat(2), NewFloat(3)).Asin() },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrDomain && r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+			t.Errorf("%s did not panic", name)
+		}()
+	}
+}
+
+func TestIntervalCompare(t *testing.T) {
+	i := func(lo, hi float64) *Interval { return NewInterval(NewFloat(lo), NewFloat(hi)) }
+	cases := []struct {
+		x, y                *Interval
+		less, lessEq, equal Tribool
+	}{
+		{i(1, 2), i(3, 4), TriTrue, TriTrue, TriFalse},
+		{i(1, 2), i(2, 4), TriUnknown, TriTrue, TriUnknown},
+		{i(1, 3), i(2, 4), TriUnknown, TriUnknown, TriUnknown},
+		{i(3, 4), i(1, 2), TriFalse, TriFalse, TriFalse},
+		{i(2, 4), i(1, 2), TriFalse, TriUnknown, TriUnknown},
+		{i(2, 2), i(2, 2), TriFalse, TriTrue, TriTrue},
+	}
+	for _, c := range cases {
+		if got := c.x.Less(c.y); got != c.less {
+			t.Errorf("%v < %v is %v", c.x, c.y, got)
+		}
+		if got := c.x.LessEqual(c.y); got != c.lessEq {
+			t.Errorf("%v <= %v is %v", c.x, c.y, got)
+		}
+		if got := c.x.Equal(c.y); got != c.equal {
+			t.Errorf("%v == %v is %v", c.x, c.y, got)
+		}
+		if c.y.Greater(c.x) != c.less || c.y.GreaterEqual(c.x) != c.lessEq {
+			t.Errorf("%v > %v is %v", c.y, c.x, c.y.Greater(c.x))
+		}
+	}
+	if s := TriUnknown.String(); s != "unknown" {
+		t.Errorf("TriUnknown is %q", s)
+	}
+
+	x, y := i(1, 3), i(2, 5)
+	if got, ok := x.Intersect(y); !ok || got.String() != "[2, 3]" {
+		t.Errorf("%v ∩ %v = %v, %v", x, y, got, ok)
+	}
+	if got, ok := x.Intersect(i(4, 5)); ok || got != nil {
+		t.Errorf("%v ∩ [4, 5] = %v, %v", x, got, ok)
+	}
+	if got := x.Hull(i(4, 5)); got.String() != "[1, 5]" {
+		t.Errorf("hull of %v and [4, 5] = %v", x, got)
+	}
+	if got := y.Width(); !sameFloat64(got, 3) {
+		t.Errorf("width of %v = %v", y, got)
+	}
+	if got := y.Midpoint(); !sameFloat64(got, 3.5) {
+		t.Errorf("midpoint of %v = %v", y, got)
+	}
+	if got := i(1, math.Inf(1)).Width(); !got.IsInf() {
+		t.Errorf("width of [1, +Inf] = %v", got)
+	}
+	if got := i(math.Inf(-1), math.Inf(1)).Midpoint(); !sameFloat64(got, 0) {
+		t.Errorf("midpoint of (-Inf, +Inf) = %v", got)
+	}
+	// the width of a narrow interval is rounded up
+	tenth := IntervalFromRat(NewRat(1, 10), 53)
+	if w := tenth.Add(IntervalFromFloat(NewFloat(1e10))).Width(); w.Sign() <= 0 {
+		t.Errorf("width of %v = %v", tenth, w)
+	}
+}
@@ -0,0 +1,428 @@
+package mathx
+
+// This file is for fast arithmetic modulo a fixed integer.
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// ModRing is the ring of integers modulo a fixed m, with the per-modulus
+// work done once up front. Elements of odd moduli are kept in Montgomery
+// form, so that multiplication needs no division; even moduli use Barrett
+// reduction. This is intended for loops that do many modular operations
+// against the same modulus, such as primality testing and factoring:
+//
+//	r := mathx.NewModRing(n)
+//	x := r.Elem(a)
+//	y := x.Exp(e).Mul(x)
+//	fmt.Println(y.Int())
+//
+// A ModRing is immutable and safe for concurrent use.
+type ModRing struct {
+	m    *big.Int
+	mont bool
+
+	// Montgomery parameters, for odd m, with R = 2^(n*_W)
+	mw   []big.Word // the words of m, length n
+	n    int
+	minv big.Word // -m^-1 mod 2^_W
+	r2   *big.Int // R^2 mod m
+
+	// Barrett parameters, for even m
+	k  uint     // bit length of m
+	mu *big.Int // floor(4^k / m)
+}
+
+// ModInt is an element of a ModRing. Like Int, it is immutable, and each
+// operation returns a new element. Elements of different rings must not
+// be mixed.
+type ModInt struct {
+	ring *ModRing
+	x    *big.Int // in Montgomery form for odd moduli
+}
+
+// NewModRing returns the ring of integers modulo m. It panics if m < 1.
+func NewModRing(m *Int) *ModRing {
+	if m.Sign() <= 0 {
+		panic("mathx: ModRing modulus must be positive")
+	}
+	r := &ModRing{m: new(big.Int).Set((*big.Int)(m))}
+	if r.m.Bit(0) == 1 {
+		r.mont = true
+		r.mw = r.m.Bits()
+		r.n = len(r.mw)
+		// Newton's iteration doubles the number of correct low bits
+		inv := r.mw[0]
+		for i := 0; i < 6; i++ {
+			inv *= 2 - r.mw[0]*inv
+		}
+		r.minv = -inv
+		r.r2 = new(big.Int).Lsh(bigOne, uint(2*r.n*bits.UintSize))
+		r.r2.Mod(r.r2, r.m)
+	} else {
+		r.k = uint(r.m.BitLen())
+		r.mu = new(big.Int).Lsh(bigOne, 2*r.k)
+		r.mu.Quo(r.mu, r.m)
+	}
+	return r
+}
+
+// Modulus returns the modulus of this ring.
+func (r *ModRing) Modulus() *Int {
+	return (*Int)(new(big.Int).Set(r.m))
+}
+
+// Elem returns x reduced into this ring.
+func (r *ModRing) Elem(x *Int) *ModInt {
+	v := new(big.Int).Mod((*big.Int)(x), r.m)
+	if r.mont {
+		v = r.montMul(v, r.r2)
+	}
+	return &ModInt{r, v}
+}
+
+// Elem64 returns x reduced into this ring.
+func (r *ModRing) Elem64(x int64) *ModInt {
+	return r.Elem(NewInt(x))
+}
+
+// Zero returns the additive identity of this ring.
+func (r *ModRing) Zero() *ModInt {
+	return &ModInt{r, new(big.Int)}
+}
+
+// One returns the multiplicative identity of this ring.
+func (r *ModRing) One() *ModInt {
+	return r.Elem64(1)
+}
+
+// mul returns x*y reduced modulo m, in whichever form this ring uses.
+func (r *ModRing) mul(x, y *big.Int) *big.Int {
+	if r.mont {
+		return r.montMul(x, y)
+	}
+	return r.barrett(new(big.Int).Mul(x, y))
+}
+
+// barrett reduces 0 <= x < m^2 modulo m.
+func (r *ModRing) barrett(x *big.Int) *big.Int {
+	q := new(big.Int).Rsh(x, r.k-1)
+	q.Mul(q, r.mu)
+	q.Rsh(q, r.k+1)
+	q.Mul(q, r.m)
+	x.Sub(x, q)
+	for x.Cmp(r.m) >= 0 {
+		x.Sub(x, r.m)
+	}
+	return x
+}
+
+// montMul returns x*y/R mod m for 0 <= x, y < m.
+func (r *ModRing) montMul(x, y *big.Int) *big.Int {
+	z := make([]big.Word, r.n)
+	t := make([]big.Word, r.n+2)
+	r.montMulWords(z, r.pad(x), r.pad(y), t)
+	return new(big.Int).SetBits(z)
+}
+
+// pad returns the words of 0 <= x < m, extended to the length of m.
+func (r *ModRing) pad(x *big.Int) []big.Word {
+	w := x.Bits()
+	if len(w) == r.n {
+		return w
+	}
+	p := make([]big.Word, r.n)
+	copy(p, w)
+	return p
+}
+
+// montMulWords sets z to a*b/R mod m, using the coarsely integrated
+// operand scanning (CIOS) method. z, a, and b have the length of m, and
+// t is scratch space of two more words; z may alias a or b.
+func (r *ModRing) montMulWords(z, a, b, t []big.Word) {
+	n := r.n
+	for i := range t {
+		t[i] = 0
+	}
+	for i := 0; i < n; i++ {
+		// t += a * b[i]
+		var c big.Word
+		for j := 0; j < n; j++ {
+			c, t[j] = mulAddWWW(a[j], b[i], t[j], c)
+		}
+		t[n], c = addWW(t[n], c)
+		t[n+1] = c
+
+		// t = (t + q*m) / 2^_W, where q makes the low word vanish
+		q := t[0] * r.minv
+		c, _ = mulAddWWW(q, r.mw[0], t[0], 0)
+		for j := 1; j < n; j++ {
+			c, t[j-1] = mulAddWWW(q, r.mw[j], t[j], c)
+		}
+		t[n-1], c = addWW(t[n], c)
+		t[n] = t[n+1] + c
+	}
+	if t[n] != 0 || cmpWords(t[:n], r.mw) >= 0 {
+		var borrow uint
+		for j := 0; j < n; j++ {
+			var d uint
+			d, borrow = bits.Sub(uint(t[j]), uint(r.mw[j]), borrow)
+			t[j] = big.Word(d)
+		}
+	}
+	copy(z, t[:n])
+}
+
+// mulAddWWW returns the high and low words of x*y + c + d.
+func mulAddWWW(x, y, c, d big.Word) (big.Word, big.Word) {
+	hi, lo := bits.Mul(uint(x), uint(y))
+	var cc uint
+	lo, cc = bits.Add(lo, uint(c), 0)
+	hi += cc
+	lo, cc = bits.Add(lo, uint(d), 0)
+	hi += cc
+	return big.Word(hi), big.Word(lo)
+}
+
+// addWW returns the sum and carry of x + y.
+func addWW(x, y big.Word) (big.Word, big.Word) {
+	s, c := bits.Add(uint(x), uint(y), 0)
+	return big.Word(s), big.Word(c)
+}
+
+// cmpWords compares two little-endian word slices of the same length.
+func cmpWords(x, y []big.Word) int {
+	for i := len(x) - 1; i >= 0; i-- {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Ring returns the ring that this element belongs to.
+func (z *ModInt) Ring() *ModRing {
+	return z.ring
+}
+
+// Int returns the representative of this element in [0, m).
+func (z *ModInt) Int() *Int {
+	if z.ring.mont {
+		return (*Int)(z.ring.montMul(z.x, bigOne))
+	}
+	return (*Int)(new(big.Int).Set(z.x))
+}
+
+// String returns the representative of this element in base 10.
+func (z *ModInt) String() string {
+	return z.Int().String()
+}
+
+// IsZero returns true if this is the zero element.
+func (z *ModInt) IsZero() bool {
+	return z.x.Sign() == 0
+}
+
+// Equal returns true if this and y are the same element.
+func (z *ModInt) Equal(y *ModInt) bool {
+	return z.x.Cmp(y.x) == 0
+}
+
+// Add returns this plus y.
+func (z *ModInt) Add(y *ModInt) *ModInt {
+	s := new(big.Int).Add(z.x, y.x)
+	if s.Cmp(z.ring.m) >= 0 {
+		s.Sub(s, z.ring.m)
+	}
+	return &ModInt{z.ring, s}
+}
+
+// Sub returns this minus y.
+func (z *ModInt) Sub(y *ModInt) *ModInt {
+	s := new(big.Int).Sub(z.x, y.x)
+	if s.Sign() < 0 {
+		s.Add(s, z.ring.m)
+	}
+	return &ModInt{z.ring, s}
+}
+
+// Neg returns the additive inverse of this.
+func (z *ModInt) Neg() *ModInt {
+	if z.x.Sign() == 0 {
+		return z
+	}
+	return &ModInt{z.ring, new(big.Int).Sub(z.ring.m, z.x)}
+}
+
+// Mul returns this times y.
+func (z *ModInt) Mul(y *ModInt) *ModInt {
+	return &ModInt{z.ring, z.ring.mul(z.x, y.x)}
+}
+
+// Square returns this times itself.
+func (z *ModInt) Square() *ModInt {
+	return &ModInt{z.ring, z.ring.mul(z.x, z.x)}
+}
+
+// Inv returns the multiplicative inverse of this, and false if there is
+// none (that is, if this is not coprime to the modulus).
+func (z *ModInt) Inv() (*ModInt, bool) {
+	a := (*big.Int)(z.Int())
+	if new(big.Int).GCD(nil, nil, a, z.ring.m).Cmp(bigOne) != 0 {
+		return nil, false
+	}
+	if z.ring.m.Cmp(bigOne) == 0 {
+		return z, true
+	}
+	return z.ring.Elem((*Int)(a.ModInverse(a, z.ring.m))), true
+}
+
+// Exp returns this raised to the power e, using sliding-window
+// exponentiation. If e is negative, this must be invertible; otherwise,
+// Exp returns nil.
+func (z *ModInt) Exp(e *Int) *ModInt {
+	x := z
+	if e.Sign() < 0 {
+		var ok bool
+		if x, ok = z.Inv(); !ok {
+			return nil
+		}
+		e = e.Neg()
+	}
+	ebits := (*big.Int)(e)
+	nbits := ebits.BitLen()
+	if nbits == 0 {
+		return z.ring.One()
+	}
+	w := expWindowSize(nbits)
+	if z.ring.mont {
+		return &ModInt{z.ring, z.ring.montExp(x.x, ebits, w)}
+	}
+
+	// odd powers x, x^3, ..., x^(2^w - 1)
+	table := make([]*big.Int, 1<<uint(w-1))
+	table[0] = x.x
+	if w > 1 {
+		x2 := z.ring.mul(x.x, x.x)
+		for i := 1; i < len(table); i++ {
+			table[i] = z.ring.mul(table[i-1], x2)
+		}
+	}
+
+	var acc *big.Int
+	for i := nbits - 1; i >= 0; {
+		if ebits.Bit(i) == 0 {
+			acc = z.ring.mul(acc, acc)
+			i--
+			continue
+		}
+		// find the longest window ending in a one bit
+		j := i - w + 1
+		if j < 0 {
+			j = 0
+		}
+		for ebits.Bit(j) == 0 {
+			j++
+		}
+		v := 0
+		for k := i; k >= j; k-- {
+			v = v<<1 | int(ebits.Bit(k))
+		}
+		if acc == nil {
+			acc = table[v>>1]
+		} else {
+			for k := i; k >= j; k-- {
+				acc = z.ring.mul(acc, acc)
+			}
+			acc = z.ring.mul(acc, table[v>>1])
+		}
+		i = j - 1
+	}
+	return &ModInt{z.ring, acc}
+}
+
+// montExp is the sliding-window exponentiation of Exp, working directly
+// on Montgomery words so that the loop does not allocate.
+func (r *ModRing) montExp(x, e *big.Int, w int) *big.Int {
+	n := r.n
+	t := make([]big.Word, n+2)
+	table := make([][]big.Word, 1<<uint(w-1))
+	table[0] = r.pad(x)
+	if w > 1 {
+		x2 := make([]big.Word, n)
+		r.montMulWords(x2, table[0], table[0], t)
+		for i := 1; i < len(table); i++ {
+			table[i] = make([]big.Word, n)
+			r.montMulWords(table[i], table[i-1], x2, t)
+		}
+	}
+
+	acc := make([]big.Word, n)
+	started := false
+	for i := e.BitLen() - 1; i >= 0; {
+		if e.Bit(i) == 0 {
+			r.montMulWords(acc, acc, acc, t)
+			i--
+			continue
+		}
+		j := i - w + 1
+		if j < 0 {
+			j = 0
+		}
+		for e.Bit(j) == 0 {
+			j++
+		}
+		v := 0
+		for k := i; k >= j; k-- {
+			v = v<<1 | int(e.Bit(k))
+		}
+		if !started {
+			copy(acc, table[v>>1])
+			started = true
+		} else {
+			for k := i; k >= j; k-- {
+				r.montMulWords(acc, acc, acc, t)
+			}
+			r.montMulWords(acc, acc, table[v>>1], t)
+		}
+		i = j - 1
+	}
+	return new(big.Int).SetBits(acc)
+}
+
+// expWindowSize picks a window size for an exponent of the given length,
+// balancing the table size against the number of multiplications.
+func expWindowSize(nbits int) int {
+	switch {
+	case nbits <= 8:
+		return 1
+	case nbits <= 24:
+		return 2
+	case nbits <= 80:
+		return 3
+	case nbits <= 240:
+		return 4
+	case nbits <= 672:
+		return 5
+	}
+	return 6
+}
+
+// Sqrt returns a square root of this, and false if there is none. The
+// modulus must be prime; otherwise, the result is undefined.
+func (z *ModInt) Sqrt() (*ModInt, bool) {
+	if z.ring.m.IsInt64() && z.ring.m.Int64() == 2 {
+		// modulo 2, 0 and 1 are their own square roots; big.Int.ModSqrt
+		// only handles odd primes
+		return z, true
+	}
+	s := new(big.Int).ModSqrt((*big.Int)(z.Int()), z.ring.m)
+	if s == nil {
+		return nil, false
+	}
+	return z.ring.Elem((*Int)(s)), true
+}
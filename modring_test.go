@@ -0,0 +1,114 @@
+package mathx
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var modRingTestModuli = []string{
+	"1", "2", "3", "12", "101", "65536", "4294967311",
+	"18446744073709551557", "18446744073709551616",
+	"170141183460469231731687303715884105727",
+	"340282366920938463463374607431768211456",
+	"93845895110924997939619620205961794350920309182366517272043413179685324014762",
+	"93845895110924997939619620205961794350920309182366517272043413179685324014761",
+}
+
+func TestModRingArithmetic(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, ms := range modRingTestModuli {
+		m, _ := NewIntFromString(ms, 10)
+		r := NewModRing(m)
+		if r.Modulus().Cmp(m) != 0 {
+			t.Fatalf("Modulus() = %v, expected %v", r.Modulus(), m)
+		}
+		for i := 0; i < 50; i++ {
+			a := Rand(rnd, m.Mul64(3)).Sub(m)
+			b := Rand(rnd, m)
+			x, y := r.Elem(a), r.Elem(b)
+			if got := x.Int(); got.Cmp(a.Mod(m)) != 0 {
+				t.Fatalf("Elem(%v).Int() = %v mod %v", a, got, m)
+			}
+			if got := x.Add(y).Int(); got.Cmp(a.Add(b).Mod(m)) != 0 {
+				t.Errorf("%v + %v = %v mod %v", a, b, got, m)
+			}
+			if got := x.Sub(y).Int(); got.Cmp(a.Sub(b).Mod(m)) != 0 {
+				t.Errorf("%v - %v = %v mod %v", a, b, got, m)
+			}
+			if got := x.Neg().Int(); got.Cmp(a.Neg().Mod(m)) != 0 {
+				t.Errorf("-%v = %v mod %v", a, got, m)
+			}
+			if got := x.Mul(y).Int(); got.Cmp(a.Mul(b).Mod(m)) != 0 {
+				t.Errorf("%v * %v = %v mod %v", a, b, got, m)
+			}
+			if got := x.Square().Int(); got.Cmp(a.Mul(a).Mod(m)) != 0 {
+				t.Errorf("%v^2 = %v mod %v", a, got, m)
+			}
+			e := Rand(rnd, NewInt(1).Lsh(uint(1+i*20)))
+			if got := x.Exp(e).Int(); got.Cmp(a.Exp(e, m)) != 0 {
+				t.Errorf("%v^%v = %v mod %v", a, e, got, m)
+			}
+			if inv, ok := x.Inv(); ok {
+				if !inv.Mul(x).Equal(r.One()) {
+					t.Errorf("%v * %v != 1 mod %v", a, inv, m)
+				}
+				if !x.Exp(e.Neg()).Mul(x.Exp(e)).Equal(r.One()) {
+					t.Errorf("%v^-%v is wrong mod %v", a, e, m)
+				}
+			} else if a.GCD(m).Cmp(NewInt(1)) == 0 {
+				t.Errorf("%v should be invertible mod %v", a, m)
+			}
+		}
+	}
+}
+
+func TestModRingSqrt(t *testing.T) {
+	p, _ := NewIntFromString("170141183460469231731687303715884105727", 10)
+	r := NewModRing(p)
+	for i := int64(1); i < 100; i++ {
+		x := r.Elem64(i)
+		s, ok := x.Square().Sqrt()
+		if !ok || !s.Square().Equal(x.Square()) {
+			t.Errorf("Sqrt(%d^2) = %v, %v", i, s, ok)
+		}
+	}
+	// -1 is not a square modulo a prime that is 3 mod 4
+	if _, ok := r.Elem64(-1).Sqrt(); ok {
+		t.Errorf("-1 should not be a square mod %v", p)
+	}
+	// 2 is the one even prime
+	two := NewModRing(NewInt(2))
+	for i := int64(0); i < 2; i++ {
+		if s, ok := two.Elem64(i).Sqrt(); !ok || !s.Equal(two.Elem64(i)) {
+			t.Errorf("Sqrt(%d) mod 2 = %v, %v", i, s, ok)
+		}
+	}
+}
+
+func BenchmarkModRingExp(b *testing.B) {
+	m, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	e := m.Sub64(1)
+	x := NewModRing(m).Elem64(3)
+	for i := 0; i < b.N; i++ {
+		x.Exp(e)
+	}
+}
+
+func BenchmarkIntExp(b *testing.B) {
+	m, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	e := m.Sub64(1)
+	x := NewInt(3)
+	for i := 0; i < b.N; i++ {
+		x.Exp(e, m)
+	}
+}
+
+func BenchmarkModRingMul(b *testing.B) {
+	m, _ := NewIntFromString("93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	r := NewModRing(m)
+	x := r.Elem(m.Sub64(3))
+	y := r.Elem(m.Sub64(5))
+	for i := 0; i < b.N; i++ {
+		x = x.Mul(y)
+	}
+}
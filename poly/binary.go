@@ -0,0 +1,56 @@
+package poly
+
+// This file is for the binary format; see the mathx package for its
+// description.
+
+import (
+	"encoding/binary"
+
+	"github.com/swenson/mathx"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *IntPolynomial) MarshalBinary() ([]byte, error) {
+	buf := binary.AppendUvarint(nil, uint64(len(p.coeffs)))
+	for i := range p.coeffs {
+		c, err := p.coeffs[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, c...)
+	}
+	return mathx.AppendBinaryRecord(nil, mathx.TagPolynomial, buf), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The coefficients
+// are replaced, not modified, so other holders of them are unaffected.
+func (p *IntPolynomial) UnmarshalBinary(data []byte) error {
+	payload, rest, err := mathx.ReadBinaryRecord(data, mathx.TagPolynomial)
+	if err != nil {
+		return err
+	}
+	n, k := binary.Uvarint(payload)
+	// each coefficient takes at least 3 bytes
+	if len(rest) != 0 || k <= 0 || n > uint64(len(payload)/3) {
+		return mathx.ErrBinaryFormat
+	}
+	payload = payload[k:]
+	coeffs := make([]mathx.Int, n)
+	for i := range coeffs {
+		var c []byte
+		c, payload, err = mathx.ReadBinaryRecord(payload, mathx.TagInt)
+		if err != nil {
+			return err
+		}
+		x, err := mathx.ReadBinaryInt(c)
+		if err != nil {
+			return err
+		}
+		coeffs[i] = mathx.Int(*x)
+	}
+	if len(payload) != 0 {
+		return mathx.ErrBinaryFormat
+	}
+	p.coeffs = coeffs
+	return nil
+}
@@ -0,0 +1,26 @@
+package poly
+
+import "testing"
+
+func TestIntPolynomialBinary(t *testing.T) {
+	for _, s := range []string{"0", "x", "x^2 - 4*x + 3", "-123456789012345678901234567890*x^5 + 7"} {
+		p := ParseIntPoly(s)
+		data, err := p.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := new(IntPolynomial)
+		if err := q.UnmarshalBinary(data); err != nil || q.String() != p.String() || q.Degree() != p.Degree() {
+			t.Errorf("%v unmarshaled as %v, %v", p, q, err)
+		}
+	}
+	for _, data := range [][]byte{
+		{1, 'P', 1, 9},                  // too many coefficients
+		{1, 'P', 4, 1, 1, 'F', 0},       // not an Int
+		{1, 'P', 6, 1, 1, 'I', 1, 0, 5}, // trailing data
+	} {
+		if err := new(IntPolynomial).UnmarshalBinary(data); err == nil {
+			t.Errorf("decoding %v should fail", data)
+		}
+	}
+}
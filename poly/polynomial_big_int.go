@@ -73,13 +73,10 @@ func (p *IntPolynomial) IsIrreducible() bool {
 		return true
 	}
 	if p.Degree() == 2 {
-		c, b, a := p.coeffs[0], p.coeffs[1], p.coeffs[2]
-		b2 := b.Mul(&b)
-		ac4 := mathx.NewInt(4)
-		ac4 = ac4.Mul(&a)
-		ac4 = ac4.Mul(&c)
-		b2 = b2.Sub(ac4)
-		return !mathx.IsSquare((*mathx.Int)(b2))
+		c, b, a := &p.coeffs[0], &p.coeffs[1], &p.coeffs[2]
+		var d mathx.IntBuilder
+		d.AddMul(a, c).Mul64(-4).AddMul(b, b)
+		return !mathx.IsSquare(d.Freeze())
 	}
 	/*
 		// check the gcd of the coefficients
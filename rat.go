@@ -0,0 +1,289 @@
+package mathx
+
+// This file is for Rat, the immutable counterpart of big.Rat, and its
+// conversions to and from Int and Float.
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Rat is an immutable arbitrary-precision rational number, wrapping the
+// built-in math/big.Rat (which is mutable), with the same two-argument API
+// as Int. For example:
+//
+//	a := mathx.NewRat(1, 3)
+//	b := a.Add(mathx.NewRat(1, 6)) // 1/2
+//
+// The zero value is 0.
+type Rat big.Rat
+
+// NewRat returns a/b in lowest terms. It panics if b is 0, as big.NewRat
+// does.
+func NewRat(a, b int64) *Rat {
+	return (*Rat)(big.NewRat(a, b))
+}
+
+// NewRatFromString returns the number represented by s, which can be a
+// fraction "a/b" or a decimal number with an optional exponent, as in
+// big.Rat.SetString, and whether it was valid.
+func NewRatFromString(s string) (*Rat, bool) {
+	r, ok := new(big.Rat).SetString(s)
+	return (*Rat)(r), ok
+}
+
+// RatFromInt returns x as a Rat.
+func RatFromInt(x *Int) *Rat {
+	return (*Rat)(new(big.Rat).SetInt((*big.Int)(x)))
+}
+
+// RatFromFloat returns x as a Rat, exactly. It panics with ErrDomain if x
+// is infinite.
+func RatFromFloat(x *Float) *Rat {
+	if x.IsInf() {
+		panic(ErrDomain)
+	}
+	r, _ := (*big.Float)(x).Rat(nil)
+	return (*Rat)(r)
+}
+
+// Float returns this rounded to prec bits with the rounding mode, with the
+// accuracy of the result reported by Acc.
+func (z *Rat) Float(prec uint, mode big.RoundingMode) *Float {
+	return (*Float)(newFloat(prec).SetMode(mode).SetRat((*big.Rat)(z)))
+}
+
+// Float64 returns the float64 nearest to this, and whether it is exact.
+func (z *Rat) Float64() (float64, bool) {
+	return (*big.Rat)(z).Float64()
+}
+
+// Num returns the numerator of this in lowest terms, which has the sign of
+// this.
+func (z *Rat) Num() *Int {
+	return (*Int)(new(big.Int).Set((*big.Rat)(z).Num()))
+}
+
+// Denom returns the denominator of this in lowest terms, which is positive.
+func (z *Rat) Denom() *Int {
+	return (*Int)(new(big.Int).Set((*big.Rat)(z).Denom()))
+}
+
+// Cmp compares this to the argument (x), returning something < 0 if
+// this < x, == 0 if this == x, and > 0 if this > x.
+func (z *Rat) Cmp(x *Rat) int {
+	return (*big.Rat)(z).Cmp((*big.Rat)(x))
+}
+
+// Sign returns the sign of this (-1 if < 0, 1 if > 0, and 0 if == 0).
+func (z *Rat) Sign() int {
+	return (*big.Rat)(z).Sign()
+}
+
+// IsInt returns whether the denominator of this is 1.
+func (z *Rat) IsInt() bool {
+	return (*big.Rat)(z).IsInt()
+}
+
+// Add returns this + y.
+func (z *Rat) Add(y *Rat) *Rat {
+	return (*Rat)(new(big.Rat).Add((*big.Rat)(z), (*big.Rat)(y)))
+}
+
+// Sub returns this - y.
+func (z *Rat) Sub(y *Rat) *Rat {
+	return (*Rat)(new(big.Rat).Sub((*big.Rat)(z), (*big.Rat)(y)))
+}
+
+// Mul returns this * y.
+func (z *Rat) Mul(y *Rat) *Rat {
+	return (*Rat)(new(big.Rat).Mul((*big.Rat)(z), (*big.Rat)(y)))
+}
+
+// Quo returns this / y. It panics if y is 0, as big.Rat.Quo does; see
+// QuoChecked for a version that returns an error.
+func (z *Rat) Quo(y *Rat) *Rat {
+	return (*Rat)(new(big.Rat).Quo((*big.Rat)(z), (*big.Rat)(y)))
+}
+
+// Neg returns this with the sign flipped.
+func (z *Rat) Neg() *Rat {
+	return (*Rat)(new(big.Rat).Neg((*big.Rat)(z)))
+}
+
+// Abs returns the absolute value of this.
+func (z *Rat) Abs() *Rat {
+	return (*Rat)(new(big.Rat).Abs((*big.Rat)(z)))
+}
+
+// Inv returns 1/this. It panics if this is 0, as big.Rat.Inv does.
+func (z *Rat) Inv() *Rat {
+	return (*Rat)(new(big.Rat).Inv((*big.Rat)(z)))
+}
+
+// Pow returns this**n, exactly, with 0**0 = 1. It panics if this is 0 and
+// n is negative, as Inv does.
+func (z *Rat) Pow(n int64) *Rat {
+	x := (*big.Rat)(z)
+	if n < 0 {
+		x = new(big.Rat).Inv(x)
+	}
+	// the powers of coprime integers are coprime, so this is in lowest terms
+	e := new(big.Int).Abs(big.NewInt(n))
+	num := new(big.Int).Exp(x.Num(), e, nil)
+	den := new(big.Int).Exp(x.Denom(), e, nil)
+	return (*Rat)(new(big.Rat).SetFrac(num, den))
+}
+
+// Floor returns the greatest integer that is at most this.
+func (z *Rat) Floor() *Int {
+	x := (*big.Rat)(z)
+	// Div is Euclidean division, which rounds down for positive divisors
+	return (*Int)(new(big.Int).Div(x.Num(), x.Denom()))
+}
+
+// Ceil returns the least integer that is at least this.
+func (z *Rat) Ceil() *Int {
+	return z.Neg().Floor().Neg()
+}
+
+// Trunc returns the integer part of this, rounding toward zero.
+func (z *Rat) Trunc() *Int {
+	x := (*big.Rat)(z)
+	return (*Int)(new(big.Int).Quo(x.Num(), x.Denom()))
+}
+
+// String returns this as "a/b", even if the denominator is 1.
+func (z *Rat) String() string {
+	return (*big.Rat)(z).String()
+}
+
+// RatString returns this as "a/b", or as "a" if the denominator is 1.
+func (z *Rat) RatString() string {
+	return (*big.Rat)(z).RatString()
+}
+
+// FloatString returns this in decimal with n digits after the point,
+// rounded to nearest with ties away from zero.
+func (z *Rat) FloatString(n int) string {
+	return (*big.Rat)(z).FloatString(n)
+}
+
+// DecimalString returns the exact decimal expansion of this, with the
+// repeating digits in parentheses: 1/7 is "0.(142857)", 1/6 is "0.1(6)",
+// -5/4 is "-1.25", and 3 is "3". The repeating part can have as many
+// digits as the denominator less one.
+func (z *Rat) DecimalString() string {
+	x := (*big.Rat)(z)
+	num, den := new(big.Int).Abs(x.Num()), x.Denom()
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	var b strings.Builder
+	if x.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	b.WriteString(q.String())
+	if r.Sign() == 0 {
+		return b.String()
+	}
+	b.WriteByte('.')
+	// the digits start repeating after k, the larger of the powers of 2 and
+	// 5 in the denominator
+	k := 0
+	for _, p := range []int64{2, 5} {
+		d, m, i := new(big.Int).Set(den), new(big.Int), 0
+		for ; ; i++ {
+			if d.QuoRem(d, big.NewInt(p), m); m.Sign() != 0 {
+				break
+			}
+		}
+		if i > k {
+			k = i
+		}
+	}
+	ten := big.NewInt(10)
+	r.Mul(r, new(big.Int).Exp(ten, big.NewInt(int64(k)), nil))
+	pre, r := new(big.Int).QuoRem(r, den, new(big.Int))
+	writeDigits(&b, pre, k)
+	if r.Sign() == 0 {
+		return b.String()
+	}
+	// with p the period, r/den = rep/(10**p - 1)
+	p := 0
+	for t := new(big.Int).Set(r); ; {
+		p++
+		if t.Mul(t, ten).Mod(t, den); t.Cmp(r) == 0 {
+			break
+		}
+	}
+	rep := new(big.Int).Exp(ten, big.NewInt(int64(p)), nil)
+	rep.Sub(rep, bigOne).Mul(rep, r).Quo(rep, den)
+	b.WriteByte('(')
+	writeDigits(&b, rep, p)
+	b.WriteByte(')')
+	return b.String()
+}
+
+// writeDigits writes x in decimal, padded with zeros to n digits.
+func writeDigits(b *strings.Builder, x *big.Int, n int) {
+	if n == 0 {
+		return
+	}
+	s := x.String()
+	b.WriteString(strings.Repeat("0", n-len(s)))
+	b.WriteString(s)
+}
+
+// GobDecode decodes the data from the buffer, and changes this.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that Gob works; see DecodeRatGob for an
+// alternative.
+func (z *Rat) GobDecode(buf []byte) error {
+	return (*big.Rat)(z).GobDecode(buf)
+}
+
+// GobEncode encodes this as a gob byte array.
+func (z *Rat) GobEncode() ([]byte, error) {
+	return (*big.Rat)(z).GobEncode()
+}
+
+// MarshalText marshals this to a text string, "a/b" or "a" if the
+// denominator is 1.
+func (z *Rat) MarshalText() (text []byte, err error) {
+	return (*big.Rat)(z).MarshalText()
+}
+
+// UnmarshalText unmarshals the text buffer into this, in any form accepted
+// by NewRatFromString.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see ParseRatText
+// for an alternative.
+func (z *Rat) UnmarshalText(text []byte) error {
+	return (*big.Rat)(z).UnmarshalText(text)
+}
+
+// MarshalJSON marshals this to a JSON string holding its text, as "1/3"
+// cannot be a JSON number, or to null if this is nil.
+func (z *Rat) MarshalJSON() ([]byte, error) {
+	if z == nil {
+		return []byte("null"), nil
+	}
+	text, err := z.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(`"` + string(text) + `"`), nil
+}
+
+// UnmarshalJSON unmarshals a JSON string or number into this, and ignores
+// null, as encoding/json does.
+// WARNING: this breaks immutability since it can change the underlying data.
+// This is unavoidable with the way that unmarshaling works; see ParseRatJSON
+// for an alternative.
+func (z *Rat) UnmarshalJSON(data []byte) error {
+	x, err := ParseRatJSON(data)
+	if err != nil || x == nil {
+		return err
+	}
+	(*big.Rat)(z).Set((*big.Rat)(x))
+	return nil
+}
@@ -0,0 +1,195 @@
+package mathx
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestRatArithmetic(t *testing.T) {
+	a, b := NewRat(1, 3), NewRat(-1, 6)
+	cases := []struct {
+		name string
+		got  *Rat
+		want string
+	}{
+		{"a + b", a.Add(b), "1/6"},
+		{"a - b", a.Sub(b), "1/2"},
+		{"a b", a.Mul(b), "-1/18"},
+		{"a / b", a.Quo(b), "-2"},
+		{"-b", b.Neg(), "1/6"},
+		{"|b|", b.Abs(), "1/6"},
+		{"1/b", b.Inv(), "-6"},
+		{"b**3", b.Pow(3), "-1/216"},
+		{"b**-2", b.Pow(-2), "36"},
+		{"a**0", a.Pow(0), "1"},
+		{"0**0", NewRat(0, 1).Pow(0), "1"},
+		{"(2/3)**-1", NewRat(2, 3).Pow(-1), "3/2"},
+	}
+	for _, c := range cases {
+		if s := c.got.RatString(); s != c.want {
+			t.Errorf("%s = %s, expected %s", c.name, s, c.want)
+		}
+	}
+	// the operands are unchanged
+	if a.String() != "1/3" || b.String() != "-1/6" {
+		t.Errorf("operands changed to %v and %v", a, b)
+	}
+	var zero Rat
+	if zero.Sign() != 0 || !zero.IsInt() || zero.Add(a).Cmp(a) != 0 {
+		t.Errorf("zero value is %v", &zero)
+	}
+}
+
+func TestRatIntegerParts(t *testing.T) {
+	cases := []struct {
+		a, b               int64
+		floor, ceil, trunc int64
+	}{
+		{7, 2, 3, 4, 3},
+		{-7, 2, -4, -3, -3},
+		{6, 3, 2, 2, 2},
+		{-6, 3, -2, -2, -2},
+		{1, 3, 0, 1, 0},
+		{-1, 3, -1, 0, 0},
+		{0, 5, 0, 0, 0},
+	}
+	for _, c := range cases {
+		x := NewRat(c.a, c.b)
+		if f, cl, tr := x.Floor().Int64(), x.Ceil().Int64(), x.Trunc().Int64(); f != c.floor || cl != c.ceil || tr != c.trunc {
+			t.Errorf("%v: Floor, Ceil, Trunc = %d, %d, %d, expected %d, %d, %d", x, f, cl, tr, c.floor, c.ceil, c.trunc)
+		}
+	}
+	x := NewRat(-10, 4)
+	if x.Num().Int64() != -5 || x.Denom().Int64() != 2 {
+		t.Errorf("%v has parts %v and %v", x, x.Num(), x.Denom())
+	}
+}
+
+func TestRatConversions(t *testing.T) {
+	n, _ := NewIntFromString("123456789012345678901234567890", 10)
+	if r := RatFromInt(n); !r.IsInt() || r.Num().Cmp(n) != 0 {
+		t.Errorf("RatFromInt(%v) = %v", n, r)
+	}
+	if r := RatFromFloat(NewFloat(-0.375)); r.Cmp(NewRat(-3, 8)) != 0 {
+		t.Errorf("RatFromFloat(-0.375) = %v", r)
+	}
+	third := NewRat(1, 3)
+	for _, mode := range allModes {
+		f := third.Float(100, mode)
+		want := newFloat(100).SetMode(mode).Quo(floatOne, big.NewFloat(3))
+		if f.Cmp((*Float)(want)) != 0 || f.Acc() != want.Acc() || f.Prec() != 100 {
+			t.Errorf("1/3 at 100 bits %v = %v (%v), expected %v (%v)", mode, f, f.Acc(), want, want.Acc())
+		}
+	}
+	if f := NewRat(3, 4).Float(2, big.ToZero); !sameFloat64(f, 0.75) || f.Acc() != big.Exact {
+		t.Errorf("3/4 at 2 bits = %v (%v)", f, f.Acc())
+	}
+	if f, exact := third.Float64(); f != 1.0/3 || exact {
+		t.Errorf("Float64(1/3) = %v, %v", f, exact)
+	}
+	if r, ok := NewRatFromString("-1.25e2"); !ok || r.Cmp(NewRat(-125, 1)) != 0 {
+		t.Errorf("NewRatFromString(-1.25e2) = %v, %v", r, ok)
+	}
+	if r, ok := NewRatFromString("6/-4"); ok {
+		t.Errorf("NewRatFromString(6/-4) = %v", r)
+	}
+	defer func() {
+		if recover() != ErrDomain {
+			t.Errorf("RatFromFloat(Inf) should panic with ErrDomain")
+		}
+	}()
+	RatFromFloat(NewFloat(math.Inf(1)))
+}
+
+func TestRatDecimalString(t *testing.T) {
+	cases := []struct {
+		a, b int64
+		want string
+	}{
+		{1, 7, "0.(142857)"},
+		{1, 6, "0.1(6)"},
+		{-1, 3, "-0.(3)"},
+		{-5, 4, "-1.25"},
+		{3, 1, "3"},
+		{0, 1, "0"},
+		{22, 7, "3.(142857)"},
+		{1, 12, "0.08(3)"},
+		{1, 81, "0.(012345679)"},
+		{7, 1000, "0.007"},
+		{1, 9900, "0.00(01)"},
+		{-100, 99, "-1.(01)"},
+		{1, 250, "0.004"},
+		{1, 97, "0.(010309278350515463917525773195876288659793814432989690721649484536082474226804123711340206185567)"},
+	}
+	for _, c := range cases {
+		if s := NewRat(c.a, c.b).DecimalString(); s != c.want {
+			t.Errorf("%d/%d is %s, expected %s", c.a, c.b, s, c.want)
+		}
+	}
+}
+
+type ratRecord struct {
+	X *Rat
+	Y *Rat
+}
+
+func TestRatEncoding(t *testing.T) {
+	x := NewRat(-22, 7)
+	n := RatFromInt(NewInt(5))
+
+	data, err := json.Marshal(ratRecord{X: x})
+	if err != nil || string(data) != `{"X":"-22/7","Y":null}` {
+		t.Fatalf("json.Marshal gave %s, %v", data, err)
+	}
+	var rec ratRecord
+	if err := json.Unmarshal(data, &rec); err != nil || rec.X.Cmp(x) != 0 || rec.Y != nil {
+		t.Errorf("json.Unmarshal gave %+v, %v", rec, err)
+	}
+	if err := json.Unmarshal([]byte(`{"X":1.5,"Y":"5"}`), &rec); err != nil || rec.X.Cmp(NewRat(3, 2)) != 0 || rec.Y.Cmp(n) != 0 {
+		t.Errorf("json.Unmarshal of numbers gave %+v, %v", rec, err)
+	}
+	if err := json.Unmarshal([]byte(`{"X":"1/0"}`), &rec); err == nil {
+		t.Errorf("json.Unmarshal of 1/0 should fail")
+	}
+
+	for _, r := range []*Rat{x, n} {
+		text, _ := r.MarshalText()
+		if y, err := ParseRatText(text); err != nil || y.Cmp(r) != 0 {
+			t.Errorf("ParseRatText(%s) gave %v, %v", text, y, err)
+		}
+		data, _ := r.MarshalJSON()
+		if y, err := ParseRatJSON(data); err != nil || y.Cmp(r) != 0 {
+			t.Errorf("ParseRatJSON(%s) gave %v, %v", data, y, err)
+		}
+		buf, _ := r.GobEncode()
+		if y, err := DecodeRatGob(buf); err != nil || y.Cmp(r) != 0 {
+			t.Errorf("DecodeRatGob gave %v, %v", y, err)
+		}
+	}
+	if text, _ := n.MarshalText(); string(text) != "5" {
+		t.Errorf("5 has text %s", text)
+	}
+	if y, err := ParseRatJSON([]byte("null")); err != nil || y != nil {
+		t.Errorf("ParseRatJSON(null) gave %v, %v", y, err)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(ratRecord{X: x, Y: n}); err != nil {
+		t.Fatal(err)
+	}
+	var got ratRecord
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil || got.X.Cmp(x) != 0 || got.Y.Cmp(n) != 0 {
+		t.Errorf("gob round trip gave %+v, %v", got, err)
+	}
+}
+
+func BenchmarkRatDecimalString(b *testing.B) {
+	x := NewRat(1, 9973)
+	for i := 0; i < b.N; i++ {
+		x.DecimalString()
+	}
+}
@@ -0,0 +1,342 @@
+package mathx
+
+// This file is for Real, lazy computable real numbers, which are evaluated
+// as scaled Ints to whatever precision is asked of them.
+
+import (
+	"math"
+	"math/big"
+	"sync"
+)
+
+// Real is an immutable computable real number x: a function that, for any
+// n, returns an Int a with |x 2**n - a| < 1, so that a/2**n is x to n
+// bits after the binary point. Operations on Reals build new functions and
+// compute nothing; a Real is only evaluated when it is asked for an
+// approximation, at the precision it is asked for, and each operand to the
+// precision that needs:
+//
+//	x := mathx.RealFromInt(mathx.NewInt(2)).Sqrt().Exp()
+//	x.FloatString(10)    // "4.1132503788"
+//	x.FloatString(10000) // the same number, to 10000 digits
+//
+// A Real keeps its most precise approximation so far, so a Real used in
+// several places is evaluated once for each precision increase. Equality
+// of Reals cannot be decided, so Cmp takes a tolerance, and the operations
+// that need to know that a number is not 0, such as Quo, Log and Float, do
+// not return for 0. The zero value is 0.
+type Real struct {
+	approx func(n int) *Int
+	mu     sync.Mutex
+	n      int
+	a      *Int
+}
+
+// NewReal returns the Real that f computes: for any n, f(n) must return an
+// Int a with |x 2**n - a| < 1.
+func NewReal(f func(n int) *Int) *Real {
+	return &Real{approx: f}
+}
+
+// shift returns a 2**k rounded to nearest.
+func shift(a *Int, k int) *Int {
+	if k >= 0 {
+		return a.Lsh(uint(k))
+	}
+	// Rsh rounds down
+	return a.Add(NewInt(1).Lsh(uint(-k - 1))).Rsh(uint(-k))
+}
+
+// scaledFloat returns x 2**n rounded to nearest, for finite x.
+func scaledFloat(x *Float, n int) *Int {
+	t := new(big.Float).SetMantExp((*big.Float)(x), n)
+	i, _ := (*Float)(t).Round(big.ToNearestEven).Int()
+	return i
+}
+
+// Approx returns an Int a with |x 2**n - a| < 1, for any n.
+func (z *Real) Approx(n int) *Int {
+	if z.approx == nil {
+		return NewInt(0)
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if z.a != nil && z.n >= n {
+		// the error is less than 2**(n - z.n) + 1/2, which is at most 1
+		return shift(z.a, n-z.n)
+	}
+	z.n, z.a = n, z.approx(n)
+	return z.a
+}
+
+// RealFromInt returns x as a Real.
+func RealFromInt(x *Int) *Real {
+	return NewReal(func(n int) *Int {
+		return shift(x, n)
+	})
+}
+
+// RealFromRat returns x as a Real.
+func RealFromRat(x *Rat) *Real {
+	num, den := (*big.Rat)(x).Num(), (*big.Rat)(x).Denom()
+	return NewReal(func(n int) *Int {
+		if n >= 0 {
+			return (*Int)(roundQuo(new(big.Int).Lsh(num, uint(n)), den, big.ToNearestEven))
+		}
+		return (*Int)(roundQuo(num, new(big.Int).Lsh(den, uint(-n)), big.ToNearestEven))
+	})
+}
+
+// RealFromFloat returns x as a Real. It panics with ErrDomain if x is
+// infinite.
+func RealFromFloat(x *Float) *Real {
+	if x.IsInf() {
+		panic(ErrDomain)
+	}
+	return NewReal(func(n int) *Int {
+		return scaledFloat(x, n)
+	})
+}
+
+// RealPi returns π as a Real.
+func RealPi() *Real {
+	return NewReal(func(n int) *Int {
+		// π < 4, so at is within 1.5 ulps = 1.5 2**(2-w) = 3/8 2**-n
+		w := n + 4
+		if w < 2 {
+			w = 2
+		}
+		return scaledFloat((*Float)(piConstant.at(uint(w))), n)
+	})
+}
+
+// magnitude returns k >= 1 with |x| < 2**k.
+func (z *Real) magnitude() int {
+	return z.Approx(0).Abs().Add64(1).BitLen()
+}
+
+// msd returns e with |x| > 2**-e and the sign of x. It does not return if
+// x is 0.
+func (z *Real) msd() (int, int) {
+	for m := 0; ; m = 2*m + 8 {
+		if a := z.Approx(m); a.Abs().Cmp(NewInt(2)) >= 0 {
+			// |x| > (|a| - 1)/2**m >= 2**(bitlen(|a| - 1) - 1 - m)
+			return m + 1 - a.Abs().Sub64(1).BitLen(), a.Sign()
+		}
+	}
+}
+
+// Add returns this + y.
+func (z *Real) Add(y *Real) *Real {
+	return NewReal(func(n int) *Int {
+		// the error is less than 2/4 + 1/2
+		return shift(z.Approx(n+2).Add(y.Approx(n+2)), -2)
+	})
+}
+
+// Sub returns this - y.
+func (z *Real) Sub(y *Real) *Real {
+	return z.Add(y.Neg())
+}
+
+// Neg returns -this.
+func (z *Real) Neg() *Real {
+	return NewReal(func(n int) *Int {
+		return z.Approx(n).Neg()
+	})
+}
+
+// Abs returns |this|.
+func (z *Real) Abs() *Real {
+	return NewReal(func(n int) *Int {
+		return z.Approx(n).Abs()
+	})
+}
+
+// Mul returns this y.
+func (z *Real) Mul(y *Real) *Real {
+	return NewReal(func(n int) *Int {
+		// with A and B the approximations to nx and ny bits,
+		// |x y - A B| <= |x| |y - B| + |B| |x - A|, where |x| < 2**kx and
+		// |B| < 2**(ky+1), and each term is less than 2**-(n+2)
+		kx, ky := z.magnitude(), y.magnitude()
+		nx, ny := n+ky+3, n+kx+2
+		if nx < 0 {
+			nx = 0
+		}
+		if ny < 0 {
+			ny = 0
+		}
+		return shift(z.Approx(nx).Mul(y.Approx(ny)), n-nx-ny)
+	})
+}
+
+// Inv returns 1/this. It does not return if this is 0.
+func (z *Real) Inv() *Real {
+	return NewReal(func(n int) *Int {
+		// with |x| > 2**-e and B the approximation to k >= e+1 bits,
+		// |B| >= 2**-(e+1), so |1/x - 1/B| < 2**-k 2**e 2**(e+1)
+		e, _ := z.msd()
+		k := n + 2*e + 2
+		if k < e+1 {
+			k = e + 1
+		}
+		b := (*big.Int)(z.Approx(k))
+		num, den := new(big.Int).SetInt64(int64(b.Sign())), new(big.Int).Abs(b)
+		if s := n + k; s >= 0 {
+			num.Lsh(num, uint(s))
+		} else {
+			den.Lsh(den, uint(-s))
+		}
+		return (*Int)(roundQuo(num, den, big.ToNearestEven))
+	})
+}
+
+// Quo returns this / y. It does not return if y is 0.
+func (z *Real) Quo(y *Real) *Real {
+	return z.Mul(y.Inv())
+}
+
+// Sqrt returns the square root of this. Its approximations panic with
+// ErrNaN once they find that this is negative.
+func (z *Real) Sqrt() *Real {
+	return NewReal(func(n int) *Int {
+		// with T the approximation to 2m bits, |sqrt(x) - sqrt(T)| < 2**-m,
+		// and the integer square root is within 2**-m of sqrt(T)
+		m := n + 2
+		t := z.Approx(2 * m)
+		switch t.Sign() {
+		case -1:
+			if t.Cmp(NewInt(-1)) <= 0 {
+				panic(ErrNaN)
+			}
+			fallthrough
+		case 0:
+			return NewInt(0)
+		}
+		return shift(t.Sqrt(), -2)
+	})
+}
+
+// Exp returns e**this. Its approximations panic with ErrOverflow if this
+// is more than big.MaxExp log(2), about 1.49e9, where e**this is beyond the
+// exponent range of Float.
+func (z *Real) Exp() *Real {
+	return NewReal(func(n int) *Int {
+		// e**x < e**(a+1) < 2**m; x > a-1, so for a > 1488522236, x is more
+		// than big.MaxExp log(2) and e**x overflows
+		a := z.Approx(0)
+		if a.Cmp(NewInt(1488522236)) > 0 {
+			panic(ErrOverflow)
+		}
+		m := 0
+		if a.Sign() >= 0 {
+			m = int(float64(a.Int64()+1)*math.Log2E) + 2
+		}
+		// with A the approximation to k bits, |e**x - e**A| < 2**(m+1-k), and
+		// e**A rounded to w bits is within 2**(m+1-w-1)
+		k, w := n+m+3, n+m+4
+		if k < 1 {
+			k = 1
+		}
+		if w < 2 {
+			w = 2
+		}
+		x := new(big.Float).SetInt((*big.Int)(z.Approx(k)))
+		x.SetMantExp(x, -k)
+		y := (&FloatContext{Prec: uint(w)}).Exp((*Float)(x))
+		if y.IsInf() {
+			panic(ErrOverflow)
+		}
+		return scaledFloat(y, n)
+	})
+}
+
+// Log returns the natural logarithm of this. It does not return if this
+// is 0, and its approximations panic with ErrNaN if this is negative.
+func (z *Real) Log() *Real {
+	return NewReal(func(n int) *Int {
+		e, sign := z.msd()
+		if sign < 0 {
+			panic(ErrNaN)
+		}
+		// with A the approximation to k >= e+1 bits, A > 2**-(e+1), so
+		// |log x - log A| < 2**-k 2**(e+1); |log A| < 2**b, so log A rounded
+		// to w bits is within 2**(b-w-1)
+		k := n + e + 4
+		if k < e+1 {
+			k = e + 1
+		}
+		m := e + 1
+		if kx := z.magnitude() + 1; kx > m {
+			m = kx
+		}
+		b := NewInt(int64(m)).BitLen()
+		w := n + b + 3
+		if w < 2 {
+			w = 2
+		}
+		x := new(big.Float).SetInt((*big.Int)(z.Approx(k)))
+		x.SetMantExp(x, -k)
+		return scaledFloat((&FloatContext{Prec: uint(w)}).Log((*Float)(x)), n)
+	})
+}
+
+// Cmp compares this to y, returning -1 if this < y and +1 if this > y,
+// which is then proven, or 0 if |this - y| < 2**-tol. For numbers closer
+// than that, it may return 0 or their order.
+func (z *Real) Cmp(y *Real, tol int) int {
+	d := z.Sub(y)
+	// cheap approximations settle most comparisons
+	for m := 0; ; m = 2*m + 8 {
+		if m > tol+2 {
+			m = tol + 2
+		}
+		// |d 2**m - a| < 1, so a != 0 has the sign of d, and a = 0 means
+		// |d| < 2**-m
+		if a := d.Approx(m); a.Sign() != 0 {
+			return a.Sign()
+		}
+		if m == tol+2 {
+			return 0
+		}
+	}
+}
+
+// Float returns this rounded to prec bits, or 64 if prec is 0, within an
+// ulp of this. It does not return if this is 0.
+func (z *Real) Float(prec uint) *Float {
+	if prec == 0 {
+		prec = 64
+	}
+	// with |a| >= 2**(prec+2), the error of a/2**n is less than a quarter of
+	// an ulp, and rounding adds at most half an ulp
+	want := int(prec) + 3
+	for n := want; ; {
+		a := z.Approx(n)
+		l := a.Abs().BitLen()
+		if l >= want {
+			x := new(big.Float).SetInt((*big.Int)(a))
+			return rounded(x.SetMantExp(x, -n), prec, big.ToNearestEven)
+		}
+		n += want - l + 1
+	}
+}
+
+// FloatString returns this in decimal with digits digits after the point,
+// within one unit in the last digit.
+func (z *Real) FloatString(digits int) string {
+	if digits < 0 {
+		digits = 0
+	}
+	// 2**-k < 10**-digits / 8, and rounding adds at most half a unit
+	k := digits*10/3 + 3
+	a := (*big.Int)(z.Approx(k))
+	r := new(big.Rat).SetFrac(a, new(big.Int).Lsh(bigOne, uint(k)))
+	return r.FloatString(digits)
+}
+
+// String returns this with 10 digits after the point, as by FloatString.
+func (z *Real) String() string {
+	return z.FloatString(10)
+}
@@ -0,0 +1,167 @@
+package mathx
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// checkApprox returns whether a is an approximation of x to n bits:
+// |x 2**n - a| < 1.
+func checkApprox(a *Int, x *big.Rat, n int) bool {
+	s := new(big.Rat).SetInt(new(big.Int).Lsh(bigOne, uint(absInt(n))))
+	if n < 0 {
+		s.Inv(s)
+	}
+	d := new(big.Rat).Sub(new(big.Rat).Mul(x, s), new(big.Rat).SetInt((*big.Int)(a)))
+	return d.Abs(d).Cmp(big.NewRat(1, 1)) < 0
+}
+
+func TestRealArithmetic(t *testing.T) {
+	ops := map[string]struct {
+		f     func(x, y *Real) *Real
+		exact func(x, y *big.Rat) *big.Rat
+	}{
+		"Add": {(*Real).Add, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Add(x, y) }},
+		"Sub": {(*Real).Sub, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Sub(x, y) }},
+		"Mul": {(*Real).Mul, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) }},
+		"Quo": {(*Real).Quo, func(x, y *big.Rat) *big.Rat { return new(big.Rat).Quo(x, y) }},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for name, op := range ops {
+		for i := 0; i < 100; i++ {
+			a := big.NewRat(rnd.Int63n(2001)-1000, rnd.Int63n(1000)+1)
+			b := big.NewRat(rnd.Int63n(2001)-1000, rnd.Int63n(1000)+1)
+			if b.Sign() == 0 {
+				continue
+			}
+			got := op.f(RealFromRat((*Rat)(a)), RealFromRat((*Rat)(b)))
+			want := op.exact(a, b)
+			// asking for less precision after more uses the kept approximation
+			for _, n := range []int{-5, 0, 10, 100, 3} {
+				if g := got.Approx(n); !checkApprox(g, want, n) {
+					t.Errorf("%s(%v, %v) to %d bits = %v, expected about %v", name, a, b, n, g, want.FloatString(20))
+				}
+			}
+		}
+	}
+
+	third := RealFromRat(NewRat(1, 3))
+	if got := third.Mul(RealFromInt(NewInt(3))).Cmp(RealFromInt(NewInt(1)), 200); got != 0 {
+		t.Errorf("3 (1/3) <=> 1 = %d", got)
+	}
+	if got := new(Real).Add(RealFromFloat(NewFloat(0.5))).FloatString(3); got != "0.500" {
+		t.Errorf("0 + 0.5 = %s", got)
+	}
+}
+
+func TestRealFunctions(t *testing.T) {
+	two := RealFromInt(NewInt(2))
+	cases := []struct {
+		name string
+		x    *Real
+		f    func(c *FloatContext) *Float
+	}{
+		{"Sqrt(2)", two.Sqrt(), func(c *FloatContext) *Float { return c.Sqrt(NewFloat(2)) }},
+		{"Exp(2)", two.Exp(), func(c *FloatContext) *Float { return c.Exp(NewFloat(2)) }},
+		{"Exp(-2)", two.Neg().Exp(), func(c *FloatContext) *Float { return c.Exp(NewFloat(-2)) }},
+		{"Log(2)", two.Log(), func(c *FloatContext) *Float { return c.Log(NewFloat(2)) }},
+		{"Log(1/1000)", RealFromRat(NewRat(1, 1000)).Log(), func(c *FloatContext) *Float { return c.Neg(c.Log(NewFloat(1000))) }},
+		{"Pi", RealPi(), func(c *FloatContext) *Float { return Pi(c.Prec) }},
+		{"Exp(Sqrt(2))", two.Sqrt().Exp(), func(c *FloatContext) *Float { return c.Exp(c.Sqrt(NewFloat(2).SetPrec(c.Prec + 10))) }},
+	}
+	for _, c := range cases {
+		for _, prec := range []uint{10, 53, 1000} {
+			got := c.x.Float(prec)
+			// the high-precision value is within an ulp of the value at prec
+			want := c.f(&FloatContext{Prec: prec + 100})
+			d := new(Float).SetPrec(prec + 100).Sub(got).Add(want).Abs()
+			if got.Prec() != prec || d.Cmp(got.Ulp()) >= 0 {
+				t.Errorf("%s to %d bits = %v, expected %v", c.name, prec, got, want)
+			}
+		}
+	}
+
+	// Log and Exp are inverse, up to the tolerance
+	x := RealFromRat(NewRat(22, 7))
+	if got := x.Log().Exp().Cmp(x, 500); got != 0 {
+		t.Errorf("Exp(Log(22/7)) <=> 22/7 = %d", got)
+	}
+	if got := two.Sqrt().Mul(two.Sqrt()).Cmp(two, 500); got != 0 {
+		t.Errorf("Sqrt(2)**2 <=> 2 = %d", got)
+	}
+	if got := RealPi().Cmp(RealFromRat(NewRat(355, 113)), 500); got != -1 {
+		t.Errorf("Pi <=> 355/113 = %d", got)
+	}
+	if got := two.Sqrt().Exp().String(); got != "4.1132503788" {
+		t.Errorf("Exp(Sqrt(2)) = %s", got)
+	}
+
+	// e**(2**30) is within the exponent range of Float, and so is the
+	// approximation to about 60 bits
+	big30 := NewFloat(1).SetPrec(100).Mul(NewFloat(1 << 30))
+	e30 := (&FloatContext{Prec: 100}).Exp(big30)
+	_, e := e30.MantExp()
+	n := 60 - e
+	want, _ := new(big.Float).SetMantExp((*big.Float)(e30), n).Rat(nil)
+	if got := RealFromFloat(big30).Exp().Approx(n); !checkApprox(got, want, 0) {
+		t.Errorf("Exp(2**30) to %d bits = %v, expected about %v", n, got, want.FloatString(3))
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrOverflow {
+				t.Errorf("Exp(1.5e9) panicked with %v", r)
+			}
+		}()
+		RealFromInt(NewInt(1.5e9)).Exp().Approx(0)
+		t.Errorf("Exp(1.5e9) did not panic")
+	}()
+
+	for name, f := range map[string]func(){
+		"Sqrt(-1)": func() { RealFromInt(NewInt(-1)).Sqrt().Approx(10) },
+		"Log(-1)":  func() { RealFromInt(NewInt(-1)).Log().Approx(10) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrNaN {
+					t.Errorf("%s panicked with %v", name, r)
+				}
+			}()
+			f()
+			t.Errorf("%s did not panic", name)
+		}()
+	}
+}
+
+func TestRealDigits(t *testing.T) {
+	// 10 or 10000 digits from the same formula
+	pi := RealPi()
+	if got := pi.FloatString(50); got != "3.14159265358979323846264338327950288419716939937511" {
+		t.Errorf("Pi = %s", got)
+	}
+	long := pi.FloatString(10000)
+	if !strings.HasPrefix(long, "3.1415926535897932384626433832795028841971693993751058209") || len(long) != 10002 {
+		t.Errorf("Pi to 10000 digits = %s...", long[:60])
+	}
+	// fewer digits agree, but for the last, which may be rounded differently
+	if !strings.HasPrefix(long, pi.FloatString(9990)[:9990]) {
+		t.Errorf("Pi to 9990 digits disagrees")
+	}
+
+	// a Real is evaluated lazily, and keeps its best approximation
+	calls := 0
+	x := NewReal(func(n int) *Int {
+		calls++
+		return RealFromRat(NewRat(1, 7)).Approx(n)
+	})
+	y := x.Add(x).Mul(x)
+	if calls != 0 {
+		t.Errorf("building 2x**2 evaluated x %d times", calls)
+	}
+	y.FloatString(20)
+	y.FloatString(10)
+	if got := y.FloatString(20); got != "0.04081632653061224490" || calls > 4 {
+		t.Errorf("2 (1/7)**2 = %s, evaluating 1/7 %d times", got, calls)
+	}
+}
@@ -0,0 +1,163 @@
+package mathx
+
+// This file is for database/sql support. Int and Float implement
+// driver.Valuer directly, but cannot implement sql.Scanner: their Scan
+// methods implement fmt.Scanner, and scanning would mutate an immutable
+// value in any case. NullInt and NullFloat are used as scan destinations
+// instead, and work for columns with or without NULLs.
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Value implements driver.Valuer. Integers that fit in an int64 are
+// stored as one; larger integers are stored as decimal strings, which
+// NUMERIC columns accept without loss. A nil *Int is stored as NULL.
+func (z *Int) Value() (driver.Value, error) {
+	if z == nil {
+		return nil, nil
+	}
+	if (*big.Int)(z).IsInt64() {
+		return z.Int64(), nil
+	}
+	return z.String(), nil
+}
+
+// ParseIntSQL returns a new Int from a value read from a database/sql
+// driver: an int64, a float64 with an integer value, or a string or
+// []byte in base 10.
+func ParseIntSQL(src interface{}) (*Int, error) {
+	switch v := src.(type) {
+	case int64:
+		return NewInt(v), nil
+	case float64:
+		f := big.NewFloat(v)
+		if !f.IsInt() {
+			return nil, fmt.Errorf("mathx: cannot scan non-integer %v into Int", v)
+		}
+		i, _ := f.Int(nil)
+		return (*Int)(i), nil
+	case []byte:
+		return parseIntSQLText(string(v))
+	case string:
+		return parseIntSQLText(v)
+	}
+	return nil, fmt.Errorf("mathx: cannot scan %T into Int", src)
+}
+
+func parseIntSQLText(s string) (*Int, error) {
+	x, ok := NewIntFromString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("mathx: cannot scan %q into Int", s)
+	}
+	return x, nil
+}
+
+// NullInt is an Int that may be NULL, for use with database/sql. It
+// implements sql.Scanner, replacing (never mutating) the held Int, and
+// driver.Valuer.
+type NullInt struct {
+	Int   *Int
+	Valid bool // Valid is true if Int is not NULL
+}
+
+// Scan implements sql.Scanner.
+func (n *NullInt) Scan(src interface{}) error {
+	if src == nil {
+		n.Int, n.Valid = nil, false
+		return nil
+	}
+	x, err := ParseIntSQL(src)
+	if err != nil {
+		return err
+	}
+	n.Int, n.Valid = x, true
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n NullInt) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Int.Value()
+}
+
+// Value implements driver.Valuer. The Float is stored as the shortest
+// decimal string that reads back to the same value at its precision.
+// Infinities cannot be stored, and give ErrOverflow. A nil *Float is
+// stored as NULL.
+func (z *Float) Value() (driver.Value, error) {
+	if z == nil {
+		return nil, nil
+	}
+	if z.IsInf() {
+		return nil, ErrOverflow
+	}
+	return z.Text('g', -1), nil
+}
+
+// ParseFloatSQL returns a new Float from a value read from a database/sql
+// driver: an int64, a float64 (other than NaN, for which it returns
+// ErrNaN), or a decimal string or []byte. Strings are
+// read with at least 64 bits, and with more for long strings, so that no
+// stored digits are dropped. A Float stored with Value and read back
+// rounds (with SetPrec) to exactly the original.
+func ParseFloatSQL(src interface{}) (*Float, error) {
+	switch v := src.(type) {
+	case int64:
+		return FloatFromInt64(v), nil
+	case float64:
+		if math.IsNaN(v) {
+			return nil, ErrNaN
+		}
+		return NewFloat(v), nil
+	case []byte:
+		return parseFloatSQLText(string(v))
+	case string:
+		return parseFloatSQLText(v)
+	}
+	return nil, fmt.Errorf("mathx: cannot scan %T into Float", src)
+}
+
+func parseFloatSQLText(s string) (*Float, error) {
+	f, _, err := ParseFloat(s, 10, textPrec(len(s)), big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("mathx: cannot scan %s into Float: %v", strconv.Quote(s), err)
+	}
+	return f, nil
+}
+
+// NullFloat is a Float that may be NULL, for use with database/sql. It
+// implements sql.Scanner, replacing (never mutating) the held Float, and
+// driver.Valuer.
+type NullFloat struct {
+	Float *Float
+	Valid bool // Valid is true if Float is not NULL
+}
+
+// Scan implements sql.Scanner.
+func (n *NullFloat) Scan(src interface{}) error {
+	if src == nil {
+		n.Float, n.Valid = nil, false
+		return nil
+	}
+	x, err := ParseFloatSQL(src)
+	if err != nil {
+		return err
+	}
+	n.Float, n.Valid = x, true
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (n NullFloat) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Float.Value()
+}
@@ -0,0 +1,217 @@
+package mathx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// fakeDriver is a tiny in-process database/sql driver with one table per
+// DSN. Every Exec appends its arguments as a row, and every Query returns
+// all of the rows.
+type fakeDriver struct {
+	tables map[string]*[][]driver.Value
+}
+
+type fakeConn struct{ table *[][]driver.Value }
+type fakeStmt struct{ conn *fakeConn }
+
+type fakeRows struct {
+	rows [][]driver.Value
+	i    int
+}
+
+var fakeDB = &fakeDriver{tables: make(map[string]*[][]driver.Value)}
+
+func init() {
+	sql.Register("mathxfake", fakeDB)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	if d.tables[name] == nil {
+		d.tables[name] = new([][]driver.Value)
+	}
+	return &fakeConn{d.tables[name]}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	*s.conn.table = append(*s.conn.table, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{rows: *s.conn.table}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	cols := make([]string, len(r.rows[0]))
+	for i := range cols {
+		cols[i] = string(rune('a' + i))
+	}
+	return cols
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+func openFakeDB(t *testing.T, name string) *sql.DB {
+	db, err := sql.Open("mathxfake", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSQLIntRoundTrip(t *testing.T) {
+	db := openFakeDB(t, t.Name())
+	defer db.Close()
+
+	huge, _ := NewIntFromString("-93845895110924997939619620205961794350920309182366517272043413179685324014761", 10)
+	values := []*Int{NewInt(0), NewInt(-12), NewInt(1).Lsh(63).Sub64(1), NewInt(1).Lsh(63), huge}
+	for _, v := range values {
+		if _, err := db.Exec("INSERT", v, NullInt{v, true}, NullInt{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// raw []byte and float64 sources, as some drivers produce them
+	if _, err := db.Exec("INSERT", []byte("123456789012345678901234567890"), 42.0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	i := 0
+	for ; rows.Next(); i++ {
+		var a, b, c NullInt
+		if err := rows.Scan(&a, &b, &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Valid || c.Int != nil {
+			t.Errorf("row %d: NULL scanned as %v", i, c.Int)
+		}
+		if i == len(values) {
+			if a.Int.String() != "123456789012345678901234567890" || b.Int.Int64() != 42 {
+				t.Errorf("row %d: scanned %v, %v", i, a.Int, b.Int)
+			}
+			continue
+		}
+		if !a.Valid || a.Int.Cmp(values[i]) != 0 || !b.Valid || b.Int.Cmp(values[i]) != 0 {
+			t.Errorf("row %d: scanned %v, %v, expected %v", i, a.Int, b.Int, values[i])
+		}
+	}
+	if i != len(values)+1 {
+		t.Errorf("scanned %d rows", i)
+	}
+
+	var n NullInt
+	if err := n.Scan(1.5); err == nil {
+		t.Errorf("scanning 1.5 into NullInt should fail")
+	}
+	if err := n.Scan("twelve"); err == nil {
+		t.Errorf("scanning \"twelve\" into NullInt should fail")
+	}
+	if err := n.Scan(true); err == nil {
+		t.Errorf("scanning a bool into NullInt should fail")
+	}
+}
+
+func TestSQLFloatRoundTrip(t *testing.T) {
+	db := openFakeDB(t, t.Name())
+	defer db.Close()
+
+	rnd := rand.New(rand.NewSource(1))
+	var values []*Float
+	for _, prec := range []uint{24, 53, 64, 200, 1000} {
+		for i := 0; i < 20; i++ {
+			f := new(big.Float).SetPrec(prec).SetInt64(rnd.Int63())
+			f.Quo(f, new(big.Float).SetInt64(rnd.Int63n(1000000)+1))
+			f.SetMantExp(f, rnd.Intn(2000)-1000)
+			values = append(values, (*Float)(f))
+		}
+	}
+	for _, v := range values {
+		if _, err := db.Exec("INSERT", v, NullFloat{v, true}, NullFloat{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for i := 0; rows.Next(); i++ {
+		var a, b, c NullFloat
+		if err := rows.Scan(&a, &b, &c); err != nil {
+			t.Fatal(err)
+		}
+		if c.Valid {
+			t.Errorf("row %d: NULL scanned as %v", i, c.Float)
+		}
+		for _, x := range []*Float{a.Float, b.Float} {
+			if got := x.SetPrec(values[i].Prec()); got.Cmp(values[i]) != 0 {
+				t.Errorf("row %d: scanned %v, expected %v", i, got, values[i])
+			}
+		}
+	}
+
+	inf := (*Float)(new(big.Float).SetInf(false))
+	if _, err := inf.Value(); err != ErrOverflow {
+		t.Errorf("Value(+Inf) gave %v", err)
+	}
+
+	// a driver can hand back a NaN, which no Float holds
+	nan := openFakeDB(t, t.Name()+"NaN")
+	defer nan.Close()
+	if _, err := nan.Exec("INSERT", math.NaN()); err != nil {
+		t.Fatal(err)
+	}
+	var n NullFloat
+	if err := nan.QueryRow("SELECT").Scan(&n); !errors.Is(err, ErrNaN) {
+		t.Errorf("scanning NaN gave %v, %v", n.Float, err)
+	}
+}
+
+func TestSQLNilValues(t *testing.T) {
+	db := openFakeDB(t, t.Name())
+	defer db.Close()
+
+	// database/sql only turns nil pointers into NULL for value receivers,
+	// so Value itself must handle a nil *Int or *Float
+	if _, err := db.Exec("INSERT", (*Int)(nil), (*Float)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	var a NullInt
+	var b NullFloat
+	if err := db.QueryRow("SELECT").Scan(&a, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a.Valid || b.Valid {
+		t.Errorf("nil values were stored as %v, %v", a.Int, b.Float)
+	}
+}